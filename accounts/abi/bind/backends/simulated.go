@@ -936,6 +936,10 @@ func (fb *filterBackend) LastAcceptedBlock() *types.Block {
 	return fb.bc.LastAcceptedBlock()
 }
 
+func (fb *filterBackend) HistoryTail() uint64 {
+	return fb.bc.HistoryTail()
+}
+
 func (fb *filterBackend) GetMaxBlocksPerRequest() int64 {
 	return eth.DefaultSettings.MaxBlocksPerRequest
 }
@@ -1008,6 +1012,8 @@ func (fb *filterBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event
 
 func (fb *filterBackend) BloomStatus() (uint64, uint64) { return 4096, 0 }
 
+func (fb *filterBackend) LogIndexStatus() *core.LogIndexStatus { return nil }
+
 func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.MatcherSession) {
 	panic("not supported")
 }
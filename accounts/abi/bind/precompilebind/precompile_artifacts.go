@@ -0,0 +1,292 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompilebind
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+)
+
+// Paths of the artifacts generated by PrecompileArtifacts, relative to the
+// root of the repository. "%s" is replaced by the snake case type of the
+// precompile, and "%S" by its type.
+const (
+	SolidityInterfacePath = "contracts/contracts/interfaces/I%S.sol"
+	SolidityTestPath      = "contracts/contracts/test/Example%STest.sol"
+	HardhatTestPath       = "contracts/test/%s.ts"
+	GenesisPath           = "tests/precompile/genesis/%s.json"
+
+	// UpgradeFileName is the name of the upgrade JSON skeleton generated by
+	// PrecompileBind next to the precompile files.
+	UpgradeFileName = "upgrade.json"
+)
+
+// tmplArtifactData is the data structure required to fill the templates of the
+// precompile artifacts, which are not Go sources.
+type tmplArtifactData struct {
+	Package   string
+	Type      string // Type of the precompile, e.g. HelloWorld
+	SnakeType string // Snake case type of the precompile, e.g. hello_world
+	ConfigKey string
+	AllowList bool
+	Funcs     []*tmplSolidityFunc
+	Events    []*tmplSolidityEvent
+	Structs   []*tmplSolidityStruct
+}
+
+// tmplSolidityFunc is a function of the Solidity interface of a precompile.
+type tmplSolidityFunc struct {
+	Name       string
+	Inputs     string // Comma separated parameter declarations
+	Outputs    string // Comma separated return declarations
+	Mutability string // State mutability modifier, empty for non-payable functions
+}
+
+// tmplSolidityEvent is an event of the Solidity interface of a precompile.
+type tmplSolidityEvent struct {
+	Name   string
+	Inputs string // Comma separated parameter declarations
+}
+
+// tmplSolidityStruct is a struct declared by the Solidity interface of a
+// precompile, for the tuples of its functions and events.
+type tmplSolidityStruct struct {
+	Name   string
+	Fields []string // Field declarations
+}
+
+// PrecompileArtifacts generates the Solidity interface, the Hardhat test
+// scaffolding and the genesis of the e2e tests of a precompile from its ABI.
+// The names of the returned files are paths relative to the root of the
+// repository.
+func PrecompileArtifacts(typ string, abiData string, pkg string) ([]PrecompileBindFile, error) {
+	data, err := newArtifactData(typ, abiData, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := []struct {
+		path     string
+		template string
+		isTest   bool
+	}{
+		{SolidityInterfacePath, tmplSourcePrecompileSolidityInterface, false},
+		{SolidityTestPath, tmplSourcePrecompileSolidityTest, true},
+		{HardhatTestPath, tmplSourcePrecompileHardhatTest, true},
+		{GenesisPath, tmplSourcePrecompileGenesis, true},
+	}
+	result := make([]PrecompileBindFile, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		content, err := renderArtifact(artifact.template, data)
+		if err != nil {
+			return nil, err
+		}
+		fileName := strings.NewReplacer("%s", data.SnakeType, "%S", data.Type).Replace(artifact.path)
+		result = append(result, NewPrecompileBindFile(fileName, content, artifact.isTest))
+	}
+	return result, nil
+}
+
+// precompileUpgrade generates the upgrade JSON skeleton of a precompile.
+func precompileUpgrade(typ string, abiData string, pkg string) (PrecompileBindFile, error) {
+	data, err := newArtifactData(typ, abiData, pkg)
+	if err != nil {
+		return PrecompileBindFile{}, err
+	}
+	content, err := renderArtifact(tmplSourcePrecompileUpgrade, data)
+	if err != nil {
+		return PrecompileBindFile{}, err
+	}
+	return NewPrecompileBindFile(UpgradeFileName, content, false), nil
+}
+
+// ModuleRegistration returns the import line registering the precompile
+// package at [importPath] in precompile/registry/registry.go.
+func ModuleRegistration(importPath string) string {
+	return fmt.Sprintf("\t_ %q", path.Clean(importPath))
+}
+
+func renderArtifact(source string, data *tmplArtifactData) (string, error) {
+	funcs := template.FuncMap{
+		"decapitalise": decapitaliseFirst,
+		"upper":        strings.ToUpper,
+	}
+	tmpl, err := template.New("").Funcs(funcs).Parse(source)
+	if err != nil {
+		return "", err
+	}
+	buffer := new(bytes.Buffer)
+	if err := tmpl.Execute(buffer, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func newArtifactData(typ string, abiData string, pkg string) (*tmplArtifactData, error) {
+	evmABI, err := abi.JSON(strings.NewReader(abiData))
+	if err != nil {
+		return nil, err
+	}
+	data := &tmplArtifactData{
+		Package:   pkg,
+		Type:      typ,
+		SnakeType: toSnakeCase(typ),
+		ConfigKey: decapitaliseFirst(typ) + "Config",
+		AllowList: true,
+	}
+	for name := range allowlist.AllowListABI.Methods {
+		if _, ok := evmABI.Methods[name]; !ok {
+			data.AllowList = false
+		}
+	}
+
+	structs := newSolidityStructs(typ)
+	methodNames := make([]string, 0, len(evmABI.Methods))
+	for name := range evmABI.Methods {
+		if _, ok := allowlist.AllowListABI.Methods[name]; ok && data.AllowList {
+			// The functions of the allow list are inherited from IAllowList.
+			continue
+		}
+		methodNames = append(methodNames, name)
+	}
+	slices.Sort(methodNames)
+	for _, name := range methodNames {
+		method := evmABI.Methods[name]
+		fn := &tmplSolidityFunc{
+			Name:    method.RawName,
+			Inputs:  structs.args(method.Inputs, "calldata", false),
+			Outputs: structs.args(method.Outputs, "memory", false),
+		}
+		switch method.StateMutability {
+		case "view", "pure", "payable":
+			fn.Mutability = method.StateMutability
+		}
+		data.Funcs = append(data.Funcs, fn)
+	}
+
+	eventNames := make([]string, 0, len(evmABI.Events))
+	for name := range evmABI.Events {
+		if _, ok := allowlist.AllowListABI.Events[name]; ok && data.AllowList {
+			continue
+		}
+		eventNames = append(eventNames, name)
+	}
+	slices.Sort(eventNames)
+	for _, name := range eventNames {
+		event := evmABI.Events[name]
+		data.Events = append(data.Events, &tmplSolidityEvent{
+			Name:   event.RawName,
+			Inputs: structs.args(event.Inputs, "", true),
+		})
+	}
+	data.Structs = structs.list
+	return data, nil
+}
+
+// solidityStructs collects the structs declared for the tuples of a precompile
+// interface.
+type solidityStructs struct {
+	typ    string
+	list   []*tmplSolidityStruct
+	byName map[string]bool
+}
+
+func newSolidityStructs(typ string) *solidityStructs {
+	return &solidityStructs{typ: typ, byName: make(map[string]bool)}
+}
+
+// args returns the comma separated declarations of [args]. Reference types
+// are declared in [location], if not empty.
+func (s *solidityStructs) args(args abi.Arguments, location string, event bool) string {
+	decls := make([]string, 0, len(args))
+	for _, arg := range args {
+		decl := s.solidityType(arg.Type)
+		if location != "" && isReferenceType(arg.Type) {
+			decl += " " + location
+		}
+		if event && arg.Indexed {
+			decl += " indexed"
+		}
+		if arg.Name != "" {
+			decl += " " + arg.Name
+		}
+		decls = append(decls, decl)
+	}
+	return strings.Join(decls, ", ")
+}
+
+// solidityType returns the Solidity type of [t], declaring the structs of its
+// tuples.
+func (s *solidityStructs) solidityType(t abi.Type) string {
+	switch t.T {
+	case abi.SliceTy:
+		return s.solidityType(*t.Elem) + "[]"
+	case abi.ArrayTy:
+		return fmt.Sprintf("%s[%d]", s.solidityType(*t.Elem), t.Size)
+	case abi.TupleTy:
+		// Tuple names are prefixed by the interface declaring them.
+		name := strings.TrimPrefix(strings.TrimPrefix(t.TupleRawName, "I"+s.typ), s.typ)
+		if name == "" {
+			name = fmt.Sprintf("Struct%d", len(s.list))
+		}
+		if !s.byName[name] {
+			s.byName[name] = true
+			st := &tmplSolidityStruct{Name: name}
+			s.list = append(s.list, st)
+			for i, elem := range t.TupleElems {
+				st.Fields = append(st.Fields, fmt.Sprintf("%s %s", s.solidityType(*elem), t.TupleRawNames[i]))
+			}
+		}
+		return name
+	default:
+		return t.String()
+	}
+}
+
+// isReferenceType returns true if [t] must be declared with a data location.
+func isReferenceType(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	default:
+		return false
+	}
+}
+
+// toSnakeCase converts a camel case [input] to snake case, e.g. HelloWorld to
+// hello_world.
+func toSnakeCase(input string) string {
+	var b strings.Builder
+	runes := []rune(input)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word before an uppercase letter following a
+			// lowercase letter, or starting a word after an acronym.
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// decapitaliseFirst converts [input] to camel case with a lowercase first
+// letter, like the decapitalise function of the Go templates.
+func decapitaliseFirst(input string) string {
+	if input == "" {
+		return input
+	}
+	camel := abi.ToCamelCase(input)
+	return strings.ToLower(camel[:1]) + camel[1:]
+}
@@ -0,0 +1,76 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompilebind
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/stretchr/testify/require"
+)
+
+const helloWorldABI = `[
+	{"type":"function","name":"sayHello","stateMutability":"view","inputs":[],"outputs":[{"name":"result","type":"string"}]},
+	{"type":"function","name":"setGreeting","stateMutability":"nonpayable","inputs":[{"name":"response","type":"string"}],"outputs":[]},
+	{"type":"event","name":"GreetingChanged","anonymous":false,"inputs":[{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"greeting","type":"string"}]}
+]`
+
+func TestPrecompileArtifacts(t *testing.T) {
+	require := require.New(t)
+
+	files, err := PrecompileArtifacts("HelloWorld", helloWorldABI, "helloworld")
+	require.NoError(err)
+
+	contents := make(map[string]string)
+	for _, file := range files {
+		require.Equal(file.FileName != "contracts/contracts/interfaces/IHelloWorld.sol", file.IsTest, file.FileName)
+		contents[file.FileName] = file.Content
+	}
+	require.Len(contents, 4)
+
+	iface := contents["contracts/contracts/interfaces/IHelloWorld.sol"]
+	require.Contains(iface, "interface IHelloWorld {")
+	require.Contains(iface, "event GreetingChanged(address indexed sender, string greeting);")
+	require.Contains(iface, "function sayHello() external view returns (string memory result);")
+	require.Contains(iface, "function setGreeting(string calldata response) external;")
+
+	require.Contains(contents["contracts/contracts/test/ExampleHelloWorldTest.sol"], "function step_setGreeting() public {")
+	require.Contains(contents["contracts/test/hello_world.ts"], `test("should call sayHello", "step_sayHello")`)
+
+	var genesis map[string]any
+	require.NoError(json.Unmarshal([]byte(contents["tests/precompile/genesis/hello_world.json"]), &genesis))
+	require.Contains(genesis["config"], "helloWorldConfig")
+}
+
+func TestPrecompileArtifactsAllowList(t *testing.T) {
+	require := require.New(t)
+
+	files, err := PrecompileArtifacts("HelloWorld", allowlist.AllowListRawABI, "helloworld")
+	require.NoError(err)
+	require.Contains(files[0].Content, "interface IHelloWorld is IAllowList {")
+	require.NotContains(files[0].Content, "function setAdmin")
+
+	upgrade, err := precompileUpgrade("HelloWorld", allowlist.AllowListRawABI, "helloworld")
+	require.NoError(err)
+	require.Equal(UpgradeFileName, upgrade.FileName)
+
+	var parsed struct {
+		PrecompileUpgrades []map[string]map[string]any `json:"precompileUpgrades"`
+	}
+	require.NoError(json.Unmarshal([]byte(upgrade.Content), &parsed))
+	require.Len(parsed.PrecompileUpgrades, 1)
+	require.Contains(parsed.PrecompileUpgrades[0]["helloWorldConfig"], "adminAddresses")
+}
+
+func TestToSnakeCase(t *testing.T) {
+	for input, expected := range map[string]string{
+		"HelloWorld":     "hello_world",
+		"IRewardManager": "i_reward_manager",
+		"NativeMinter":   "native_minter",
+		"ABCToken":       "abc_token",
+	} {
+		require.Equal(t, expected, toSnakeCase(input), input)
+	}
+}
@@ -33,6 +33,7 @@ package precompilebind
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/ava-labs/subnet-evm/accounts/abi"
@@ -108,6 +109,12 @@ func PrecompileBind(types []string, abiData string, bytecodes []string, fsigs []
 	result = append(result, NewPrecompileBindFile(ModuleFileName, moduleBind, false))
 	result = append(result, NewPrecompileBindFile(EventFileName, eventBind, false))
 
+	upgradeFile, err := precompileUpgrade(types[0], abiData, pkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate upgrade skeleton: %w", err)
+	}
+	result = append(result, upgradeFile)
+
 	if generateTests {
 		configTestBind, err := bind.BindHelper(types, abis, bytecodes, fsigs, pkg, lang, libs, aliases, configTestHook)
 		if err != nil {
@@ -176,8 +183,14 @@ func createPrecompileHook(abifilename string, template string) bind.BindHook {
 	}
 }
 
+// allowListEnabled returns true if [funcs] contains the allow list functions.
+// The enumeration functions are optional, so that ABIs written before they
+// were added are still detected.
 func allowListEnabled(funcs map[string]*bind.TmplMethod) bool {
 	for key := range allowlist.AllowListABI.Methods {
+		if slices.Contains(allowlist.EnumerableMembersFunctions, key) {
+			continue
+		}
 		if _, ok := funcs[key]; !ok {
 			return false
 		}
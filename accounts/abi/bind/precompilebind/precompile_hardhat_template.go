@@ -0,0 +1,46 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package precompilebind
+
+// tmplSourcePrecompileHardhatTest is the Hardhat test template of a
+// precompile, running the steps of its DS-Test contract.
+const tmplSourcePrecompileHardhatTest = `// Code generated
+// This file is a generated Hardhat test with the skeleton of test cases.
+// The file is generated by a template. Please inspect every code and comment in this file before use.
+
+import { ethers } from "hardhat"
+import { test } from "./utils"
+
+// make sure this is always an admin for the precompile in tests/precompile/genesis/{{.SnakeType}}.json
+const ADMIN_ADDRESS = "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"
+{{- if .AllowList}}
+// SET THE ADDRESS OF THE PRECOMPILE HERE, as set in module.go
+const {{upper .SnakeType}}_ADDRESS = "{ASUITABLEHEXADDRESS}"
+{{- end}}
+
+describe("Example{{.Type}}Test", function () {
+  this.timeout("30s")
+
+  beforeEach("Setup DS-Test contract", async function () {
+    const signer = await ethers.getSigner(ADMIN_ADDRESS)
+    const factory = await ethers.getContractFactory("Example{{.Type}}Test", signer)
+    const contract = await factory.deploy()
+    await contract.waitForDeployment()
+    this.testContract = contract
+    {{- if .AllowList}}
+
+    // Allow the test contract to call the precompile
+    const precompile = await ethers.getContractAt("I{{.Type}}", {{upper .SnakeType}}_ADDRESS, signer)
+    const tx = await precompile.setAdmin(contract.target)
+    await tx.wait()
+    {{- end}}
+
+    const setUp = await contract.setUp()
+    await setUp.wait()
+  })
+{{range .Funcs}}
+  // CUSTOM CODE STARTS HERE
+  test("should call {{.Name}}", "step_{{.Name}}")
+{{end -}}
+})
+`
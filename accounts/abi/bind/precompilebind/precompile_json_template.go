@@ -0,0 +1,71 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package precompilebind
+
+// tmplSourcePrecompileGenesis is the genesis template of the e2e tests of a
+// precompile, enabling the precompile at genesis.
+const tmplSourcePrecompileGenesis = `{
+  "config": {
+    "chainId": 99999,
+    "homesteadBlock": 0,
+    "eip150Block": 0,
+    "eip155Block": 0,
+    "eip158Block": 0,
+    "byzantiumBlock": 0,
+    "constantinopleBlock": 0,
+    "petersburgBlock": 0,
+    "istanbulBlock": 0,
+    "muirGlacierBlock": 0,
+    "feeConfig": {
+      "gasLimit": 20000000,
+      "minBaseFee": 1000000000,
+      "targetGas": 100000000,
+      "baseFeeChangeDenominator": 48,
+      "minBlockGasCost": 0,
+      "maxBlockGasCost": 10000000,
+      "targetBlockRate": 2,
+      "blockGasCostStep": 500000
+    },
+    "{{.ConfigKey}}": {
+      "blockTimestamp": 0{{if .AllowList}},
+      "adminAddresses": [
+        "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"
+      ]{{end}}
+    }
+  },
+  "alloc": {
+    "8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC": {
+      "balance": "0x52B7D2DCC80CD2E4000000"
+    },
+    "0x0Fa8EA536Be85F32724D57A37758761B86416123": {
+      "balance": "0x52B7D2DCC80CD2E4000000"
+    }
+  },
+  "nonce": "0x0",
+  "timestamp": "0x5FCB13D0",
+  "extraData": "0x00",
+  "gasLimit": "0x1312D00",
+  "difficulty": "0x0",
+  "mixHash": "0x0000000000000000000000000000000000000000000000000000000000000000",
+  "coinbase": "0x0000000000000000000000000000000000000000",
+  "number": "0x0",
+  "gasUsed": "0x0",
+  "parentHash": "0x0000000000000000000000000000000000000000000000000000000000000000"
+}
+`
+
+// tmplSourcePrecompileUpgrade is the upgrade.json template of a precompile,
+// enabling the precompile with a network upgrade.
+const tmplSourcePrecompileUpgrade = `{
+  "precompileUpgrades": [
+    {
+      "{{.ConfigKey}}": {
+        "blockTimestamp": 0{{if .AllowList}},
+        "adminAddresses": [],
+        "managerAddresses": [],
+        "enabledAddresses": []{{end}}
+      }
+    }
+  ]
+}
+`
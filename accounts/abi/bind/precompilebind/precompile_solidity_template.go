@@ -0,0 +1,64 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package precompilebind
+
+// tmplSourcePrecompileSolidityInterface is the Solidity interface template of
+// a precompile.
+const tmplSourcePrecompileSolidityInterface = `//SPDX-License-Identifier: MIT
+// Code generated
+// This file is a generated Solidity interface of the precompile.
+// The file is generated by a template. Please inspect every code and comment in this file before use.
+pragma solidity ^0.8.24;
+{{- if .AllowList}}
+import "./IAllowList.sol";
+{{- end}}
+
+interface I{{.Type}}{{if .AllowList}} is IAllowList{{end}} {
+{{- range .Structs}}
+  struct {{.Name}} {
+  {{- range .Fields}}
+    {{.}};
+  {{- end}}
+  }
+{{ end}}
+{{- range .Events}}
+  event {{.Name}}({{.Inputs}});
+{{ end}}
+{{- range .Funcs}}
+  function {{.Name}}({{.Inputs}}) external{{if .Mutability}} {{.Mutability}}{{end}}{{if .Outputs}} returns ({{.Outputs}}){{end}};
+{{ end -}}
+}
+`
+
+// tmplSourcePrecompileSolidityTest is the DS-Test contract template of a
+// precompile, driven by the Hardhat test of the precompile.
+const tmplSourcePrecompileSolidityTest = `//SPDX-License-Identifier: MIT
+// Code generated
+// This file is a generated DS-Test contract with the skeleton of test steps.
+// The file is generated by a template. Please inspect every code and comment in this file before use.
+pragma solidity ^0.8.24;
+
+import "../interfaces/I{{.Type}}.sol";
+{{- if .AllowList}}
+import "./AllowListTest.sol";
+{{- else}}
+import "ds-test/src/test.sol";
+{{- end}}
+
+// SET THE ADDRESS OF THE PRECOMPILE HERE, as set in module.go
+address constant {{upper .SnakeType}}_ADDRESS = {ASUITABLEHEXADDRESS};
+
+contract Example{{.Type}}Test is {{if .AllowList}}AllowListTest{{else}}DSTest{{end}} {
+  I{{.Type}} {{decapitalise .Type}} = I{{.Type}}({{upper .SnakeType}}_ADDRESS);
+
+  function setUp() public {
+    // CUSTOM CODE STARTS HERE
+  }
+{{range .Funcs}}
+  function step_{{.Name}}() public {
+    // CUSTOM CODE STARTS HERE
+    // Call {{decapitalise $.Type}}.{{.Name}} and assert on its results.
+  }
+{{end -}}
+}
+`
@@ -30,6 +30,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -64,6 +66,19 @@ var (
 		Name:  "out",
 		Usage: "Output folder for the generated precompile files, - for STDOUT (default = ./precompile/contracts/{pkg}). Test files won't be generated if STDOUT is used",
 	}
+	artifactsOutFlag = &cli.StringFlag{
+		Name:  "artifacts-out",
+		Usage: "Root folder of the repository to generate the Solidity interface, Hardhat tests and genesis into",
+		Value: ".",
+	}
+	forceFlag = &cli.BoolFlag{
+		Name:  "force",
+		Usage: "Overwrite existing Solidity interface, Hardhat test and genesis files",
+	}
+	moduleRegistrationFlag = &cli.BoolFlag{
+		Name:  "module-registration",
+		Usage: "Print the import line registering the precompile module in precompile/registry",
+	}
 )
 
 var app = flags.NewApp("subnet-evm precompile generator tool")
@@ -72,6 +87,9 @@ func init() {
 	app.Name = "precompilegen"
 	app.Flags = []cli.Flag{
 		abiFlag,
+		artifactsOutFlag,
+		forceFlag,
+		moduleRegistrationFlag,
 		outFlag,
 		pkgFlag,
 		typeFlag,
@@ -150,6 +168,12 @@ func precompilegen(c *cli.Context) error {
 		utils.Fatalf("Failed to generate precompile: %v", err)
 	}
 
+	// Generate the Solidity interface, Hardhat tests and e2e genesis
+	artifacts, err := precompilebind.PrecompileArtifacts(kind, string(abi), pkg)
+	if err != nil {
+		utils.Fatalf("Failed to generate precompile artifacts: %v", err)
+	}
+
 	// Either flush it out to a file or display on the standard output
 	// Skip displaying test codes here.
 	if isOutStdout {
@@ -159,9 +183,41 @@ func precompilegen(c *cli.Context) error {
 				fmt.Printf("%s\n", file.Content)
 			}
 		}
+		for _, file := range artifacts {
+			if !file.IsTest {
+				fmt.Printf("-----file: %s-----\n", file.FileName)
+				fmt.Printf("%s\n", file.Content)
+			}
+		}
 		return nil
 	}
 
+	// Determine the import path before writing anything, so that an output
+	// folder outside of the module is rejected up front.
+	var importPath string
+	if c.Bool(moduleRegistrationFlag.Name) {
+		importPath, err = moduleImportPath(outFlagStr)
+		if err != nil {
+			utils.Fatalf("Failed to determine import path of %s: %v", outFlagStr, err)
+		}
+	}
+
+	// Refuse to overwrite existing artifacts, which are likely to have been
+	// edited since they were generated.
+	artifactsOut := c.String(artifactsOutFlag.Name)
+	if !c.Bool(forceFlag.Name) {
+		var existing []string
+		for _, file := range artifacts {
+			outputPath := filepath.Join(artifactsOut, file.FileName)
+			if _, err := os.Stat(outputPath); err == nil {
+				existing = append(existing, outputPath)
+			}
+		}
+		if len(existing) > 0 {
+			utils.Fatalf("Refusing to overwrite existing files (use --%s): %s", forceFlag.Name, strings.Join(existing, ", "))
+		}
+	}
+
 	if _, err := os.Stat(outFlagStr); os.IsNotExist(err) {
 		os.MkdirAll(outFlagStr, 0o700) // Create your file
 	}
@@ -184,10 +240,58 @@ func precompilegen(c *cli.Context) error {
 		utils.Fatalf("Failed to write README: %v", err)
 	}
 
+	for _, file := range artifacts {
+		outputPath := filepath.Join(artifactsOut, file.FileName)
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+			utils.Fatalf("Failed to create folder for %s: %v", file.FileName, err)
+		}
+		if err := os.WriteFile(outputPath, []byte(file.Content), 0o600); err != nil {
+			utils.Fatalf("Failed to write generated file %s: %v", file.FileName, err)
+		}
+	}
+
 	fmt.Println("Precompile files generated successfully at: ", outFlagStr)
+	fmt.Println("Precompile artifacts generated successfully at: ", artifactsOut)
+
+	if c.Bool(moduleRegistrationFlag.Name) {
+		fmt.Println("Add the following import to precompile/registry/registry.go:")
+		fmt.Println(precompilebind.ModuleRegistration(importPath))
+	}
 	return nil
 }
 
+// moduleImportPath returns the Go import path of the precompile package
+// generated into [out], which must be inside the module of the current
+// directory.
+func moduleImportPath(out string) (string, error) {
+	output, err := exec.Command("go", "list", "-m", "-f", "{{.Path}}\n{{.Dir}}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find module: %w", err)
+	}
+	module := strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)
+	if len(module) != 2 {
+		return "", fmt.Errorf("unexpected module %q", output)
+	}
+	return importPath(module[0], module[1], out)
+}
+
+// importPath returns the Go import path of the package in [dir], which must be
+// inside the module [modulePath] rooted at [moduleDir].
+func importPath(modulePath, moduleDir, dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(moduleDir, absDir)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of module %s at %s", dir, modulePath, moduleDir)
+	}
+	return path.Join(modulePath, filepath.ToSlash(rel)), nil
+}
+
 func main() {
 	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LevelInfo, true)))
 
@@ -0,0 +1,48 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportPath(t *testing.T) {
+	moduleDir := t.TempDir()
+	tests := map[string]struct {
+		dir      string
+		expected string
+		err      bool
+	}{
+		"relative": {
+			dir:      filepath.Join(moduleDir, "precompile", "contracts", "foo"),
+			expected: "github.com/ava-labs/subnet-evm/precompile/contracts/foo",
+		},
+		"module root": {
+			dir:      moduleDir,
+			expected: "github.com/ava-labs/subnet-evm",
+		},
+		"outside module": {
+			dir: filepath.Join(filepath.Dir(moduleDir), "foo"),
+			err: true,
+		},
+		"sibling with module prefix": {
+			dir: moduleDir + "-foo",
+			err: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := importPath("github.com/ava-labs/subnet-evm", moduleDir, test.dir)
+			if test.err {
+				require.ErrorContains(t, err, "outside of module")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, path)
+		})
+	}
+}
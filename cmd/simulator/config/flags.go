@@ -6,9 +6,12 @@ package config
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -29,12 +32,63 @@ const (
 	BatchSizeKey      = "batch-size"
 	MetricsPortKey    = "metrics-port"
 	MetricsOutputKey  = "metrics-output"
+	WorkloadsKey      = "workloads"
+	StorageSlotsKey   = "storage-slots"
+	AllowListKey      = "allow-list-address"
+	WarpPayloadKey    = "warp-payload-size"
+	LoadProfileKey    = "load-profile"
+	TargetTPSKey      = "target-tps"
+	StepDurationKey   = "step-duration"
+	RampStepTPSKey    = "ramp-step-tps"
+	RampStepsKey      = "ramp-steps"
+	SpikeTPSKey       = "spike-tps"
+	SpikeDurationKey  = "spike-duration"
+	DegradationKey    = "latency-degradation-factor"
 )
 
+// Load profiles supported by the simulator. The batch profile issues and
+// confirms [BatchSize] txs at a time, while the others issue txs at a
+// scheduled rate independently of their confirmation.
+const (
+	BatchProfile    = "batch"
+	ConstantProfile = "constant"
+	RampProfile     = "ramp"
+	SpikeProfile    = "spike"
+)
+
+var validProfiles = []string{
+	BatchProfile,
+	ConstantProfile,
+	RampProfile,
+	SpikeProfile,
+}
+
+// Workloads supported by the simulator.
+const (
+	TransferWorkload      = "transfer"
+	ERC20Workload         = "erc20"
+	DeployWorkload        = "deploy"
+	StorageWorkload       = "storage"
+	NativeMinterWorkload  = "nativeminter"
+	AllowListReadWorkload = "allowlist-read"
+	WarpWorkload          = "warp"
+)
+
+var validWorkloads = []string{
+	TransferWorkload,
+	ERC20Workload,
+	DeployWorkload,
+	StorageWorkload,
+	NativeMinterWorkload,
+	AllowListReadWorkload,
+	WarpWorkload,
+}
+
 var (
 	ErrNoEndpoints = errors.New("must specify at least one endpoint")
 	ErrNoWorkers   = errors.New("must specify non-zero number of workers")
 	ErrNoTxs       = errors.New("must specify non-zero number of txs-per-worker")
+	ErrNoWorkloads = errors.New("must specify at least one workload")
 )
 
 type Config struct {
@@ -48,6 +102,24 @@ type Config struct {
 	BatchSize     uint64        `json:"batch-size"`
 	MetricsPort   uint64        `json:"metrics-port"`
 	MetricsOutput string        `json:"metrics-output"`
+	Workloads     []string      `json:"workloads"`
+	StorageSlots  uint64        `json:"storage-slots"`
+	AllowList     string        `json:"allow-list-address"`
+	WarpPayload   int           `json:"warp-payload-size"`
+	LoadProfile   string        `json:"load-profile"`
+	TargetTPS     float64       `json:"target-tps"`
+	StepDuration  time.Duration `json:"step-duration"`
+	RampStepTPS   float64       `json:"ramp-step-tps"`
+	RampSteps     int           `json:"ramp-steps"`
+	SpikeTPS      float64       `json:"spike-tps"`
+	SpikeDuration time.Duration `json:"spike-duration"`
+	Degradation   float64       `json:"latency-degradation-factor"`
+}
+
+// OpenLoop returns true if the load profile issues txs independently of their
+// confirmation.
+func (c Config) OpenLoop() bool {
+	return c.LoadProfile != BatchProfile
 }
 
 func BuildConfig(v *viper.Viper) (Config, error) {
@@ -62,6 +134,18 @@ func BuildConfig(v *viper.Viper) (Config, error) {
 		BatchSize:     v.GetUint64(BatchSizeKey),
 		MetricsPort:   v.GetUint64(MetricsPortKey),
 		MetricsOutput: v.GetString(MetricsOutputKey),
+		Workloads:     v.GetStringSlice(WorkloadsKey),
+		StorageSlots:  v.GetUint64(StorageSlotsKey),
+		AllowList:     v.GetString(AllowListKey),
+		WarpPayload:   v.GetInt(WarpPayloadKey),
+		LoadProfile:   v.GetString(LoadProfileKey),
+		TargetTPS:     v.GetFloat64(TargetTPSKey),
+		StepDuration:  v.GetDuration(StepDurationKey),
+		RampStepTPS:   v.GetFloat64(RampStepTPSKey),
+		RampSteps:     v.GetInt(RampStepsKey),
+		SpikeTPS:      v.GetFloat64(SpikeTPSKey),
+		SpikeDuration: v.GetDuration(SpikeDurationKey),
+		Degradation:   v.GetFloat64(DegradationKey),
 	}
 	if len(c.Endpoints) == 0 {
 		return c, ErrNoEndpoints
@@ -80,6 +164,54 @@ func BuildConfig(v *viper.Viper) (Config, error) {
 	if c.MaxTipCap < 0 {
 		return c, fmt.Errorf("invalid max tip cap %d <= 0", c.MaxTipCap)
 	}
+	if len(c.Workloads) == 0 {
+		return c, ErrNoWorkloads
+	}
+	for _, workload := range c.Workloads {
+		if !slices.Contains(validWorkloads, workload) {
+			return c, fmt.Errorf("invalid workload %q, must be one of %s", workload, strings.Join(validWorkloads, ", "))
+		}
+	}
+	if c.StorageSlots == 0 {
+		return c, fmt.Errorf("invalid storage slots %d, must be > 0", c.StorageSlots)
+	}
+	if !common.IsHexAddress(c.AllowList) {
+		return c, fmt.Errorf("invalid allow list address %q", c.AllowList)
+	}
+	if c.WarpPayload < 0 {
+		return c, fmt.Errorf("invalid warp payload size %d < 0", c.WarpPayload)
+	}
+	if !slices.Contains(validProfiles, c.LoadProfile) {
+		return c, fmt.Errorf("invalid load profile %q, must be one of %s", c.LoadProfile, strings.Join(validProfiles, ", "))
+	}
+	if !c.OpenLoop() {
+		return c, nil
+	}
+	if c.TargetTPS <= 0 {
+		return c, fmt.Errorf("invalid target tps %v, must be > 0", c.TargetTPS)
+	}
+	if c.StepDuration <= 0 {
+		return c, fmt.Errorf("invalid step duration %v, must be > 0", c.StepDuration)
+	}
+	if c.Degradation <= 1 {
+		return c, fmt.Errorf("invalid latency degradation factor %v, must be > 1", c.Degradation)
+	}
+	switch c.LoadProfile {
+	case RampProfile:
+		if c.RampSteps <= 0 {
+			return c, fmt.Errorf("invalid ramp steps %d, must be > 0", c.RampSteps)
+		}
+		if c.RampStepTPS < 0 {
+			return c, fmt.Errorf("invalid ramp step tps %v, must be >= 0", c.RampStepTPS)
+		}
+	case SpikeProfile:
+		if c.SpikeTPS <= c.TargetTPS {
+			return c, fmt.Errorf("invalid spike tps %v, must be > target tps %v", c.SpikeTPS, c.TargetTPS)
+		}
+		if c.SpikeDuration <= 0 {
+			return c, fmt.Errorf("invalid spike duration %v, must be > 0", c.SpikeDuration)
+		}
+	}
 	return c, nil
 }
 
@@ -126,4 +258,16 @@ func addSimulatorFlags(fs *pflag.FlagSet) {
 	fs.Uint64(BatchSizeKey, 100, "Specify the batchsize for the worker to issue and confirm txs")
 	fs.Uint64(MetricsPortKey, 8082, "Specify the port to use for the metrics server")
 	fs.String(MetricsOutputKey, "", "Specify the file to write metrics in json format, or empy to write to stdout (defaults to stdout)")
+	fs.StringSlice(WorkloadsKey, []string{TransferWorkload}, fmt.Sprintf("Specify a comma separated list of workloads assigned to workers in a round-robin fashion (%s)", strings.Join(validWorkloads, ", ")))
+	fs.Uint64(StorageSlotsKey, 10, "Specify the number of new storage slots written by each transaction of the storage workload (must be > 0)")
+	fs.String(AllowListKey, txallowlist.ContractAddress.Hex(), "Specify the address of the allow list precompile read by the allowlist-read workload")
+	fs.Int(WarpPayloadKey, 32, "Specify the size in bytes of the payload of each message sent by the warp workload")
+	fs.String(LoadProfileKey, BatchProfile, fmt.Sprintf("Specify the load profile (%s). Profiles other than batch issue txs at a scheduled rate, ignoring txs-per-worker and batch-size", strings.Join(validProfiles, ", ")))
+	fs.Float64(TargetTPSKey, 100, "Specify the aggregate TPS of the constant profile, the starting TPS of the ramp profile, or the base TPS of the spike profile (must be > 0)")
+	fs.Duration(StepDurationKey, 30*time.Second, "Specify the duration of the constant profile, of each step of the ramp profile, or of each base phase of the spike profile")
+	fs.Float64(RampStepTPSKey, 50, "Specify the TPS added at every step of the ramp profile")
+	fs.Int(RampStepsKey, 5, "Specify the number of steps of the ramp profile")
+	fs.Float64(SpikeTPSKey, 500, "Specify the aggregate TPS during the spike of the spike profile (must be > target-tps)")
+	fs.Duration(SpikeDurationKey, 10*time.Second, "Specify the duration of the spike of the spike profile")
+	fs.Float64(DegradationKey, 2, "Specify the factor by which the median latency must exceed that of the lowest rate phase to be considered degraded (must be > 1)")
 }
@@ -21,7 +21,6 @@ import (
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/ethclient"
 	"github.com/ava-labs/subnet-evm/params"
-	"github.com/ethereum/go-ethereum/common"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"golang.org/x/sync/errgroup"
@@ -40,6 +39,14 @@ type Loader[T txs.THash] struct {
 	txSequences []txs.TxSequence[T]
 	batchSize   uint64
 	metrics     *metrics.Metrics
+
+	// workloads are the names of the workloads of the txSequences, if known,
+	// used to label their metrics.
+	workloads []string
+	// profile is the aggregate open-loop profile shared by the txSequences,
+	// or nil to issue and confirm them in batches.
+	profile  txs.Profile
+	recorder *txs.LatencyRecorder
 }
 
 func New[T txs.THash](
@@ -60,7 +67,15 @@ func (l *Loader[T]) Execute(ctx context.Context) error {
 	log.Info("Constructing tx agents...", "numAgents", len(l.txSequences))
 	agents := make([]txs.Agent[T], 0, len(l.txSequences))
 	for i := 0; i < len(l.txSequences); i++ {
-		agents = append(agents, txs.NewIssueNAgent(l.txSequences[i], l.clients[i], l.batchSize, l.metrics))
+		m := l.metrics
+		if i < len(l.workloads) {
+			m = m.ForWorkload(l.workloads[i])
+		}
+		if l.profile != nil {
+			agents = append(agents, txs.NewOpenLoopAgent(l.txSequences[i], l.clients[i], l.profile.Split(len(l.txSequences)), m, l.recorder))
+			continue
+		}
+		agents = append(agents, txs.NewIssueNAgent(l.txSequences[i], l.clients[i], l.batchSize, m))
 	}
 
 	log.Info("Starting tx agents...")
@@ -183,12 +198,34 @@ func ExecuteLoader(ctx context.Context, config config.Config) error {
 		}
 	}
 
-	// Each address needs: params.GWei * MaxFeeCap * params.TxGas * TxsPerWorker total wei
-	// to fund gas for all of their transactions.
+	workloads, err := workerWorkloads(config)
+	if err != nil {
+		return err
+	}
+
+	var (
+		profile      txs.Profile
+		txsPerWorker = config.TxsPerWorker
+	)
+	if config.OpenLoop() {
+		profile, err = newProfile(config)
+		if err != nil {
+			return err
+		}
+		txsPerWorker = profile.Split(config.Workers).NumTxs()
+		log.Info("Using open-loop load profile", "profile", config.LoadProfile, "duration", profile.Duration(), "txsPerWorker", txsPerWorker)
+	}
+
+	// Each address needs: params.GWei * MaxFeeCap * (TxGas * TxsPerWorker + SetupGas) total wei
+	// to fund gas for all of their transactions, using the most expensive workload.
+	var maxGas uint64
+	for _, workload := range workloads {
+		maxGas = max(maxGas, workload.TxGas()*txsPerWorker+workload.SetupGas())
+	}
 	maxFeeCap := new(big.Int).Mul(big.NewInt(params.GWei), big.NewInt(config.MaxFeeCap))
-	minFundsPerAddr := new(big.Int).Mul(maxFeeCap, big.NewInt(int64(config.TxsPerWorker*params.TxGas)))
+	minFundsPerAddr := new(big.Int).Mul(maxFeeCap, new(big.Int).SetUint64(maxGas))
 	fundStart := time.Now()
-	log.Info("Distributing funds", "numTxsPerWorker", config.TxsPerWorker, "minFunds", minFundsPerAddr)
+	log.Info("Distributing funds", "numTxsPerWorker", txsPerWorker, "minFunds", minFundsPerAddr)
 	keys, err = DistributeFunds(ctx, clients[0], keys, config.Workers, minFundsPerAddr, m)
 	if err != nil {
 		return err
@@ -196,10 +233,8 @@ func ExecuteLoader(ctx context.Context, config config.Config) error {
 	log.Info("Distributed funds successfully", "time", time.Since(fundStart))
 
 	pks := make([]*ecdsa.PrivateKey, 0, len(keys))
-	senders := make([]common.Address, 0, len(keys))
 	for _, key := range keys {
 		pks = append(pks, key.PrivKey)
-		senders = append(senders, key.Address)
 	}
 
 	bigGwei := big.NewInt(params.GWei)
@@ -210,27 +245,45 @@ func ExecuteLoader(ctx context.Context, config config.Config) error {
 	if err != nil {
 		return fmt.Errorf("failed to fetch chainID: %w", err)
 	}
-	signer := types.LatestSignerForChainID(chainID)
+	txOpts := txs.TxOptions{
+		ChainID:   chainID,
+		Signer:    types.LatestSignerForChainID(chainID),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+	}
 
-	log.Info("Creating transaction sequences...")
-	txGenerator := func(key *ecdsa.PrivateKey, nonce uint64) (*types.Transaction, error) {
-		addr := ethcrypto.PubkeyToAddress(key.PublicKey)
-		return types.SignNewTx(key, signer, &types.DynamicFeeTx{
-			ChainID:   chainID,
-			Nonce:     nonce,
-			GasTipCap: gasTipCap,
-			GasFeeCap: gasFeeCap,
-			Gas:       params.TxGas,
-			To:        &addr,
-			Data:      nil,
-			Value:     common.Big0,
+	log.Info("Setting up workloads...", "workloads", config.Workloads)
+	setupStart := time.Now()
+	txGenerators := make([]txs.CreateTx, len(workloads))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, workload := range workloads {
+		i, workload := i, workload
+		eg.Go(func() error {
+			txGenerator, err := workload.Setup(egCtx, client, pks[i], txOpts)
+			if err != nil {
+				return fmt.Errorf("worker %d: %w", i, err)
+			}
+			txGenerators[i] = txGenerator
+			return nil
 		})
 	}
-	txSequenceStart := time.Now()
-	txSequences, err := txs.GenerateTxSequences(ctx, txGenerator, clients[0], pks, config.TxsPerWorker, false)
-	if err != nil {
+	if err := eg.Wait(); err != nil {
 		return err
 	}
+	log.Info("Set up workloads successfully", "time", time.Since(setupStart))
+
+	log.Info("Creating transaction sequences...")
+	txSequenceStart := time.Now()
+	txSequences := make([]txs.TxSequence[*types.Transaction], 0, len(workloads))
+	workloadNames := make([]string, 0, len(workloads))
+	for i, workload := range workloads {
+		txSequence, err := txs.GenerateTxSequence(ctx, txGenerators[i], client, pks[i], txsPerWorker, false)
+		if err != nil {
+			return err
+		}
+		txSequences = append(txSequences, txSequence)
+		workloadNames = append(workloadNames, workload.Name())
+	}
 	log.Info("Created transaction sequences successfully", "time", time.Since(txSequenceStart))
 
 	workers := make([]txs.Worker[*types.Transaction], 0, len(clients))
@@ -238,7 +291,15 @@ func ExecuteLoader(ctx context.Context, config config.Config) error {
 		workers = append(workers, NewSingleAddressTxWorker(ctx, client, ethcrypto.PubkeyToAddress(pks[i].PublicKey)))
 	}
 	loader := New(workers, txSequences, config.BatchSize, m)
+	loader.workloads = workloadNames
+	if profile != nil {
+		loader.profile = profile
+		loader.recorder = txs.NewLatencyRecorder(profile)
+	}
 	err = loader.Execute(ctx)
+	if loader.recorder != nil {
+		txs.LogReport(loader.recorder.Report(), config.Degradation, m)
+	}
 	prerr := m.Print(config.MetricsOutput) // Print regardless of execution error
 	if prerr != nil {
 		log.Warn("Failed to print metrics", "error", prerr)
@@ -0,0 +1,25 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package load
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/cmd/simulator/config"
+	"github.com/ava-labs/subnet-evm/cmd/simulator/txs"
+)
+
+// newProfile returns the aggregate open-loop profile configured by [c].
+func newProfile(c config.Config) (txs.Profile, error) {
+	switch c.LoadProfile {
+	case config.ConstantProfile:
+		return txs.ConstantProfile(c.TargetTPS, c.StepDuration), nil
+	case config.RampProfile:
+		return txs.RampProfile(c.TargetTPS, c.RampStepTPS, c.RampSteps, c.StepDuration), nil
+	case config.SpikeProfile:
+		return txs.SpikeProfile(c.TargetTPS, c.SpikeTPS, c.StepDuration, c.SpikeDuration), nil
+	default:
+		return nil, fmt.Errorf("unknown open-loop profile %q", c.LoadProfile)
+	}
+}
@@ -0,0 +1,55 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package load
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/cmd/simulator/config"
+	"github.com/ava-labs/subnet-evm/cmd/simulator/txs"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newWorkload returns the workload called [name], configured by [c].
+func newWorkload(name string, c config.Config) (txs.Workload, error) {
+	switch name {
+	case config.TransferWorkload:
+		return txs.NewTransferWorkload(), nil
+	case config.ERC20Workload:
+		return txs.NewERC20Workload(), nil
+	case config.DeployWorkload:
+		return txs.NewDeployWorkload(), nil
+	case config.StorageWorkload:
+		return txs.NewStorageWorkload(c.StorageSlots), nil
+	case config.NativeMinterWorkload:
+		return txs.NewNativeMinterWorkload()
+	case config.AllowListReadWorkload:
+		return txs.NewAllowListReadWorkload(common.HexToAddress(c.AllowList))
+	case config.WarpWorkload:
+		return txs.NewWarpWorkload(c.WarpPayload)
+	default:
+		return nil, fmt.Errorf("unknown workload %q", name)
+	}
+}
+
+// workerWorkloads returns the workload of each of the [config.Workers]
+// workers, assigning the configured workloads in a round-robin fashion.
+func workerWorkloads(c config.Config) ([]txs.Workload, error) {
+	workloads := make(map[string]txs.Workload, len(c.Workloads))
+	for _, name := range c.Workloads {
+		if _, ok := workloads[name]; ok {
+			continue
+		}
+		workload, err := newWorkload(name, c)
+		if err != nil {
+			return nil, err
+		}
+		workloads[name] = workload
+	}
+	result := make([]txs.Workload, 0, c.Workers)
+	for i := 0; i < c.Workers; i++ {
+		result = append(result, workloads[c.Workloads[i%len(c.Workloads)]])
+	}
+	return result, nil
+}
@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -24,6 +26,17 @@ type Metrics struct {
 	ConfirmationTxTimes prometheus.Summary
 	// Summary of the quantiles of Individual Issuance To Confirmation Tx Times
 	IssuanceToConfirmationTxTimes prometheus.Summary
+	// Count of confirmed txs per workload
+	ConfirmedTxs *prometheus.CounterVec
+	// Summary of the quantiles of Individual Issuance To Confirmation Tx Times per workload
+	WorkloadIssuanceToConfirmationTxTimes *prometheus.SummaryVec
+	// Summary of the quantiles of Individual Issuance To Confirmation Tx Times per phase of an open-loop profile
+	PhaseIssuanceToConfirmationTxTimes *prometheus.SummaryVec
+	// Highest accepted TPS of an open-loop profile before the latency degrades
+	SustainedTPS prometheus.Gauge
+
+	// workload labels the per workload metrics, if not empty
+	workload string
 }
 
 func NewDefaultMetrics() *Metrics {
@@ -50,13 +63,60 @@ func NewMetrics(reg *prometheus.Registry) *Metrics {
 			Help:       "Individual Tx Issuance To Confirmation Times for a Load Test",
 			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 		}),
+		ConfirmedTxs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workload_confirmed_txs",
+			Help: "Confirmed Txs per Workload for a Load Test",
+		}, []string{"workload"}),
+		WorkloadIssuanceToConfirmationTxTimes: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Name:       "workload_tx_issuance_to_confirmation_time",
+			Help:       "Individual Tx Issuance To Confirmation Times per Workload for a Load Test",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		}, []string{"workload"}),
+		PhaseIssuanceToConfirmationTxTimes: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Name:       "phase_tx_issuance_to_confirmation_time",
+			Help:       "Individual Tx Issuance To Confirmation Times per Phase of an Open-Loop Load Test",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		}, []string{"phase", "target_tps"}),
+		SustainedTPS: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "sustained_tps",
+			Help: "Highest Accepted TPS of an Open-Loop Load Test before the Latency Degrades",
+		}),
 	}
 	reg.MustRegister(m.IssuanceTxTimes)
 	reg.MustRegister(m.ConfirmationTxTimes)
 	reg.MustRegister(m.IssuanceToConfirmationTxTimes)
+	reg.MustRegister(m.ConfirmedTxs)
+	reg.MustRegister(m.WorkloadIssuanceToConfirmationTxTimes)
+	reg.MustRegister(m.PhaseIssuanceToConfirmationTxTimes)
+	reg.MustRegister(m.SustainedTPS)
 	return m
 }
 
+// ForWorkload returns a copy of [m] that also records the txs it observes in
+// the metrics of [workload].
+func (m *Metrics) ForWorkload(workload string) *Metrics {
+	cpy := *m
+	cpy.workload = workload
+	return &cpy
+}
+
+// ObserveWorkloadConfirmation records a tx confirmed [issuanceToConfirmation]
+// after its issuance in the metrics of the workload of [m], if any.
+func (m *Metrics) ObserveWorkloadConfirmation(issuanceToConfirmation time.Duration) {
+	if m.workload == "" {
+		return
+	}
+	m.ConfirmedTxs.WithLabelValues(m.workload).Inc()
+	m.WorkloadIssuanceToConfirmationTxTimes.WithLabelValues(m.workload).Observe(issuanceToConfirmation.Seconds())
+}
+
+// ObservePhaseConfirmation records a tx issued during [phase] of an open-loop
+// profile, at [targetTPS], and confirmed [issuanceToConfirmation] after its
+// issuance.
+func (m *Metrics) ObservePhaseConfirmation(phase int, targetTPS float64, issuanceToConfirmation time.Duration) {
+	m.PhaseIssuanceToConfirmationTxTimes.WithLabelValues(strconv.Itoa(phase), strconv.FormatFloat(targetTPS, 'g', -1, 64)).Observe(issuanceToConfirmation.Seconds())
+}
+
 type MetricsServer struct {
 	metricsPort     string
 	metricsEndpoint string
@@ -120,6 +120,7 @@ func (a issueNAgent[T]) Execute(ctx context.Context) error {
 			issuanceToConfirmationIndividualDuration := time.Since(txMap[tx.Hash()])
 			m.ConfirmationTxTimes.Observe(confirmationIndividualDuration.Seconds())
 			m.IssuanceToConfirmationTxTimes.Observe(issuanceToConfirmationIndividualDuration.Seconds())
+			m.ObserveWorkloadConfirmation(issuanceToConfirmationIndividualDuration)
 			delete(txMap, tx.Hash())
 			confirmedCount++
 		}
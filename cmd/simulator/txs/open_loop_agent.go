@@ -0,0 +1,307 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/cmd/simulator/metrics"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// issuedTx is a tx issued by an openLoopAgent, awaiting confirmation.
+type issuedTx[T THash] struct {
+	tx       T
+	phase    int
+	issuedAt time.Time
+}
+
+// openLoopAgent issues txs at the rates of a profile, independently of their
+// confirmation, and records the latency from their issuance to their
+// acceptance.
+type openLoopAgent[T THash] struct {
+	sequence TxSequence[T]
+	worker   Worker[T]
+	profile  Profile
+	metrics  *metrics.Metrics
+	recorder *LatencyRecorder
+}
+
+// NewOpenLoopAgent creates a new openLoopAgent issuing the txs of [sequence]
+// at the rates of [profile], and recording their latencies in [recorder].
+func NewOpenLoopAgent[T THash](sequence TxSequence[T], worker Worker[T], profile Profile, metrics *metrics.Metrics, recorder *LatencyRecorder) Agent[T] {
+	return &openLoopAgent[T]{
+		sequence: sequence,
+		worker:   worker,
+		profile:  profile,
+		metrics:  metrics,
+		recorder: recorder,
+	}
+}
+
+// Execute issues txs on the schedule of the profile, while confirming the
+// issued txs concurrently.
+func (a openLoopAgent[T]) Execute(ctx context.Context) error {
+	issued := make(chan issuedTx[T], a.profile.NumTxs())
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		defer close(issued)
+		return a.issue(ctx, issued)
+	})
+	eg.Go(func() error {
+		return a.confirm(ctx, issued)
+	})
+	return eg.Wait()
+}
+
+// issue issues the txs of the sequence at the rate of each phase of the
+// profile, starting at the start of the recorder. If the issuance of a tx is
+// late, the next txs are issued immediately until the schedule is caught up.
+func (a openLoopAgent[T]) issue(ctx context.Context, issued chan<- issuedTx[T]) error {
+	var (
+		txChan     = a.sequence.Chan()
+		phaseStart = a.recorder.Start()
+		m          = a.metrics
+	)
+	for i, phase := range a.profile {
+		var (
+			interval = time.Duration(float64(time.Second) / phase.TPS)
+			numTxs   = phase.numTxs()
+			maxLag   time.Duration
+		)
+		for j := uint64(0); j < numTxs; j++ {
+			scheduled := phaseStart.Add(time.Duration(j) * interval)
+			if wait := time.Until(scheduled); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			var tx T
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case next, ok := <-txChan:
+				if !ok {
+					return fmt.Errorf("tx sequence exhausted after %d txs of phase %d", j, i)
+				}
+				tx = next
+			}
+			issuedAt := time.Now()
+			maxLag = max(maxLag, issuedAt.Sub(scheduled))
+			if err := a.worker.IssueTx(ctx, tx); err != nil {
+				return fmt.Errorf("failed to issue transaction %d of phase %d: %w", j, i, err)
+			}
+			m.IssuanceTxTimes.Observe(time.Since(issuedAt).Seconds())
+			a.recorder.recordIssued(i)
+			issued <- issuedTx[T]{tx: tx, phase: i, issuedAt: issuedAt}
+		}
+		if maxLag > interval {
+			log.Warn("Issuance fell behind schedule", "phase", i, "targetTPS", phase.TPS, "maxLag", maxLag)
+		}
+		phaseStart = phaseStart.Add(phase.Duration)
+	}
+	return nil
+}
+
+// confirm confirms the issued txs in order, recording their latencies.
+func (a openLoopAgent[T]) confirm(ctx context.Context, issued <-chan issuedTx[T]) error {
+	m := a.metrics
+	for tx := range issued {
+		confirmedStart := time.Now()
+		if err := a.worker.ConfirmTx(ctx, tx.tx); err != nil {
+			return fmt.Errorf("failed to await transaction %s: %w", tx.tx.Hash(), err)
+		}
+		acceptedAt := time.Now()
+		latency := acceptedAt.Sub(tx.issuedAt)
+		m.ConfirmationTxTimes.Observe(acceptedAt.Sub(confirmedStart).Seconds())
+		m.IssuanceToConfirmationTxTimes.Observe(latency.Seconds())
+		m.ObserveWorkloadConfirmation(latency)
+		m.ObservePhaseConfirmation(tx.phase, a.recorder.profile[tx.phase].TPS, latency)
+		a.recorder.recordAccepted(tx.phase, acceptedAt, latency)
+	}
+	return nil
+}
+
+// LatencyRecorder records the latencies of the txs issued by the agents
+// sharing a load profile, and reports them per phase of the profile.
+type LatencyRecorder struct {
+	profile Profile
+
+	startOnce sync.Once
+	start     time.Time
+
+	lock     sync.Mutex
+	issued   []int
+	accepted [][]time.Time
+	latency  [][]time.Duration
+}
+
+// NewLatencyRecorder creates a LatencyRecorder for the phases of [profile],
+// which is the aggregate profile of all the agents.
+func NewLatencyRecorder(profile Profile) *LatencyRecorder {
+	return &LatencyRecorder{
+		profile:  profile,
+		issued:   make([]int, len(profile)),
+		accepted: make([][]time.Time, len(profile)),
+		latency:  make([][]time.Duration, len(profile)),
+	}
+}
+
+// Start returns the time at which the profile starts, which is set by the
+// first call. Every agent sharing the recorder follows the same schedule.
+func (r *LatencyRecorder) Start() time.Time {
+	r.startOnce.Do(func() {
+		r.start = time.Now()
+	})
+	return r.start
+}
+
+func (r *LatencyRecorder) recordIssued(phase int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.issued[phase]++
+}
+
+func (r *LatencyRecorder) recordAccepted(phase int, acceptedAt time.Time, latency time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.accepted[phase] = append(r.accepted[phase], acceptedAt)
+	r.latency[phase] = append(r.latency[phase], latency)
+}
+
+// PhaseReport summarizes the txs issued during a phase of a load profile.
+type PhaseReport struct {
+	TargetTPS float64
+	Duration  time.Duration
+	Issued    int
+	Accepted  int
+	// AcceptedTPS is the rate at which txs were accepted during the phase,
+	// regardless of the phase they were issued in.
+	AcceptedTPS float64
+	// Latency percentiles from the issuance to the acceptance of the txs
+	// issued during the phase.
+	P50, P90, P99 time.Duration
+}
+
+// Report returns the report of every phase of the profile.
+func (r *LatencyRecorder) Report() []PhaseReport {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	reports := make([]PhaseReport, 0, len(r.profile))
+	phaseStart := r.start
+	for i, phase := range r.profile {
+		phaseEnd := phaseStart.Add(phase.Duration)
+		var acceptedInPhase int
+		for _, accepted := range r.accepted {
+			for _, acceptedAt := range accepted {
+				if !acceptedAt.Before(phaseStart) && acceptedAt.Before(phaseEnd) {
+					acceptedInPhase++
+				}
+			}
+		}
+		latencies := slices.Clone(r.latency[i])
+		slices.Sort(latencies)
+		reports = append(reports, PhaseReport{
+			TargetTPS:   phase.TPS,
+			Duration:    phase.Duration,
+			Issued:      r.issued[i],
+			Accepted:    len(latencies),
+			AcceptedTPS: float64(acceptedInPhase) / phase.Duration.Seconds(),
+			P50:         percentile(latencies, 0.5),
+			P90:         percentile(latencies, 0.9),
+			P99:         percentile(latencies, 0.99),
+		})
+		phaseStart = phaseEnd
+	}
+	return reports
+}
+
+// SustainedTPS returns the highest accepted rate of the phases of [reports],
+// in increasing order of target rate, before the median latency exceeds
+// [degradationFactor] times the median latency of the phase with the lowest
+// target rate. Returns the index of the phase where the latency degraded, or
+// -1 if it did not.
+func SustainedTPS(reports []PhaseReport, degradationFactor float64) (float64, int) {
+	order := make([]int, 0, len(reports))
+	for i, report := range reports {
+		if report.Accepted > 0 {
+			order = append(order, i)
+		}
+	}
+	if len(order) == 0 {
+		return 0, -1
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		switch {
+		case reports[i].TargetTPS < reports[j].TargetTPS:
+			return -1
+		case reports[i].TargetTPS > reports[j].TargetTPS:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	var (
+		baseline  = float64(reports[order[0]].P50)
+		sustained float64
+	)
+	for _, i := range order {
+		if float64(reports[i].P50) > degradationFactor*baseline {
+			return sustained, i
+		}
+		sustained = max(sustained, reports[i].AcceptedTPS)
+	}
+	return sustained, -1
+}
+
+// LogReport logs the reports of the phases, and the sustained rate before the
+// latency degrades by [degradationFactor].
+func LogReport(reports []PhaseReport, degradationFactor float64, m *metrics.Metrics) {
+	for i, report := range reports {
+		log.Info("Phase report",
+			"phase", i,
+			"targetTPS", report.TargetTPS,
+			"duration", report.Duration,
+			"issued", report.Issued,
+			"accepted", report.Accepted,
+			"acceptedTPS", report.AcceptedTPS,
+			"p50", report.P50,
+			"p90", report.P90,
+			"p99", report.P99,
+		)
+	}
+	sustained, degradedPhase := SustainedTPS(reports, degradationFactor)
+	m.SustainedTPS.Set(sustained)
+	if degradedPhase < 0 {
+		log.Info("Latency did not degrade", "degradationFactor", degradationFactor, "sustainedTPS", sustained)
+		return
+	}
+	log.Info("Latency degraded",
+		"degradationFactor", degradationFactor,
+		"phase", degradedPhase,
+		"targetTPS", reports[degradedPhase].TargetTPS,
+		"sustainedTPS", sustained,
+	)
+}
+
+// percentile returns the [p] percentile of the sorted [latencies].
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(latencies)))) - 1
+	return latencies[max(i, 0)]
+}
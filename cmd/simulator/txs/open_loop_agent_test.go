@@ -0,0 +1,124 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		p         float64
+		want      time.Duration
+	}{
+		{name: "empty", latencies: nil, p: 0.5, want: 0},
+		{name: "single", latencies: []time.Duration{7}, p: 0.99, want: 7},
+		{name: "zero", latencies: latencies, p: 0, want: 1},
+		{name: "p50", latencies: latencies, p: 0.5, want: 5},
+		{name: "p90", latencies: latencies, p: 0.9, want: 9},
+		{name: "p99", latencies: latencies, p: 0.99, want: 10},
+		{name: "max", latencies: latencies, p: 1, want: 10},
+		{name: "p50 of odd length", latencies: []time.Duration{1, 2, 3}, p: 0.5, want: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, percentile(test.latencies, test.p))
+		})
+	}
+}
+
+func TestSustainedTPS(t *testing.T) {
+	tests := []struct {
+		name          string
+		reports       []PhaseReport
+		factor        float64
+		wantSustained float64
+		wantDegraded  int
+	}{
+		{
+			name:          "no reports",
+			factor:        2,
+			wantSustained: 0,
+			wantDegraded:  -1,
+		},
+		{
+			name: "nothing accepted",
+			reports: []PhaseReport{
+				{TargetTPS: 10, Accepted: 0, P50: time.Second},
+			},
+			factor:        2,
+			wantSustained: 0,
+			wantDegraded:  -1,
+		},
+		{
+			name: "no degradation",
+			reports: []PhaseReport{
+				{TargetTPS: 10, Accepted: 10, AcceptedTPS: 10, P50: time.Second},
+				{TargetTPS: 20, Accepted: 20, AcceptedTPS: 19, P50: time.Second},
+				{TargetTPS: 30, Accepted: 30, AcceptedTPS: 28, P50: 2 * time.Second},
+			},
+			factor:        2,
+			wantSustained: 28,
+			wantDegraded:  -1,
+		},
+		{
+			name: "degradation",
+			reports: []PhaseReport{
+				{TargetTPS: 10, Accepted: 10, AcceptedTPS: 10, P50: time.Second},
+				{TargetTPS: 20, Accepted: 20, AcceptedTPS: 20, P50: time.Second},
+				{TargetTPS: 30, Accepted: 30, AcceptedTPS: 25, P50: 3 * time.Second},
+				{TargetTPS: 40, Accepted: 40, AcceptedTPS: 30, P50: time.Second},
+			},
+			factor:        2,
+			wantSustained: 20,
+			wantDegraded:  2,
+		},
+		{
+			name: "orders phases by target rate",
+			reports: []PhaseReport{
+				{TargetTPS: 10, Accepted: 10, AcceptedTPS: 10, P50: time.Second},
+				{TargetTPS: 50, Accepted: 50, AcceptedTPS: 35, P50: 5 * time.Second},
+				{TargetTPS: 10, Accepted: 10, AcceptedTPS: 10, P50: 4 * time.Second},
+			},
+			factor:        3,
+			wantSustained: 10,
+			wantDegraded:  2,
+		},
+		{
+			name: "skips phases without accepted txs",
+			reports: []PhaseReport{
+				{TargetTPS: 5, Accepted: 0},
+				{TargetTPS: 10, Accepted: 10, AcceptedTPS: 9, P50: time.Second},
+				{TargetTPS: 20, Accepted: 20, AcceptedTPS: 18, P50: 3 * time.Second},
+			},
+			factor:        2,
+			wantSustained: 9,
+			wantDegraded:  2,
+		},
+		{
+			name: "keeps the highest accepted rate",
+			reports: []PhaseReport{
+				{TargetTPS: 10, Accepted: 10, AcceptedTPS: 10, P50: time.Second},
+				{TargetTPS: 20, Accepted: 20, AcceptedTPS: 15, P50: time.Second},
+				{TargetTPS: 30, Accepted: 30, AcceptedTPS: 12, P50: time.Second},
+			},
+			factor:        2,
+			wantSustained: 15,
+			wantDegraded:  -1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sustained, degraded := SustainedTPS(test.reports, test.factor)
+			require.Equal(t, test.wantSustained, sustained)
+			require.Equal(t, test.wantDegraded, degraded)
+		})
+	}
+}
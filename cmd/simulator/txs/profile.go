@@ -0,0 +1,86 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"math"
+	"time"
+)
+
+// Phase is a period of a load profile during which txs are issued at a
+// constant rate.
+type Phase struct {
+	TPS      float64
+	Duration time.Duration
+}
+
+// numTxs returns the number of txs issued during the phase.
+func (p Phase) numTxs() uint64 {
+	return uint64(math.Ceil(p.TPS * p.Duration.Seconds()))
+}
+
+// Profile is a sequence of phases of open-loop load, where txs are issued on
+// a schedule, independently of the confirmation of the previous txs.
+type Profile []Phase
+
+// ConstantProfile returns a profile issuing [tps] txs per second for
+// [duration].
+func ConstantProfile(tps float64, duration time.Duration) Profile {
+	return Profile{{TPS: tps, Duration: duration}}
+}
+
+// RampProfile returns a profile of [steps] phases of [stepDuration], starting
+// at [startTPS] txs per second and increasing the rate by [stepTPS] at every
+// step.
+func RampProfile(startTPS float64, stepTPS float64, steps int, stepDuration time.Duration) Profile {
+	profile := make(Profile, 0, steps)
+	for i := 0; i < steps; i++ {
+		profile = append(profile, Phase{
+			TPS:      startTPS + float64(i)*stepTPS,
+			Duration: stepDuration,
+		})
+	}
+	return profile
+}
+
+// SpikeProfile returns a profile issuing [baseTPS] txs per second for
+// [baseDuration], then [spikeTPS] for [spikeDuration], and [baseTPS] again for
+// [baseDuration] to observe the recovery from the spike.
+func SpikeProfile(baseTPS float64, spikeTPS float64, baseDuration time.Duration, spikeDuration time.Duration) Profile {
+	return Profile{
+		{TPS: baseTPS, Duration: baseDuration},
+		{TPS: spikeTPS, Duration: spikeDuration},
+		{TPS: baseTPS, Duration: baseDuration},
+	}
+}
+
+// Split returns the profile of each of [n] workers sharing the load of [p].
+func (p Profile) Split(n int) Profile {
+	split := make(Profile, 0, len(p))
+	for _, phase := range p {
+		split = append(split, Phase{
+			TPS:      phase.TPS / float64(n),
+			Duration: phase.Duration,
+		})
+	}
+	return split
+}
+
+// NumTxs returns the number of txs issued by the profile.
+func (p Profile) NumTxs() uint64 {
+	var numTxs uint64
+	for _, phase := range p {
+		numTxs += phase.numTxs()
+	}
+	return numTxs
+}
+
+// Duration returns the duration of the profile.
+func (p Profile) Duration() time.Duration {
+	var duration time.Duration
+	for _, phase := range p {
+		duration += phase.Duration
+	}
+	return duration
+}
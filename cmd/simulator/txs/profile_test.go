@@ -0,0 +1,108 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileNumTxs(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		want    uint64
+	}{
+		{
+			name:    "empty",
+			profile: Profile{},
+			want:    0,
+		},
+		{
+			name:    "constant",
+			profile: ConstantProfile(100, 10*time.Second),
+			want:    1000,
+		},
+		{
+			name:    "fractional rate rounds up",
+			profile: ConstantProfile(0.5, 3*time.Second),
+			want:    2,
+		},
+		{
+			name:    "sub-second phase",
+			profile: ConstantProfile(10, 250*time.Millisecond),
+			want:    3,
+		},
+		{
+			name:    "ramp",
+			profile: RampProfile(10, 5, 3, 2*time.Second),
+			want:    20 + 30 + 40,
+		},
+		{
+			name:    "spike",
+			profile: SpikeProfile(10, 100, 5*time.Second, time.Second),
+			want:    50 + 100 + 50,
+		},
+		{
+			name:    "rounds up every phase",
+			profile: Profile{{TPS: 1.5, Duration: time.Second}, {TPS: 1.5, Duration: time.Second}},
+			want:    4,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, test.profile.NumTxs())
+		})
+	}
+}
+
+func TestProfileSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		n       int
+		want    Profile
+	}{
+		{
+			name:    "single worker",
+			profile: ConstantProfile(100, time.Minute),
+			n:       1,
+			want:    Profile{{TPS: 100, Duration: time.Minute}},
+		},
+		{
+			name:    "even split",
+			profile: RampProfile(10, 10, 2, time.Second),
+			n:       5,
+			want:    Profile{{TPS: 2, Duration: time.Second}, {TPS: 4, Duration: time.Second}},
+		},
+		{
+			name:    "uneven split",
+			profile: ConstantProfile(10, 4*time.Second),
+			n:       4,
+			want:    Profile{{TPS: 2.5, Duration: 4 * time.Second}},
+		},
+		{
+			name:    "empty",
+			profile: Profile{},
+			n:       3,
+			want:    Profile{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			split := test.profile.Split(test.n)
+			require.Equal(test.want, split)
+			require.Equal(test.profile.Duration(), split.Duration())
+			// The workers issue at least the txs of the profile, and at most
+			// one more per worker and phase due to rounding.
+			numTxs := uint64(test.n) * split.NumTxs()
+			require.GreaterOrEqual(numTxs, test.profile.NumTxs())
+			require.LessOrEqual(numTxs, test.profile.NumTxs()+uint64(test.n*len(test.profile)))
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/nativeminter"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// gasMargin is added to the gas limit of the transactions calling contracts,
+// to account for the execution overhead around the metered operations.
+const gasMargin = 10_000
+
+var (
+	// tokenCode deploys a minimal ERC-20 style token, which assigns the whole
+	// supply to the deployer and supports transfer(address,uint256) and
+	// balanceOf(address). Balances are stored at the slot of the address and
+	// every transfer emits a Transfer event.
+	//
+	// Constructor:
+	//   PUSH32 2^256-1 CALLER SSTORE; CODECOPY runtime; RETURN runtime
+	// Runtime:
+	//   selector := CALLDATALOAD(0) >> 224
+	//   transfer:  require(SLOAD(CALLER) >= amount)
+	//              SSTORE(CALLER, SLOAD(CALLER) - amount)
+	//              SSTORE(to, SLOAD(to) + amount)
+	//              LOG3(amount, Transfer, CALLER, to); RETURN true
+	//   balanceOf: RETURN SLOAD(owner)
+	tokenCode = common.FromHex("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff3355607d602f600039607d6000f360003560e01c8063a9059cbb14602c57806370a0823114601f575b600080fd5b6004355460005260206000f35b6024353354818110601a578190033355600435805482019055600052600435337fddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef60206000a3600160005260206000f3")
+
+	// storageCode deploys a contract that, given the calldata (start, count),
+	// writes the value i+1 to every slot i in [start, start+count).
+	//
+	// Runtime:
+	//   for i := CALLDATALOAD(0); i < CALLDATALOAD(0) + CALLDATALOAD(32); i++ {
+	//       SSTORE(i, i+1)
+	//   }
+	storageCode = common.FromHex("6020600c60003960206000f3602035600035908101905b81811015601e57806001018155600101600a565b00")
+
+	// transferSelector is the selector of transfer(address,uint256).
+	transferSelector = common.FromHex("a9059cbb")
+)
+
+const (
+	tokenDeployGas   = 150_000
+	tokenTransferGas = 60_000
+	storageDeployGas = 100_000
+	storageSlotGas   = 25_000
+)
+
+// TxOptions are the fee and signing parameters of the transactions created
+// by a Workload.
+type TxOptions struct {
+	ChainID   *big.Int
+	Signer    types.Signer
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// Workload creates the transactions of a kind of load.
+type Workload interface {
+	// Name returns the name of the workload.
+	Name() string
+	// TxGas returns the gas limit of every transaction created by the
+	// workload.
+	TxGas() uint64
+	// SetupGas returns the gas used to set up the workload for a key.
+	SetupGas() uint64
+	// Setup prepares the workload for the transactions signed by [key], for
+	// example by deploying the contracts they call, and returns the
+	// generator of the transactions.
+	Setup(ctx context.Context, client ethclient.Client, key *ecdsa.PrivateKey, opts TxOptions) (CreateTx, error)
+}
+
+// callWorkload is a workload of transactions calling a contract, which may
+// be deployed during setup.
+type callWorkload struct {
+	name      string
+	gas       uint64
+	code      []byte // deployed during setup if not nil, and called instead of [to]
+	deployGas uint64
+	self      bool // if true, transactions are sent to their sender instead of [to]
+	to        common.Address
+	data      func(sender common.Address, nonce uint64) []byte
+}
+
+func (w *callWorkload) Name() string { return w.name }
+
+func (w *callWorkload) TxGas() uint64 { return w.gas }
+
+func (w *callWorkload) SetupGas() uint64 { return w.deployGas }
+
+func (w *callWorkload) Setup(ctx context.Context, client ethclient.Client, key *ecdsa.PrivateKey, opts TxOptions) (CreateTx, error) {
+	to := w.to
+	if w.code != nil {
+		var err error
+		to, err = deployContract(ctx, client, key, opts, w.code, w.deployGas)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up %s workload: %w", w.name, err)
+		}
+	}
+	return func(key *ecdsa.PrivateKey, nonce uint64) (*types.Transaction, error) {
+		sender := ethcrypto.PubkeyToAddress(key.PublicKey)
+		to := to
+		if w.self {
+			to = sender
+		}
+		return types.SignNewTx(key, opts.Signer, &types.DynamicFeeTx{
+			ChainID:   opts.ChainID,
+			Nonce:     nonce,
+			GasTipCap: opts.GasTipCap,
+			GasFeeCap: opts.GasFeeCap,
+			Gas:       w.gas,
+			To:        &to,
+			Data:      w.data(sender, nonce),
+			Value:     common.Big0,
+		})
+	}, nil
+}
+
+// NewTransferWorkload returns a workload of zero-value self-transfers.
+func NewTransferWorkload() Workload {
+	return &callWorkload{
+		name: "transfer",
+		gas:  params.TxGas,
+		self: true,
+		data: func(common.Address, uint64) []byte { return nil },
+	}
+}
+
+// NewERC20Workload returns a workload of token transfers to a new recipient
+// per transaction, from a token deployed by every sender.
+func NewERC20Workload() Workload {
+	return &callWorkload{
+		name:      "erc20",
+		gas:       tokenTransferGas,
+		code:      tokenCode,
+		deployGas: tokenDeployGas,
+		data: func(sender common.Address, nonce uint64) []byte {
+			recipient := ethcrypto.CreateAddress(sender, nonce)
+			data := append([]byte{}, transferSelector...)
+			data = append(data, common.LeftPadBytes(recipient.Bytes(), 32)...)
+			return append(data, common.LeftPadBytes(common.Big1.Bytes(), 32)...)
+		},
+	}
+}
+
+// NewStorageWorkload returns a workload of transactions writing [slots] new
+// storage slots each, to a contract deployed by every sender.
+func NewStorageWorkload(slots uint64) Workload {
+	return &callWorkload{
+		name:      "storage",
+		gas:       callGas(64, slots*storageSlotGas),
+		code:      storageCode,
+		deployGas: storageDeployGas,
+		data: func(_ common.Address, nonce uint64) []byte {
+			start := new(big.Int).Mul(new(big.Int).SetUint64(nonce), new(big.Int).SetUint64(slots))
+			data := common.LeftPadBytes(start.Bytes(), 32)
+			return append(data, common.LeftPadBytes(new(big.Int).SetUint64(slots).Bytes(), 32)...)
+		},
+	}
+}
+
+// NewNativeMinterWorkload returns a workload of transactions minting one wei
+// of the native token to their sender. The senders must be enabled on the
+// native minter allow list for the transactions to succeed.
+func NewNativeMinterWorkload() (Workload, error) {
+	data, err := nativeminter.PackMintNativeCoin(common.Address{}, common.Big1)
+	if err != nil {
+		return nil, err
+	}
+	return &callWorkload{
+		name: "nativeminter",
+		gas:  callGas(len(data), nativeminter.MintGasCost+nativeminter.NativeCoinMintedEventGasCost),
+		to:   nativeminter.ContractAddress,
+		data: func(sender common.Address, _ uint64) []byte {
+			data, _ := nativeminter.PackMintNativeCoin(sender, common.Big1)
+			return data
+		},
+	}, nil
+}
+
+// NewAllowListReadWorkload returns a workload of transactions reading the
+// role of their sender from the allow list precompile at [precompile].
+func NewAllowListReadWorkload(precompile common.Address) (Workload, error) {
+	data, err := allowlist.PackReadAllowList(common.Address{})
+	if err != nil {
+		return nil, err
+	}
+	return &callWorkload{
+		name: "allowlist-read",
+		gas:  callGas(len(data), allowlist.ReadAllowListGasCost),
+		to:   precompile,
+		data: func(sender common.Address, _ uint64) []byte {
+			data, _ := allowlist.PackReadAllowList(sender)
+			return data
+		},
+	}, nil
+}
+
+// NewWarpWorkload returns a workload of transactions sending warp messages
+// with random payloads of [payloadSize] bytes.
+func NewWarpWorkload(payloadSize int) (Workload, error) {
+	data, err := warp.PackSendWarpMessage(make([]byte, payloadSize))
+	if err != nil {
+		return nil, err
+	}
+	return &callWorkload{
+		name: "warp",
+		gas:  callGas(len(data), warp.SendWarpMessageGasCost+warp.SendWarpMessageGasCostPerByte*uint64(len(data))),
+		to:   warp.ContractAddress,
+		data: func(common.Address, uint64) []byte {
+			payload := make([]byte, payloadSize)
+			_, _ = rand.Read(payload)
+			data, _ := warp.PackSendWarpMessage(payload)
+			return data
+		},
+	}, nil
+}
+
+// NewDeployWorkload returns a workload of token contract deployments.
+func NewDeployWorkload() Workload {
+	return &deployWorkload{}
+}
+
+type deployWorkload struct{}
+
+func (*deployWorkload) Name() string { return "deploy" }
+
+func (*deployWorkload) TxGas() uint64 { return tokenDeployGas }
+
+func (*deployWorkload) SetupGas() uint64 { return 0 }
+
+func (w *deployWorkload) Setup(_ context.Context, _ ethclient.Client, _ *ecdsa.PrivateKey, opts TxOptions) (CreateTx, error) {
+	return func(key *ecdsa.PrivateKey, nonce uint64) (*types.Transaction, error) {
+		return types.SignNewTx(key, opts.Signer, &types.DynamicFeeTx{
+			ChainID:   opts.ChainID,
+			Nonce:     nonce,
+			GasTipCap: opts.GasTipCap,
+			GasFeeCap: opts.GasFeeCap,
+			Gas:       w.TxGas(),
+			Data:      tokenCode,
+			Value:     common.Big0,
+		})
+	}, nil
+}
+
+// callGas returns the gas limit of a transaction with [dataLen] bytes of
+// calldata, using [execGas] to execute.
+func callGas(dataLen int, execGas uint64) uint64 {
+	return params.TxGas + params.TxDataNonZeroGasEIP2028*uint64(dataLen) + execGas + gasMargin
+}
+
+// deployContract deploys [code] from [key] and waits for the deployment to be
+// accepted, returning the address of the contract.
+func deployContract(ctx context.Context, client ethclient.Client, key *ecdsa.PrivateKey, opts TxOptions, code []byte, gas uint64) (common.Address, error) {
+	address := ethcrypto.PubkeyToAddress(key.PublicKey)
+	nonce, err := client.NonceAt(ctx, address, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	tx, err := types.SignNewTx(key, opts.Signer, &types.DynamicFeeTx{
+		ChainID:   opts.ChainID,
+		Nonce:     nonce,
+		GasTipCap: opts.GasTipCap,
+		GasFeeCap: opts.GasFeeCap,
+		Gas:       gas,
+		Data:      code,
+		Value:     common.Big0,
+	})
+	if err != nil {
+		return common.Address{}, err
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return common.Address{}, fmt.Errorf("failed to issue deployment: %w", err)
+	}
+	for {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return common.Address{}, fmt.Errorf("deployment %s failed", tx.Hash())
+			}
+			log.Debug("deployed contract", "address", receipt.ContractAddress, "deployer", address)
+			return receipt.ContractAddress, nil
+		}
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return common.Address{}, fmt.Errorf("failed to await deployment %s: %w", tx.Hash(), ctx.Err())
+		}
+	}
+}
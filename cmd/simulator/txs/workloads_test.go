@@ -0,0 +1,217 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm/runtime"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testDeployer = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testHolder   = common.HexToAddress("0x1000000000000000000000000000000000000002")
+
+	transferEventTopic = ethcrypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	balanceOfSelector  = common.FromHex("70a08231")
+)
+
+// newRuntimeConfig returns the config of an EVM runtime with the latest
+// upgrades, executing as [origin] with [txGas] gas minus the intrinsic gas of
+// a tx with [data].
+func newRuntimeConfig(t *testing.T, statedb *state.StateDB, origin common.Address, data []byte, creation bool, txGas uint64) *runtime.Config {
+	t.Helper()
+
+	cfg := &runtime.Config{
+		ChainConfig: params.TestChainConfig,
+		Origin:      origin,
+		BlockNumber: common.Big1,
+		State:       statedb,
+	}
+	rules := cfg.ChainConfig.Rules(cfg.BlockNumber, cfg.Time)
+	intrinsicGas, err := core.IntrinsicGas(data, nil, creation, rules)
+	require.NoError(t, err)
+	require.Greater(t, txGas, intrinsicGas)
+	cfg.GasLimit = txGas - intrinsicGas
+	return cfg
+}
+
+// deployTestCode deploys [code] from [testDeployer] with the gas limit of
+// [txGas], and returns its address.
+func deployTestCode(t *testing.T, statedb *state.StateDB, code []byte, txGas uint64) common.Address {
+	t.Helper()
+
+	cfg := newRuntimeConfig(t, statedb, testDeployer, code, true, txGas)
+	_, address, _, err := runtime.Create(code, cfg)
+	require.NoError(t, err)
+	require.NotEmpty(t, statedb.GetCode(address))
+	return address
+}
+
+func packTokenCall(selector []byte, args ...*big.Int) []byte {
+	data := append([]byte{}, selector...)
+	for _, arg := range args {
+		data = append(data, math.U256Bytes(new(big.Int).Set(arg))...)
+	}
+	return data
+}
+
+func TestTokenCode(t *testing.T) {
+	var (
+		recipient = common.HexToAddress("0x2000000000000000000000000000000000000001")
+		maxSupply = new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 256), common.Big1)
+		balanceOf = func(account common.Address) []byte {
+			return packTokenCall(balanceOfSelector, account.Big())
+		}
+	)
+	tests := []struct {
+		name       string
+		sender     common.Address
+		data       []byte
+		wantErr    error
+		wantRet    []byte
+		wantLog    bool
+		wantSender *big.Int
+		wantTo     *big.Int
+	}{
+		{
+			name:       "transfer",
+			sender:     testDeployer,
+			data:       packTokenCall(transferSelector, recipient.Big(), big.NewInt(7)),
+			wantRet:    common.LeftPadBytes(common.Big1.Bytes(), 32),
+			wantLog:    true,
+			wantSender: new(big.Int).Sub(maxSupply, big.NewInt(107)),
+			wantTo:     big.NewInt(7),
+		},
+		{
+			name:       "transfer whole balance",
+			sender:     testHolder,
+			data:       packTokenCall(transferSelector, recipient.Big(), big.NewInt(100)),
+			wantRet:    common.LeftPadBytes(common.Big1.Bytes(), 32),
+			wantLog:    true,
+			wantSender: common.Big0,
+			wantTo:     big.NewInt(100),
+		},
+		{
+			name:       "transfer exceeding balance",
+			sender:     testHolder,
+			data:       packTokenCall(transferSelector, recipient.Big(), big.NewInt(101)),
+			wantErr:    vmerrs.ErrExecutionReverted,
+			wantSender: big.NewInt(100),
+			wantTo:     common.Big0,
+		},
+		{
+			name:    "balanceOf deployer",
+			sender:  testHolder,
+			data:    balanceOf(testDeployer),
+			wantRet: math.U256Bytes(new(big.Int).Sub(maxSupply, big.NewInt(100))),
+		},
+		{
+			name:    "balanceOf holder",
+			sender:  testDeployer,
+			data:    balanceOf(testHolder),
+			wantRet: math.U256Bytes(big.NewInt(100)),
+		},
+		{
+			name:    "unknown selector",
+			sender:  testDeployer,
+			data:    packTokenCall(common.FromHex("18160ddd")),
+			wantErr: vmerrs.ErrExecutionReverted,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			token := deployTestCode(t, statedb, tokenCode, tokenDeployGas)
+
+			// Fund [testHolder] from the deployer, which holds the whole supply.
+			fund := packTokenCall(transferSelector, testHolder.Big(), big.NewInt(100))
+			cfg := newRuntimeConfig(t, statedb, testDeployer, fund, false, tokenTransferGas)
+			_, _, err := runtime.Call(token, fund, cfg)
+			require.NoError(err)
+			statedb.Finalise(true)
+			logs := len(statedb.Logs())
+
+			cfg = newRuntimeConfig(t, statedb, test.sender, test.data, false, tokenTransferGas)
+			ret, _, err := runtime.Call(token, test.data, cfg)
+			require.ErrorIs(err, test.wantErr)
+			if test.wantRet != nil {
+				require.Equal(test.wantRet, ret)
+			}
+			if test.wantSender != nil {
+				require.Equal(common.BigToHash(test.wantSender), statedb.GetState(token, common.BytesToHash(test.sender.Bytes())))
+				require.Equal(common.BigToHash(test.wantTo), statedb.GetState(token, common.BytesToHash(recipient.Bytes())))
+			}
+
+			newLogs := statedb.Logs()[logs:]
+			if !test.wantLog {
+				require.Empty(newLogs)
+				return
+			}
+			require.Len(newLogs, 1)
+			require.Equal(token, newLogs[0].Address)
+			require.Equal([]common.Hash{
+				transferEventTopic,
+				common.BytesToHash(test.sender.Bytes()),
+				common.BytesToHash(recipient.Bytes()),
+			}, newLogs[0].Topics)
+			require.Equal(test.wantTo, new(big.Int).SetBytes(newLogs[0].Data))
+		})
+	}
+}
+
+func TestTokenCodeDeployWorkload(t *testing.T) {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	token := deployTestCode(t, statedb, tokenCode, NewDeployWorkload().TxGas())
+	require.Equal(t, common.MaxHash, statedb.GetState(token, common.BytesToHash(testDeployer.Bytes())))
+}
+
+func TestStorageCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		nonce uint64
+		slots uint64
+	}{
+		{name: "no slots", nonce: 3, slots: 0},
+		{name: "one slot", nonce: 0, slots: 1},
+		{name: "many slots", nonce: 2, slots: 10},
+		{name: "large batch", nonce: 1, slots: 200},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			contract := deployTestCode(t, statedb, storageCode, storageDeployGas)
+
+			workload := NewStorageWorkload(test.slots).(*callWorkload)
+			data := workload.data(testDeployer, test.nonce)
+			start := test.nonce * test.slots
+			cfg := newRuntimeConfig(t, statedb, testDeployer, data, false, workload.TxGas())
+			_, _, err := runtime.Call(contract, data, cfg)
+			require.NoError(err)
+
+			for i := uint64(0); i < start+test.slots+1; i++ {
+				want := common.Hash{}
+				if i >= start && i < start+test.slots {
+					want = common.BigToHash(new(big.Int).SetUint64(i + 1))
+				}
+				require.Equal(want, statedb.GetState(contract, common.BigToHash(new(big.Int).SetUint64(i))), "slot %d", i)
+			}
+		})
+	}
+}
@@ -18,4 +18,13 @@ interface IAllowList {
 
   // Read the status of [addr].
   function readAllowList(address addr) external view returns (uint256 role);
+
+  // Read the number of addresses with [role].
+  // Only available if the precompile was configured with enumerableMembers.
+  function getRoleMemberCount(uint256 role) external view returns (uint256 count);
+
+  // Read at most [limit] addresses with [role], starting from the [offset]-th one.
+  // The order of the addresses changes when roles are modified.
+  // Only available if the precompile was configured with enumerableMembers.
+  function getRoleMembers(uint256 role, uint256 offset, uint256 limit) external view returns (address[] memory members);
 }
@@ -0,0 +1,28 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// SPDX-License-Identifier: MIT
+
+pragma solidity ^0.8.24;
+
+// Public keys are compressed BLS12-381 G1 points (48 bytes) and signatures are
+// compressed G2 points (96 bytes), using the same scheme as Avalanche Warp Messaging.
+interface IBLSVerifier {
+  // aggregatePublicKeys returns the compressed aggregate of [publicKeys].
+  // Reverts if any of [publicKeys] is malformed.
+  function aggregatePublicKeys(bytes[] calldata publicKeys) external view returns (bytes memory aggregatePublicKey);
+
+  // aggregateVerify returns whether [signature] is an aggregate signature of [message] by all of [publicKeys].
+  function aggregateVerify(
+    bytes[] calldata publicKeys,
+    bytes calldata message,
+    bytes calldata signature
+  ) external view returns (bool valid);
+
+  // verify returns whether [signature] is a signature of [message] by [publicKey].
+  function verify(
+    bytes calldata publicKey,
+    bytes calldata message,
+    bytes calldata signature
+  ) external view returns (bool valid);
+}
@@ -0,0 +1,21 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// SPDX-License-Identifier: MIT
+
+pragma solidity ^0.8.24;
+
+// IBlockHistory serves the headers of the last 8191 ancestors of the current
+// block, unlike BLOCKHASH which only serves the last 256 blocks. Every node,
+// including one that joined with state sync, has the headers of this window.
+// Calls revert if [blockNumber] is not lower than the current block number, or
+// if it is more than 8191 blocks below it.
+interface IBlockHistory {
+  // getBlockHash returns the hash of the block at [blockNumber].
+  function getBlockHash(uint256 blockNumber) external view returns (bytes32 blockHash);
+
+  // getBlockHeader returns the hash, timestamp, base fee and state root of the block at [blockNumber].
+  function getBlockHeader(
+    uint256 blockNumber
+  ) external view returns (bytes32 blockHash, uint256 timestamp, uint256 baseFee, bytes32 stateRoot);
+}
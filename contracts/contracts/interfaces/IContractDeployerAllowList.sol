@@ -0,0 +1,23 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.24;
+import "./IAllowList.sol";
+
+// Code hash rules are only available if the precompile was configured with codeHashRules.
+// Addresses without the enabled role can then deploy contracts whose init code
+// hash or runtime code hash has been allowed by an admin.
+interface IContractDeployerAllowList is IAllowList {
+  event InitCodeHashSet(bytes32 indexed codeHash, address indexed sender, bool allowed);
+  event RuntimeCodeHashSet(bytes32 indexed codeHash, address indexed sender, bool allowed);
+
+  // Allow or disallow the deployment of contracts with the init code hash [codeHash].
+  function setAllowedInitCodeHash(bytes32 codeHash, bool allowed) external;
+
+  // Allow or disallow the deployment of contracts with the runtime code hash [codeHash].
+  function setAllowedRuntimeCodeHash(bytes32 codeHash, bool allowed) external;
+
+  // Read whether contracts with the init code hash [codeHash] can be deployed by anyone.
+  function isAllowedInitCodeHash(bytes32 codeHash) external view returns (bool allowed);
+
+  // Read whether contracts with the runtime code hash [codeHash] can be deployed by anyone.
+  function isAllowedRuntimeCodeHash(bytes32 codeHash) external view returns (bool allowed);
+}
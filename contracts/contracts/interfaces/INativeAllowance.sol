@@ -0,0 +1,51 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// SPDX-License-Identifier: MIT
+
+pragma solidity ^0.8.24;
+
+// INativeAllowance provides ERC-20 allowance semantics over the native coin, so
+// that it can be used in approve/transferFrom flows without being wrapped.
+// Balances are the native balances of the accounts, and transferFrom moves
+// native coin directly between them. Permits follow EIP-2612 with the EIP-712
+// domain (name "NativeAllowance", version "1", chain ID, precompile address,
+// salt) returned by DOMAIN_SEPARATOR.
+interface INativeAllowance {
+  event Approval(address indexed owner, address indexed spender, uint256 value);
+  event Transfer(address indexed from, address indexed to, uint256 value);
+
+  // balanceOf returns the native balance of [account].
+  function balanceOf(address account) external view returns (uint256 balance);
+
+  // allowance returns the amount of native coin that [spender] can transfer on behalf of [owner].
+  function allowance(address owner, address spender) external view returns (uint256 remaining);
+
+  // approve sets the allowance of [spender] over the native coin of the caller to [value].
+  // An allowance of type(uint256).max is never decreased by transferFrom.
+  function approve(address spender, uint256 value) external returns (bool success);
+
+  // transferFrom transfers [value] native coin from [from] to [to] using the
+  // allowance of the caller.
+  function transferFrom(address from, address to, uint256 value) external returns (bool success);
+
+  // permit sets the allowance of [spender] over the native coin of [owner] to
+  // [value] with an EIP-712 signature of [owner], as specified by EIP-2612.
+  function permit(
+    address owner,
+    address spender,
+    uint256 value,
+    uint256 deadline,
+    uint8 v,
+    bytes32 r,
+    bytes32 s
+  ) external;
+
+  // nonces returns the nonce of [owner] for permit signatures.
+  function nonces(address owner) external view returns (uint256 nonce);
+
+  // DOMAIN_SEPARATOR returns the EIP-712 domain separator used for permit signatures.
+  // Its salt is the timestamp of the upgrade that activated the precompile, so
+  // permits signed before the precompile was disabled and enabled again are invalid.
+  function DOMAIN_SEPARATOR() external view returns (bytes32 domainSeparator);
+}
@@ -0,0 +1,41 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.24;
+import "./IAllowList.sol";
+
+// Quotas are only available if the precompile was configured with quotas.
+// Admins can then limit the gas used by an address in a window of gasWindow
+// seconds, the value of each of its transactions and the value it transfers
+// in a day. Windows slide: the usage of the previous window is weighted by
+// its overlap with the last gasWindow seconds (or day). Value limits also
+// apply to native allowance transfers from the account. A zero limit means
+// that the corresponding usage is not limited. Transactions of an address
+// with a quota are charged 20,000 gas for recording their gas usage if
+// maxGas is set, and 60,000 gas for recording their value if maxDailyValue
+// is set and they transfer value.
+interface ITxAllowList is IAllowList {
+  event QuotaSet(
+    address indexed account,
+    address indexed sender,
+    uint64 maxGas,
+    uint64 gasWindow,
+    uint256 maxTxValue,
+    uint256 maxDailyValue
+  );
+
+  // Set the quota of [account], or remove it if all limits are zero.
+  function setQuota(
+    address account,
+    uint64 maxGas,
+    uint64 gasWindow,
+    uint256 maxTxValue,
+    uint256 maxDailyValue
+  ) external;
+
+  // Read the quota of [account].
+  function readQuota(
+    address account
+  ) external view returns (uint64 maxGas, uint64 gasWindow, uint256 maxTxValue, uint256 maxDailyValue);
+
+  // Read the estimated usage of the quota of [account] in its sliding windows.
+  function readQuotaUsage(address account) external view returns (uint64 gasUsed, uint256 valueUsed);
+}
@@ -94,6 +94,7 @@ var (
 	badBlockCounter               = metrics.NewRegisteredCounter("chain/block/bad/count", nil)
 
 	txUnindexTimer      = metrics.NewRegisteredCounter("chain/txs/unindex", nil)
+	historyPruneTimer   = metrics.NewRegisteredCounter("chain/history/prune", nil)
 	acceptedTxsCounter  = metrics.NewRegisteredCounter("chain/txs/accepted", nil)
 	processedTxsCounter = metrics.NewRegisteredCounter("chain/txs/processed", nil)
 
@@ -185,8 +186,13 @@ type CacheConfig struct {
 	AcceptedCacheSize               int     // Depth of accepted headers cache and accepted logs cache at the accepted tip
 	TransactionHistory              uint64  // Number of recent blocks for which to maintain transaction lookup indices
 	SkipTxIndexing                  bool    // Whether to skip transaction indexing
+	BlockHistory                    uint64  // Number of recent blocks for which to keep bodies and receipts
+	LogIndexing                     bool    // Whether to maintain the (address, topic0, block) log index on accept
 	StateHistory                    uint64  // Number of blocks from head whose state histories are reserved.
 	StateScheme                     string  // Scheme used to store ethereum states and merkle tree nodes on top
+	StateDiffDir                    string  // Directory to write the state diffs of accepted blocks to, or empty to disable them
+	StateDiffFileSize               uint64  // Size in bytes at which a state diff file is rotated
+	StateDiffMaxFiles               int     // Number of state diff files to keep, or 0 to keep all of them
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
@@ -337,7 +343,16 @@ type BlockChain struct {
 	acceptedLogsCache FIFOCache[common.Hash, [][]*types.Log]
 
 	// [txIndexTailLock] is used to synchronize the updating of the tx index tail.
+	// It is also held while pruning block history, as that deletes tx indices.
 	txIndexTailLock sync.Mutex
+
+	// [stateDiffs] writes the state diffs of accepted blocks, if enabled.
+	stateDiffs *stateDiffWriter
+
+	// [processing] holds the headers of the blocks that were verified but are
+	// not yet accepted or rejected, keyed by hash.
+	processing     map[common.Hash]*types.Header
+	processingLock sync.RWMutex
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -389,6 +404,7 @@ func NewBlockChain(
 		acceptorQueue:       make(chan *types.Block, cacheConfig.AcceptorQueueLimit),
 		quit:                make(chan struct{}),
 		acceptedLogsCache:   NewFIFOCache[common.Hash, [][]*types.Log](cacheConfig.AcceptedCacheSize),
+		processing:          make(map[common.Hash]*types.Header),
 	}
 	bc.stateCache = state.NewDatabaseWithNodeDB(bc.db, bc.triedb)
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
@@ -405,6 +421,13 @@ func NewBlockChain(
 
 	bc.currentBlock.Store(nil)
 
+	if cacheConfig.StateDiffDir != "" {
+		bc.stateDiffs, err = newStateDiffWriter(cacheConfig.StateDiffDir, cacheConfig.StateDiffFileSize, cacheConfig.StateDiffMaxFiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the state manager
 	bc.stateManager = NewTrieWriter(bc.triedb, cacheConfig)
 
@@ -447,12 +470,21 @@ func NewBlockChain(
 	// Warm up [hc.acceptedNumberCache] and [acceptedLogsCache]
 	bc.warmAcceptedCaches()
 
+	// Record the first block whose logs will be indexed on accept
+	bc.initLogIndexTail()
+
 	// if txlookup limit is 0 (uindexing disabled), we don't need to repair the tx index tail.
 	if bc.cacheConfig.TransactionHistory != 0 {
 		latestStateSynced := rawdb.GetLatestSyncPerformed(bc.db)
 		bc.setTxIndexTail(latestStateSynced)
 	}
 
+	// if block history is 0 (history pruning disabled), we don't need to repair the block history tail.
+	if bc.cacheConfig.BlockHistory != 0 {
+		latestStateSynced := rawdb.GetLatestSyncPerformed(bc.db)
+		bc.setBlockHistoryTail(latestStateSynced)
+	}
+
 	// Start processing accepted blocks effects in the background
 	go bc.startAcceptor()
 
@@ -474,6 +506,25 @@ func NewBlockChain(
 			bc.maintainTxIndex(headCh)
 		}()
 	}
+
+	// Start block history pruner if required.
+	if bc.cacheConfig.BlockHistory != 0 {
+		bc.wg.Add(1)
+		var (
+			headCh = make(chan ChainEvent, 1) // Buffered to avoid locking up the event feed
+			sub    = bc.SubscribeChainAcceptedEvent(headCh)
+		)
+		go func() {
+			defer bc.wg.Done()
+			if sub == nil {
+				log.Warn("could not create chain accepted subscription to prune block history")
+				return
+			}
+			defer sub.Unsubscribe()
+
+			bc.maintainBlockHistory(headCh)
+		}()
+	}
 	return bc, nil
 }
 
@@ -495,6 +546,10 @@ func (bc *BlockChain) unindexBlocks(tail uint64, head uint64, done chan struct{}
 		return
 	}
 
+	// Block history pruning may have moved the tail forward since it was read.
+	if current := rawdb.ReadTxIndexTail(bc.db); current != nil && *current > tail {
+		tail = *current
+	}
 	if head-txLookupLimit+1 >= tail {
 		// Unindex a part of stale indices and forward index tail to HEAD-limit
 		rawdb.UnindexTransactions(bc.db, tail, head-txLookupLimit+1, bc.quit)
@@ -557,9 +612,97 @@ func (bc *BlockChain) maintainTxIndex(headCh <-chan ChainEvent) {
 	}
 }
 
+// pruneBlockHistory deletes the bodies, receipts and tx indices of blocks
+// older than the configured block history.
+func (bc *BlockChain) pruneBlockHistory(tail uint64, head uint64, done chan struct{}) {
+	start := time.Now()
+	blockHistory := bc.cacheConfig.BlockHistory
+	bc.txIndexTailLock.Lock()
+	defer func() {
+		historyPruneTimer.Inc(time.Since(start).Milliseconds())
+		bc.txIndexTailLock.Unlock()
+		close(done)
+		bc.wg.Done()
+	}()
+
+	// The tail may have been moved forward by state sync since it was read.
+	if current := rawdb.ReadBlockHistoryTail(bc.db); current != nil && *current > tail {
+		tail = *current
+	}
+	if head < blockHistory || head-blockHistory+1 <= tail {
+		return
+	}
+	rawdb.PruneBlockHistory(bc.db, tail, head-blockHistory+1, bc.quit)
+
+	// The tx indices of pruned blocks were deleted along with their bodies, so
+	// the unindexer must not revisit them.
+	newTail := rawdb.ReadBlockHistoryTail(bc.db)
+	if txTail := rawdb.ReadTxIndexTail(bc.db); txTail != nil && *txTail < *newTail {
+		rawdb.WriteTxIndexTail(bc.db, *newTail)
+	}
+}
+
+// maintainBlockHistory is responsible for the deletion of block bodies and
+// receipts outside of the configured block history. Headers are kept, and
+// removed data is never reconstructed.
+// Invariant: If BlockHistory is 0, it means all blocks will be preserved.
+// Meaning that this function should never be called.
+func (bc *BlockChain) maintainBlockHistory(headCh <-chan ChainEvent) {
+	blockHistory := bc.cacheConfig.BlockHistory
+
+	var (
+		done    chan struct{} // Non-nil if background pruning routine is active.
+		pending uint64        // Latest head accepted while pruning was active.
+	)
+	log.Info("Initialized block history pruner", "limit", blockHistory)
+
+	prune := func(head uint64) {
+		done = make(chan struct{})
+		// Note: tail will not be nil since it is initialized in NewBlockChain.
+		tail := rawdb.ReadBlockHistoryTail(bc.db)
+		bc.wg.Add(1)
+		go bc.pruneBlockHistory(*tail, head, done)
+	}
+
+	// Launch the initial pruning if chain is not empty, so that a lowered
+	// limit takes effect without waiting for the next accepted block.
+	if head := bc.LastAcceptedBlock(); head.NumberU64() >= blockHistory {
+		prune(head.NumberU64())
+	}
+
+	for {
+		select {
+		case head := <-headCh:
+			headNum := head.Block.NumberU64()
+			if headNum < blockHistory {
+				break
+			}
+
+			if done == nil {
+				prune(headNum)
+			} else {
+				pending = headNum
+			}
+		case <-done:
+			done = nil
+			if pending != 0 {
+				prune(pending)
+				pending = 0
+			}
+		case <-bc.quit:
+			if done != nil {
+				log.Info("Waiting background block history pruner to exit")
+				<-done
+			}
+			return
+		}
+	}
+}
+
 // writeBlockAcceptedIndices writes any indices that must be persisted for accepted block.
 // This includes the following:
 // - transaction lookup indices
+// - log index entries (if enabled)
 // - updating the acceptor tip index
 func (bc *BlockChain) writeBlockAcceptedIndices(b *types.Block) error {
 	batch := bc.db.NewBatch()
@@ -576,12 +719,31 @@ func (bc *BlockChain) batchBlockAcceptedIndices(batch ethdb.Batch, b *types.Bloc
 	if !bc.cacheConfig.SkipTxIndexing {
 		rawdb.WriteTxLookupEntriesByBlock(batch, b)
 	}
+	if bc.cacheConfig.LogIndexing {
+		for _, receipt := range rawdb.ReadRawReceipts(bc.db, b.Hash(), b.NumberU64()) {
+			rawdb.WriteLogIndexEntries(batch, b.NumberU64(), receipt.Logs)
+		}
+	}
 	if err := rawdb.WriteAcceptorTip(batch, b.Hash()); err != nil {
 		return fmt.Errorf("%w: failed to write acceptor tip key", err)
 	}
 	return nil
 }
 
+// initLogIndexTail records the first block whose logs will be indexed by the
+// acceptor. If log indexing is disabled, the marker is removed so that
+// re-enabling it later does not treat the index as contiguous across the
+// blocks accepted in between.
+func (bc *BlockChain) initLogIndexTail() {
+	if !bc.cacheConfig.LogIndexing {
+		rawdb.DeleteLogIndexTail(bc.db)
+		return
+	}
+	if rawdb.ReadLogIndexTail(bc.db) == nil {
+		rawdb.WriteLogIndexTail(bc.db, bc.lastAccepted.NumberU64()+1)
+	}
+}
+
 // flattenSnapshot attempts to flatten a block of [hash] to disk.
 func (bc *BlockChain) flattenSnapshot(postAbortWork func() error, hash common.Hash) error {
 	// If snapshots are not initialized, perform [postAbortWork] immediately.
@@ -667,6 +829,12 @@ func (bc *BlockChain) startAcceptor() {
 			log.Crit("failed to write accepted block effects", "err", err)
 		}
 
+		if bc.stateDiffs != nil {
+			if err := bc.stateDiffs.accept(next); err != nil {
+				log.Error("failed to write state diff", "number", next.NumberU64(), "hash", next.Hash(), "err", err)
+			}
+		}
+
 		// Ensure [hc.acceptedNumberCache] and [acceptedLogsCache] have latest content
 		bc.hc.acceptedNumberCache.Put(next.NumberU64(), next.Header())
 		logs := bc.collectUnflattenedLogs(next, false)
@@ -1034,6 +1202,12 @@ func (bc *BlockChain) stopWithoutSaving() {
 	// Waiting for background processes to complete
 	log.Info("Waiting for background processes to complete")
 	bc.wg.Wait()
+
+	if bc.stateDiffs != nil {
+		if err := bc.stateDiffs.close(); err != nil {
+			log.Error("Failed to close state diff file", "err", err)
+		}
+	}
 }
 
 // Stop stops the blockchain service. If any imports are currently in progress
@@ -1161,6 +1335,7 @@ func (bc *BlockChain) Accept(block *types.Block) error {
 	// Enqueue block in the acceptor
 	bc.lastAccepted = block
 	bc.addAcceptorQueue(block)
+	bc.removeProcessing(block.Hash())
 	acceptedBlockGasUsedCounter.Inc(int64(block.GasUsed()))
 	acceptedTxsCounter.Inc(int64(len(block.Transactions())))
 	if baseFee := block.BaseFee(); baseFee != nil {
@@ -1218,6 +1393,10 @@ func (bc *BlockChain) Reject(block *types.Block) error {
 			log.Error("unable to discard snap from rejected block", "block", block.Hash(), "number", block.NumberU64(), "root", block.Root())
 		}
 	}
+	if bc.stateDiffs != nil {
+		bc.stateDiffs.discard(block.Hash())
+	}
+	bc.removeProcessing(block.Hash())
 
 	// Remove the block since its data is no longer needed
 	batch := bc.db.NewBatch()
@@ -1229,6 +1408,21 @@ func (bc *BlockChain) Reject(block *types.Block) error {
 	return nil
 }
 
+// removeProcessing removes the decided block [hash] from the processing
+// blocks, along with any block that can no longer be accepted. Assumes
+// [bc.chainmu] is held.
+func (bc *BlockChain) removeProcessing(hash common.Hash) {
+	bc.processingLock.Lock()
+	defer bc.processingLock.Unlock()
+
+	delete(bc.processing, hash)
+	for hash, processing := range bc.processing {
+		if processing.Number.Cmp(bc.lastAccepted.Number()) <= 0 {
+			delete(bc.processing, hash)
+		}
+	}
+}
+
 // writeKnownBlock updates the head block flag with a known block
 // and introduces chain reorg if necessary.
 func (bc *BlockChain) writeKnownBlock(block *types.Block) error {
@@ -1451,6 +1645,9 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 	if err != nil {
 		return err
 	}
+	if bc.stateDiffs != nil && writes {
+		statedb.EnableStateDiff()
+	}
 	blockStateInitTimer.Inc(time.Since(substart).Milliseconds())
 
 	// Enable prefetching to pull in trie node paths while processing transactions
@@ -1509,6 +1706,12 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 	if err := bc.writeBlockAndSetHead(block, receipts, logs, statedb); err != nil {
 		return err
 	}
+	if bc.stateDiffs != nil {
+		bc.stateDiffs.add(block, statedb.StateDiff())
+	}
+	bc.processingLock.Lock()
+	bc.processing[block.Hash()] = block.Header()
+	bc.processingLock.Unlock()
 	// Update the metrics touched during block commit
 	accountCommitTimer.Inc(statedb.AccountCommits.Milliseconds())   // Account commits are complete, we can mark them
 	storageCommitTimer.Inc(statedb.StorageCommits.Milliseconds())   // Storage commits are complete, we can mark them
@@ -2215,6 +2418,11 @@ func (bc *BlockChain) ResetToStateSyncedBlock(block *types.Block) error {
 	if err := rawdb.WriteSyncPerformed(batch, block.NumberU64()); err != nil {
 		return err
 	}
+	// Blocks below the state synced block are not available locally, so the
+	// log index can only be contiguous from here onwards.
+	if bc.cacheConfig.LogIndexing {
+		rawdb.WriteLogIndexTail(batch, block.NumberU64()+1)
+	}
 
 	if err := batch.Write(); err != nil {
 		return err
@@ -2224,6 +2432,9 @@ func (bc *BlockChain) ResetToStateSyncedBlock(block *types.Block) error {
 	if bc.cacheConfig.TransactionHistory != 0 {
 		bc.setTxIndexTail(block.NumberU64())
 	}
+	if bc.cacheConfig.BlockHistory != 0 {
+		bc.setBlockHistoryTail(block.NumberU64())
+	}
 
 	// Update all in-memory chain markers
 	bc.lastAccepted = block
@@ -2274,3 +2485,18 @@ func (bc *BlockChain) setTxIndexTail(newTail uint64) error {
 	}
 	return nil
 }
+
+// setBlockHistoryTail moves the block history tail forward to [newTail] if it
+// is behind. The genesis block is never pruned, so the tail is at least 1.
+func (bc *BlockChain) setBlockHistoryTail(newTail uint64) {
+	bc.txIndexTailLock.Lock()
+	defer bc.txIndexTailLock.Unlock()
+
+	if newTail == 0 {
+		newTail = 1
+	}
+	if tail := rawdb.ReadBlockHistoryTail(bc.db); tail == nil || newTail > *tail {
+		log.Info("Repairing block history tail", "new", newTail)
+		rawdb.WriteBlockHistoryTail(bc.db, newTail)
+	}
+}
@@ -27,7 +27,9 @@
 package core
 
 import (
+	"bytes"
 	"math/big"
+	"sort"
 
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/consensus"
@@ -198,6 +200,44 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	return receipts
 }
 
+// HistoryTail returns the number of the oldest block whose body and receipts
+// have not been pruned, or 0 if block history has never been pruned. The
+// genesis block is always kept.
+func (bc *BlockChain) HistoryTail() uint64 {
+	if tail := rawdb.ReadBlockHistoryTail(bc.db); tail != nil {
+		return *tail
+	}
+	return 0
+}
+
+// GetProcessingHeader returns the header of block [hash] if it was verified
+// but is not yet accepted or rejected, or nil otherwise.
+func (bc *BlockChain) GetProcessingHeader(hash common.Hash) *types.Header {
+	bc.processingLock.RLock()
+	defer bc.processingLock.RUnlock()
+
+	return bc.processing[hash]
+}
+
+// ProcessingBlocks returns the headers of the blocks that were verified but
+// are not yet accepted or rejected, sorted by number and hash.
+func (bc *BlockChain) ProcessingBlocks() []*types.Header {
+	bc.processingLock.RLock()
+	headers := make([]*types.Header, 0, len(bc.processing))
+	for _, header := range bc.processing {
+		headers = append(headers, header)
+	}
+	bc.processingLock.RUnlock()
+
+	sort.Slice(headers, func(i, j int) bool {
+		if c := headers[i].Number.Cmp(headers[j].Number); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(headers[i].Hash().Bytes(), headers[j].Hash().Bytes()) < 0
+	})
+	return headers
+}
+
 // GetCanonicalHash returns the canonical hash for a given block number
 func (bc *BlockChain) GetCanonicalHash(number uint64) common.Hash {
 	return bc.hc.GetCanonicalHash(number)
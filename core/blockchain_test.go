@@ -4,10 +4,14 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/subnet-evm/consensus/dummy"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
@@ -300,6 +304,75 @@ func TestBlockChainOfflinePruningUngracefulShutdown(t *testing.T) {
 	}
 }
 
+func TestBlockChainOfflinePruningResume(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		chainDB = rawdb.NewMemoryDatabase()
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int), FeeConfig: params.DefaultFeeConfig},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	// Use archive mode, so the state of every block is on disk before pruning.
+	blockchain, err := createBlockChain(chainDB, archiveConfig, gspec, common.Hash{})
+	require.NoError(t, err)
+
+	signer := types.HomesteadSigner{}
+	_, chain, _, err := GenerateChainWithGenesis(gspec, blockchain.engine, 10, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+	lastAcceptedHash := blockchain.LastConsensusAcceptedBlock().Hash()
+	targetRoot := blockchain.LastAcceptedBlock().Root()
+	blockchain.Stop()
+
+	// Mark the range of the first block's state root as swept by an
+	// interrupted run, so it must be skipped when pruning resumes.
+	skipped := chain[0].Root()
+	require.NoError(t, rawdb.WriteOfflinePruningCursor(chainDB, skipped[0], targetRoot, nil))
+
+	progress := pruner.NewProgress()
+	p, err := pruner.NewPruner(chainDB, pruner.Config{
+		Datadir:     t.TempDir(),
+		BloomSize:   256,
+		Parallelism: 4,
+		Progress:    progress,
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.Prune(targetRoot))
+
+	report := progress.Report()
+	require.Equal(t, pruner.PhaseDone, report.Phase)
+	require.NotZero(t, report.Deleted)
+
+	for _, block := range chain[:len(chain)-1] {
+		root := block.Root()
+		has, err := chainDB.Has(root[:])
+		require.NoError(t, err)
+		require.Equal(t, root[0] == skipped[0], has, "state root of block %d", block.NumberU64())
+	}
+	for r := 0; r < 256; r++ {
+		_, _, ok := rawdb.ReadOfflinePruningCursor(chainDB, byte(r))
+		require.False(t, ok, "cursor of range %d not deleted", r)
+	}
+
+	blockchain, err = createBlockChain(chainDB, archiveConfig, gspec, lastAcceptedHash)
+	require.NoError(t, err)
+	defer blockchain.Stop()
+	require.True(t, blockchain.HasState(targetRoot))
+}
+
 func testRepopulateMissingTriesParallel(t *testing.T, parallelism int) {
 	var (
 		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -635,6 +708,77 @@ func TestTransactionIndices(t *testing.T) {
 	}
 }
 
+func TestBlockHistoryPruning(t *testing.T) {
+	// Configure and generate a sample block chain
+	require := require.New(t)
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		funds   = big.NewInt(10000000000000)
+		gspec   = &Genesis{
+			Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+			Alloc:  GenesisAlloc{addr1: {Balance: funds}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, dummy.NewFaker(), 128, 10, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		require.NoError(err)
+		block.AddTx(tx)
+	})
+	require.NoError(err)
+
+	conf := &CacheConfig{
+		TrieCleanLimit:            256,
+		TrieDirtyLimit:            256,
+		TrieDirtyCommitTarget:     20,
+		TriePrefetcherParallelism: 4,
+		Pruning:                   true,
+		CommitInterval:            16,
+		SnapshotLimit:             256,
+		SnapshotNoBuild:           true, // Ensure the test errors if snapshot initialization fails
+		AcceptorQueueLimit:        64,
+		TransactionHistory:        64,
+		BlockHistory:              32,
+	}
+
+	chainDB := rawdb.NewMemoryDatabase()
+	chain, err := createBlockChain(chainDB, conf, gspec, common.Hash{})
+	require.NoError(err)
+
+	_, err = chain.InsertChain(blocks)
+	require.NoError(err)
+	for _, block := range blocks {
+		require.NoError(chain.Accept(block))
+	}
+	chain.DrainAcceptorQueue()
+
+	lastAcceptedBlock := blocks[len(blocks)-1]
+	tail := lastAcceptedBlock.NumberU64() - conf.BlockHistory + 1
+	require.Eventually(func() bool { return chain.HistoryTail() == tail }, 30*time.Second, 100*time.Millisecond)
+	chain.Stop()
+
+	// The tx indices of pruned blocks are deleted with their bodies, so the
+	// tx index tail is moved past the longer transaction history.
+	require.Equal(tail, *rawdb.ReadTxIndexTail(chainDB))
+	require.True(rawdb.HasBody(chainDB, chain.Genesis().Hash(), 0))
+	for _, block := range blocks {
+		number := block.NumberU64()
+		require.True(rawdb.HasHeader(chainDB, block.Hash(), number))
+		require.Equal(number >= tail, rawdb.HasBody(chainDB, block.Hash(), number), "body %d", number)
+		require.Equal(number >= tail, rawdb.HasReceipts(chainDB, block.Hash(), number), "receipts %d", number)
+		require.Equal(number >= tail, rawdb.ReadTxLookupEntry(chainDB, block.Transactions()[0].Hash()) != nil, "tx index %d", number)
+	}
+
+	// The chain can be restarted from the retained blocks.
+	chain, err = createBlockChain(chainDB, conf, gspec, lastAcceptedBlock.Hash())
+	require.NoError(err)
+	require.Equal(tail, chain.HistoryTail())
+	chain.Stop()
+}
+
 func getTail(limit uint64, lastAccepted uint64) *uint64 {
 	if limit == 0 {
 		return nil
@@ -648,6 +792,198 @@ func getTail(limit uint64, lastAccepted uint64) *uint64 {
 	return &tail
 }
 
+func TestStateDiffExport(t *testing.T) {
+	require := require.New(t)
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = common.Address{2}
+		funds   = big.NewInt(10000000000000)
+		gspec   = &Genesis{
+			Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+			Alloc:  GenesisAlloc{addr1: {Balance: funds}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, dummy.NewFaker(), 8, 10, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		require.NoError(err)
+		block.AddTx(tx)
+	})
+	require.NoError(err)
+
+	// Rotate after every block and keep the files of the last 4 blocks.
+	conf := *archiveConfig
+	conf.StateDiffDir = t.TempDir()
+	conf.StateDiffFileSize = 1
+	conf.StateDiffMaxFiles = 4
+
+	chain, err := createBlockChain(rawdb.NewMemoryDatabase(), &conf, gspec, common.Hash{})
+	require.NoError(err)
+	_, err = chain.InsertChain(blocks)
+	require.NoError(err)
+	for _, block := range blocks {
+		require.NoError(chain.Accept(block))
+	}
+	chain.DrainAcceptorQueue()
+	require.Empty(chain.stateDiffs.pending)
+	chain.Stop()
+
+	files, err := filepath.Glob(filepath.Join(conf.StateDiffDir, "statediff-*.jsonl"))
+	require.NoError(err)
+	require.Len(files, conf.StateDiffMaxFiles)
+	for i, file := range files {
+		block := blocks[len(blocks)-conf.StateDiffMaxFiles+i]
+		data, err := os.ReadFile(file)
+		require.NoError(err)
+
+		var diff BlockStateDiff
+		require.NoError(json.Unmarshal(data, &diff))
+		require.EqualValues(block.NumberU64(), diff.Number)
+		require.Equal(block.Hash(), diff.Hash)
+		require.Equal(block.Root(), diff.Root)
+
+		// Each block sends 10000 wei from addr1 to addr2.
+		var sender, recipient *state.AccountDiff
+		for _, account := range diff.Accounts {
+			switch account.Address {
+			case addr1:
+				sender = account
+			case addr2:
+				recipient = account
+			}
+		}
+		require.NotNil(sender)
+		require.EqualValues(block.NumberU64()-1, sender.Old.Nonce)
+		require.EqualValues(block.NumberU64(), sender.New.Nonce)
+		require.NotNil(recipient)
+		require.Equal(new(big.Int).Mul(big.NewInt(10000), block.Number()), recipient.New.Balance.ToInt())
+	}
+}
+
+func TestStateDiffExportGap(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	w, err := newStateDiffWriter(dir, 0, 0)
+	require.NoError(err)
+
+	blocks := make([]*types.Block, 3)
+	for i := range blocks {
+		blocks[i] = types.NewBlockWithHeader(&types.Header{
+			Number: big.NewInt(int64(i + 1)),
+			Root:   common.Hash{byte(i + 1)},
+		})
+	}
+	accounts := []*state.AccountDiff{{Address: common.Address{1}}}
+	w.add(blocks[0], accounts)
+	w.add(blocks[2], accounts)
+	// The diff of the second block is missing, as if it was processed before
+	// the writer was enabled.
+	for _, block := range blocks {
+		require.NoError(w.accept(block))
+	}
+	require.NoError(w.close())
+
+	data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%s%020d%s", stateDiffFilePrefix, 1, stateDiffFileSuffix)))
+	require.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(lines, len(blocks))
+	for i, line := range lines {
+		var diff BlockStateDiff
+		require.NoError(json.Unmarshal([]byte(line), &diff))
+		require.EqualValues(blocks[i].NumberU64(), diff.Number)
+		require.Equal(blocks[i].Hash(), diff.Hash)
+		require.Equal(blocks[i].Root(), diff.Root)
+		if i == 1 {
+			require.True(diff.Missing)
+			require.Empty(diff.Accounts)
+		} else {
+			require.False(diff.Missing)
+			require.Len(diff.Accounts, 1)
+		}
+	}
+}
+
+func TestProcessingBlocks(t *testing.T) {
+	require := require.New(t)
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = common.Address{2}
+		funds   = big.NewInt(10000000000000)
+		gspec   = &Genesis{
+			Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+			Alloc:  GenesisAlloc{addr1: {Balance: funds}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	transfer := func(value int64) func(int, *BlockGen) {
+		return func(i int, block *BlockGen) {
+			tx, err := types.SignTx(types.NewTransaction(block.TxNonce(addr1), addr2, big.NewInt(value), params.TxGas, nil, nil), signer, key1)
+			require.NoError(err)
+			block.AddTx(tx)
+		}
+	}
+	genDB, chainA, _, err := GenerateChainWithGenesis(gspec, dummy.NewFaker(), 3, 10, transfer(1))
+	require.NoError(err)
+	chainB, _, err := GenerateChain(gspec.Config, gspec.ToBlock(), dummy.NewFaker(), genDB, 2, 10, transfer(2))
+	require.NoError(err)
+
+	chain, err := createBlockChain(rawdb.NewMemoryDatabase(), DefaultCacheConfig, gspec, common.Hash{})
+	require.NoError(err)
+	defer chain.Stop()
+
+	_, err = chain.InsertChain(chainA)
+	require.NoError(err)
+	_, err = chain.InsertChain(chainB)
+	require.NoError(err)
+
+	hashes := func() []common.Hash {
+		var hashes []common.Hash
+		for _, header := range chain.ProcessingBlocks() {
+			hashes = append(hashes, header.Hash())
+		}
+		return hashes
+	}
+	blockHashes := func(blocks ...*types.Block) []common.Hash {
+		var hashes []common.Hash
+		for _, block := range blocks {
+			hashes = append(hashes, block.Hash())
+		}
+		return hashes
+	}
+	processing := hashes()
+	require.Len(processing, 5)
+	require.ElementsMatch(blockHashes(chainA[0], chainB[0]), processing[:2])
+	require.ElementsMatch(blockHashes(chainA[1], chainB[1]), processing[2:4])
+	require.Equal(chainA[2].Hash(), processing[4])
+
+	// The state of a processing block is available, even off the preferred chain.
+	header := chain.GetProcessingHeader(chainB[1].Hash())
+	require.NotNil(header)
+	require.NotEqual(chainB[1].Hash(), chain.GetCanonicalHash(header.Number.Uint64()))
+	state, err := chain.StateAt(header.Root)
+	require.NoError(err)
+	require.EqualValues(4, state.GetBalance(addr2).Int64())
+
+	// Accepting a block removes it and the blocks conflicting with it.
+	require.NoError(chain.Accept(chainA[0]))
+	require.Nil(chain.GetProcessingHeader(chainA[0].Hash()))
+	processing = hashes()
+	require.Len(processing, 3)
+	require.ElementsMatch(blockHashes(chainA[1], chainB[1]), processing[:2])
+	require.Equal(chainA[2].Hash(), processing[2])
+
+	require.NoError(chain.Reject(chainB[0]))
+	require.NoError(chain.Reject(chainB[1]))
+	require.Equal(blockHashes(chainA[1], chainA[2]), hashes())
+
+	require.NoError(chain.Accept(chainA[1]))
+	require.NoError(chain.Accept(chainA[2]))
+	require.Empty(chain.ProcessingBlocks())
+}
+
 func TestTransactionSkipIndexing(t *testing.T) {
 	// Configure and generate a sample block chain
 	require := require.New(t)
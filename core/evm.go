@@ -100,6 +100,7 @@ func newEVMBlockContext(header *types.Header, chain ChainContext, author *common
 		CanTransfer:      CanTransfer,
 		Transfer:         Transfer,
 		GetHash:          GetHashFn(header, chain),
+		GetHeader:        GetHeaderFn(header, chain),
 		PredicateResults: predicateResults,
 		Coinbase:         beneficiary,
 		BlockNumber:      new(big.Int).Set(header.Number),
@@ -163,6 +164,42 @@ func GetHashFn(ref *types.Header, chain ChainContext) func(n uint64) common.Hash
 	}
 }
 
+// canonicalChainContext is implemented by chain contexts that can retrieve the
+// headers of the canonical chain by number.
+type canonicalChainContext interface {
+	GetHeaderByNumber(number uint64) *types.Header
+}
+
+// GetHeaderFn returns a GetHeaderFunc which retrieves the headers of the
+// ancestors of [ref] by number. Ancestors are found by following the parent
+// hashes of [ref] until reaching a header of the canonical chain, whose
+// ancestors are then read by number. This ensures the result only depends on
+// the ancestry of [ref], and not on which blocks are preferred locally. The
+// result is nil if the header is not in the database, so callers must only
+// request headers that every node is guaranteed to have.
+func GetHeaderFn(ref *types.Header, chain ChainContext) func(n uint64) *types.Header {
+	canonical, _ := chain.(canonicalChainContext)
+
+	return func(n uint64) *types.Header {
+		if ref.Number.Uint64() <= n {
+			return nil
+		}
+		hash, number := ref.ParentHash, ref.Number.Uint64()-1
+		for {
+			if canonical != nil {
+				if header := canonical.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+					return canonical.GetHeaderByNumber(n)
+				}
+			}
+			header := chain.GetHeader(hash, number)
+			if header == nil || number == n {
+				return header
+			}
+			hash, number = header.ParentHash, number-1
+		}
+	}
+}
+
 // CanTransfer checks whether there are enough funds in the address' account to make a transfer.
 // This does not take the necessary gas in to account to make the transfer valid.
 func CanTransfer(db vm.StateDB, addr common.Address, amount *big.Int) bool {
@@ -0,0 +1,66 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/consensus"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testHeaderChain struct {
+	headers   map[common.Hash]*types.Header
+	canonical map[uint64]*types.Header
+}
+
+func (*testHeaderChain) Engine() consensus.Engine { return nil }
+
+func (c *testHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return c.headers[hash]
+}
+
+func (c *testHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	return c.canonical[number]
+}
+
+func TestGetHeaderFn(t *testing.T) {
+	chain := &testHeaderChain{
+		headers:   make(map[common.Hash]*types.Header),
+		canonical: make(map[uint64]*types.Header),
+	}
+	// Build 10 blocks, with the first 6 accepted. The preferred chain forks
+	// off the chain of the processed block after block 5.
+	var (
+		parent    common.Hash
+		ancestors []*types.Header
+	)
+	for i := 0; i < 10; i++ {
+		header := &types.Header{ParentHash: parent, Number: big.NewInt(int64(i)), Time: uint64(i)}
+		chain.headers[header.Hash()] = header
+		if i <= 5 {
+			chain.canonical[uint64(i)] = header
+		} else {
+			chain.canonical[uint64(i)] = &types.Header{Number: big.NewInt(int64(i)), Extra: []byte("preferred")}
+		}
+		ancestors = append(ancestors, header)
+		parent = header.Hash()
+	}
+	ref := &types.Header{ParentHash: parent, Number: big.NewInt(10)}
+
+	getHeader := GetHeaderFn(ref, chain)
+	for i, ancestor := range ancestors {
+		require.Equal(t, ancestor.Hash(), getHeader(uint64(i)).Hash(), "ancestor %d", i)
+	}
+	require.Nil(t, getHeader(10))
+	require.Nil(t, getHeader(11))
+
+	// Headers skipped by state sync are unavailable.
+	delete(chain.canonical, 2)
+	require.Nil(t, getHeader(2))
+	require.Equal(t, ancestors[3].Hash(), getHeader(3).Hash())
+}
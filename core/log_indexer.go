@@ -0,0 +1,99 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// logIndexThrottling is the time to wait between processing two consecutive
+// log index sections, to avoid starving block processing of disk bandwidth
+// while backfilling.
+const logIndexThrottling = 100 * time.Millisecond
+
+// LogIndexer implements a core.ChainIndexer, backfilling the (address, topic0,
+// block) log index for sections of the chain that were accepted before log
+// indexing was enabled.
+//
+// Blocks accepted while log indexing is enabled are indexed by the acceptor
+// (see [BlockChain.batchBlockAcceptedIndices]).
+type LogIndexer struct {
+	db    ethdb.Database // database instance to read receipts from and write index data into
+	batch ethdb.Batch    // batch of index entries for the section being processed
+}
+
+// NewLogIndexer returns a chain indexer that generates the log index for the
+// canonical chain.
+func NewLogIndexer(db ethdb.Database, size, confirms uint64) *ChainIndexer {
+	backend := &LogIndexer{
+		db: db,
+	}
+	table := rawdb.NewTable(db, string(rawdb.LogIndexIndexPrefix))
+
+	return NewChainIndexer(db, table, backend, size, confirms, logIndexThrottling, "logindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new log index section.
+func (l *LogIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	l.batch = l.db.NewBatch()
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the logs of a new
+// header into the index.
+func (l *LogIndexer) Process(ctx context.Context, header *types.Header) error {
+	receipts := rawdb.ReadRawReceipts(l.db, header.Hash(), header.Number.Uint64())
+	for _, receipt := range receipts {
+		rawdb.WriteLogIndexEntries(l.batch, header.Number.Uint64(), receipt.Logs)
+	}
+	if l.batch.ValueSize() >= ethdb.IdealBatchSize {
+		if err := l.batch.Write(); err != nil {
+			return err
+		}
+		l.batch.Reset()
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, writing out the remaining index
+// entries of the section into the database.
+func (l *LogIndexer) Commit() error {
+	return l.batch.Write()
+}
+
+// Prune returns an empty error since we don't support pruning here.
+func (l *LogIndexer) Prune(threshold uint64) error {
+	return nil
+}
+
+// LogIndexStatus describes the portion of the accepted chain covered by the
+// log index.
+type LogIndexStatus struct {
+	SectionSize uint64  // Number of blocks in a backfilled section
+	Sections    uint64  // Number of sections backfilled by the chain indexer
+	Tail        *uint64 // Oldest block indexed on accept, nil if none
+	Head        uint64  // Last accepted block
+}
+
+// IndexedHead returns the highest block number such that the logs of every
+// block in [0, number] are covered by the log index. It returns false if the
+// index does not cover any block.
+func (s *LogIndexStatus) IndexedHead() (uint64, bool) {
+	backfilled := s.Sections * s.SectionSize
+	// The genesis block never contains logs, so a tail of 1 is contiguous with
+	// an empty backfill.
+	if s.Tail != nil && (*s.Tail <= 1 || *s.Tail <= backfilled) {
+		return s.Head, true
+	}
+	if backfilled == 0 {
+		return 0, false
+	}
+	return backfilled - 1, true
+}
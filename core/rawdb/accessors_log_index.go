@@ -0,0 +1,115 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// WriteLogIndexEntries stores a log index entry for every distinct
+// (address, topic0) pair emitted in block [number]. An additional entry under
+// the empty topic is stored for every emitting address, so that queries with a
+// wildcard first topic can be answered with a single range scan.
+//
+// Logs without topics are indexed under the empty topic only.
+func WriteLogIndexEntries(db ethdb.KeyValueWriter, number uint64, logs []*types.Log) {
+	type entry struct {
+		address common.Address
+		topic0  common.Hash
+	}
+	written := make(map[entry]struct{})
+	put := func(address common.Address, topic0 common.Hash) {
+		e := entry{address, topic0}
+		if _, ok := written[e]; ok {
+			return
+		}
+		written[e] = struct{}{}
+		if err := db.Put(logIndexKey(address, topic0, number), nil); err != nil {
+			log.Crit("Failed to store log index entry", "err", err)
+		}
+	}
+	for _, l := range logs {
+		put(l.Address, common.Hash{})
+		if len(l.Topics) > 0 {
+			put(l.Address, l.Topics[0])
+		}
+	}
+}
+
+// DeleteLogIndexEntries removes the log index entries for block [number]
+// created by WriteLogIndexEntries with the same [logs].
+func DeleteLogIndexEntries(db ethdb.KeyValueWriter, number uint64, logs []*types.Log) {
+	for _, l := range logs {
+		keys := [][]byte{logIndexKey(l.Address, common.Hash{}, number)}
+		if len(l.Topics) > 0 {
+			keys = append(keys, logIndexKey(l.Address, l.Topics[0], number))
+		}
+		for _, key := range keys {
+			if err := db.Delete(key); err != nil {
+				log.Crit("Failed to delete log index entry", "err", err)
+			}
+		}
+	}
+}
+
+// ReadLogIndexBlocks returns the ascending block numbers in [from, to] that
+// contain at least one log emitted by [address] with first topic [topic0].
+// If [topic0] is nil, blocks containing any log emitted by [address] are
+// returned. At most [limit] numbers are returned if [limit] is non-zero.
+//
+// The index is a hint: callers must still verify the logs of every returned
+// block against their filter criteria.
+func ReadLogIndexBlocks(db ethdb.Iteratee, address common.Address, topic0 *common.Hash, from, to uint64, limit int) []uint64 {
+	var topic common.Hash
+	if topic0 != nil {
+		topic = *topic0
+	}
+	prefix := logIndexKey(address, topic, 0)[:logIndexKeyLength-8]
+	it := NewKeyLengthIterator(db.NewIterator(prefix, encodeBlockNumber(from)), logIndexKeyLength)
+	defer it.Release()
+
+	var numbers []uint64
+	for it.Next() {
+		number := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		if number > to {
+			break
+		}
+		numbers = append(numbers, number)
+		if limit > 0 && len(numbers) >= limit {
+			break
+		}
+	}
+	return numbers
+}
+
+// ReadLogIndexTail retrieves the number of the oldest block whose logs have
+// been indexed on accept.
+func ReadLogIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(logIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteLogIndexTail stores the number of the oldest block whose logs have been
+// indexed on accept into the database.
+func WriteLogIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(logIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the log index tail", "err", err)
+	}
+}
+
+// DeleteLogIndexTail removes the log index tail marker from the database.
+func DeleteLogIndexTail(db ethdb.KeyValueWriter) {
+	if err := db.Delete(logIndexTailKey); err != nil {
+		log.Crit("Failed to delete the log index tail", "err", err)
+	}
+}
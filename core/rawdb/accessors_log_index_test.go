@@ -0,0 +1,43 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogIndexEntries(t *testing.T) {
+	var (
+		db     = NewMemoryDatabase()
+		addr1  = common.Address{0x01}
+		addr2  = common.Address{0x02}
+		topic1 = common.Hash{0x11}
+		topic2 = common.Hash{0x22}
+	)
+	WriteLogIndexEntries(db, 1, []*types.Log{{Address: addr1, Topics: []common.Hash{topic1}}, {Address: addr1, Topics: []common.Hash{topic1}}})
+	WriteLogIndexEntries(db, 2, []*types.Log{{Address: addr2, Topics: []common.Hash{topic1}}})
+	WriteLogIndexEntries(db, 3, []*types.Log{{Address: addr1}})
+	WriteLogIndexEntries(db, 256, []*types.Log{{Address: addr1, Topics: []common.Hash{topic2, topic1}}})
+
+	require.Equal(t, []uint64{1, 3, 256}, ReadLogIndexBlocks(db, addr1, nil, 0, 1000, 0))
+	require.Equal(t, []uint64{3, 256}, ReadLogIndexBlocks(db, addr1, nil, 2, 1000, 0))
+	require.Equal(t, []uint64{1, 3}, ReadLogIndexBlocks(db, addr1, nil, 0, 255, 0))
+	require.Equal(t, []uint64{1}, ReadLogIndexBlocks(db, addr1, nil, 0, 1000, 1))
+	require.Equal(t, []uint64{1}, ReadLogIndexBlocks(db, addr1, &topic1, 0, 1000, 0))
+	require.Equal(t, []uint64{256}, ReadLogIndexBlocks(db, addr1, &topic2, 0, 1000, 0))
+	require.Equal(t, []uint64{2}, ReadLogIndexBlocks(db, addr2, &topic1, 0, 1000, 0))
+
+	DeleteLogIndexEntries(db, 256, []*types.Log{{Address: addr1, Topics: []common.Hash{topic2, topic1}}})
+	require.Equal(t, []uint64{1, 3}, ReadLogIndexBlocks(db, addr1, nil, 0, 1000, 0))
+
+	require.Nil(t, ReadLogIndexTail(db))
+	WriteLogIndexTail(db, 7)
+	require.Equal(t, uint64(7), *ReadLogIndexTail(db))
+	DeleteLogIndexTail(db)
+	require.Nil(t, ReadLogIndexTail(db))
+}
@@ -240,6 +240,34 @@ func DeleteOfflinePruning(db ethdb.KeyValueStore) error {
 	return DeleteTimeMarker(db, offlinePruningKey)
 }
 
+func offlinePruningCursorKey(r byte) []byte {
+	return append(common.CopyBytes(offlinePruningCursorPrefix), r)
+}
+
+// ReadOfflinePruningCursor reads the key that offline pruning of the key range
+// starting with byte [r] resumes from, and the root of the state it prunes to.
+// An empty key means the range has been fully swept. If the range has not been
+// swept yet, [ok] is false.
+func ReadOfflinePruningCursor(db ethdb.KeyValueReader, r byte) (root common.Hash, next []byte, ok bool) {
+	data, _ := db.Get(offlinePruningCursorKey(r))
+	if len(data) < common.HashLength {
+		return common.Hash{}, nil, false
+	}
+	return common.BytesToHash(data[:common.HashLength]), data[common.HashLength:], true
+}
+
+// WriteOfflinePruningCursor stores the key that offline pruning of the key
+// range starting with byte [r] resumes from when pruning to [root].
+func WriteOfflinePruningCursor(db ethdb.KeyValueWriter, r byte, root common.Hash, next []byte) error {
+	return db.Put(offlinePruningCursorKey(r), append(root.Bytes(), next...))
+}
+
+// DeleteOfflinePruningCursor deletes the offline pruning progress of the key
+// range starting with byte [r].
+func DeleteOfflinePruningCursor(db ethdb.KeyValueWriter, r byte) error {
+	return db.Delete(offlinePruningCursorKey(r))
+}
+
 // WritePopulateMissingTries writes a marker for the current attempt to populate
 // missing tries.
 func WritePopulateMissingTries(db ethdb.KeyValueStore) error {
@@ -5,6 +5,7 @@ package rawdb
 
 import (
 	"encoding/binary"
+	"fmt"
 
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ethereum/go-ethereum/common"
@@ -191,3 +192,30 @@ func GetLatestSyncPerformed(db ethdb.Iteratee) uint64 {
 	}
 	return latestSyncPerformed
 }
+
+// ReadBlockBackfillProgress returns the height of the state synced block a
+// historical block backfill was started from and the hash of the lowest block
+// it has written, or a zero hash if no backfill progress was found.
+func ReadBlockBackfillProgress(db ethdb.KeyValueReader) (uint64, common.Hash, error) {
+	has, err := db.Has(blockBackfillKey)
+	if err != nil || !has {
+		return 0, common.Hash{}, err
+	}
+	data, err := db.Get(blockBackfillKey)
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+	if len(data) != wrappers.LongLen+common.HashLength {
+		return 0, common.Hash{}, fmt.Errorf("invalid block backfill progress length %d", len(data))
+	}
+	return binary.BigEndian.Uint64(data[:wrappers.LongLen]), common.BytesToHash(data[wrappers.LongLen:]), nil
+}
+
+// WriteBlockBackfillProgress writes [hash] as the lowest block written by the
+// historical block backfill started from the state synced block at [syncHeight].
+func WriteBlockBackfillProgress(db ethdb.KeyValueWriter, syncHeight uint64, hash common.Hash) error {
+	data := make([]byte, wrappers.LongLen+common.HashLength)
+	binary.BigEndian.PutUint64(data[:wrappers.LongLen], syncHeight)
+	copy(data[wrappers.LongLen:], hash[:])
+	return db.Put(blockBackfillKey, data)
+}
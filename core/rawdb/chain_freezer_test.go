@@ -0,0 +1,133 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestChain writes a chain of [numBlocks] blocks after genesis with their
+// receipts to [db] and returns the blocks, including genesis. Blocks at
+// heights in [skip] are not written.
+func writeTestChain(db ethdb.KeyValueWriter, numBlocks int, skip map[uint64]bool) []*types.Block {
+	blocks := make([]*types.Block, 0, numBlocks+1)
+	parentHash := common.Hash{}
+	for i := 0; i <= numBlocks; i++ {
+		block := types.NewBlockWithHeader(&types.Header{
+			ParentHash: parentHash,
+			Number:     big.NewInt(int64(i)),
+			Extra:      []byte("test block"),
+		})
+		blocks = append(blocks, block)
+		parentHash = block.Hash()
+		if skip[block.NumberU64()] {
+			continue
+		}
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		WriteReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{{CumulativeGasUsed: block.NumberU64(), Logs: []*types.Log{}}})
+	}
+	return blocks
+}
+
+func TestChainFreezer(t *testing.T) {
+	const (
+		numBlocks = 100
+		threshold = 10
+	)
+	kvdb := memorydb.New()
+	blocks := writeTestChain(kvdb, numBlocks, nil)
+	// a rejected block left behind at a frozen height
+	sideBlock := types.NewBlockWithHeader(&types.Header{ParentHash: blocks[49].Hash(), Number: big.NewInt(50), Extra: []byte("side block")})
+	WriteBlock(kvdb, sideBlock)
+	require.NoError(t, WriteAcceptorTip(kvdb, blocks[numBlocks].Hash()))
+
+	db, err := NewDatabaseWithFreezer(kvdb, t.TempDir(), "", false, threshold)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.(*freezerdb).Freeze(threshold))
+
+	frozen, err := db.Ancients()
+	require.NoError(t, err)
+	require.EqualValues(t, numBlocks-threshold+1, frozen)
+
+	for _, block := range blocks {
+		number, hash := block.NumberU64(), block.Hash()
+		require.Equal(t, hash, ReadCanonicalHash(db, number))
+		require.True(t, HasHeader(db, hash, number))
+		require.Equal(t, hash, ReadBlock(db, hash, number).Hash())
+		require.True(t, HasReceipts(db, hash, number))
+		receipts := ReadRawReceipts(db, hash, number)
+		require.Len(t, receipts, 1)
+		require.Equal(t, number, receipts[0].CumulativeGasUsed)
+
+		// frozen blocks except genesis are removed from the key-value store
+		inKeyValueStore := number == 0 || number >= frozen
+		has, err := kvdb.Has(blockBodyKey(number, hash))
+		require.NoError(t, err)
+		require.Equal(t, inKeyValueStore, has)
+		has, err = kvdb.Has(headerHashKey(number))
+		require.NoError(t, err)
+		require.Equal(t, inKeyValueStore, has)
+	}
+	require.Nil(t, ReadBlock(db, sideBlock.Hash(), sideBlock.NumberU64()))
+}
+
+func TestChainFreezerWaitsForMissingBlocks(t *testing.T) {
+	const (
+		numBlocks = 100
+		threshold = 10
+	)
+	kvdb := memorydb.New()
+	// blocks below a state synced block are missing until backfilled
+	missing := make(map[uint64]bool)
+	for i := uint64(1); i < 50; i++ {
+		missing[i] = true
+	}
+	blocks := writeTestChain(kvdb, numBlocks, missing)
+	require.NoError(t, WriteAcceptorTip(kvdb, blocks[numBlocks].Hash()))
+
+	db, err := NewDatabaseWithFreezer(kvdb, t.TempDir(), "", false, threshold)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.(*freezerdb).Freeze(threshold))
+
+	// only genesis can be frozen
+	frozen, err := db.Ancients()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, frozen)
+
+	// backfill the missing blocks
+	writeTestChain(kvdb, 49, nil)
+	require.NoError(t, db.(*freezerdb).Freeze(threshold))
+	frozen, err = db.Ancients()
+	require.NoError(t, err)
+	require.EqualValues(t, numBlocks-threshold+1, frozen)
+	for _, block := range blocks {
+		require.Equal(t, block.Hash(), ReadBlock(db, block.Hash(), block.NumberU64()).Hash())
+	}
+}
+
+func TestChainFreezerGenesisMismatch(t *testing.T) {
+	datadir := t.TempDir()
+	kvdb := memorydb.New()
+	blocks := writeTestChain(kvdb, 20, nil)
+	require.NoError(t, WriteAcceptorTip(kvdb, blocks[20].Hash()))
+	db, err := NewDatabaseWithFreezer(kvdb, datadir, "", false, 10)
+	require.NoError(t, err)
+	require.NoError(t, db.(*freezerdb).Freeze(10))
+	require.NoError(t, db.(*freezerdb).AncientStore.Close())
+
+	otherdb := memorydb.New()
+	WriteCanonicalHash(otherdb, common.Hash{1}, 0)
+	_, err = NewDatabaseWithFreezer(otherdb, datadir, "", false, 10)
+	require.ErrorContains(t, err, "genesis mismatch")
+}
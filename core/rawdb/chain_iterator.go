@@ -310,3 +310,69 @@ func UnindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 func unindexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
 	unindexTransactions(db, from, to, interrupt, hook)
 }
+
+// PruneBlockHistory deletes the bodies, receipts and txlookup indices of the
+// canonical blocks in the specified range, keeping their headers. The from is
+// included while to is excluded. The block history tail is written along with
+// the deletions, so an interrupted run resumes where it stopped.
+//
+// There is a passed channel, the whole procedure will be interrupted if any
+// signal received.
+func PruneBlockHistory(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}) {
+	// short circuit for invalid range
+	if from >= to {
+		return
+	}
+	var (
+		batch  = db.NewBatch()
+		start  = time.Now()
+		logged = start.Add(-7 * time.Second)
+		next   = from
+		// for stats reporting
+		blocks, txs = 0, 0
+	)
+loop:
+	for ; next < to; next++ {
+		select {
+		case <-interrupt:
+			break loop
+		default:
+		}
+		hash := ReadCanonicalHash(db, next)
+		if hash == (common.Hash{}) {
+			// Blocks below a state sync summary may never have been fetched.
+			continue
+		}
+		if body := ReadBody(db, hash, next); body != nil {
+			for _, tx := range body.Transactions {
+				DeleteTxLookupEntry(batch, tx.Hash())
+			}
+			txs += len(body.Transactions)
+		}
+		DeleteBody(batch, hash, next)
+		DeleteReceipts(batch, hash, next)
+		blocks++
+
+		// A batch counts the size of deletion as '1', so we need to flush more
+		// often than that.
+		if blocks%1000 == 0 {
+			WriteBlockHistoryTail(batch, next+1)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed writing batch to db", "error", err)
+				return
+			}
+			batch.Reset()
+		}
+		// If we've spent too much time already, notify the user of what we're doing
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning block history", "blocks", blocks, "txs", txs, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	WriteBlockHistoryTail(batch, next)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing batch to db", "error", err)
+		return
+	}
+	log.Debug("Pruned block history", "blocks", blocks, "txs", txs, "tail", next, "elapsed", common.PrettyDuration(time.Since(start)))
+}
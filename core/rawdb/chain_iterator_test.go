@@ -216,3 +216,69 @@ func TestIndexTransactions(t *testing.T) {
 	verify(8, 11, true, 8)
 	verify(0, 8, false, 8)
 }
+
+func TestPruneBlockHistory(t *testing.T) {
+	chainDb := NewMemoryDatabase()
+
+	var (
+		blocks []*types.Block
+		txs    []*types.Transaction
+		to     = common.BytesToAddress([]byte{0x11})
+	)
+	for i := uint64(1); i <= 10; i++ {
+		tx := types.NewTx(&types.LegacyTx{
+			Nonce:    i,
+			GasPrice: big.NewInt(11111),
+			Gas:      1111,
+			To:       &to,
+			Value:    big.NewInt(111),
+			Data:     []byte{0x11, 0x11, 0x11},
+		})
+		block := types.NewBlock(&types.Header{Number: big.NewInt(int64(i))}, []*types.Transaction{tx}, nil, nil, newTestHasher())
+		WriteBlock(chainDb, block)
+		WriteReceipts(chainDb, block.Hash(), block.NumberU64(), types.Receipts{{TxHash: tx.Hash()}})
+		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+		WriteTxLookupEntriesByBlock(chainDb, block)
+		blocks = append(blocks, block)
+		txs = append(txs, tx)
+	}
+	// verify checks whether the bodies, receipts and tx indices of the blocks
+	// in the range [from, to] are present, and that headers are always kept.
+	verify := func(from, to uint64, exist bool, tail uint64) {
+		for i := from; i <= to; i++ {
+			block := blocks[i-1]
+			if !HasHeader(chainDb, block.Hash(), i) {
+				t.Fatalf("Header %d missing", i)
+			}
+			if HasBody(chainDb, block.Hash(), i) != exist {
+				t.Fatalf("Body %d presence mismatch, want %t", i, exist)
+			}
+			if HasReceipts(chainDb, block.Hash(), i) != exist {
+				t.Fatalf("Receipts %d presence mismatch, want %t", i, exist)
+			}
+			if (ReadTxLookupEntry(chainDb, txs[i-1].Hash()) != nil) != exist {
+				t.Fatalf("Transaction index %d presence mismatch, want %t", i, exist)
+			}
+		}
+		number := ReadBlockHistoryTail(chainDb)
+		if number == nil || *number != tail {
+			t.Fatalf("Block history tail mismatch")
+		}
+	}
+	PruneBlockHistory(chainDb, 1, 5, nil)
+	verify(1, 4, false, 5)
+	verify(5, 10, true, 5)
+
+	// Pruning an empty range leaves the tail untouched
+	PruneBlockHistory(chainDb, 5, 5, nil)
+	verify(5, 10, true, 5)
+
+	// An interrupted run does not move the tail past the blocks it pruned
+	signal := make(chan struct{})
+	close(signal)
+	PruneBlockHistory(chainDb, 5, 11, signal)
+	verify(5, 10, true, 5)
+
+	PruneBlockHistory(chainDb, 5, 11, nil)
+	verify(1, 10, false, 11)
+}
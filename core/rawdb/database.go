@@ -42,6 +42,54 @@ import (
 	"github.com/olekukonko/tablewriter"
 )
 
+// freezerdb is a database wrapper that enables freezer data retrievals.
+type freezerdb struct {
+	ancientRoot string
+	ethdb.KeyValueStore
+	ethdb.AncientStore
+}
+
+// AncientDatadir returns the path of root ancient directory.
+func (frdb *freezerdb) AncientDatadir() (string, error) {
+	return frdb.ancientRoot, nil
+}
+
+// Close implements io.Closer, closing both the fast key-value store as well as
+// the slow ancient tables.
+func (frdb *freezerdb) Close() error {
+	var errs []error
+	if err := frdb.AncientStore.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := frdb.KeyValueStore.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Freeze is a helper method used for external testing to trigger and block until
+// a freeze cycle completes, without having to sleep for a minute to trigger the
+// automatic background run.
+func (frdb *freezerdb) Freeze(threshold uint64) error {
+	if frdb.AncientStore.(*chainFreezer).readonly {
+		return errReadOnly
+	}
+	// Set the freezer threshold to a temporary value
+	defer func(old uint64) {
+		frdb.AncientStore.(*chainFreezer).threshold.Store(old)
+	}(frdb.AncientStore.(*chainFreezer).threshold.Load())
+	frdb.AncientStore.(*chainFreezer).threshold.Store(threshold)
+
+	// Trigger a freeze cycle and block until it's done
+	trigger := make(chan struct{}, 1)
+	frdb.AncientStore.(*chainFreezer).trigger <- trigger
+	<-trigger
+	return nil
+}
+
 // nofreezedb is a database wrapper that disables freezer data retrievals.
 type nofreezedb struct {
 	ethdb.KeyValueStore
@@ -130,6 +178,50 @@ func NewDatabase(db ethdb.KeyValueStore) ethdb.Database {
 	return &nofreezedb{KeyValueStore: db}
 }
 
+// NewDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store with a freezer moving accepted blocks older than
+// [threshold] blocks into cold storage. The passed ancient indicates the path
+// of root ancient directory where the chain freezer can be opened.
+func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace string, readonly bool, threshold uint64) (ethdb.Database, error) {
+	// Create the idle freezer instance
+	frdb, err := newChainFreezer(filepath.Join(ancient, ChainFreezerName), namespace, readonly, threshold)
+	if err != nil {
+		return nil, err
+	}
+	// Since the freezer is stored separately from the key-value database,
+	// ensure both belong to the same chain so that we don't serve up
+	// conflicting data.
+	//
+	// Note: unlike go-ethereum, the key-value store is allowed to be missing
+	// blocks after the freezer, as state synced nodes only have the blocks
+	// above the state synced block until they are backfilled. The freezer
+	// waits for these blocks before freezing further.
+	if kvgenesis, _ := db.Get(headerHashKey(0)); len(kvgenesis) > 0 {
+		if frozen, _ := frdb.Ancients(); frozen > 0 {
+			frgenesis, err := frdb.Ancient(ChainFreezerHashTable, 0)
+			if err != nil {
+				frdb.Close()
+				return nil, fmt.Errorf("failed to retrieve genesis from ancient %v", err)
+			} else if !bytes.Equal(kvgenesis, frgenesis) {
+				frdb.Close()
+				return nil, fmt.Errorf("genesis mismatch: %#x (key-value store) != %#x (ancients)", kvgenesis, frgenesis)
+			}
+		}
+	}
+	if !frdb.readonly {
+		frdb.wg.Add(1)
+		go func() {
+			frdb.freeze(db)
+			frdb.wg.Done()
+		}()
+	}
+	return &freezerdb{
+		ancientRoot:   ancient,
+		KeyValueStore: db,
+		AncientStore:  frdb,
+	}, nil
+}
+
 // NewMemoryDatabase creates an ephemeral in-memory key-value database without a
 // freezer moving immutable chain segments into cold storage.
 func NewMemoryDatabase() ethdb.Database {
@@ -299,6 +391,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		storageSnaps    stat
 		preimages       stat
 		bloomBits       stat
+		logIndex        stat
 		cliqueSnaps     stat
 
 		// State sync statistics
@@ -362,6 +455,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
 			bloomBits.Add(size)
+		case bytes.HasPrefix(key, logIndexPrefix) && len(key) == logIndexKeyLength:
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, LogIndexIndexPrefix):
+			logIndex.Add(size)
 		case bytes.HasPrefix(key, syncStorageTriesPrefix) && len(key) == syncStorageTriesKeyLength:
 			syncProgress.Add(size)
 		case bytes.HasPrefix(key, syncSegmentsPrefix) && len(key) == syncSegmentsKeyLength:
@@ -370,13 +467,15 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			codeToFetch.Add(size)
 		case bytes.HasPrefix(key, syncPerformedPrefix) && len(key) == syncPerformedKeyLength:
 			syncPerformed.Add(size)
+		case bytes.HasPrefix(key, offlinePruningCursorPrefix) && len(key) == len(offlinePruningCursorPrefix)+1:
+			metadata.Add(size)
 		default:
 			var accounted bool
 			for _, meta := range [][]byte{
 				databaseVersionKey, headHeaderKey, headBlockKey,
 				snapshotRootKey, snapshotBlockHashKey, snapshotGeneratorKey,
-				uncleanShutdownKey, syncRootKey, txIndexTailKey,
-				persistentStateIDKey, trieJournalKey,
+				uncleanShutdownKey, syncRootKey, txIndexTailKey, blockHistoryTailKey,
+				persistentStateIDKey, trieJournalKey, logIndexTailKey, blockBackfillKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -403,6 +502,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
 		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
 		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
+		{"Key-Value store", "Log index", logIndex.Size(), logIndex.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
 		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
 		{"Key-Value store", "Path trie state lookups", stateLookups.Size(), stateLookups.Count()},
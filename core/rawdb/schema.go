@@ -66,12 +66,20 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// blockHistoryTailKey tracks the oldest block whose body and receipts have
+	// not been pruned.
+	blockHistoryTailKey = []byte("BlockHistoryTail")
+
 	// uncleanShutdownKey tracks the list of local crashes
 	uncleanShutdownKey = []byte("unclean-shutdown") // config prefix for the db
 
 	// offlinePruningKey tracks runs of offline pruning
 	offlinePruningKey = []byte("OfflinePruning")
 
+	// offlinePruningCursorPrefix tracks how far offline pruning has swept each
+	// key range of the database.
+	offlinePruningCursorPrefix = []byte("OfflinePruningCursor") // offlinePruningCursorPrefix + range (1 byte) -> state root + next key
+
 	// populateMissingTriesKey tracks runs of trie backfills
 	populateMissingTriesKey = []byte("PopulateMissingTries")
 
@@ -82,6 +90,9 @@ var (
 	// acceptorTipKey tracks the tip of the last accepted block that has been fully processed.
 	acceptorTipKey = []byte("AcceptorTipKey")
 
+	// logIndexTailKey tracks the oldest block whose logs have been indexed on accept.
+	logIndexTailKey = []byte("LogIndexTail")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerHashSuffix   = []byte("n") // headerPrefix + num (uint64 big endian) + headerHashSuffix -> hash
@@ -95,6 +106,7 @@ var (
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
+	logIndexPrefix        = []byte("x") // logIndexPrefix + address + topic0 + num (uint64 big endian) -> empty value
 
 	// Path-based storage scheme of merkle patricia trie.
 	trieNodeAccountPrefix = []byte("A") // trieNodeAccountPrefix + hexPath -> trie node
@@ -108,6 +120,9 @@ var (
 	// BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 	BloomBitsIndexPrefix = []byte("iB")
 
+	// LogIndexIndexPrefix is the data table of the log index chain indexer to track its progress
+	LogIndexIndexPrefix = []byte("iX")
+
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 
@@ -122,9 +137,13 @@ var (
 	syncSegmentsKeyLength     = len(syncSegmentsPrefix) + 2*common.HashLength
 	codeToFetchKeyLength      = len(CodeToFetchPrefix) + common.HashLength
 
+	// Log index key length
+	logIndexKeyLength = len(logIndexPrefix) + common.AddressLength + common.HashLength + wrappers.LongLen
+
 	// State sync metadata
 	syncPerformedPrefix    = []byte("sync_performed")
 	syncPerformedKeyLength = len(syncPerformedPrefix) + wrappers.LongLen // prefix + block number as uint64
+	blockBackfillKey       = []byte("block_backfill")                    // tracks the progress of backfilling blocks below the state synced block
 )
 
 // LegacyTxLookupEntry is the legacy TxLookupEntry definition with some unnecessary
@@ -206,6 +225,16 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// logIndexKey = logIndexPrefix + address + topic0 + num (uint64 big endian)
+func logIndexKey(address common.Address, topic0 common.Hash, number uint64) []byte {
+	key := make([]byte, logIndexKeyLength)
+	n := copy(key, logIndexPrefix)
+	n += copy(key[n:], address.Bytes())
+	n += copy(key[n:], topic0.Bytes())
+	binary.BigEndian.PutUint64(key[n:], number)
+	return key
+}
+
 // preimageKey = preimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(PreimagePrefix, hash.Bytes()...)
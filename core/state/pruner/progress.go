@@ -0,0 +1,103 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pruner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Phase is a stage of an offline pruning run.
+type Phase string
+
+const (
+	PhaseIdle    Phase = "idle"    // Pruning has not started
+	PhaseBloom   Phase = "bloom"   // Generating the bloom filter of the target state
+	PhasePrune   Phase = "prune"   // Deleting state entries not in the bloom filter
+	PhaseCompact Phase = "compact" // Compacting the database
+	PhaseDone    Phase = "done"    // Pruning finished successfully
+)
+
+// ProgressReport is a point-in-time view of an offline pruning run.
+type ProgressReport struct {
+	Phase    Phase
+	Complete float64            // Fraction of the current phase that is complete
+	Deleted  uint64             // Number of state entries deleted
+	Size     common.StorageSize // Size of the state entries deleted
+	Elapsed  time.Duration      // Time spent in the current phase
+	ETA      time.Duration      // Estimated time left in the current phase
+}
+
+// Progress tracks an offline pruning run. Each phase is split into tasks that
+// report the fraction of their work that is complete. It is safe for
+// concurrent use.
+type Progress struct {
+	lock    sync.Mutex
+	phase   Phase
+	started time.Time
+	tasks   []float64
+	deleted uint64
+	size    common.StorageSize
+}
+
+// NewProgress returns a tracker for a run that has not started.
+func NewProgress() *Progress {
+	return &Progress{phase: PhaseIdle}
+}
+
+// startPhase moves the run to [phase], which is made up of [tasks] tasks.
+func (p *Progress) startPhase(phase Phase, tasks int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.phase = phase
+	p.started = time.Now()
+	p.tasks = make([]float64, tasks)
+}
+
+// setTask records that [complete] of the work of [task] is done.
+func (p *Progress) setTask(task int, complete float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.tasks[task] = complete
+}
+
+// addDeleted records the deletion of [count] state entries of [size] bytes.
+func (p *Progress) addDeleted(count uint64, size common.StorageSize) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.deleted += count
+	p.size += size
+}
+
+// Report returns the current progress of the run.
+func (p *Progress) Report() ProgressReport {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	report := ProgressReport{
+		Phase:   p.phase,
+		Deleted: p.deleted,
+		Size:    p.size,
+	}
+	if p.phase == PhaseIdle || p.phase == PhaseDone {
+		return report
+	}
+	var complete float64
+	for _, task := range p.tasks {
+		complete += task
+	}
+	if len(p.tasks) > 0 {
+		report.Complete = complete / float64(len(p.tasks))
+	}
+	report.Elapsed = time.Since(p.started)
+	if report.Complete > 0 {
+		report.ETA = time.Duration(float64(report.Elapsed) * (1 - report.Complete) / report.Complete)
+	}
+	return report
+}
@@ -28,13 +28,15 @@ package pruner
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ava-labs/subnet-evm/core/rawdb"
@@ -45,6 +47,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -58,16 +61,48 @@ const (
 	// while it is being written out to detect write aborts.
 	stateBloomFileTempSuffix = ".tmp"
 
+	// stateBloomFileCheckpointSuffix is the filename suffix of a partially
+	// generated state bloom filter, so that an interrupted generation can be
+	// resumed.
+	stateBloomFileCheckpointSuffix = ".partial"
+
+	// stateBloomFileRangesSuffix is the filename suffix of the bitmap of the
+	// account ranges included in a partially generated state bloom filter.
+	stateBloomFileRangesSuffix = ".ranges"
+
+	// pruneRanges is the number of ranges the state bloom generation and the
+	// database sweep are split into. Each range covers the account hashes (or
+	// database keys) starting with the same byte.
+	pruneRanges = 256
+
 	// rangeCompactionThreshold is the minimal deleted entry number for
 	// triggering range compaction. It's a quite arbitrary number but just
 	// to avoid triggering range compaction because of small deletion.
 	rangeCompactionThreshold = 100000
 )
 
+// bloomCheckpointInterval is how often a partially generated state bloom
+// filter is written to disk.
+var bloomCheckpointInterval = 5 * time.Minute
+
 // Config includes all the configurations for pruning.
 type Config struct {
-	Datadir   string // The directory of the state database
-	BloomSize uint64 // The Megabytes of memory allocated to bloom-filter
+	Datadir     string    // The directory of the state database
+	BloomSize   uint64    // The Megabytes of memory allocated to bloom-filter
+	Parallelism int       // The number of goroutines generating the bloom-filter and sweeping the database
+	Progress    *Progress // Tracks the progress of pruning, may be nil
+}
+
+// sanitize returns a copy of [config] with invalid settings replaced.
+func (config Config) sanitize() Config {
+	if config.Parallelism < 1 {
+		log.Warn("Sanitizing pruning parallelism", "provided", config.Parallelism, "updated", 1)
+		config.Parallelism = 1
+	}
+	if config.Progress == nil {
+		config.Progress = NewProgress()
+	}
+	return config
 }
 
 // Pruner is an offline tool to prune the stale state with the
@@ -91,6 +126,7 @@ type Pruner struct {
 
 // NewPruner creates the pruner instance.
 func NewPruner(db ethdb.Database, config Config) (*Pruner, error) {
+	config = config.sanitize()
 	headBlock := rawdb.ReadHeadBlock(db)
 	if headBlock == nil {
 		return nil, errors.New("failed to load head block")
@@ -130,7 +166,7 @@ func NewPruner(db ethdb.Database, config Config) (*Pruner, error) {
 	}, nil
 }
 
-func prune(maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, start time.Time) error {
+func prune(maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, root common.Hash, config Config, start time.Time) error {
 	// Delete all stale trie nodes in the disk. With the help of state bloom
 	// the trie nodes(and codes) belong to the active state will be filtered
 	// out. A very small part of stale tries will also be filtered because of
@@ -138,13 +174,130 @@ func prune(maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, star
 	// that the false-positive is low enough(~0.05%). The probablity of the
 	// dangling node is the state root is super low. So the dangling nodes in
 	// theory will never ever be visited again.
+	//
+	// The key space is swept range by range by concurrent workers. Ranges that
+	// were fully swept before an interruption are skipped, and partially swept
+	// ones resume from their last committed batch.
+	var (
+		progress = config.Progress
+		pstart   = time.Now()
+		skipped  atomic.Uint64
+		ranges   = make(chan byte, pruneRanges)
+	)
+	progress.startPhase(PhasePrune, pruneRanges)
+	for r := 0; r < pruneRanges; r++ {
+		if cursorRoot, next, ok := rawdb.ReadOfflinePruningCursor(maindb, byte(r)); ok && cursorRoot == root && len(next) == 0 {
+			progress.setTask(r, 1)
+			continue
+		}
+		ranges <- byte(r)
+	}
+	close(ranges)
+
+	eg, ctx := errgroup.WithContext(context.Background())
+	for i := 0; i < config.Parallelism; i++ {
+		eg.Go(func() error {
+			for r := range ranges {
+				if err := sweepRange(ctx, maindb, stateBloom, root, r, progress, &skipped); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	stopLog := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(8 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report := progress.Report()
+				log.Info("Pruning state data", "nodes", report.Deleted, "skipped", skipped.Load(), "size", report.Size,
+					"complete", fmt.Sprintf("%.2f%%", report.Complete*100), "elapsed", common.PrettyDuration(report.Elapsed), "eta", common.PrettyDuration(report.ETA))
+			case <-stopLog:
+				return
+			}
+		}
+	}()
+	err := eg.Wait()
+	close(stopLog)
+	if err != nil {
+		return err
+	}
+	report := progress.Report()
+	log.Info("Pruned state data", "nodes", report.Deleted, "size", report.Size, "elapsed", common.PrettyDuration(time.Since(pstart)))
+
+	// Clear the progress of the sweep before marking it finished, so that a
+	// later run starts from scratch.
+	batch := maindb.NewBatch()
+	for r := 0; r < pruneRanges; r++ {
+		if err := rawdb.DeleteOfflinePruningCursor(batch, byte(r)); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	// Write marker to DB to indicate offline pruning finished successfully. We write before calling os.RemoveAll
+	// to guarantee that if the node dies midway through pruning, then this will run during RecoverPruning.
+	if err := rawdb.WriteOfflinePruning(maindb); err != nil {
+		return fmt.Errorf("failed to write offline pruning success marker: %w", err)
+	}
+
+	// Delete the state bloom, it marks the entire pruning procedure is
+	// finished. If any crashes or manual exit happens before this,
+	// `RecoverPruning` will pick it up in the next restarts to redo all
+	// the things.
+	if err := os.RemoveAll(bloomPath); err != nil {
+		return fmt.Errorf("failed to remove bloom filter from disk: %w", err)
+	}
+
+	// Start compactions, will remove the deleted data from the disk immediately.
+	// Note for small pruning, the compaction is skipped.
+	if report.Deleted >= rangeCompactionThreshold {
+		cstart := time.Now()
+		progress.startPhase(PhaseCompact, 16)
+		for b := 0x00; b <= 0xf0; b += 0x10 {
+			var (
+				start = []byte{byte(b)}
+				end   = []byte{byte(b + 0x10)}
+			)
+			if b == 0xf0 {
+				end = nil
+			}
+			log.Info("Compacting database", "range", fmt.Sprintf("%#x-%#x", start, end), "elapsed", common.PrettyDuration(time.Since(cstart)))
+			if err := maindb.Compact(start, end); err != nil {
+				log.Error("Database compaction failed", "error", err)
+				return err
+			}
+			progress.setTask(b/0x10, 1)
+		}
+		log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	}
+	progress.startPhase(PhaseDone, 0)
+	log.Info("State pruning successful", "pruned", report.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// sweepRange deletes the trie nodes and contract codes whose keys start with
+// byte [r] and which are not in the state bloom. The position of the sweep is
+// written with every batch of deletions, and the range is marked as swept
+// once it is done.
+func sweepRange(ctx context.Context, maindb ethdb.Database, stateBloom *stateBloom, root common.Hash, r byte, progress *Progress, skipped *atomic.Uint64) error {
 	var (
-		skipped, count int
-		size           common.StorageSize
-		pstart         = time.Now()
-		logged         = time.Now()
-		batch          = maindb.NewBatch()
-		iter           = maindb.NewIterator(nil, nil)
+		prefix = []byte{r}
+		start  []byte
+	)
+	if cursorRoot, next, ok := rawdb.ReadOfflinePruningCursor(maindb, r); ok && cursorRoot == root && len(next) > 0 {
+		start = next[1:]
+	}
+	var (
+		count uint64
+		size  common.StorageSize
+		batch = maindb.NewBatch()
+		iter  = maindb.NewIterator(prefix, start)
 	)
 	// We wrap iter.Release() in an anonymous function so that the [iter]
 	// value captured is the value of [iter] at the end of the function as opposed
@@ -167,7 +320,7 @@ func prune(maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, star
 				checkKey = codeKey
 			}
 			if stateBloom.Contain(checkKey) {
-				skipped += 1
+				skipped.Add(1)
 				continue
 			}
 			count += 1
@@ -175,81 +328,49 @@ func prune(maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, star
 			if err := batch.Delete(key); err != nil {
 				return err
 			}
-
-			var eta time.Duration // Realistically will never remain uninited
-			if done := binary.BigEndian.Uint64(key[:8]); done > 0 {
-				var (
-					left  = math.MaxUint64 - binary.BigEndian.Uint64(key[:8])
-					speed = done/uint64(time.Since(pstart)/time.Millisecond+1) + 1 // +1s to avoid division by zero
-				)
-				eta = time.Duration(left/speed) * time.Millisecond
-			}
-			if time.Since(logged) > 8*time.Second {
-				log.Info("Pruning state data", "nodes", count, "skipped", skipped, "size", size,
-					"elapsed", common.PrettyDuration(time.Since(pstart)), "eta", common.PrettyDuration(eta))
-				logged = time.Now()
-			}
 			// Recreate the iterator after every batch commit in order
 			// to allow the underlying compactor to delete the entries.
 			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := rawdb.WriteOfflinePruningCursor(batch, r, root, key); err != nil {
+					return err
+				}
 				if err := batch.Write(); err != nil {
 					return err
 				}
 				batch.Reset()
+				progress.addDeleted(count, size)
+				progress.setTask(int(r), rangeComplete(key))
+				count, size = 0, 0
 
+				if err := ctx.Err(); err != nil {
+					return err
+				}
 				iter.Release()
-				iter = maindb.NewIterator(nil, key)
+				iter = maindb.NewIterator(prefix, key[1:])
 			}
 		}
 	}
 	if err := iter.Error(); err != nil {
 		return fmt.Errorf("failed to iterate db during pruning: %w", err)
 	}
-	if batch.ValueSize() > 0 {
-		if err := batch.Write(); err != nil {
-			return err
-		}
-		batch.Reset()
-	}
-	iter.Release()
-	log.Info("Pruned state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(pstart)))
-
-	// Write marker to DB to indicate offline pruning finished successfully. We write before calling os.RemoveAll
-	// to guarantee that if the node dies midway through pruning, then this will run during RecoverPruning.
-	if err := rawdb.WriteOfflinePruning(maindb); err != nil {
-		return fmt.Errorf("failed to write offline pruning success marker: %w", err)
+	if err := rawdb.WriteOfflinePruningCursor(batch, r, root, nil); err != nil {
+		return err
 	}
-
-	// Delete the state bloom, it marks the entire pruning procedure is
-	// finished. If any crashes or manual exit happens before this,
-	// `RecoverPruning` will pick it up in the next restarts to redo all
-	// the things.
-	if err := os.RemoveAll(bloomPath); err != nil {
-		return fmt.Errorf("failed to remove bloom filter from disk: %w", err)
+	if err := batch.Write(); err != nil {
+		return err
 	}
+	progress.addDeleted(count, size)
+	progress.setTask(int(r), 1)
+	return nil
+}
 
-	// Start compactions, will remove the deleted data from the disk immediately.
-	// Note for small pruning, the compaction is skipped.
-	if count >= rangeCompactionThreshold {
-		cstart := time.Now()
-		for b := 0x00; b <= 0xf0; b += 0x10 {
-			var (
-				start = []byte{byte(b)}
-				end   = []byte{byte(b + 0x10)}
-			)
-			if b == 0xf0 {
-				end = nil
-			}
-			log.Info("Compacting database", "range", fmt.Sprintf("%#x-%#x", start, end), "elapsed", common.PrettyDuration(time.Since(cstart)))
-			if err := maindb.Compact(start, end); err != nil {
-				log.Error("Database compaction failed", "error", err)
-				return err
-			}
-		}
-		log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+// rangeComplete estimates the fraction of the range of [key] that is before
+// [key], assuming keys are uniformly distributed.
+func rangeComplete(key []byte) float64 {
+	if len(key) < 3 {
+		return 0
 	}
-	log.Info("State pruning successful", "pruned", size, "elapsed", common.PrettyDuration(time.Since(start)))
-	return nil
+	return float64(binary.BigEndian.Uint16(key[1:3])) / (1 << 16)
 }
 
 // Prune deletes all historical state nodes except the nodes belong to the
@@ -265,7 +386,7 @@ func (p *Pruner) Prune(root common.Hash) error {
 		return err
 	}
 	if stateBloomRoot != (common.Hash{}) {
-		return RecoverPruning(p.config.Datadir, p.db)
+		return RecoverPruning(p.config, p.db)
 	}
 
 	// If the target state root is not specified, return a fatal error.
@@ -281,25 +402,199 @@ func (p *Pruner) Prune(root common.Hash) error {
 		log.Info("Selecting last accepted block root as the pruning target", "root", root)
 	}
 
-	// Traverse the target state, re-construct the whole state trie and
-	// commit to the given bloom filter.
+	// Traverse the target state and the genesis, re-construct the state
+	// tries and commit to the given bloom filter.
 	start := time.Now()
-	if err := snapshot.GenerateTrie(p.snaptree, root, p.db, p.stateBloom); err != nil {
+	filterName := bloomFilterName(p.config.Datadir, root)
+	if err := p.generateBloom(root, filterName); err != nil {
+		return err
+	}
+
+	log.Info("Writing state bloom to disk", "name", filterName)
+	if err := p.stateBloom.Commit(filterName, filterName+stateBloomFileTempSuffix); err != nil {
+		return err
+	}
+	log.Info("State bloom filter committed", "name", filterName)
+
+	// The committed bloom filter supersedes the checkpoint of its generation.
+	if err := RemoveBloomCheckpoints(p.config.Datadir); err != nil {
+		return err
+	}
+	return prune(p.db, p.stateBloom, filterName, root, p.config, start)
+}
+
+// generateBloom commits the target state and the genesis state to the state
+// bloom. The storage tries and contract codes of the target state are added
+// range by range by concurrent workers, and the bloom is checkpointed to disk
+// periodically so that an interrupted generation resumes from the ranges it
+// completed. The account trie is re-constructed last to verify the root.
+func (p *Pruner) generateBloom(root common.Hash, filterName string) error {
+	var (
+		progress       = p.config.Progress
+		checkpointName = filterName + stateBloomFileCheckpointSuffix
+		rangesName     = filterName + stateBloomFileRangesSuffix
+		done           [pruneRanges]bool
+		resumed        int
+	)
+	// Checkpoints of a generation for another root cannot be reused.
+	if err := removeBloomCheckpoints(p.config.Datadir, filterName); err != nil {
+		return err
+	}
+	if ranges, err := os.ReadFile(rangesName); err == nil && len(ranges) == pruneRanges/8 {
+		stateBloom, err := NewStateBloomFromDisk(checkpointName)
+		if err != nil {
+			return fmt.Errorf("failed to load state bloom checkpoint %s: %w", checkpointName, err)
+		}
+		p.stateBloom = stateBloom
+		for r := range done {
+			if ranges[r/8]&(1<<(r%8)) != 0 {
+				done[r] = true
+				resumed++
+			}
+		}
+		log.Info("Resuming state bloom generation", "name", checkpointName, "ranges", resumed)
+	}
+
+	// The last two tasks are the account trie and the genesis.
+	progress.startPhase(PhaseBloom, pruneRanges+2)
+	ranges := make(chan byte, pruneRanges)
+	for r := 0; r < pruneRanges; r++ {
+		if done[r] {
+			progress.setTask(r, 1)
+			continue
+		}
+		ranges <- byte(r)
+	}
+	close(ranges)
+
+	var (
+		lock         sync.Mutex // Protects [done] and [checkpointed]
+		checkpointed = time.Now()
+	)
+	eg, ctx := errgroup.WithContext(context.Background())
+	for i := 0; i < p.config.Parallelism; i++ {
+		eg.Go(func() error {
+			for r := range ranges {
+				if err := p.generateRangeBloom(ctx, root, r); err != nil {
+					return err
+				}
+				lock.Lock()
+				done[r] = true
+				var err error
+				if time.Since(checkpointed) > bloomCheckpointInterval {
+					err = p.writeBloomCheckpoint(filterName, &done)
+					checkpointed = time.Now()
+				}
+				lock.Unlock()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	acctIt, err := p.snaptree.AccountIterator(root, common.Hash{}, false)
+	if err != nil {
+		return err
+	}
+	defer acctIt.Release()
+	got, err := snapshot.GenerateAccountTrie(p.stateBloom, rawdb.HashScheme, acctIt)
+	if err != nil {
 		return err
 	}
+	if got != root {
+		return fmt.Errorf("state root hash mismatch: got %x, want %x", got, root)
+	}
+	progress.setTask(pruneRanges, 1)
+
 	// Traverse the genesis, put all genesis state entries into the
 	// bloom filter too.
 	if err := extractGenesis(p.db, p.stateBloom); err != nil {
 		return err
 	}
-	filterName := bloomFilterName(p.config.Datadir, root)
+	progress.setTask(pruneRanges+1, 1)
+	return nil
+}
 
-	log.Info("Writing state bloom to disk", "name", filterName)
-	if err := p.stateBloom.Commit(filterName, filterName+stateBloomFileTempSuffix); err != nil {
+// generateRangeBloom commits the storage tries and contract codes of the
+// accounts whose hashes start with byte [r] to the state bloom.
+func (p *Pruner) generateRangeBloom(ctx context.Context, root common.Hash, r byte) error {
+	acctIt, err := p.snaptree.AccountIterator(root, common.Hash{r}, false)
+	if err != nil {
 		return err
 	}
-	log.Info("State bloom filter committed", "name", filterName)
-	return prune(p.db, p.stateBloom, filterName, start)
+	defer acctIt.Release()
+
+	for accounts := 0; acctIt.Next(); accounts++ {
+		accountHash := acctIt.Hash()
+		if accountHash[0] != r {
+			break
+		}
+		account, err := types.FullAccount(acctIt.Account())
+		if err != nil {
+			return err
+		}
+		if account.Root != types.EmptyRootHash {
+			storageIt, err := p.snaptree.StorageIterator(root, accountHash, common.Hash{}, false)
+			if err != nil {
+				return err
+			}
+			subroot, err := snapshot.GenerateStorageTrie(p.stateBloom, rawdb.HashScheme, accountHash, storageIt)
+			storageIt.Release()
+			if err != nil {
+				return err
+			}
+			if subroot != account.Root {
+				return fmt.Errorf("invalid subroot(path %x), want %x, have %x", accountHash, account.Root, subroot)
+			}
+		}
+		if !bytes.Equal(account.CodeHash, types.EmptyCodeHash.Bytes()) {
+			p.stateBloom.Put(account.CodeHash, nil)
+		}
+		if accounts%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			p.config.Progress.setTask(int(r), rangeComplete(accountHash[:]))
+		}
+	}
+	if err := acctIt.Error(); err != nil {
+		return err
+	}
+	p.config.Progress.setTask(int(r), 1)
+	return nil
+}
+
+// writeBloomCheckpoint writes the partially generated state bloom to disk,
+// along with the bitmap of the account ranges it includes.
+func (p *Pruner) writeBloomCheckpoint(filterName string, done *[pruneRanges]bool) error {
+	var (
+		checkpointName = filterName + stateBloomFileCheckpointSuffix
+		rangesName     = filterName + stateBloomFileRangesSuffix
+		ranges         = make([]byte, pruneRanges/8)
+	)
+	for r, ok := range done {
+		if ok {
+			ranges[r/8] |= 1 << (r % 8)
+		}
+	}
+	// The bloom is written first, so the bitmap never includes ranges that
+	// are missing from the bloom on disk.
+	if err := p.stateBloom.Commit(checkpointName, checkpointName+stateBloomFileTempSuffix); err != nil {
+		return err
+	}
+	if err := os.WriteFile(rangesName+stateBloomFileTempSuffix, ranges, 0666); err != nil {
+		return err
+	}
+	if err := os.Rename(rangesName+stateBloomFileTempSuffix, rangesName); err != nil {
+		return err
+	}
+	log.Info("Checkpointed state bloom", "name", checkpointName)
+	return nil
 }
 
 // RecoverPruning will resume the pruning procedure during the system restart.
@@ -309,8 +604,9 @@ func (p *Pruner) Prune(root common.Hash) error {
 // pruning can be resumed. What's more if the bloom filter is constructed, the
 // pruning **has to be resumed**. Otherwise a lot of dangling nodes may be left
 // in the disk.
-func RecoverPruning(datadir string, db ethdb.Database) error {
-	stateBloomPath, stateBloomRoot, err := findBloomFilter(datadir)
+func RecoverPruning(config Config, db ethdb.Database) error {
+	config = config.sanitize()
+	stateBloomPath, stateBloomRoot, err := findBloomFilter(config.Datadir)
 	if err != nil {
 		return err
 	}
@@ -333,7 +629,7 @@ func RecoverPruning(datadir string, db ethdb.Database) error {
 		return fmt.Errorf("cannot recover pruning to state bloom root: %s, with head block root: %s", stateBloomRoot, headBlock.Root())
 	}
 
-	return prune(db, stateBloom, stateBloomPath, time.Now())
+	return prune(db, stateBloom, stateBloomPath, stateBloomRoot, config, time.Now())
 }
 
 // extractGenesis loads the genesis state and commits all the state entries
@@ -409,6 +705,30 @@ func isBloomFilter(filename string) (bool, common.Hash) {
 	return false, common.Hash{}
 }
 
+// RemoveBloomCheckpoints deletes the checkpoints of partially generated state
+// bloom filters from [datadir]. Committed state bloom filters are kept.
+func RemoveBloomCheckpoints(datadir string) error {
+	return removeBloomCheckpoints(datadir, "")
+}
+
+// removeBloomCheckpoints deletes the checkpoints of partially generated state
+// bloom filters from [datadir], except those of the bloom filter [keep].
+func removeBloomCheckpoints(datadir string, keep string) error {
+	paths, err := filepath.Glob(filepath.Join(datadir, fmt.Sprintf("%s.*.%s.*", stateBloomFilePrefix, stateBloomFileSuffix)))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if keep != "" && strings.HasPrefix(path, keep+".") {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func findBloomFilter(datadir string) (string, common.Hash, error) {
 	var (
 		stateBloomPath string
@@ -70,6 +70,19 @@ func GenerateStorageTrieRoot(account common.Hash, it StorageIterator) (common.Ha
 	return generateTrieRoot(nil, "", it, account, stackTrieGenerate, nil, newGenerateStats(), true)
 }
 
+// GenerateAccountTrie takes an account iterator, writes the nodes of the
+// account trie into [dst] and returns its root hash. Storage tries are not
+// generated.
+func GenerateAccountTrie(dst ethdb.KeyValueWriter, scheme string, it AccountIterator) (common.Hash, error) {
+	return generateTrieRoot(dst, scheme, it, common.Hash{}, stackTrieGenerate, nil, newGenerateStats(), true)
+}
+
+// GenerateStorageTrie takes a storage iterator, writes the nodes of the
+// storage trie of [account] into [dst] and returns its root hash.
+func GenerateStorageTrie(dst ethdb.KeyValueWriter, scheme string, account common.Hash, it StorageIterator) (common.Hash, error) {
+	return generateTrieRoot(dst, scheme, it, account, stackTrieGenerate, nil, nil, false)
+}
+
 // GenerateTrie takes the whole snapshot tree as the input, traverses all the
 // accounts as well as the corresponding storages and regenerate the whole state
 // (account trie + all storage tries).
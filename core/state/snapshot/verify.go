@@ -0,0 +1,351 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/sync/errgroup"
+)
+
+// verifyRanges is the number of ranges of account hashes verified
+// concurrently. Each range covers the accounts whose hashes start with the
+// same byte.
+const verifyRanges = 256
+
+// Mismatch is a difference between the snapshot and the trie of a state, or
+// an inconsistency within either of them.
+type Mismatch struct {
+	Account common.Hash  // Hash of the account
+	Slot    *common.Hash // Hash of the storage slot, nil if the mismatch is not in a slot
+	Reason  string
+}
+
+// VerifyResult is the outcome of verifying a snapshot against its trie.
+type VerifyResult struct {
+	Root       common.Hash
+	Accounts   uint64   // Number of accounts in the trie
+	Slots      uint64   // Number of storage slots in the trie
+	Supply     *big.Int // Sum of the balances of the accounts in the trie
+	Mismatches []Mismatch
+	Truncated  bool // Whether mismatches beyond the limit were dropped
+}
+
+// verifier accumulates the result of VerifyWithTrie across its workers.
+type verifier struct {
+	tree          *Tree
+	snap          snapshot
+	root          common.Hash
+	accTrie       *trie.StateTrie
+	maxMismatches int
+
+	lock           sync.Mutex
+	result         *VerifyResult
+	snapshotSupply *big.Int
+	done           int
+}
+
+// VerifyWithTrie walks the snapshot and the trie of [root] side by side and
+// reports every account and storage slot in which they differ. The storage
+// roots of all accounts and the account root are re-computed, the code of all
+// contracts is checked to be present, and the balances of all accounts are
+// summed up. The account hash space is split into ranges verified by
+// [parallelism] concurrent workers. At most [maxMismatches] mismatches are
+// reported, unless it is zero.
+//
+// The snapshot of [root] must be available and fully generated. Since the
+// snapshot of the last accepted state is modified when the next block is
+// accepted, ErrSnapshotStale is returned if that happens during verification.
+func (t *Tree) VerifyWithTrie(ctx context.Context, root common.Hash, parallelism int, maxMismatches int) (*VerifyResult, error) {
+	snap := t.getSnapshot(root, false)
+	if snap == nil {
+		return nil, fmt.Errorf("snapshot of state %s is not available", root)
+	}
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(root), t.triedb)
+	if err != nil {
+		return nil, err
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	v := &verifier{
+		tree:          t,
+		snap:          snap,
+		root:          root,
+		accTrie:       accTrie,
+		maxMismatches: maxMismatches,
+		result: &VerifyResult{
+			Root:   root,
+			Supply: new(big.Int),
+		},
+		snapshotSupply: new(big.Int),
+	}
+
+	var (
+		start  = time.Now()
+		ranges = make(chan byte, verifyRanges)
+	)
+	for r := 0; r < verifyRanges; r++ {
+		ranges <- byte(r)
+	}
+	close(ranges)
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	// The account root is re-computed from the snapshot, which is compared
+	// with the trie account by account.
+	eg.Go(func() error {
+		acctIt, err := t.AccountIterator(root, common.Hash{}, false)
+		if err != nil {
+			return err
+		}
+		defer acctIt.Release()
+
+		got, err := GenerateAccountTrieRoot(acctIt)
+		if err != nil {
+			return err
+		}
+		if got != root {
+			v.addMismatch(Mismatch{Reason: fmt.Sprintf("account root mismatch: have %s, want %s", got, root)})
+		}
+		return nil
+	})
+	for i := 0; i < parallelism; i++ {
+		eg.Go(func() error {
+			for r := range ranges {
+				if err := v.verifyRange(egCtx, r); err != nil {
+					return err
+				}
+				v.lock.Lock()
+				v.done++
+				if v.done%16 == 0 {
+					log.Info("Verifying state", "root", root, "ranges", fmt.Sprintf("%d/%d", v.done, verifyRanges), "accounts", v.result.Accounts,
+						"slots", v.result.Slots, "mismatches", len(v.result.Mismatches), "elapsed", common.PrettyDuration(time.Since(start)))
+				}
+				v.lock.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	if snap.Stale() {
+		return nil, ErrSnapshotStale
+	}
+	if v.result.Supply.Cmp(v.snapshotSupply) != 0 {
+		v.addMismatch(Mismatch{Reason: fmt.Sprintf("total supply mismatch: trie %s, snapshot %s", v.result.Supply, v.snapshotSupply)})
+	}
+	log.Info("Verified state", "root", root, "accounts", v.result.Accounts, "slots", v.result.Slots, "supply", v.result.Supply,
+		"mismatches", len(v.result.Mismatches), "elapsed", common.PrettyDuration(time.Since(start)))
+	return v.result, nil
+}
+
+// addMismatch records [mismatch] unless the limit of mismatches is reached.
+func (v *verifier) addMismatch(mismatch Mismatch) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if v.maxMismatches > 0 && len(v.result.Mismatches) >= v.maxMismatches {
+		v.result.Truncated = true
+		return
+	}
+	log.Warn("State mismatch", "account", mismatch.Account, "slot", mismatch.Slot, "reason", mismatch.Reason)
+	v.result.Mismatches = append(v.result.Mismatches, mismatch)
+}
+
+// verifyRange verifies the accounts whose hashes start with byte [r].
+func (v *verifier) verifyRange(ctx context.Context, r byte) error {
+	if v.snap.Stale() {
+		return ErrSnapshotStale
+	}
+	snapIt, err := v.tree.AccountIterator(v.root, common.Hash{r}, false)
+	if err != nil {
+		return err
+	}
+	defer snapIt.Release()
+
+	nodeIt, err := v.accTrie.NodeIterator(common.Hash{r}.Bytes())
+	if err != nil {
+		return err
+	}
+	trieIt := trie.NewIterator(nodeIt)
+
+	var (
+		accounts       uint64
+		slots          uint64
+		supply         = new(big.Int)
+		snapshotSupply = new(big.Int)
+
+		snapOk = snapIt.Next() && snapIt.Hash()[0] == r
+		trieOk = trieIt.Next() && trieIt.Key[0] == r
+	)
+	for snapOk || trieOk {
+		if accounts%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		var cmp int
+		switch {
+		case !trieOk:
+			cmp = -1
+		case !snapOk:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(snapIt.Hash().Bytes(), trieIt.Key)
+		}
+
+		var snapAccount *types.StateAccount
+		if cmp <= 0 {
+			snapAccount, err = types.FullAccount(snapIt.Account())
+			if err != nil {
+				return err
+			}
+			snapshotSupply.Add(snapshotSupply, snapAccount.Balance)
+		}
+		if cmp < 0 {
+			v.addMismatch(Mismatch{Account: snapIt.Hash(), Reason: "account missing from trie"})
+			snapOk = snapIt.Next() && snapIt.Hash()[0] == r
+			continue
+		}
+
+		accountHash := common.BytesToHash(trieIt.Key)
+		var trieAccount types.StateAccount
+		if err := rlp.DecodeBytes(trieIt.Value, &trieAccount); err != nil {
+			v.addMismatch(Mismatch{Account: accountHash, Reason: fmt.Sprintf("invalid trie account: %v", err)})
+		} else {
+			if cmp > 0 {
+				v.addMismatch(Mismatch{Account: accountHash, Reason: "account missing from snapshot"})
+			} else if reason := compareAccounts(&trieAccount, snapAccount); reason != "" {
+				v.addMismatch(Mismatch{Account: accountHash, Reason: reason})
+			}
+			accounts++
+			supply.Add(supply, trieAccount.Balance)
+
+			storageSlots, err := v.verifyStorage(accountHash, trieAccount.Root)
+			if err != nil {
+				return err
+			}
+			slots += storageSlots
+			if codeHash := common.BytesToHash(trieAccount.CodeHash); codeHash != types.EmptyCodeHash && !rawdb.HasCode(v.tree.diskdb, codeHash) {
+				v.addMismatch(Mismatch{Account: accountHash, Reason: fmt.Sprintf("missing code %s", codeHash)})
+			}
+		}
+		if cmp == 0 {
+			snapOk = snapIt.Next() && snapIt.Hash()[0] == r
+		}
+		trieOk = trieIt.Next() && trieIt.Key[0] == r
+	}
+	if err := snapIt.Error(); err != nil {
+		return err
+	}
+	if err := trieIt.Err; err != nil {
+		v.addMismatch(Mismatch{Account: common.Hash{r}, Reason: fmt.Sprintf("account trie iteration failed: %v", err)})
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.result.Accounts += accounts
+	v.result.Slots += slots
+	v.result.Supply.Add(v.result.Supply, supply)
+	v.snapshotSupply.Add(v.snapshotSupply, snapshotSupply)
+	return nil
+}
+
+// verifyStorage verifies the storage of [accountHash] against the storage
+// trie of [storageRoot], and returns the number of slots in the trie.
+func (v *verifier) verifyStorage(accountHash common.Hash, storageRoot common.Hash) (uint64, error) {
+	snapIt, err := v.tree.StorageIterator(v.root, accountHash, common.Hash{}, false)
+	if err != nil {
+		return 0, err
+	}
+	defer snapIt.Release()
+
+	storageTrie, err := trie.NewStateTrie(trie.StorageTrieID(v.root, accountHash, storageRoot), v.tree.triedb)
+	if err != nil {
+		v.addMismatch(Mismatch{Account: accountHash, Reason: fmt.Sprintf("missing storage trie %s: %v", storageRoot, err)})
+		return 0, nil
+	}
+	nodeIt, err := storageTrie.NodeIterator(nil)
+	if err != nil {
+		v.addMismatch(Mismatch{Account: accountHash, Reason: fmt.Sprintf("missing storage trie %s: %v", storageRoot, err)})
+		return 0, nil
+	}
+	var (
+		trieIt = trie.NewIterator(nodeIt)
+		stack  = trie.NewStackTrie(nil)
+		slots  uint64
+
+		snapOk = snapIt.Next()
+		trieOk = trieIt.Next()
+	)
+	for snapOk || trieOk {
+		var cmp int
+		switch {
+		case !trieOk:
+			cmp = -1
+		case !snapOk:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(snapIt.Hash().Bytes(), trieIt.Key)
+		}
+		switch {
+		case cmp < 0:
+			slot := snapIt.Hash()
+			v.addMismatch(Mismatch{Account: accountHash, Slot: &slot, Reason: "slot missing from trie"})
+		case cmp > 0:
+			slot := common.BytesToHash(trieIt.Key)
+			v.addMismatch(Mismatch{Account: accountHash, Slot: &slot, Reason: "slot missing from snapshot"})
+		case !bytes.Equal(snapIt.Slot(), trieIt.Value):
+			slot := snapIt.Hash()
+			v.addMismatch(Mismatch{Account: accountHash, Slot: &slot, Reason: fmt.Sprintf("slot mismatch: trie %#x, snapshot %#x", trieIt.Value, snapIt.Slot())})
+		}
+		if cmp >= 0 {
+			if err := stack.Update(trieIt.Key, trieIt.Value); err != nil {
+				return 0, err
+			}
+			slots++
+			trieOk = trieIt.Next()
+		}
+		if cmp <= 0 {
+			snapOk = snapIt.Next()
+		}
+	}
+	if err := snapIt.Error(); err != nil {
+		return 0, err
+	}
+	if err := trieIt.Err; err != nil {
+		v.addMismatch(Mismatch{Account: accountHash, Reason: fmt.Sprintf("storage trie iteration failed: %v", err)})
+	} else if got := stack.Hash(); got != storageRoot {
+		v.addMismatch(Mismatch{Account: accountHash, Reason: fmt.Sprintf("storage root mismatch: have %s, want %s", got, storageRoot)})
+	}
+	return slots, nil
+}
+
+// compareAccounts returns the reason [trieAccount] and [snapAccount] differ,
+// or an empty string if they do not.
+func compareAccounts(trieAccount, snapAccount *types.StateAccount) string {
+	switch {
+	case trieAccount.Nonce != snapAccount.Nonce:
+		return fmt.Sprintf("nonce mismatch: trie %d, snapshot %d", trieAccount.Nonce, snapAccount.Nonce)
+	case trieAccount.Balance.Cmp(snapAccount.Balance) != 0:
+		return fmt.Sprintf("balance mismatch: trie %s, snapshot %s", trieAccount.Balance, snapAccount.Balance)
+	case trieAccount.Root != snapAccount.Root:
+		return fmt.Sprintf("storage root mismatch: trie %s, snapshot %s", trieAccount.Root, snapAccount.Root)
+	case !bytes.Equal(trieAccount.CodeHash, snapAccount.CodeHash):
+		return fmt.Sprintf("code hash mismatch: trie %#x, snapshot %#x", trieAccount.CodeHash, snapAccount.CodeHash)
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyWithTrie(t *testing.T) {
+	var (
+		helper   = newHelper(rawdb.HashScheme)
+		keys     = []string{"key-1", "key-2", "key-3"}
+		vals     = []string{"val-1", "val-2", "val-3"}
+		code     = []byte{0x60, 0x00}
+		codeHash = hashData(code)
+	)
+	stRoot := helper.makeStorageTrie(hashData([]byte("acc-1")), keys, vals, true)
+	helper.addAccount("acc-1", &types.StateAccount{Balance: big.NewInt(1), Root: stRoot, CodeHash: codeHash.Bytes()})
+	helper.addSnapStorage("acc-1", keys, vals)
+	rawdb.WriteCode(helper.diskdb, codeHash, code)
+
+	helper.addAccount("acc-2", &types.StateAccount{Balance: big.NewInt(2), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()})
+
+	stRoot = helper.makeStorageTrie(hashData([]byte("acc-3")), keys, vals, true)
+	helper.addAccount("acc-3", &types.StateAccount{Balance: big.NewInt(3), Root: stRoot, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.addSnapStorage("acc-3", keys, vals)
+
+	root := helper.Commit()
+	tree := NewTestTree(helper.diskdb, testBlockHash, root)
+	tree.diskdb = helper.diskdb
+	tree.triedb = helper.triedb
+
+	result, err := tree.VerifyWithTrie(context.Background(), root, 4, 0)
+	require.NoError(t, err)
+	require.Empty(t, result.Mismatches)
+	require.EqualValues(t, 3, result.Accounts)
+	require.EqualValues(t, 6, result.Slots)
+	require.EqualValues(t, 6, result.Supply.Int64())
+
+	// Corrupt the snapshot and the code.
+	var (
+		acc1 = hashData([]byte("acc-1"))
+		acc2 = hashData([]byte("acc-2"))
+		acc3 = hashData([]byte("acc-3"))
+		key2 = hashData([]byte("key-2"))
+		key4 = hashData([]byte("key-4"))
+	)
+	rawdb.WriteAccountSnapshot(helper.diskdb, acc2, types.SlimAccountRLP(types.StateAccount{Balance: big.NewInt(5), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()}))
+	rawdb.DeleteStorageSnapshot(helper.diskdb, acc3, key2)
+	rawdb.WriteStorageSnapshot(helper.diskdb, acc3, key4, []byte("val-4"))
+	rawdb.DeleteCode(helper.diskdb, codeHash)
+
+	result, err = tree.VerifyWithTrie(context.Background(), root, 4, 0)
+	require.NoError(t, err)
+	type mismatch struct {
+		account common.Hash
+		slot    common.Hash
+	}
+	got := make(map[mismatch]string)
+	for _, m := range result.Mismatches {
+		var slot common.Hash
+		if m.Slot != nil {
+			slot = *m.Slot
+		}
+		got[mismatch{m.Account, slot}] += m.Reason
+	}
+	require.Len(t, result.Mismatches, 6)
+	require.Contains(t, got[mismatch{acc1, common.Hash{}}], "missing code")
+	require.Contains(t, got[mismatch{acc2, common.Hash{}}], "balance mismatch")
+	require.Equal(t, "slot missing from snapshot", got[mismatch{acc3, key2}])
+	require.Equal(t, "slot missing from trie", got[mismatch{acc3, key4}])
+	require.Contains(t, got[mismatch{}], "account root mismatch")
+	require.Contains(t, got[mismatch{}], "total supply mismatch")
+	require.EqualValues(t, 6, result.Supply.Int64())
+
+	// Mismatches beyond the limit are dropped.
+	result, err = tree.VerifyWithTrie(context.Background(), root, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, result.Mismatches, 2)
+	require.True(t, result.Truncated)
+}
+
+func TestVerifyWithTrieMissingSnapshot(t *testing.T) {
+	helper := newHelper(rawdb.HashScheme)
+	helper.addAccount("acc-1", &types.StateAccount{Balance: big.NewInt(1), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()})
+	root := helper.Commit()
+	tree := NewTestTree(helper.diskdb, testBlockHash, root)
+	tree.diskdb = helper.diskdb
+	tree.triedb = helper.triedb
+
+	_, err := tree.VerifyWithTrie(context.Background(), common.Hash{1}, 1, 0)
+	require.ErrorContains(t, err, "not available")
+}
@@ -315,6 +315,9 @@ func (s *stateObject) updateTrie() (Trie, error) {
 		}
 		prev := s.originStorage[key]
 		s.originStorage[key] = value
+		if s.db.slotDiffs != nil {
+			s.db.recordSlotDiff(s.address, key, prev, value)
+		}
 
 		var encoded []byte // rlp-encoded value to be used by the snapshot
 		if (value == common.Hash{}) {
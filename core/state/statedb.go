@@ -149,6 +149,10 @@ type StateDB struct {
 	AccountDeleted int
 	StorageDeleted int
 
+	// State diff tracking, enabled by EnableStateDiff
+	slotDiffs map[common.Address]map[common.Hash]*SlotDiff // Storage changes since the last commit
+	stateDiff []*AccountDiff                               // Changes made before the last commit
+
 	// Testing hooks
 	onCommit func(states *triestate.Set) // Hook invoked when commit is performed
 }
@@ -820,6 +824,19 @@ func (s *StateDB) Copy() *StateDB {
 	state.accountsOrigin = copySet(state.accountsOrigin)
 	state.storagesOrigin = copy2DSet(state.storagesOrigin)
 
+	// Deep copy the tracked storage changes
+	if s.slotDiffs != nil {
+		state.slotDiffs = make(map[common.Address]map[common.Hash]*SlotDiff, len(s.slotDiffs))
+		for addr, slots := range s.slotDiffs {
+			cpy := make(map[common.Hash]*SlotDiff, len(slots))
+			for key, slot := range slots {
+				slotCopy := *slot
+				cpy[key] = &slotCopy
+			}
+			state.slotDiffs[addr] = cpy
+		}
+	}
+
 	// Deep copy the logs occurred in the scope of block
 	for hash, logs := range s.logs {
 		cpy := make([]*types.Log, len(logs))
@@ -913,6 +930,7 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 			delete(s.storages, obj.addrHash)      // Clear out any previously updated storage data (may be recreated via a resurrect)
 			delete(s.accountsOrigin, obj.address) // Clear out any previously updated account data (may be recreated via a resurrect)
 			delete(s.storagesOrigin, obj.address) // Clear out any previously updated storage data (may be recreated via a resurrect)
+			delete(s.slotDiffs, obj.address)      // Clear out any previously tracked storage changes (may be recreated via a resurrect)
 		} else {
 			obj.finalise(true) // Prefetch slots in the background
 		}
@@ -1256,6 +1274,11 @@ func (s *StateDB) commit(block uint64, deleteEmptyObjects bool, snaps *snapshot.
 	if err != nil {
 		return common.Hash{}, err
 	}
+	// Capture the changes before the state objects are committed
+	if s.slotDiffs != nil {
+		s.stateDiff = s.buildStateDiff()
+		s.slotDiffs = make(map[common.Address]map[common.Hash]*SlotDiff)
+	}
 	// Handle all state updates afterwards
 	for addr := range s.stateObjectsDirty {
 		obj := s.stateObjects[addr]
@@ -0,0 +1,136 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// AccountState is the state of an account, excluding its storage slots.
+type AccountState struct {
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	Balance     *hexutil.Big   `json:"balance"`
+	CodeHash    common.Hash    `json:"codeHash"`
+	StorageRoot common.Hash    `json:"storageRoot"`
+}
+
+func newAccountState(account *types.StateAccount) *AccountState {
+	if account == nil {
+		return nil
+	}
+	return &AccountState{
+		Nonce:       hexutil.Uint64(account.Nonce),
+		Balance:     (*hexutil.Big)(account.Balance),
+		CodeHash:    common.BytesToHash(account.CodeHash),
+		StorageRoot: account.Root,
+	}
+}
+
+func (a *AccountState) equal(b *AccountState) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Nonce == b.Nonce && a.Balance.ToInt().Cmp(b.Balance.ToInt()) == 0 && a.CodeHash == b.CodeHash && a.StorageRoot == b.StorageRoot
+}
+
+// SlotDiff is the change of a storage slot. Zero values are empty slots.
+type SlotDiff struct {
+	Old common.Hash `json:"old"`
+	New common.Hash `json:"new"`
+}
+
+// AccountDiff is the change of an account made by a block.
+type AccountDiff struct {
+	Address common.Address `json:"address"`
+	Old     *AccountState  `json:"old"` // nil if the account did not exist
+	New     *AccountState  `json:"new"` // nil if the account was deleted
+	// Destructed is true if the account was self-destructed, clearing all of
+	// its previous storage. The slots in Storage are then set from empty.
+	Destructed bool                     `json:"destructed,omitempty"`
+	Code       hexutil.Bytes            `json:"code,omitempty"` // Deployed code, if any
+	Storage    map[common.Hash]SlotDiff `json:"storage,omitempty"`
+}
+
+// EnableStateDiff makes the StateDB capture the changes made to the state
+// until each commit, which are returned by StateDiff.
+func (s *StateDB) EnableStateDiff() {
+	if s.slotDiffs == nil {
+		s.slotDiffs = make(map[common.Address]map[common.Hash]*SlotDiff)
+	}
+}
+
+// StateDiff returns the changes made to the state before the last commit,
+// sorted by address, or nil if EnableStateDiff was not called.
+func (s *StateDB) StateDiff() []*AccountDiff {
+	return s.stateDiff
+}
+
+// recordSlotDiff records the change of [key] of [addr] from [prev] to
+// [value], keeping the value it had before the first change.
+func (s *StateDB) recordSlotDiff(addr common.Address, key, prev, value common.Hash) {
+	slots := s.slotDiffs[addr]
+	if slots == nil {
+		slots = make(map[common.Hash]*SlotDiff)
+		s.slotDiffs[addr] = slots
+	}
+	if slot, ok := slots[key]; ok {
+		slot.New = value
+		return
+	}
+	slots[key] = &SlotDiff{Old: prev, New: value}
+}
+
+// buildStateDiff returns the changes made to the state since the last commit.
+// It must be called during commit, before the state objects are committed.
+func (s *StateDB) buildStateDiff() []*AccountDiff {
+	addrs := make(map[common.Address]struct{}, len(s.stateObjectsDirty)+len(s.stateObjectsDestruct))
+	for addr := range s.stateObjectsDirty {
+		addrs[addr] = struct{}{}
+	}
+	for addr := range s.stateObjectsDestruct {
+		addrs[addr] = struct{}{}
+	}
+	diffs := make([]*AccountDiff, 0, len(addrs))
+	for addr := range addrs {
+		var (
+			diff         = &AccountDiff{Address: addr}
+			obj          = s.stateObjects[addr]
+			prev, exists = s.stateObjectsDestruct[addr]
+		)
+		if exists {
+			diff.Old = newAccountState(prev)
+			diff.Destructed = prev != nil
+		} else if obj != nil {
+			diff.Old = newAccountState(obj.origin)
+		}
+		if obj != nil && !obj.deleted {
+			diff.New = newAccountState(&obj.data)
+			if obj.dirtyCode {
+				diff.Code = common.CopyBytes(obj.code)
+			}
+			for key, slot := range s.slotDiffs[addr] {
+				if slot.Old == slot.New {
+					continue
+				}
+				if diff.Storage == nil {
+					diff.Storage = make(map[common.Hash]SlotDiff)
+				}
+				diff.Storage[key] = *slot
+			}
+		}
+		if !diff.Destructed && diff.Code == nil && len(diff.Storage) == 0 && diff.Old.equal(diff.New) {
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return bytes.Compare(diffs[i].Address[:], diffs[j].Address[:]) < 0
+	})
+	return diffs
+}
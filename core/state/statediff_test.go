@@ -0,0 +1,106 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStateDiff(t *testing.T) {
+	var (
+		db      = NewDatabase(rawdb.NewMemoryDatabase())
+		updated = common.Address{1}
+		created = common.Address{2}
+		deleted = common.Address{3}
+		touched = common.Address{4}
+		code    = []byte{0x60, 0x00}
+	)
+	state, _ := New(types.EmptyRootHash, db, nil)
+	state.SetBalance(updated, big.NewInt(1))
+	state.SetState(updated, common.Hash{1}, common.Hash{1})
+	state.SetState(updated, common.Hash{2}, common.Hash{2})
+	state.SetBalance(deleted, big.NewInt(3))
+	state.SetState(deleted, common.Hash{1}, common.Hash{1})
+	state.SetBalance(touched, big.NewInt(4))
+	root, err := state.Commit(0, false, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if diff := state.StateDiff(); diff != nil {
+		t.Fatalf("state diff captured without being enabled: %v", diff)
+	}
+
+	state, _ = New(root, db, nil)
+	state.EnableStateDiff()
+	state.SetNonce(updated, 1)
+	state.SetState(updated, common.Hash{1}, common.Hash{3}) // changed
+	state.SetState(updated, common.Hash{2}, common.Hash{})  // cleared
+	state.SetState(updated, common.Hash{3}, common.Hash{5}) // changed back below
+	state.SetState(updated, common.Hash{3}, common.Hash{})
+	state.SetBalance(created, big.NewInt(2))
+	state.SetCode(created, code)
+	state.SelfDestruct(deleted)
+	state.SetBalance(touched, big.NewInt(4)) // unchanged
+	if _, err := state.Commit(1, false, false); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+
+	diff := state.StateDiff()
+	if len(diff) != 3 {
+		t.Fatalf("unexpected number of account diffs: have %d, want 3", len(diff))
+	}
+	// Diffs are sorted by address.
+	if diff[0].Address != updated || diff[1].Address != created || diff[2].Address != deleted {
+		t.Fatalf("unexpected account diffs: %v, %v, %v", diff[0].Address, diff[1].Address, diff[2].Address)
+	}
+
+	d := diff[0]
+	if d.Old == nil || d.New == nil || d.Old.Nonce != 0 || d.New.Nonce != 1 || d.New.Balance.ToInt().Int64() != 1 {
+		t.Fatalf("unexpected account change of updated account: %+v -> %+v", d.Old, d.New)
+	}
+	if d.Old.StorageRoot == d.New.StorageRoot {
+		t.Fatal("storage root of updated account did not change")
+	}
+	wantSlots := map[common.Hash]SlotDiff{
+		{1}: {Old: common.Hash{1}, New: common.Hash{3}},
+		{2}: {Old: common.Hash{2}, New: common.Hash{}},
+	}
+	if len(d.Storage) != len(wantSlots) {
+		t.Fatalf("unexpected storage diff of updated account: %v", d.Storage)
+	}
+	for key, want := range wantSlots {
+		if have := d.Storage[key]; have != want {
+			t.Fatalf("unexpected diff of slot %x: have %v, want %v", key, have, want)
+		}
+	}
+
+	d = diff[1]
+	if d.Old != nil || d.New == nil || d.New.Balance.ToInt().Int64() != 2 || d.New.CodeHash != crypto.Keccak256Hash(code) {
+		t.Fatalf("unexpected account change of created account: %+v -> %+v", d.Old, d.New)
+	}
+	if string(d.Code) != string(code) {
+		t.Fatalf("unexpected code of created account: %x", d.Code)
+	}
+
+	d = diff[2]
+	if !d.Destructed || d.Old == nil || d.New != nil || d.Old.Balance.ToInt().Int64() != 3 {
+		t.Fatalf("unexpected account change of deleted account: %+v -> %+v", d.Old, d.New)
+	}
+
+	// The next commit only captures the changes made since this one.
+	state.SetState(updated, common.Hash{1}, common.Hash{4})
+	if _, err := state.Commit(2, false, false); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	diff = state.StateDiff()
+	if len(diff) != 1 || diff[0].Storage[common.Hash{1}] != (SlotDiff{Old: common.Hash{3}, New: common.Hash{4}}) {
+		t.Fatalf("unexpected state diff after second commit: %v", diff)
+	}
+}
@@ -0,0 +1,205 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	stateDiffFilePrefix = "statediff-"
+	stateDiffFileSuffix = ".jsonl"
+
+	// defaultStateDiffFileSize is the size at which a state diff file is
+	// rotated if none is configured.
+	defaultStateDiffFileSize = 256 * 1024 * 1024
+)
+
+// BlockStateDiff is the change of the state made by an accepted block. It is
+// written as a line of JSON to the state diff files.
+type BlockStateDiff struct {
+	Number   hexutil.Uint64       `json:"number"`
+	Hash     common.Hash          `json:"hash"`
+	Root     common.Hash          `json:"stateRoot"`
+	Accounts []*state.AccountDiff `json:"accounts"`
+	// Missing marks a gap in the diffs: the change made by the block is
+	// unknown, and consumers must rebuild their state from [Root].
+	Missing bool `json:"missing,omitempty"`
+}
+
+// stateDiffWriter holds the state diffs of processing blocks and appends them
+// to rotating files in [dir] once the blocks are accepted. Each file is named
+// after the number of the first block it contains.
+type stateDiffWriter struct {
+	dir      string
+	fileSize uint64 // Size at which the current file is rotated
+	maxFiles int    // Number of files to keep, or 0 to keep all of them
+
+	lock    sync.Mutex
+	pending map[common.Hash]*BlockStateDiff // Diffs of processing blocks
+
+	file *os.File // Current file, opened on the first write
+	size uint64   // Size of the current file
+}
+
+func newStateDiffWriter(dir string, fileSize uint64, maxFiles int) (*stateDiffWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state diff directory %s: %w", dir, err)
+	}
+	if fileSize == 0 {
+		fileSize = defaultStateDiffFileSize
+	}
+	return &stateDiffWriter{
+		dir:      dir,
+		fileSize: fileSize,
+		maxFiles: maxFiles,
+		pending:  make(map[common.Hash]*BlockStateDiff),
+	}, nil
+}
+
+// add holds the state diff of [block] until it is accepted or rejected.
+func (w *stateDiffWriter) add(block *types.Block, accounts []*state.AccountDiff) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.pending[block.Hash()] = &BlockStateDiff{
+		Number:   hexutil.Uint64(block.NumberU64()),
+		Hash:     block.Hash(),
+		Root:     block.Root(),
+		Accounts: accounts,
+	}
+}
+
+// discard drops the state diff of rejected block [hash].
+func (w *stateDiffWriter) discard(hash common.Hash) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	delete(w.pending, hash)
+}
+
+// accept writes the state diff of accepted [block] to the current file,
+// rotating it if it is full. If the diff is unknown, a gap marker is written
+// instead.
+func (w *stateDiffWriter) accept(block *types.Block) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	diff, ok := w.pending[block.Hash()]
+	if !ok {
+		// Blocks processed before the writer was enabled have no diff.
+		log.Warn("Missing state diff of accepted block", "number", block.NumberU64(), "hash", block.Hash())
+		diff = &BlockStateDiff{
+			Number:  hexutil.Uint64(block.NumberU64()),
+			Hash:    block.Hash(),
+			Root:    block.Root(),
+			Missing: true,
+		}
+	}
+	delete(w.pending, block.Hash())
+
+	line, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if w.file != nil && w.size+uint64(len(line)) > w.fileSize {
+		if err := w.closeFile(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.openFile(block.NumberU64()); err != nil {
+			return err
+		}
+	}
+	n, err := w.file.Write(line)
+	w.size += uint64(n)
+	return err
+}
+
+// openFile opens the file starting at block [number], and removes the oldest
+// files beyond [maxFiles].
+func (w *stateDiffWriter) openFile(number uint64) error {
+	name := filepath.Join(w.dir, fmt.Sprintf("%s%020d%s", stateDiffFilePrefix, number, stateDiffFileSuffix))
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open state diff file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file, w.size = file, uint64(info.Size())
+
+	if w.maxFiles == 0 {
+		return nil
+	}
+	files, err := w.files()
+	if err != nil {
+		return err
+	}
+	for len(files) > w.maxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return fmt.Errorf("failed to remove state diff file: %w", err)
+		}
+		log.Debug("Removed state diff file", "file", files[0])
+		files = files[1:]
+	}
+	return nil
+}
+
+// files returns the state diff files in [dir], oldest first.
+func (w *stateDiffWriter) files() ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, stateDiffFilePrefix) && strings.HasSuffix(name, stateDiffFileSuffix) {
+			files = append(files, filepath.Join(w.dir, name))
+		}
+	}
+	// Block numbers are zero padded, so files sort by their first block.
+	sort.Strings(files)
+	return files, nil
+}
+
+// closeFile syncs and closes the current file, if any.
+func (w *stateDiffWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Sync()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.file, w.size = nil, 0
+	return err
+}
+
+// close closes the current file. Diffs of blocks that were not accepted are
+// dropped, since the blocks are processed again after a restart.
+func (w *stateDiffWriter) close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.closeFile()
+}
@@ -29,6 +29,7 @@ package core
 import (
 	"crypto/ecdsa"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ava-labs/subnet-evm/consensus"
@@ -38,7 +39,10 @@ import (
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
 	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ava-labs/subnet-evm/utils"
 	"github.com/ethereum/go-ethereum/common"
@@ -421,6 +425,137 @@ func TestBadTxAllowListBlock(t *testing.T) {
 	}
 }
 
+// TestTxAllowListQuotaBlock tests that the blockchain accepts a block whose
+// transactions stay within the quota of their sender on the TX Allow List, and
+// rejects a block with transactions exceeding it.
+func TestTxAllowListQuotaBlock(t *testing.T) {
+	var (
+		testAddr = common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+
+		// Recording the usage of the quota writes a slot for the gas used, and
+		// three slots for the value transferred.
+		txGas      = params.TxGas + contract.WriteGasCostPerSlot
+		valueTxGas = txGas + 3*contract.WriteGasCostPerSlot
+
+		config = &params.ChainConfig{
+			ChainID:             big.NewInt(1),
+			FeeConfig:           params.DefaultFeeConfig,
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			MuirGlacierBlock:    big.NewInt(0),
+			NetworkUpgrades: params.NetworkUpgrades{
+				SubnetEVMTimestamp: utils.NewUint64(0),
+			},
+			GenesisPrecompiles: params.Precompiles{
+				txallowlist.ConfigKey: &txallowlist.Config{
+					AllowListConfig: allowlist.AllowListConfig{EnabledAddresses: []common.Address{testAddr}},
+					Upgrade:         precompileconfig.Upgrade{BlockTimestamp: utils.NewUint64(0)},
+					Quotas: &txallowlist.QuotasConfig{
+						AddressQuotas: map[common.Address]*txallowlist.Quota{
+							testAddr: {MaxGas: 5 * txGas, GasWindow: 3600, MaxTxValue: big.NewInt(100), MaxDailyValue: big.NewInt(150)},
+						},
+					},
+				},
+			},
+		}
+		signer     = types.LatestSigner(config)
+		testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+		gspec = &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				testAddr: GenesisAccount{
+					Balance: big.NewInt(1000000000000000000), // 1 ether
+					Nonce:   0,
+				},
+			},
+			GasLimit: config.FeeConfig.GasLimit.Uint64(),
+		}
+	)
+
+	mkTx := func(nonce uint64, gas uint64, value int64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: big.NewInt(225000000000),
+			Gas:       gas,
+			To:        &common.Address{},
+			Value:     big.NewInt(value),
+		}), signer, testKey)
+		return tx
+	}
+	mkDynamicTx := func(nonce uint64, value int64) *types.Transaction {
+		if value == 0 {
+			return mkTx(nonce, txGas, value)
+		}
+		return mkTx(nonce, valueTxGas, value)
+	}
+
+	// Transactions within the quota are accepted and their usage is recorded.
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, dummy.NewCoinbaseFaker(), 1, 10, func(i int, b *BlockGen) {
+		b.AddTx(mkDynamicTx(0, 100))
+		b.AddTx(mkDynamicTx(1, 0))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockchain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), DefaultCacheConfig, gspec, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{}, false)
+	defer blockchain.Stop()
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	statedb, err := blockchain.StateAt(blocks[0].Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := txallowlist.GetQuotaUsage(statedb, testAddr, blocks[0].Time())
+	if usage.GasUsed != txGas+valueTxGas || usage.ValueUsed.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected quota usage: gas %d, value %s", usage.GasUsed, usage.ValueUsed)
+	}
+
+	for name, tt := range map[string]struct {
+		txs  []*types.Transaction
+		want string
+	}{
+		"gas quota exceeded": {
+			txs:  []*types.Transaction{mkDynamicTx(0, 0), mkDynamicTx(1, 0), mkDynamicTx(2, 0), mkDynamicTx(3, 0), mkDynamicTx(4, 0), mkDynamicTx(5, 0)},
+			want: "gas quota exceeded: gas limit 41000, used 205000, max 205000: address 0x71562b71999873DB5b286dF957af199Ec94617F7",
+		},
+		"quota usage not covered": {
+			txs:  []*types.Transaction{mkTx(0, txGas, 1)},
+			want: "intrinsic gas too low: have 41000, want 101000",
+		},
+		"tx value quota exceeded": {
+			txs:  []*types.Transaction{mkDynamicTx(0, 101)},
+			want: "transaction value quota exceeded: value 101, max 100: address 0x71562b71999873DB5b286dF957af199Ec94617F7",
+		},
+		"daily value quota exceeded": {
+			txs:  []*types.Transaction{mkDynamicTx(0, 100), mkDynamicTx(1, 51)},
+			want: "daily value quota exceeded: value 51, used 100, max 150: address 0x71562b71999873DB5b286dF957af199Ec94617F7",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			blockchain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), DefaultCacheConfig, gspec, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{}, false)
+			defer blockchain.Stop()
+
+			block := GenerateBadBlock(blockchain.Genesis(), dummy.NewCoinbaseFaker(), tt.txs, gspec.Config)
+			_, err := blockchain.InsertChain(types.Blocks{block})
+			if err == nil {
+				t.Fatal("block imported without errors")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("have \"%v\"\nwant \"%v\"\n", err, tt.want)
+			}
+		})
+	}
+}
+
 // GenerateBadBlock constructs a "block" which contains the transactions. The transactions are not expected to be
 // valid, and no proper post-state can be made. But from the perspective of the blockchain, the block is sufficiently
 // valid to be considered for import:
@@ -351,12 +351,15 @@ func (st *StateTransition) preCheck() error {
 			return fmt.Errorf("%w: address %v", vmerrs.ErrAddrProhibited, msg.From)
 		}
 
-		// Check that the sender is on the tx allow list if enabled
+		// Check that the sender is on the tx allow list and within its quota if enabled
 		if st.evm.ChainConfig().IsPrecompileEnabled(txallowlist.ContractAddress, st.evm.Context.Time) {
 			txAllowListRole := txallowlist.GetTxAllowListStatus(st.state, msg.From)
 			if !txAllowListRole.IsEnabled() {
 				return fmt.Errorf("%w: %s", vmerrs.ErrSenderAddressNotAllowListed, msg.From)
 			}
+			if err := txallowlist.CheckQuota(st.state, msg.From, st.evm.Context.Time, msg.GasLimit, msg.Value); err != nil {
+				return fmt.Errorf("%w: address %v", err, msg.From)
+			}
 		}
 	}
 	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
@@ -431,8 +434,8 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	// 1. the nonce of the message caller is correct
 	// 2. caller has enough balance to cover transaction fee(gaslimit * gasprice)
 	// 3. the amount of gas required is available in the block
-	// 4. the message caller is on the tx allow list (if enabled)
-	// 5. the purchased gas is enough to cover intrinsic usage
+	// 4. the message caller is on the tx allow list and within its quota (if enabled)
+	// 5. the purchased gas is enough to cover intrinsic usage and the usage of the quota (if enabled)
 	// 6. there is no overflow when calculating intrinsic gas
 	// 7. caller has enough balance to cover asset transfer for **topmost** call
 
@@ -465,6 +468,17 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}
 	st.gasRemaining -= gas
 
+	// Charge the storage writes recording the usage of the quota of the sender
+	// on the tx allow list after execution.
+	recordQuota := !msg.SkipAccountChecks && rules.IsPrecompileEnabled(txallowlist.ContractAddress)
+	if recordQuota {
+		quotaGas := txallowlist.ConsumeQuotaGasCost(st.state, msg.From, msg.Value)
+		if st.gasRemaining < quotaGas {
+			return nil, fmt.Errorf("%w: have %d, want %d", ErrIntrinsicGas, st.gasRemaining+gas, gas+quotaGas)
+		}
+		st.gasRemaining -= quotaGas
+	}
+
 	// Check clause 6
 	if msg.Value.Sign() > 0 && !st.evm.Context.CanTransfer(st.state, msg.From, msg.Value) {
 		return nil, fmt.Errorf("%w: address %v", ErrInsufficientFundsForTransfer, msg.From.Hex())
@@ -494,6 +508,16 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	gasRefund := st.refundGas(rules.IsSubnetEVM)
 	st.state.AddBalance(st.evm.Context.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), msg.GasPrice))
 
+	// Record the usage of the quota of the sender on the tx allow list. The value
+	// is only transferred if the execution succeeded.
+	if recordQuota {
+		value := msg.Value
+		if vmerr != nil {
+			value = common.Big0
+		}
+		txallowlist.ConsumeQuota(st.state, msg.From, st.evm.Context.Time, st.gasUsed(), value)
+	}
+
 	return &ExecutionResult{
 		UsedGas:     st.gasUsed(),
 		RefundedGas: gasRefund,
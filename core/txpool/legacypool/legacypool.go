@@ -685,6 +685,7 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 			pool.currentHead.Load().Time,
 		),
 		MinimumFee: pool.minimumFee,
+		Time:       pool.currentHead.Load().Time,
 
 		FirstNonceGap: nil, // Pool allows arbitrary arrival order, don't invalidate nonce gaps
 		UsedAndLeftSlots: func(addr common.Address) (int, int) {
@@ -210,6 +210,10 @@ type ValidationOptionsWithState struct {
 
 	Rules      params.Rules
 	MinimumFee *big.Int
+
+	// Time is the timestamp of the current head, used to compute the usage of
+	// the time windowed quotas of the tx allow list.
+	Time uint64
 }
 
 // ValidateTransactionWithState is a helper method to check whether a transaction
@@ -271,12 +275,16 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 		}
 	}
 
-	// If the tx allow list is enabled, return an error if the from address is not allow listed.
+	// If the tx allow list is enabled, return an error if the from address is not allow listed
+	// or if the transaction exceeds its quota.
 	if opts.Rules.IsPrecompileEnabled(txallowlist.ContractAddress) {
 		txAllowListRole := txallowlist.GetTxAllowListStatus(opts.State, from)
 		if !txAllowListRole.IsEnabled() {
 			return fmt.Errorf("%w: %s", vmerrs.ErrSenderAddressNotAllowListed, from)
 		}
+		if err := txallowlist.CheckQuota(opts.State, from, opts.Time, tx.Gas(), tx.Value()); err != nil {
+			return fmt.Errorf("%w: address %v", err, from)
+		}
 	}
 
 	return nil
@@ -69,6 +69,9 @@ type (
 	// GetHashFunc returns the n'th block hash in the blockchain
 	// and is used by the BLOCKHASH EVM op code.
 	GetHashFunc func(uint64) common.Hash
+	// GetHeaderFunc returns the header of the n'th block in the blockchain
+	// and is used by stateful precompiles to access historical headers
+	GetHeaderFunc func(uint64) *types.Header
 )
 
 func (evm *EVM) precompile(addr common.Address) (contract.StatefulPrecompiledContract, bool) {
@@ -91,6 +94,11 @@ func (evm *EVM) precompile(addr common.Address) (contract.StatefulPrecompiledCon
 	if ok {
 		return p, true
 	}
+	if evm.chainRules.IsBLS12381 {
+		if p, ok := PrecompiledContractsBLS[addr]; ok {
+			return p, true
+		}
+	}
 
 	// Otherwise, check the chain rules for the additionally configured precompiles.
 	if _, ok = evm.chainRules.ActivePrecompiles[addr]; ok {
@@ -111,6 +119,9 @@ type BlockContext struct {
 	Transfer TransferFunc
 	// GetHash returns the hash corresponding to n
 	GetHash GetHashFunc
+	// GetHeader returns the header corresponding to n. It may be nil if
+	// headers are not available to the EVM.
+	GetHeader GetHeaderFunc
 	// PredicateResults are the results of predicate verification available throughout the EVM's execution.
 	// PredicateResults may be nil if it is not encoded in the block's header.
 	PredicateResults *predicate.Results
@@ -140,6 +151,25 @@ func (b *BlockContext) GetPredicateResults(txHash common.Hash, address common.Ad
 	return b.PredicateResults.GetResults(txHash, address)
 }
 
+func (b *BlockContext) GetAncestorHeader(number uint64) *contract.AncestorHeader {
+	if b.GetHeader == nil {
+		return nil
+	}
+	header := b.GetHeader(number)
+	if header == nil {
+		return nil
+	}
+	ancestor := &contract.AncestorHeader{
+		Hash: header.Hash(),
+		Time: header.Time,
+		Root: header.Root,
+	}
+	if header.BaseFee != nil {
+		ancestor.BaseFee = new(big.Int).Set(header.BaseFee)
+	}
+	return ancestor
+}
+
 // TxContext provides the EVM with information about a transaction.
 // All fields can change between transactions.
 type TxContext struct {
@@ -536,10 +566,16 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		return nil, common.Address{}, 0, vmerrs.ErrContractAddressCollision
 	}
 	// If the allow list is enabled, check that [evm.TxContext.Origin] has permission to deploy a contract.
+	// With code hash rules, anyone can deploy a contract whose init code hash is allowed, or whose
+	// runtime code hash is allowed, which is checked once the init code has run.
+	checkRuntimeCodeHash := false
 	if evm.chainRules.IsPrecompileEnabled(deployerallowlist.ContractAddress) {
 		allowListRole := deployerallowlist.GetContractDeployerAllowListStatus(evm.StateDB, evm.TxContext.Origin)
 		if !allowListRole.IsEnabled() {
-			return nil, common.Address{}, 0, fmt.Errorf("tx.origin %s is not authorized to deploy a contract", evm.TxContext.Origin)
+			if !deployerallowlist.IsCodeHashRulesEnabled(evm.StateDB) {
+				return nil, common.Address{}, 0, fmt.Errorf("tx.origin %s is not authorized to deploy a contract", evm.TxContext.Origin)
+			}
+			checkRuntimeCodeHash = !deployerallowlist.IsAllowedCodeHash(evm.StateDB, deployerallowlist.InitCodeHash, codeAndHash.Hash())
 		}
 	}
 
@@ -576,6 +612,13 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		err = vmerrs.ErrInvalidCode
 	}
 
+	// Reject code whose hash is not allowed if tx.origin is only authorized to deploy allowed code.
+	if err == nil && checkRuntimeCodeHash {
+		if runtimeCodeHash := crypto.Keccak256Hash(ret); !deployerallowlist.IsAllowedCodeHash(evm.StateDB, deployerallowlist.RuntimeCodeHash, runtimeCodeHash) {
+			err = fmt.Errorf("tx.origin %s is not authorized to deploy a contract with init code hash %s and runtime code hash %s", evm.TxContext.Origin, codeAndHash.Hash(), runtimeCodeHash)
+		}
+	}
+
 	// if the contract creation ran successfully and no errors were returned
 	// calculate the gas required to store the code. If the code could not
 	// be stored due to not enough gas set an error and let it be handled
@@ -6,8 +6,10 @@ package vm
 import (
 	"testing"
 
+	"github.com/ava-labs/subnet-evm/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIsProhibited(t *testing.T) {
@@ -34,3 +36,29 @@ func TestIsProhibited(t *testing.T) {
 	assert.False(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000100")))
 	assert.False(t, IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000100")))
 }
+
+func TestBLS12381Precompiles(t *testing.T) {
+	g1Add := common.BytesToAddress([]byte{0x0b})
+	rules := params.TestChainConfig.Rules(common.Big0, 0)
+	rules.IsCancun = true
+	rules.IsBLS12381 = false
+
+	evm := &EVM{chainRules: rules}
+	_, ok := evm.precompile(g1Add)
+	require.False(t, ok)
+	require.NotContains(t, ActivePrecompiles(rules), g1Add)
+
+	rules.IsBLS12381 = true
+	evm = &EVM{chainRules: rules}
+	p, ok := evm.precompile(g1Add)
+	require.True(t, ok)
+	require.Equal(t, PrecompiledContractsBLS[g1Add], p)
+	active := ActivePrecompiles(rules)
+	require.Subset(t, active, PrecompiledAddressesBLS)
+	require.Subset(t, active, PrecompiledAddressesCancun)
+
+	// The point evaluation precompile must not be shadowed by BLS12-381.
+	p, ok = evm.precompile(common.BytesToAddress([]byte{0x0a}))
+	require.True(t, ok)
+	require.Equal(t, PrecompiledContractsCancun[common.BytesToAddress([]byte{0x0a})], p)
+}
@@ -27,6 +27,7 @@
 package runtime
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"os"
@@ -43,8 +44,12 @@ import (
 	"github.com/ava-labs/subnet-evm/eth/tracers"
 	"github.com/ava-labs/subnet-evm/eth/tracers/logger"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/deployerallowlist"
+	"github.com/ava-labs/subnet-evm/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/asm"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	// force-load js tracers to trigger registration
 	_ "github.com/ava-labs/subnet-evm/eth/tracers/js"
@@ -135,6 +140,89 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestCreateWithDeployerCodeHashRules(t *testing.T) {
+	// initCode returns the single byte runtime code 0x00.
+	initCode := []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE8),
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	initCodeHash := crypto.Keccak256Hash(initCode)
+	runtimeCodeHash := crypto.Keccak256Hash([]byte{0x00})
+	enabledAddr := common.HexToAddress("0x01")
+	otherAddr := common.HexToAddress("0x02")
+
+	tests := map[string]struct {
+		origin      common.Address
+		setup       func(state *state.StateDB)
+		expectedErr string
+	}{
+		"enabled origin": {
+			origin: enabledAddr,
+			setup:  func(state *state.StateDB) {},
+		},
+		"origin without role and code hash rules disabled": {
+			origin:      otherAddr,
+			setup:       func(state *state.StateDB) {},
+			expectedErr: "is not authorized to deploy a contract",
+		},
+		"allowed init code hash": {
+			origin: otherAddr,
+			setup: func(state *state.StateDB) {
+				deployerallowlist.EnableCodeHashRules(state)
+				deployerallowlist.SetAllowedCodeHash(state, deployerallowlist.InitCodeHash, initCodeHash, true)
+			},
+		},
+		"allowed runtime code hash": {
+			origin: otherAddr,
+			setup: func(state *state.StateDB) {
+				deployerallowlist.EnableCodeHashRules(state)
+				deployerallowlist.SetAllowedCodeHash(state, deployerallowlist.RuntimeCodeHash, runtimeCodeHash, true)
+			},
+		},
+		"no allowed code hash": {
+			origin: otherAddr,
+			setup: func(state *state.StateDB) {
+				deployerallowlist.EnableCodeHashRules(state)
+				deployerallowlist.SetAllowedCodeHash(state, deployerallowlist.RuntimeCodeHash, common.Hash{1}, true)
+			},
+			expectedErr: "is not authorized to deploy a contract with init code hash",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			deployerallowlist.SetContractDeployerAllowListStatus(statedb, enabledAddr, allowlist.EnabledRole)
+			test.setup(statedb)
+
+			cfg := &Config{State: statedb, Origin: test.origin}
+			setDefaults(cfg)
+			cfg.ChainConfig.GenesisPrecompiles = params.Precompiles{
+				deployerallowlist.ConfigKey: deployerallowlist.NewConfig(utils.NewUint64(0), nil, nil, nil),
+			}
+			_, address, _, err := Create(initCode, cfg)
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", test.expectedErr, err)
+				}
+				if code := statedb.GetCode(address); len(code) != 0 {
+					t.Fatalf("expected no code to be deployed, got %x", code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("didn't expect error", err)
+			}
+			if code := statedb.GetCode(address); !bytes.Equal(code, []byte{0x00}) {
+				t.Fatalf("expected code 0x00 to be deployed, got %x", code)
+			}
+		})
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
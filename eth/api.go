@@ -27,9 +27,14 @@
 package eth
 
 import (
+	"errors"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
+var errProcessingQueriesDisabled = errors.New("processing block queries are disabled")
+
 // EthereumAPI provides an API to access Ethereum full node-related information.
 type EthereumAPI struct {
 	e *Ethereum
@@ -49,3 +54,39 @@ func (api *EthereumAPI) Etherbase() (common.Address, error) {
 func (api *EthereumAPI) Coinbase() (common.Address, error) {
 	return api.Etherbase()
 }
+
+// ProcessingBlock is a block that was verified but is not yet accepted or
+// rejected.
+type ProcessingBlock struct {
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Number     hexutil.Uint64 `json:"number"`
+	Timestamp  hexutil.Uint64 `json:"timestamp"`
+	StateRoot  common.Hash    `json:"stateRoot"`
+	Preferred  bool           `json:"preferred"` // Whether the block is on the preferred chain
+}
+
+// GetProcessingBlocks returns the blocks that were verified but are not yet
+// accepted or rejected, sorted by number. Their parent hashes link them to
+// each other or to the last accepted block, forming the tree of blocks being
+// decided by consensus. The state of these blocks can be queried by their
+// hash.
+func (api *EthereumAPI) GetProcessingBlocks() ([]*ProcessingBlock, error) {
+	if !api.e.APIBackend.IsAllowProcessingQueries() {
+		return nil, errProcessingQueriesDisabled
+	}
+	headers := api.e.blockchain.ProcessingBlocks()
+	blocks := make([]*ProcessingBlock, len(headers))
+	for i, header := range headers {
+		number := header.Number.Uint64()
+		blocks[i] = &ProcessingBlock{
+			Hash:       header.Hash(),
+			ParentHash: header.ParentHash,
+			Number:     hexutil.Uint64(number),
+			Timestamp:  hexutil.Uint64(header.Time),
+			StateRoot:  header.Root,
+			Preferred:  api.e.blockchain.GetCanonicalHash(number) == header.Hash(),
+		}
+	}
+	return blocks, nil
+}
@@ -29,6 +29,7 @@ package eth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
@@ -47,18 +48,36 @@ import (
 	"github.com/ava-labs/subnet-evm/params"
 	"github.com/ava-labs/subnet-evm/rpc"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 )
 
 var ErrUnfinalizedData = errors.New("cannot query unfinalized data")
 
+// prunedHistoryError is returned when the body or receipts of a block were
+// pruned because the block is older than the configured block history.
+type prunedHistoryError struct {
+	tail uint64 // oldest block whose body and receipts are available
+}
+
+func (e *prunedHistoryError) Error() string {
+	return fmt.Sprintf("pruned history unavailable: oldest available block is %d", e.tail)
+}
+
+// ErrorCode returns the JSON error code for pruned history.
+func (e *prunedHistoryError) ErrorCode() int { return rpc.ErrcodePrunedHistory }
+
+// ErrorData returns the oldest block whose body and receipts are available.
+func (e *prunedHistoryError) ErrorData() interface{} { return hexutil.Uint64(e.tail) }
+
 // EthAPIBackend implements ethapi.Backend and tracers.Backend for full nodes
 type EthAPIBackend struct {
 	extRPCEnabled            bool
 	allowUnprotectedTxs      bool
 	allowUnprotectedTxHashes map[common.Hash]struct{} // Invariant: read-only after creation.
 	allowUnfinalizedQueries  bool
+	allowProcessingQueries   bool
 	eth                      *Ethereum
 	gpo                      *gasprice.Oracle
 }
@@ -76,10 +95,22 @@ func (b *EthAPIBackend) SetAllowUnfinalizedQueries(allow bool) {
 	b.allowUnfinalizedQueries = allow
 }
 
+func (b *EthAPIBackend) IsAllowProcessingQueries() bool {
+	return b.allowProcessingQueries
+}
+
+func (b *EthAPIBackend) SetAllowProcessingQueries(allow bool) {
+	b.allowProcessingQueries = allow
+}
+
 func (b *EthAPIBackend) CurrentBlock() *types.Header {
 	return b.eth.blockchain.CurrentBlock()
 }
 
+func (b *EthAPIBackend) HistoryTail() uint64 {
+	return b.eth.blockchain.HistoryTail()
+}
+
 func (b *EthAPIBackend) LastAcceptedBlock() *types.Block {
 	return b.eth.LastAcceptedBlock()
 }
@@ -172,9 +203,32 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 		}
 	}
 
+	if err := b.checkHistory(uint64(number)); err != nil {
+		return nil, err
+	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
 }
 
+// checkHistory returns a pruned history error if the body and receipts of
+// block [number] have been pruned. The genesis block is never pruned.
+func (b *EthAPIBackend) checkHistory(number uint64) error {
+	if tail := b.eth.blockchain.HistoryTail(); number != 0 && number < tail {
+		return &prunedHistoryError{tail: tail}
+	}
+	return nil
+}
+
+// checkHistoryByHash is like checkHistory for a block identified by [hash].
+func (b *EthAPIBackend) checkHistoryByHash(hash common.Hash) error {
+	if b.eth.blockchain.HistoryTail() == 0 {
+		return nil
+	}
+	if header := b.eth.blockchain.GetHeaderByHash(hash); header != nil {
+		return b.checkHistory(header.Number.Uint64())
+	}
+	return nil
+}
+
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -182,13 +236,16 @@ func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*typ
 
 	block := b.eth.blockchain.GetBlockByHash(hash)
 	if block == nil {
-		return nil, nil
+		return nil, b.checkHistoryByHash(hash)
 	}
 
 	number := block.Number()
 	if b.eth.blockchain.GetCanonicalHash(number.Uint64()) != hash {
 		return nil, nil
 	}
+	if err := b.checkHistory(number.Uint64()); err != nil {
+		return nil, err
+	}
 
 	acceptedBlock := b.eth.LastAcceptedBlock()
 	if !b.IsAllowUnfinalizedQueries() && acceptedBlock != nil {
@@ -204,6 +261,9 @@ func (b *EthAPIBackend) GetBody(ctx context.Context, hash common.Hash, number rp
 	if number < 0 || hash == (common.Hash{}) {
 		return nil, errors.New("invalid arguments; expect hash and no special block numbers")
 	}
+	if err := b.checkHistory(uint64(number)); err != nil {
+		return nil, err
+	}
 	if body := b.eth.blockchain.GetBody(hash); body != nil {
 		return body, nil
 	}
@@ -258,9 +318,18 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 		return nil, nil, err
 	}
 	if hash, ok := blockNrOrHash.Hash(); ok {
-		header, err := b.HeaderByHash(ctx, hash)
-		if err != nil {
-			return nil, nil, err
+		// The state of a processing block may be queried by its hash even if
+		// it is not on the preferred chain.
+		var header *types.Header
+		if b.IsAllowProcessingQueries() {
+			header = b.eth.blockchain.GetProcessingHeader(hash)
+		}
+		if header == nil {
+			var err error
+			header, err = b.HeaderByHash(ctx, hash)
+			if err != nil {
+				return nil, nil, err
+			}
 		}
 		if header == nil {
 			return nil, nil, errors.New("header for hash not found")
@@ -278,6 +347,9 @@ func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (type
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	if err := b.checkHistoryByHash(hash); err != nil {
+		return nil, err
+	}
 	return b.eth.blockchain.GetReceiptsByHash(hash), nil
 }
 
@@ -285,6 +357,9 @@ func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash, number ui
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	if err := b.checkHistory(number); err != nil {
+		return nil, err
+	}
 	return b.eth.blockchain.GetLogs(hash, number), nil
 }
 
@@ -477,6 +552,19 @@ func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, sections
 }
 
+func (b *EthAPIBackend) LogIndexStatus() *core.LogIndexStatus {
+	if b.eth.logIndexer == nil {
+		return nil
+	}
+	sections, _, _ := b.eth.logIndexer.Sections()
+	return &core.LogIndexStatus{
+		SectionSize: params.BloomBitsBlocks,
+		Sections:    sections,
+		Tail:        rawdb.ReadLogIndexTail(b.eth.chainDb),
+		Head:        b.eth.blockchain.LastAcceptedBlock().NumberU64(),
+	}
+}
+
 func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	for i := 0; i < bloomFilterThreads; i++ {
 		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.eth.bloomRequests)
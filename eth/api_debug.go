@@ -371,3 +371,10 @@ func (api *DebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64, error
 	}
 	return 0, errors.New("no state found")
 }
+
+// GetAccessibleHistory returns the first block number whose body and receipts
+// are available on disk. Headers are available for all blocks, but the bodies
+// and receipts of blocks older than the configured block history are pruned.
+func (api *DebugAPI) GetAccessibleHistory() hexutil.Uint64 {
+	return hexutil.Uint64(api.eth.blockchain.HistoryTail())
+}
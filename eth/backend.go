@@ -69,7 +69,8 @@ type Config = ethconfig.Config
 var DefaultSettings Settings = Settings{MaxBlocksPerRequest: 2000}
 
 type Settings struct {
-	MaxBlocksPerRequest int64 // Maximum number of blocks to serve per getLogs request
+	MaxBlocksPerRequest         int64  // Maximum number of blocks to serve per getLogs request
+	MaxSubscriptionReplayBlocks uint64 // Maximum number of accepted blocks a resumed subscription may replay
 }
 
 // PushGossiper sends pushes pending transactions to peers until they are
@@ -99,6 +100,8 @@ type Ethereum struct {
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
 
+	logIndexer *core.ChainIndexer // Log index backfiller, nil if log indexing is disabled
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -111,6 +114,8 @@ type Ethereum struct {
 
 	shutdownTracker *shutdowncheck.ShutdownTracker // Tracks if and when the node has shutdown ungracefully
 
+	pruningProgress *pruner.Progress // Tracks the progress of offline pruning
+
 	stackRPCs []rpc.API
 
 	settings Settings // Settings for Ethereum API
@@ -154,6 +159,7 @@ func New(
 		return nil, err
 	}
 	// Try to recover offline state pruning only in hash-based.
+	pruningProgress := pruner.NewProgress()
 	if scheme == rawdb.HashScheme {
 		// Note: RecoverPruning must be called to handle the case that we are midway through offline pruning.
 		// If the data directory is changed in between runs preventing RecoverPruning from performing its job correctly,
@@ -161,7 +167,13 @@ func New(
 		// Since RecoverPruning will only continue a pruning run that already began, we do not need to ensure that
 		// reprocessState has already been called and completed successfully. To ensure this, we must maintain
 		// that Prune is only run after reprocessState has finished successfully.
-		if err := pruner.RecoverPruning(config.OfflinePruningDataDirectory, chainDb); err != nil {
+		prunerConfig := pruner.Config{
+			Datadir:     config.OfflinePruningDataDirectory,
+			BloomSize:   config.OfflinePruningBloomFilterSize,
+			Parallelism: config.OfflinePruningParallelism,
+			Progress:    pruningProgress,
+		}
+		if err := pruner.RecoverPruning(prunerConfig, chainDb); err != nil {
 			log.Error("Failed to recover state", "error", err)
 		}
 	}
@@ -184,6 +196,7 @@ func New(
 		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
 		settings:          settings,
 		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
+		pruningProgress:   pruningProgress,
 	}
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	dbVer := "<nil>"
@@ -224,6 +237,11 @@ func New(
 			AcceptedCacheSize:               config.AcceptedCacheSize,
 			TransactionHistory:              config.TransactionHistory,
 			SkipTxIndexing:                  config.SkipTxIndexing,
+			BlockHistory:                    config.BlockHistory,
+			LogIndexing:                     config.LogIndexing,
+			StateDiffDir:                    config.StateDiffDir,
+			StateDiffFileSize:               config.StateDiffFileSize,
+			StateDiffMaxFiles:               config.StateDiffMaxFiles,
 			StateHistory:                    config.StateHistory,
 			StateScheme:                     scheme,
 		}
@@ -247,6 +265,10 @@ func New(
 	}
 
 	eth.bloomIndexer.Start(eth.blockchain)
+	if config.LogIndexing {
+		eth.logIndexer = core.NewLogIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms)
+		eth.logIndexer.Start(eth.blockchain)
+	}
 
 	// config.BlobPool.Datadir = ""
 	// blobPool := blobpool.New(config.BlobPool, &chainWithFinalBlock{eth.blockchain})
@@ -270,6 +292,7 @@ func New(
 		allowUnprotectedTxs:      config.AllowUnprotectedTxs,
 		allowUnprotectedTxHashes: allowUnprotectedTxHashes,
 		allowUnfinalizedQueries:  config.AllowUnfinalizedQueries,
+		allowProcessingQueries:   config.AllowProcessingQueries,
 		eth:                      eth,
 	}
 	if config.AllowUnprotectedTxs {
@@ -304,8 +327,10 @@ func (s *Ethereum) APIs() []rpc.API {
 	apis = append(apis, s.stackRPCs...)
 
 	// Create [filterSystem] with the log cache size set in the config.
+	maxReplayBlocks := s.settings.MaxSubscriptionReplayBlocks
 	filterSystem := filters.NewFilterSystem(s.APIBackend, filters.Config{
-		Timeout: 5 * time.Minute,
+		Timeout:         5 * time.Minute,
+		MaxReplayBlocks: &maxReplayBlocks,
 	})
 
 	// Append all the local APIs and return
@@ -366,6 +391,13 @@ func (s *Ethereum) ChainDb() ethdb.Database           { return s.chainDb }
 func (s *Ethereum) NetVersion() uint64               { return s.networkID }
 func (s *Ethereum) ArchiveMode() bool                { return !s.config.Pruning }
 func (s *Ethereum) BloomIndexer() *core.ChainIndexer { return s.bloomIndexer }
+func (s *Ethereum) LogIndexer() *core.ChainIndexer   { return s.logIndexer }
+
+// OfflinePruningProgress returns the progress of offline pruning, which is
+// performed while the node starts.
+func (s *Ethereum) OfflinePruningProgress() pruner.ProgressReport {
+	return s.pruningProgress.Report()
+}
 
 // Start implements node.Lifecycle, starting all internal goroutines needed by the
 // Ethereum protocol implementation.
@@ -382,6 +414,9 @@ func (s *Ethereum) Start() {
 // FIXME remove error from type if this will never return an error
 func (s *Ethereum) Stop() error {
 	s.bloomIndexer.Close()
+	if s.logIndexer != nil {
+		s.logIndexer.Close()
+	}
 	close(s.closeBloomHandler)
 	s.txPool.Close()
 	s.blockchain.Stop()
@@ -442,6 +477,13 @@ func (s *Ethereum) handleOfflinePruning(cacheConfig *core.CacheConfig, gspec *co
 		if err := rawdb.DeleteOfflinePruning(s.chainDb); err != nil {
 			return fmt.Errorf("failed to write offline pruning disabled marker: %w", err)
 		}
+		// Discard the checkpoints of a state bloom generation that was
+		// interrupted, since it will not be resumed.
+		if s.config.OfflinePruningDataDirectory != "" {
+			if err := pruner.RemoveBloomCheckpoints(s.config.OfflinePruningDataDirectory); err != nil {
+				return fmt.Errorf("failed to remove offline pruning checkpoints: %w", err)
+			}
+		}
 		return nil
 	}
 
@@ -463,10 +505,12 @@ func (s *Ethereum) handleOfflinePruning(cacheConfig *core.CacheConfig, gspec *co
 	// Allow the blockchain to be garbage collected immediately, since we will shut down the chain after offline pruning completes.
 	s.blockchain.Stop()
 	s.blockchain = nil
-	log.Info("Starting offline pruning", "dataDir", s.config.OfflinePruningDataDirectory, "bloomFilterSize", s.config.OfflinePruningBloomFilterSize)
+	log.Info("Starting offline pruning", "dataDir", s.config.OfflinePruningDataDirectory, "bloomFilterSize", s.config.OfflinePruningBloomFilterSize, "parallelism", s.config.OfflinePruningParallelism)
 	prunerConfig := pruner.Config{
-		BloomSize: s.config.OfflinePruningBloomFilterSize,
-		Datadir:   s.config.OfflinePruningDataDirectory,
+		BloomSize:   s.config.OfflinePruningBloomFilterSize,
+		Datadir:     s.config.OfflinePruningDataDirectory,
+		Parallelism: s.config.OfflinePruningParallelism,
+		Progress:    s.pruningProgress,
 	}
 
 	pruner, err := pruner.NewPruner(s.chainDb, prunerConfig)
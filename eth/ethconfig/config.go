@@ -137,6 +137,11 @@ type Config struct {
 	// AllowUnfinalizedQueries allow unfinalized queries
 	AllowUnfinalizedQueries bool
 
+	// AllowProcessingQueries allows querying the state of verified blocks that
+	// are not yet accepted or rejected by their hash, even if unfinalized
+	// queries are not allowed.
+	AllowProcessingQueries bool
+
 	// AllowUnprotectedTxs allow unprotected transactions to be locally issued.
 	// Unprotected transactions are transactions that are signed without EIP-155
 	// replay protection.
@@ -150,6 +155,7 @@ type Config struct {
 	OfflinePruning                bool
 	OfflinePruningBloomFilterSize uint64
 	OfflinePruningDataDirectory   string
+	OfflinePruningParallelism     int
 
 	// SkipUpgradeCheck disables checking that upgrades must take place before the last
 	// accepted block. Skipping this check is useful when a node operator does not update
@@ -173,4 +179,20 @@ type Config struct {
 	// This is useful for validators that don't need to index transactions.
 	// TxLookupLimit can be still used to control unindexing old transactions.
 	SkipTxIndexing bool
+
+	// BlockHistory is the maximum number of blocks from head whose bodies and
+	// receipts are reserved:
+	//  * 0:   means no limit
+	//  * N:   means N block limit [HEAD-N+1, HEAD] and delete older bodies and receipts
+	BlockHistory uint64 `toml:",omitempty"`
+
+	// LogIndexing maintains an (address, topic0, block) index of accepted logs,
+	// backfilled in the background, to serve wide-range log queries.
+	LogIndexing bool
+
+	// StateDiffDir is the directory to write the state diffs of accepted
+	// blocks to, as rotating JSONL files. An empty directory disables them.
+	StateDiffDir      string `toml:",omitempty"`
+	StateDiffFileSize uint64 `toml:",omitempty"`
+	StateDiffMaxFiles int    `toml:",omitempty"`
 }
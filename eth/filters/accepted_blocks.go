@@ -0,0 +1,135 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/internal/ethapi"
+	"github.com/ava-labs/subnet-evm/rpc"
+)
+
+// AcceptedBlock is the notification of an acceptedBlocks subscription. The
+// block includes its full transactions, and the logs of the block are
+// included in its receipts.
+type AcceptedBlock struct {
+	Block    map[string]interface{}   `json:"block"`
+	Receipts []map[string]interface{} `json:"receipts"`
+}
+
+// AcceptedBlocks creates a subscription that delivers every accepted block
+// with its receipts, strictly in accepted order. Since accepted blocks are
+// final, a delivered block is never reorged, and rejected blocks are never
+// delivered.
+//
+// If from is set, the accepted blocks starting at that height are replayed
+// before switching to live delivery. A client resuming a dropped subscription
+// from the height of the last block it received gets every block at least
+// once. Replaying from below the block history tail is rejected, since the
+// bodies and receipts of those blocks were pruned. If a block cannot be
+// delivered the subscription stops, rather than skipping it.
+func (api *FilterAPI) AcceptedBlocks(ctx context.Context, from *rpc.BlockNumber) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	// The bodies and receipts of blocks below the history tail were pruned,
+	// so they cannot be replayed.
+	if from != nil && *from >= 0 {
+		if tail := api.sys.backend.HistoryTail(); uint64(*from) < tail {
+			return nil, fmt.Errorf("cannot replay accepted blocks from %d: history before block %d is pruned", *from, tail)
+		}
+	}
+
+	// Subscribe before reading the accepted head, so that any block accepted
+	// in between is either replayed or delivered live.
+	headers := make(chan *types.Header)
+	headersSub := api.events.SubscribeAcceptedHeads(headers)
+
+	var (
+		start uint64
+		head  uint64
+		err   error
+	)
+	if from != nil {
+		start = uint64(*from)
+		head, err = api.replayHead(*from)
+	} else {
+		// Only deliver blocks accepted after subscribing.
+		head, err = api.acceptedHead()
+		start = head + 1
+	}
+	if err != nil {
+		headersSub.Unsubscribe()
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	replay := func(ctx context.Context, out chan<- *types.Header) error {
+		for number := start; number <= head; number++ {
+			header, err := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				return fmt.Errorf("header %d not found", number)
+			}
+			select {
+			case out <- header:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+	number := func(h *types.Header) uint64 { return h.Number.Uint64() }
+	notify := func(ctx context.Context, h *types.Header) error {
+		block, err := api.acceptedBlock(ctx, h)
+		if err != nil {
+			return err
+		}
+		return notifier.Notify(rpcSub.ID, block)
+	}
+
+	go replayAndForward(notifier, rpcSub, headersSub, headers, start, head, replay, number, notify)
+	return rpcSub, nil
+}
+
+// acceptedBlock returns the notification of the accepted block of [header].
+func (api *FilterAPI) acceptedBlock(ctx context.Context, header *types.Header) (*AcceptedBlock, error) {
+	var (
+		hash   = header.Hash()
+		number = header.Number.Uint64()
+		config = api.sys.backend.ChainConfig()
+	)
+	body, err := api.sys.backend.GetBody(ctx, hash, rpc.BlockNumber(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body of block %d: %w", number, err)
+	}
+	if body == nil {
+		return nil, fmt.Errorf("body of block %d not found", number)
+	}
+	receipts, err := api.sys.backend.GetReceipts(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipts of block %d: %w", number, err)
+	}
+	block := types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles)
+	txs := block.Transactions()
+	if len(txs) != len(receipts) {
+		return nil, errors.New("receipts length mismatch")
+	}
+
+	signer := types.MakeSigner(config, block.Number(), block.Time())
+	result := &AcceptedBlock{
+		Block:    ethapi.RPCMarshalBlock(block, true, true, config),
+		Receipts: make([]map[string]interface{}, len(receipts)),
+	}
+	for i, receipt := range receipts {
+		result.Receipts[i] = ethapi.MarshalReceipt(receipt, hash, number, signer, txs[i], i)
+	}
+	return result, nil
+}
@@ -0,0 +1,133 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testAcceptedBlock struct {
+	Block struct {
+		Number       hexutil.Uint64 `json:"number"`
+		Hash         common.Hash    `json:"hash"`
+		Transactions []struct {
+			Hash common.Hash `json:"hash"`
+		} `json:"transactions"`
+	} `json:"block"`
+	Receipts []struct {
+		TransactionHash common.Hash  `json:"transactionHash"`
+		Logs            []*types.Log `json:"logs"`
+	} `json:"receipts"`
+}
+
+func TestAcceptedBlocksSubscription(t *testing.T) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{MaxReplayBlocks: utils.NewUint64(8)})
+		api          = NewFilterAPI(sys)
+		addr         = common.BytesToAddress([]byte("jeff"))
+		gspec        = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(1),
+		}
+	)
+	_, chain, receipts, err := core.GenerateChainWithGenesis(gspec, dummy.NewFaker(), 12, 10, func(i int, gen *core.BlockGen) {
+		gen.AddUncheckedReceipt(makeReceipt(addr))
+		gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+	})
+	require.NoError(t, err)
+	gspec.MustCommit(db, trie.NewDatabase(db, trie.HashDefaults))
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	// Only the first 10 blocks are accepted, the remaining ones are delivered live.
+	rawdb.WriteHeadBlockHash(db, chain[9].Hash())
+
+	server := rpc.NewServer(0)
+	defer server.Stop()
+	require.NoError(t, server.RegisterName("eth", api))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	receive := func(blocks chan *testAcceptedBlock, sub *rpc.ClientSubscription, n int) []uint64 {
+		var numbers []uint64
+		for len(numbers) < n {
+			select {
+			case block := <-blocks:
+				number := uint64(block.Block.Number)
+				require.Equal(t, chain[number-1].Hash(), block.Block.Hash)
+				require.Len(t, block.Block.Transactions, 1)
+				require.Len(t, block.Receipts, 1)
+				require.Equal(t, block.Block.Transactions[0].Hash, block.Receipts[0].TransactionHash)
+				require.Len(t, block.Receipts[0].Logs, 1)
+				require.Equal(t, addr, block.Receipts[0].Logs[0].Address)
+				numbers = append(numbers, number)
+			case err := <-sub.Err():
+				t.Fatal(err)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for blocks, received %v", numbers)
+			}
+		}
+		return numbers
+	}
+	sendAccepted := func(blocks []*types.Block) {
+		for _, block := range blocks {
+			backend.chainAcceptedFeed.Send(core.ChainEvent{Block: block, Hash: block.Hash()})
+		}
+	}
+
+	// Without a resume height, only blocks accepted after subscribing are
+	// delivered.
+	liveBlocks := make(chan *testAcceptedBlock)
+	liveSub, err := client.EthSubscribe(context.Background(), liveBlocks, "acceptedBlocks")
+	require.NoError(t, err)
+	defer liveSub.Unsubscribe()
+	go sendAccepted(chain[9:])
+	require.Equal(t, []uint64{11, 12}, receive(liveBlocks, liveSub, 2))
+
+	// The replay limit is enforced
+	blocks := make(chan *testAcceptedBlock)
+	_, err = client.EthSubscribe(context.Background(), blocks, "acceptedBlocks", rpc.BlockNumber(1))
+	require.ErrorContains(t, err, "exceeds maximum of 8")
+
+	sub, err := client.EthSubscribe(context.Background(), blocks, "acceptedBlocks", rpc.BlockNumber(5))
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	// A stale live event for an already replayed block must not be delivered
+	// twice, while blocks above the replayed range are delivered live.
+	go sendAccepted(chain[8:])
+	require.Equal(t, []uint64{5, 6, 7, 8, 9, 10, 11, 12}, receive(blocks, sub, 8))
+
+	_, err = client.EthSubscribe(context.Background(), blocks, "acceptedBlocks", rpc.BlockNumber(-1))
+	require.ErrorContains(t, err, errInvalidResumeHeight.Error())
+
+	// Blocks below the history tail cannot be replayed.
+	rawdb.WriteBlockHistoryTail(db, 6)
+	_, err = client.EthSubscribe(context.Background(), blocks, "acceptedBlocks", rpc.BlockNumber(5))
+	require.ErrorContains(t, err, "history before block 6 is pruned")
+
+	tailBlocks := make(chan *testAcceptedBlock)
+	tailSub, err := client.EthSubscribe(context.Background(), tailBlocks, "acceptedBlocks", rpc.BlockNumber(6))
+	require.NoError(t, err)
+	defer tailSub.Unsubscribe()
+	require.Equal(t, []uint64{6, 7, 8, 9, 10}, receive(tailBlocks, tailSub, 5))
+}
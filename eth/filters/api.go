@@ -54,6 +54,10 @@ var (
 // The maximum number of topic criteria allowed, vm.LOG4 - vm.LOG0
 const maxTopics = 4
 
+// The maximum number of logs gathered for a single page of GetLogsPage. A page
+// is always extended to the end of the block in which the limit is reached.
+const maxLogsPageSize = 10_000
+
 // filter is a helper struct that holds meta information over the filter type
 // and associated subscription in the event system.
 type filter struct {
@@ -282,12 +286,20 @@ func (api *FilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+//
+// If from is set, the headers of the accepted blocks starting at that height are
+// replayed before switching to live delivery of accepted headers, allowing a
+// client to resume a dropped subscription without missing any header.
+func (api *FilterAPI) NewHeads(ctx context.Context, from *rpc.BlockNumber) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
 
+	if from != nil {
+		return api.resumeHeads(notifier, *from)
+	}
+
 	rpcSub := notifier.CreateSubscription()
 
 	go func() {
@@ -320,12 +332,20 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+//
+// If from is set, the matching logs of the accepted blocks starting at that
+// height are replayed before switching to live delivery of accepted logs,
+// allowing a client to resume a dropped subscription without missing any log.
+func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria, from *rpc.BlockNumber) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
 
+	if from != nil {
+		return api.resumeLogs(notifier, crit, *from)
+	}
+
 	var (
 		rpcSub      = notifier.CreateSubscription()
 		matchedLogs = make(chan []*types.Log)
@@ -460,6 +480,90 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 	return returnLogs(logs), err
 }
 
+// LogsPage is a page of logs returned by GetLogsPage.
+type LogsPage struct {
+	Logs []*types.Log `json:"logs"`
+	// NextFromBlock is the block to query from to retrieve the next page, or
+	// nil if the requested range has been exhausted.
+	NextFromBlock *hexutil.Uint64 `json:"nextFromBlock"`
+}
+
+// GetLogsPage returns logs matching the given range criteria, stopping at the
+// first block boundary after pageSize logs have been gathered. Callers retrieve
+// subsequent pages by repeating the query with fromBlock set to the returned
+// nextFromBlock.
+func (api *FilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, pageSize *hexutil.Uint64) (*LogsPage, error) {
+	if len(crit.Topics) > maxTopics {
+		return nil, errExceedMaxTopics
+	}
+	if crit.BlockHash != nil {
+		return nil, errors.New("pagination is not supported for block hash queries")
+	}
+	begin := rpc.LatestBlockNumber.Int64()
+	if crit.FromBlock != nil {
+		begin = crit.FromBlock.Int64()
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if crit.ToBlock != nil {
+		end = crit.ToBlock.Int64()
+	}
+	if begin > 0 && end > 0 && begin > end {
+		return nil, errInvalidBlockRange
+	}
+	limit := maxLogsPageSize
+	if pageSize != nil && *pageSize > 0 && *pageSize < maxLogsPageSize {
+		limit = int(*pageSize)
+	}
+	filter := api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
+	filter.limit = limit
+
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page := &LogsPage{Logs: returnLogs(logs)}
+	if filter.limitReached() && filter.begin <= filter.end {
+		next := hexutil.Uint64(filter.begin)
+		page.NextFromBlock = &next
+	}
+	return page, nil
+}
+
+// LogIndexStatus describes the coverage of the log index.
+type LogIndexStatus struct {
+	Enabled     bool            `json:"enabled"`
+	SectionSize hexutil.Uint64  `json:"sectionSize"`
+	Sections    hexutil.Uint64  `json:"sections"`
+	Tail        *hexutil.Uint64 `json:"tail"`
+	IndexedHead *hexutil.Uint64 `json:"indexedHead"`
+	Head        hexutil.Uint64  `json:"head"`
+}
+
+// GetLogIndexStatus returns the range of accepted blocks covered by the log
+// index. Log queries filtering on addresses are served from the index up to
+// indexedHead and fall back to bloom filtering above it.
+func (api *FilterAPI) GetLogIndexStatus() *LogIndexStatus {
+	status := api.sys.backend.LogIndexStatus()
+	if status == nil {
+		return &LogIndexStatus{}
+	}
+	res := &LogIndexStatus{
+		Enabled:     true,
+		SectionSize: hexutil.Uint64(status.SectionSize),
+		Sections:    hexutil.Uint64(status.Sections),
+		Head:        hexutil.Uint64(status.Head),
+	}
+	if status.Tail != nil {
+		tail := hexutil.Uint64(*status.Tail)
+		res.Tail = &tail
+	}
+	if head, ok := status.IndexedHead(); ok {
+		indexedHead := hexutil.Uint64(head)
+		res.IndexedHead = &indexedHead
+	}
+	return res
+}
+
 // UninstallFilter removes the filter with the given filter id.
 func (api *FilterAPI) UninstallFilter(id rpc.ID) bool {
 	api.filtersMu.Lock()
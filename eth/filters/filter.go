@@ -31,8 +31,10 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/ava-labs/subnet-evm/core/bloombits"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/rpc"
 	"github.com/ethereum/go-ethereum/common"
@@ -48,6 +50,9 @@ type Filter struct {
 	block      *common.Hash // Block hash if filtering a single block
 	begin, end int64        // Range interval if filtering multiple blocks
 
+	limit int // Stop range filtering at the first block boundary after this many logs (0 = unlimited)
+	found int // Number of logs gathered so far by range filtering
+
 	matcher *bloombits.Matcher
 }
 
@@ -235,6 +240,22 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 			size, sections = f.sys.backend.BloomStatus()
 			err            error
 		)
+		// Serve the range covered by the log index first, if any
+		if status := f.sys.backend.LogIndexStatus(); status != nil && len(f.addresses) > 0 {
+			if head, ok := status.IndexedHead(); ok && head >= uint64(f.begin) {
+				if head > end {
+					head = end
+				}
+				if err = f.logIndexedLogs(ctx, head, logChan); err != nil {
+					errChan <- err
+					return
+				}
+			}
+		}
+		if f.limitReached() {
+			errChan <- nil
+			return
+		}
 		if indexed := sections * size; indexed > uint64(f.begin) {
 			if indexed > end {
 				indexed = end + 1
@@ -244,6 +265,10 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 				return
 			}
 		}
+		if f.limitReached() {
+			errChan <- nil
+			return
+		}
 
 		if err := f.unindexedLogs(ctx, end, logChan); err != nil {
 			errChan <- err
@@ -295,6 +320,9 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 			for _, log := range found {
 				logChan <- log
 			}
+			if f.found += len(found); f.limitReached() {
+				return nil
+			}
 
 		case <-ctx.Done():
 			return ctx.Err()
@@ -302,6 +330,62 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 	}
 }
 
+// logIndexedLogs returns the logs matching the filter criteria in the range
+// [f.begin, end] based on the log index. The caller must ensure the range is
+// covered by the index.
+func (f *Filter) logIndexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
+	// The index is keyed by the first topic only, so any further topics are
+	// checked against the receipts of the candidate blocks.
+	var topics []*common.Hash
+	if len(f.topics) > 0 && len(f.topics[0]) > 0 {
+		for i := range f.topics[0] {
+			topics = append(topics, &f.topics[0][i])
+		}
+	} else {
+		topics = []*common.Hash{nil}
+	}
+	candidates := make(map[uint64]struct{})
+	for _, address := range f.addresses {
+		for _, topic := range topics {
+			for _, number := range rawdb.ReadLogIndexBlocks(f.sys.backend.ChainDb(), address, topic, uint64(f.begin), end, 0) {
+				candidates[number] = struct{}{}
+			}
+		}
+	}
+	numbers := make([]uint64, 0, len(candidates))
+	for number := range candidates {
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+
+	for _, number := range numbers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header == nil || err != nil {
+			return err
+		}
+		found, err := f.checkMatches(ctx, header)
+		if err != nil {
+			return err
+		}
+		for _, log := range found {
+			select {
+			case logChan <- log:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		f.begin = int64(number) + 1
+		if f.found += len(found); f.limitReached() {
+			return nil
+		}
+	}
+	f.begin = int64(end) + 1
+	return nil
+}
+
 // unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
@@ -321,10 +405,20 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64, logChan chan *ty
 				return ctx.Err()
 			}
 		}
+		if f.found += len(found); f.limitReached() {
+			f.begin++
+			return nil
+		}
 	}
 	return nil
 }
 
+// limitReached reports whether range filtering has gathered at least [limit]
+// logs and should stop at the current block boundary.
+func (f *Filter) limitReached() bool {
+	return f.limit > 0 && f.found >= f.limit
+}
+
 // blockLogs returns the logs matching the filter criteria within a single block.
 func (f *Filter) blockLogs(ctx context.Context, header *types.Header) ([]*types.Log, error) {
 	if bloomFilter(header.Bloom, f.addresses, f.topics) {
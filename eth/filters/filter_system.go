@@ -48,13 +48,18 @@ import (
 
 // Config represents the configuration of the filter system.
 type Config struct {
-	Timeout time.Duration // how long filters stay active (default: 5min)
+	Timeout         time.Duration // how long filters stay active (default: 5min)
+	MaxReplayBlocks *uint64       // maximum number of accepted blocks a resumed subscription may replay, 0 disables replay (default: 4096)
 }
 
 func (cfg Config) withDefaults() Config {
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 5 * time.Minute
 	}
+	if cfg.MaxReplayBlocks == nil {
+		maxReplayBlocks := uint64(4096)
+		cfg.MaxReplayBlocks = &maxReplayBlocks
+	}
 	return cfg
 }
 
@@ -82,10 +87,18 @@ type Backend interface {
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 
+	// LogIndexStatus returns the coverage of the log index, or nil if log
+	// indexing is disabled.
+	LogIndexStatus() *core.LogIndexStatus
+
 	// Added to the backend interface to support limiting of logs requests
 	IsAllowUnfinalizedQueries() bool
 	LastAcceptedBlock() *types.Block
 	GetMaxBlocksPerRequest() int64
+
+	// HistoryTail returns the oldest block whose body and receipts have not
+	// been pruned, or 0 if block history has never been pruned.
+	HistoryTail() uint64
 }
 
 // FilterSystem holds resources shared by all filters.
@@ -55,6 +55,7 @@ import (
 type testBackend struct {
 	db                ethdb.Database
 	sections          uint64
+	logIndex          *core.LogIndexStatus
 	txFeed            event.Feed
 	acceptedTxFeed    event.Feed
 	logsFeed          event.Feed
@@ -89,6 +90,13 @@ func (b *testBackend) LastAcceptedBlock() *types.Block {
 	return rawdb.ReadHeadBlock(b.db)
 }
 
+func (b *testBackend) HistoryTail() uint64 {
+	if tail := rawdb.ReadBlockHistoryTail(b.db); tail != nil {
+		return *tail
+	}
+	return 0
+}
+
 func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
 	var (
 		hash common.Hash
@@ -187,6 +195,10 @@ func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
 
+func (b *testBackend) LogIndexStatus() *core.LogIndexStatus {
+	return b.logIndex
+}
+
 func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	requests := make(chan chan *bloombits.Retrieval)
 
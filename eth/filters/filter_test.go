@@ -44,6 +44,7 @@ import (
 	"github.com/ava-labs/subnet-evm/rpc"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
@@ -403,3 +404,82 @@ func patchWant(t *testing.T, want string, blocks []*types.Block) string {
 	}
 	return string(result)
 }
+
+func TestLogIndexFilters(t *testing.T) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		key1, _      = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1        = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2        = common.BytesToAddress([]byte("jeff"))
+		addr3        = common.BytesToAddress([]byte("ethereum"))
+
+		gspec = &core.Genesis{
+			Alloc:   core.GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+			BaseFee: big.NewInt(1),
+			Config:  params.TestChainConfig,
+		}
+		logBlocks = map[int]common.Address{3: addr1, 10: addr2, 11: addr1, 20: addr3, 35: addr1}
+	)
+	_, chain, receipts, err := core.GenerateChainWithGenesis(gspec, dummy.NewFaker(), 40, 10, func(i int, gen *core.BlockGen) {
+		if addr, ok := logBlocks[i]; ok {
+			gen.AddUncheckedReceipt(makeReceipt(addr))
+			gen.AddUncheckedTx(types.NewTransaction(999, common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+		}
+	})
+	require.NoError(t, err)
+	gspec.MustCommit(db, trie.NewDatabase(db, trie.HashDefaults))
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		// Only index the first 30 blocks, leaving the rest to bloom filtering.
+		if block.NumberU64() <= 30 {
+			for _, receipt := range receipts[i] {
+				rawdb.WriteLogIndexEntries(db, block.NumberU64(), receipt.Logs)
+			}
+		}
+	}
+	tail := uint64(1)
+	backend.logIndex = &core.LogIndexStatus{SectionSize: 4096, Tail: &tail, Head: 30}
+
+	logs, err := sys.NewRangeFilter(0, int64(rpc.LatestBlockNumber), []common.Address{addr1, addr3}, nil).Logs(context.Background())
+	require.NoError(t, err)
+	var numbers []uint64
+	for _, log := range logs {
+		numbers = append(numbers, log.BlockNumber)
+	}
+	require.Equal(t, []uint64{4, 12, 21, 36}, numbers)
+
+	t.Run("pagination", func(t *testing.T) {
+		var (
+			api   = NewFilterAPI(sys)
+			from  = rpc.BlockNumber(0)
+			pages [][]uint64
+		)
+		pageSize := hexutil.Uint64(1)
+		for {
+			page, err := api.GetLogsPage(context.Background(), FilterCriteria{FromBlock: big.NewInt(int64(from)), Addresses: []common.Address{addr1}}, &pageSize)
+			require.NoError(t, err)
+			var numbers []uint64
+			for _, log := range page.Logs {
+				numbers = append(numbers, log.BlockNumber)
+			}
+			pages = append(pages, numbers)
+			if page.NextFromBlock == nil {
+				break
+			}
+			from = rpc.BlockNumber(*page.NextFromBlock)
+		}
+		// The last page confirms that no logs remain after the final match.
+		require.Equal(t, [][]uint64{{4}, {12}, {36}, nil}, pages)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		status := NewFilterAPI(sys).GetLogIndexStatus()
+		require.True(t, status.Enabled)
+		require.NotNil(t, status.IndexedHead)
+		require.Equal(t, hexutil.Uint64(30), *status.IndexedHead)
+	})
+}
@@ -0,0 +1,231 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var errInvalidResumeHeight = errors.New("resume height must be a block number")
+
+// resumeHeads creates a subscription that replays the headers of the accepted
+// blocks starting at [from], followed by live accepted headers.
+func (api *FilterAPI) resumeHeads(notifier *rpc.Notifier, from rpc.BlockNumber) (*rpc.Subscription, error) {
+	// Subscribe before reading the accepted head, so that any block accepted
+	// in between is either replayed or delivered live.
+	headers := make(chan *types.Header)
+	headersSub := api.events.SubscribeAcceptedHeads(headers)
+
+	head, err := api.replayHead(from)
+	if err != nil {
+		headersSub.Unsubscribe()
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	replay := func(ctx context.Context, out chan<- *types.Header) error {
+		for number := uint64(from); number <= head; number++ {
+			header, err := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				return fmt.Errorf("header %d not found", number)
+			}
+			select {
+			case out <- header:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+	number := func(h *types.Header) uint64 { return h.Number.Uint64() }
+	notify := func(_ context.Context, h *types.Header) error { return notifier.Notify(rpcSub.ID, h) }
+
+	go replayAndForward(notifier, rpcSub, headersSub, headers, uint64(from), head, replay, number, notify)
+	return rpcSub, nil
+}
+
+// resumeLogs creates a subscription that replays the logs matching [crit] of
+// the accepted blocks starting at [from], followed by live accepted logs.
+func (api *FilterAPI) resumeLogs(notifier *rpc.Notifier, crit FilterCriteria, from rpc.BlockNumber) (*rpc.Subscription, error) {
+	// Subscribe before reading the accepted head, so that any block accepted
+	// in between is either replayed or delivered live.
+	matchedLogs := make(chan []*types.Log)
+	logsSub, err := api.events.SubscribeAcceptedLogs(interfaces.FilterQuery(crit), matchedLogs)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := api.replayHead(from)
+	if err != nil {
+		logsSub.Unsubscribe()
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	replay := func(ctx context.Context, out chan<- []*types.Log) error {
+		// Respect the per-request block limit by replaying in chunks.
+		chunk := uint64(api.sys.backend.GetMaxBlocksPerRequest())
+		for begin := uint64(from); begin <= head; {
+			end := head
+			if chunk > 0 && end-begin+1 > chunk {
+				end = begin + chunk - 1
+			}
+			logs, err := api.sys.NewRangeFilter(int64(begin), int64(end), crit.Addresses, crit.Topics).Logs(ctx)
+			if err != nil {
+				return err
+			}
+			for _, log := range logs {
+				select {
+				case out <- []*types.Log{log}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			begin = end + 1
+		}
+		return nil
+	}
+	number := func(logs []*types.Log) uint64 {
+		if len(logs) == 0 {
+			return 0
+		}
+		return logs[0].BlockNumber
+	}
+	notify := func(_ context.Context, logs []*types.Log) error {
+		for _, log := range logs {
+			log := log
+			if err := notifier.Notify(rpcSub.ID, &log); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	go replayAndForward(notifier, rpcSub, logsSub, matchedLogs, uint64(from), head, replay, number, notify)
+	return rpcSub, nil
+}
+
+// replayHead returns the last accepted block to replay up to when resuming a
+// subscription from [from], enforcing the configured replay limit.
+func (api *FilterAPI) replayHead(from rpc.BlockNumber) (uint64, error) {
+	if from < 0 {
+		return 0, errInvalidResumeHeight
+	}
+	head, err := api.acceptedHead()
+	if err != nil {
+		return 0, err
+	}
+	maxReplayBlocks := *api.sys.cfg.MaxReplayBlocks
+	if start := uint64(from); start <= head && head-start+1 > maxReplayBlocks {
+		return 0, fmt.Errorf("requested replay of %d blocks from %d exceeds maximum of %d", head-start+1, start, maxReplayBlocks)
+	}
+	return head, nil
+}
+
+// acceptedHead returns the number of the last accepted block.
+func (api *FilterAPI) acceptedHead() (uint64, error) {
+	lastAccepted := api.sys.backend.LastAcceptedBlock()
+	if lastAccepted == nil {
+		return 0, errors.New("last accepted block not found")
+	}
+	return lastAccepted.NumberU64(), nil
+}
+
+// replayAndForward notifies the items produced by [replay] for the accepted
+// blocks from [start] up to [head], followed by the items received on [live]
+// for blocks from [start] that are above [head]. Live items received while
+// replaying are queued, so that the event loop is never blocked by a replay in
+// progress. The subscription ends if an item cannot be notified, so that no
+// item is skipped. The context passed to [replay] and [notify] is cancelled
+// when the subscription ends.
+func replayAndForward[T any](
+	notifier *rpc.Notifier,
+	rpcSub *rpc.Subscription,
+	sub *Subscription,
+	live <-chan T,
+	start uint64,
+	head uint64,
+	replay func(ctx context.Context, out chan<- T) error,
+	number func(T) uint64,
+	notify func(ctx context.Context, item T) error,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		sub.Unsubscribe()
+	}()
+	// Cancel a replay or notification in progress as soon as the
+	// subscription ends.
+	go func() {
+		select {
+		case <-rpcSub.Err():
+		case <-notifier.Closed():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	var (
+		replayed  = make(chan T)
+		replayErr = make(chan error, 1)
+		replaying = true
+		queued    []T
+	)
+	go func() {
+		replayErr <- replay(ctx, replayed)
+	}()
+
+	// Live items of blocks that were replayed, or precede [start], are dropped.
+	first := head + 1
+	if start > first {
+		first = start
+	}
+	forward := func(item T) bool {
+		if err := notify(ctx, item); err != nil {
+			log.Warn("Failed to notify subscription", "id", rpcSub.ID, "err", err)
+			return false
+		}
+		return true
+	}
+	for {
+		select {
+		case item := <-replayed:
+			if !forward(item) {
+				return
+			}
+		case err := <-replayErr:
+			if err != nil {
+				log.Warn("Failed to replay subscription", "id", rpcSub.ID, "err", err)
+				return
+			}
+			replaying = false
+			for _, item := range queued {
+				if number(item) >= first && !forward(item) {
+					return
+				}
+			}
+			queued = nil
+		case item := <-live:
+			if replaying {
+				queued = append(queued, item)
+			} else if number(item) >= first && !forward(item) {
+				return
+			}
+		case <-rpcSub.Err(): // client send an unsubscribe request
+			return
+		case <-notifier.Closed(): // connection dropped
+			return
+		}
+	}
+}
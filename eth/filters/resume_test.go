@@ -0,0 +1,115 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeHeadsSubscription(t *testing.T) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{MaxReplayBlocks: utils.NewUint64(8)})
+		api          = NewFilterAPI(sys)
+		gspec        = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(1),
+		}
+	)
+	_, chain, _, err := core.GenerateChainWithGenesis(gspec, dummy.NewFaker(), 12, 10, func(i int, gen *core.BlockGen) {})
+	require.NoError(t, err)
+	gspec.MustCommit(db, trie.NewDatabase(db, trie.HashDefaults))
+	// Only the first 10 blocks are accepted, the remaining ones are delivered live.
+	for _, block := range chain[:10] {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+	}
+
+	server := rpc.NewServer(0)
+	defer server.Stop()
+	require.NoError(t, server.RegisterName("eth", api))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	// The replay limit is enforced
+	headers := make(chan *types.Header)
+	_, err = client.EthSubscribe(context.Background(), headers, "newHeads", rpc.BlockNumber(1))
+	require.ErrorContains(t, err, "exceeds maximum of 8")
+
+	sub, err := client.EthSubscribe(context.Background(), headers, "newHeads", rpc.BlockNumber(5))
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	// Resuming above the accepted head skips the live blocks below it.
+	aheadHeaders := make(chan *types.Header)
+	aheadSub, err := client.EthSubscribe(context.Background(), aheadHeaders, "newHeads", rpc.BlockNumber(12))
+	require.NoError(t, err)
+	defer aheadSub.Unsubscribe()
+
+	// A stale live event for an already replayed block must not be delivered
+	// twice, while blocks above the replayed range are delivered live.
+	go func() {
+		for _, block := range chain[8:] {
+			backend.chainAcceptedFeed.Send(core.ChainEvent{Block: block, Hash: block.Hash()})
+		}
+	}()
+
+	var numbers []uint64
+	for len(numbers) < 8 {
+		select {
+		case header := <-headers:
+			numbers = append(numbers, header.Number.Uint64())
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for headers, received %v", numbers)
+		}
+	}
+	require.Equal(t, []uint64{5, 6, 7, 8, 9, 10, 11, 12}, numbers)
+	select {
+	case header := <-aheadHeaders:
+		require.Equal(t, uint64(12), header.Number.Uint64())
+	case err := <-aheadSub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for header")
+	}
+
+	_, err = client.EthSubscribe(context.Background(), headers, "newHeads", rpc.BlockNumber(-1))
+	require.ErrorContains(t, err, errInvalidResumeHeight.Error())
+}
+
+func TestResumeReplayDisabled(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{MaxReplayBlocks: utils.NewUint64(0)})
+		api    = NewFilterAPI(sys)
+		gspec  = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(1),
+		}
+	)
+	genesis := gspec.MustCommit(db, trie.NewDatabase(db, trie.HashDefaults))
+	rawdb.WriteHeadBlockHash(db, genesis.Hash())
+
+	// Resuming is only allowed from the first block that is not accepted yet.
+	_, err := api.replayHead(rpc.BlockNumber(0))
+	require.ErrorContains(t, err, "exceeds maximum of 0")
+	_, err = api.replayHead(rpc.BlockNumber(1))
+	require.NoError(t, err)
+}
@@ -1071,6 +1071,10 @@ func overrideConfig(original *params.ChainConfig, override *params.ChainConfig)
 		copy.EUpgradeTimestamp = timestamp
 		canon = false
 	}
+	if timestamp := override.BLS12381Timestamp; timestamp != nil {
+		copy.BLS12381Timestamp = timestamp
+		canon = false
+	}
 	if timestamp := override.CancunTime; timestamp != nil {
 		copy.CancunTime = timestamp
 		canon = false
@@ -0,0 +1,361 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/feemanager"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks that can be simulated
+	// by a single SimulateV1 request.
+	maxSimulateBlocks = 256
+
+	// maxCalls is the maximum number of calls that can be executed by a single
+	// CallMany or SimulateV1 request, across all of its blocks.
+	maxCalls = 1000
+)
+
+var (
+	errSimulateTooManyBlocks = fmt.Errorf("too many blocks to simulate (max %d)", maxSimulateBlocks)
+	errTooManyCalls          = fmt.Errorf("too many calls (max %d)", maxCalls)
+)
+
+// CallManyArgs is a call executed by CallMany. Its state overrides are applied
+// on top of the state left by the previous calls before it is executed.
+type CallManyArgs struct {
+	TransactionArgs
+	StateOverrides *StateOverride `json:"stateOverrides"`
+}
+
+// CallManyResult is the outcome of a single call executed by CallMany or
+// SimulateV1.
+//
+// Logs are attributed to placeholder transaction hashes, since the calls are
+// not transactions.
+type CallManyResult struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Logs       []*types.Log   `json:"logs"`
+	ErrCode    int            `json:"errCode,omitempty"` // EVM error code
+	Err        string         `json:"error,omitempty"`   // Any error encountered during the execution
+}
+
+// CallMany executes the given calls in order on the state of the given block,
+// such that every call observes the state changes of the calls before it.
+//
+// The block overrides apply to all the calls. The RPC gas cap limits the gas
+// used by all the calls together. Like Call, this function does not make any
+// changes to the state or blockchain.
+func (s *BlockChainAPI) CallMany(ctx context.Context, calls []CallManyArgs, blockNrOrHash *rpc.BlockNumberOrHash, blockOverrides *BlockOverrides) ([]*CallManyResult, error) {
+	if len(calls) > maxCalls {
+		return nil, errTooManyCalls
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, header, err := callStateAndHeader(ctx, s.b, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	ctx, cancel := withCallTimeout(ctx, s.b.RPCEVMTimeout())
+	defer cancel()
+
+	var (
+		exec     = newCallExecutor(s.b, state, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
+		blockCtx = core.NewEVMBlockContext(header, NewChainContext(ctx, s.b), nil)
+		results  = make([]*CallManyResult, 0, len(calls))
+	)
+	blockOverrides.Apply(&blockCtx)
+	// Apply the upgrades activated between the block and the overridden time.
+	if err := core.ApplyUpgrades(s.b.ChainConfig(), &header.Time, &blockCtx, state); err != nil {
+		return nil, err
+	}
+	for i, call := range calls {
+		result, err := exec.call(ctx, header, &blockCtx, header.Hash(), i, call.TransactionArgs, call.StateOverrides)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SimulateBlock is a block of calls simulated by SimulateV1.
+type SimulateBlock struct {
+	BlockOverrides *BlockOverrides   `json:"blockOverrides"`
+	StateOverrides *StateOverride    `json:"stateOverrides"`
+	Calls          []TransactionArgs `json:"calls"`
+}
+
+// SimulateOpts are the arguments of SimulateV1.
+type SimulateOpts struct {
+	BlockStateCalls []SimulateBlock `json:"blockStateCalls"`
+}
+
+// SimulateBlockResult is the outcome of a block simulated by SimulateV1.
+type SimulateBlockResult struct {
+	Number    hexutil.Uint64    `json:"number"`
+	Hash      common.Hash       `json:"hash"`
+	Timestamp hexutil.Uint64    `json:"timestamp"`
+	GasLimit  hexutil.Uint64    `json:"gasLimit"`
+	GasUsed   hexutil.Uint64    `json:"gasUsed"`
+	BaseFee   *hexutil.Big      `json:"baseFeePerGas"`
+	Calls     []*CallManyResult `json:"calls"`
+}
+
+// SimulateV1 executes a sequence of blocks of calls on top of the given block.
+// Every block is applied on the state left by the previous blocks, after
+// applying the upgrades activated since its parent and its state overrides.
+// Unless overridden, every block increments the number and timestamp of its
+// parent by one, and its gas limit and base fee follow the fee config like
+// those of a real block. The calls of a block cannot use more gas than its gas
+// limit.
+//
+// The RPC gas cap limits the gas used by all the calls of all the blocks
+// together. Like Call, this function does not make any changes to the state
+// or blockchain.
+func (s *BlockChainAPI) SimulateV1(ctx context.Context, opts SimulateOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimulateBlockResult, error) {
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, errSimulateTooManyBlocks
+	}
+	calls := 0
+	for _, block := range opts.BlockStateCalls {
+		calls += len(block.Calls)
+	}
+	if calls > maxCalls {
+		return nil, errTooManyCalls
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, parent, err := callStateAndHeader(ctx, s.b, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	ctx, cancel := withCallTimeout(ctx, s.b.RPCEVMTimeout())
+	defer cancel()
+
+	var (
+		exec    = newCallExecutor(s.b, state, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
+		results = make([]*SimulateBlockResult, 0, len(opts.BlockStateCalls))
+	)
+	for i, block := range opts.BlockStateCalls {
+		header, blockCtx, err := s.simulatedHeader(ctx, state, parent, block.BlockOverrides)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if err := core.ApplyUpgrades(s.b.ChainConfig(), &parent.Time, blockCtx, state); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if err := block.StateOverrides.Apply(state); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		result := &SimulateBlockResult{
+			Number:    hexutil.Uint64(header.Number.Uint64()),
+			Hash:      header.Hash(),
+			Timestamp: hexutil.Uint64(header.Time),
+			GasLimit:  hexutil.Uint64(header.GasLimit),
+			BaseFee:   (*hexutil.Big)(header.BaseFee),
+			Calls:     make([]*CallManyResult, 0, len(block.Calls)),
+		}
+		for j, call := range block.Calls {
+			// Like the transactions of a real block, the calls cannot use more
+			// gas than the block gas limit.
+			remaining := header.GasLimit - uint64(result.GasUsed)
+			if call.Gas == nil {
+				call.Gas = (*hexutil.Uint64)(&remaining)
+			} else if uint64(*call.Gas) > remaining {
+				return nil, fmt.Errorf("block %d, call %d: %w: gas %d exceeds remaining block gas %d", i, j, core.ErrGasLimitReached, uint64(*call.Gas), remaining)
+			}
+			callResult, err := exec.call(ctx, header, blockCtx, result.Hash, j, call, nil)
+			if err != nil {
+				return nil, fmt.Errorf("block %d, call %d: %w", i, j, err)
+			}
+			result.GasUsed += callResult.GasUsed
+			result.Calls = append(result.Calls, callResult)
+		}
+		results = append(results, result)
+		// The gas used by the block determines the base fee of its child.
+		header.GasUsed = uint64(result.GasUsed)
+		parent = header
+	}
+	return results, nil
+}
+
+// simulatedHeader returns the header and block context of a block simulated
+// on top of [parent], with the given overrides applied. Like a real block, its
+// gas limit and base fee are derived from the fee config in effect at
+// [parent], which is read from [state] since simulated parents are not stored.
+func (s *BlockChainAPI) simulatedHeader(ctx context.Context, state *state.StateDB, parent *types.Header, overrides *BlockOverrides) (*types.Header, *vm.BlockContext, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase,
+		Difficulty: parent.Difficulty,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 1,
+	}
+	if overrides != nil && overrides.Time != nil {
+		header.Time = uint64(*overrides.Time)
+	}
+	if header.Time < parent.Time {
+		return nil, nil, fmt.Errorf("block timestamp %d must not be less than parent timestamp %d", header.Time, parent.Time)
+	}
+	config := s.b.ChainConfig()
+	if config.IsSubnetEVM(header.Time) {
+		feeConfig, err := simulatedFeeConfig(config, state, parent)
+		if err != nil {
+			return nil, nil, err
+		}
+		header.GasLimit = feeConfig.GasLimit.Uint64()
+		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(config, feeConfig, parent, header.Time)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	blockCtx := core.NewEVMBlockContext(header, NewChainContext(ctx, s.b), nil)
+	overrides.Apply(&blockCtx)
+	if blockCtx.BlockNumber.Cmp(parent.Number) <= 0 {
+		return nil, nil, fmt.Errorf("block number %d must be greater than parent number %d", blockCtx.BlockNumber, parent.Number)
+	}
+	header.Coinbase = blockCtx.Coinbase
+	header.Difficulty = blockCtx.Difficulty
+	header.Number = blockCtx.BlockNumber
+	header.GasLimit = blockCtx.GasLimit
+	header.BaseFee = blockCtx.BaseFee
+	return header, &blockCtx, nil
+}
+
+// simulatedFeeConfig returns the fee config in effect at [parent] like
+// GetFeeConfigAt, reading the fee manager precompile from [state].
+func simulatedFeeConfig(config *params.ChainConfig, state *state.StateDB, parent *types.Header) (commontype.FeeConfig, error) {
+	if !config.IsPrecompileEnabled(feemanager.ContractAddress, parent.Time) {
+		return config.FeeConfig, nil
+	}
+	feeConfig := feemanager.GetStoredFeeConfig(state)
+	if err := feeConfig.Verify(); err != nil {
+		return commontype.EmptyFeeConfig, err
+	}
+	return feeConfig, nil
+}
+
+// callExecutor executes a sequence of calls on a shared state.
+type callExecutor struct {
+	b       Backend
+	state   *state.StateDB
+	timeout time.Duration
+	gasCap  uint64 // gas available to all the calls together, or 0 for no limit
+	gasUsed uint64 // gas used by the calls executed so far
+	count   uint64 // number of calls executed, used to derive placeholder tx hashes
+}
+
+func newCallExecutor(b Backend, state *state.StateDB, timeout time.Duration, gasCap uint64) *callExecutor {
+	return &callExecutor{
+		b:       b,
+		state:   state,
+		timeout: timeout,
+		gasCap:  gasCap,
+	}
+}
+
+// call applies [overrides] and executes [args] in [blockCtx] on the state left
+// by the previous calls. Errors that would make the call an invalid
+// transaction are returned, while execution errors are part of the result.
+func (e *callExecutor) call(ctx context.Context, header *types.Header, blockCtx *vm.BlockContext, blockHash common.Hash, txIndex int, args TransactionArgs, overrides *StateOverride) (*CallManyResult, error) {
+	if err := overrides.Apply(e.state); err != nil {
+		return nil, err
+	}
+	gasCap := e.gasCap
+	if gasCap != 0 {
+		if e.gasUsed >= gasCap {
+			return nil, fmt.Errorf("gas cap of %d exhausted by previous calls", e.gasCap)
+		}
+		gasCap -= e.gasUsed
+	}
+	msg, err := args.ToMessage(gasCap, blockCtx.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	e.count++
+	txHash := common.BigToHash(new(big.Int).SetUint64(e.count))
+	e.state.SetTxContext(txHash, txIndex)
+	evm := e.b.GetEVM(ctx, msg, e.state, header, &vm.Config{NoBaseFee: true}, blockCtx)
+
+	// Cancel the evm if the context is done before the call completes.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.GasLimit))
+	if err := e.state.Error(); err != nil {
+		return nil, err
+	}
+	if evm.Cancelled() {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", e.timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("err: %w (supplied gas %d)", err, msg.GasLimit)
+	}
+	e.gasUsed += result.UsedGas
+	// Finalise the state as done between transactions, so that the next call
+	// observes the changes of this one.
+	e.state.Finalise(true)
+
+	reply := &CallManyResult{
+		ReturnData: result.ReturnData,
+		GasUsed:    hexutil.Uint64(result.UsedGas),
+		Logs:       e.state.GetLogs(txHash, blockCtx.BlockNumber.Uint64(), blockHash),
+	}
+	if reply.Logs == nil {
+		reply.Logs = []*types.Log{}
+	}
+	if result.Err != nil {
+		var rpcErr rpc.Error
+		if errors.As(result.Err, &rpcErr) {
+			reply.ErrCode = rpcErr.ErrorCode()
+		}
+		reply.Err = result.Err.Error()
+	}
+	// If the result contains a revert reason, try to unpack and return it.
+	if len(result.Revert()) > 0 {
+		err := newRevertError(result.Revert())
+		reply.ErrCode = err.ErrorCode()
+		reply.Err = err.Error()
+	}
+	return reply, nil
+}
+
+// withCallTimeout returns a context that is cancelled after [timeout], or a
+// cancellable context if [timeout] is not positive.
+func withCallTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/allowlist"
 	"github.com/ava-labs/subnet-evm/rpc"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -168,3 +169,112 @@ func (s *BlockChainAPI) GetActiveRulesAt(ctx context.Context, blockTimestamp *ui
 	}
 	return res
 }
+
+// maxAllowListMembers is the maximum number of members of each role returned
+// by a single GetAllowListMembers request.
+const maxAllowListMembers = 1000
+
+// AllowListMembersResult contains the addresses of each role of an allow list
+// precompile, and the total number of members of each role.
+type AllowListMembersResult struct {
+	Admins       []common.Address `json:"admins"`
+	Managers     []common.Address `json:"managers"`
+	Enabled      []common.Address `json:"enabled"`
+	AdminCount   hexutil.Uint64   `json:"adminCount"`
+	ManagerCount hexutil.Uint64   `json:"managerCount"`
+	EnabledCount hexutil.Uint64   `json:"enabledCount"`
+}
+
+// GetAllowListMembers returns the addresses of each role of the allow list precompile
+// at [precompileAddr] in the state of the given block. The precompile must have been
+// configured with enumerableMembers.
+//
+// Like the getRoleMembers function of the precompile, at most [limit] members of
+// each role are returned, starting from the [offset]-th member. [limit] defaults
+// to and cannot exceed [maxAllowListMembers].
+func (s *BlockChainAPI) GetAllowListMembers(ctx context.Context, precompileAddr common.Address, blockNrOrHash rpc.BlockNumberOrHash, offset *hexutil.Uint64, limit *hexutil.Uint64) (*AllowListMembersResult, error) {
+	var from, count uint64 = 0, maxAllowListMembers
+	if offset != nil {
+		from = uint64(*offset)
+	}
+	if limit != nil {
+		if uint64(*limit) > maxAllowListMembers {
+			return nil, fmt.Errorf("limit %d exceeds maximum of %d", *limit, maxAllowListMembers)
+		}
+		count = uint64(*limit)
+	}
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if !allowlist.IsEnumerableMembersEnabled(state, precompileAddr) {
+		return nil, fmt.Errorf("enumerable members are not enabled for precompile %s", precompileAddr)
+	}
+	members := func(role allowlist.Role) ([]common.Address, hexutil.Uint64) {
+		total := allowlist.GetRoleMemberCount(state, precompileAddr, role)
+		return allowlist.GetRoleMembers(state, precompileAddr, role, from, count), hexutil.Uint64(total)
+	}
+	res := new(AllowListMembersResult)
+	res.Admins, res.AdminCount = members(allowlist.AdminRole)
+	res.Managers, res.ManagerCount = members(allowlist.ManagerRole)
+	res.Enabled, res.EnabledCount = members(allowlist.EnabledRole)
+	return res, state.Error()
+}
+
+// UpgradeTimelineEntry is an upgrade of the chain config, annotated with the
+// first accepted block in which it is active.
+type UpgradeTimelineEntry struct {
+	params.UpgradeTimelineEntry
+	// ActivationBlock is nil if no accepted block has reached the upgrade
+	// timestamp yet, or if the activating block is not available locally.
+	ActivationBlock *hexutil.Uint64 `json:"activationBlock,omitempty"`
+}
+
+// GetUpgradeTimeline returns the network upgrades, precompile activations and
+// deactivations, and state upgrades of the chain config, ordered by timestamp.
+func (s *BlockChainAPI) GetUpgradeTimeline(ctx context.Context) ([]UpgradeTimelineEntry, error) {
+	var (
+		head     = s.b.LastAcceptedBlock().Header()
+		timeline = s.b.ChainConfig().UpgradeTimeline()
+		entries  = make([]UpgradeTimelineEntry, 0, len(timeline))
+	)
+	for _, upgrade := range timeline {
+		number, err := s.firstBlockAtTime(ctx, upgrade.Timestamp, head)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, UpgradeTimelineEntry{
+			UpgradeTimelineEntry: upgrade,
+			ActivationBlock:      number,
+		})
+	}
+	return entries, nil
+}
+
+// firstBlockAtTime returns the number of the first block up to [head] with a
+// timestamp of at least [timestamp], or nil if there is no such block or if a
+// block it depends on is not available.
+func (s *BlockChainAPI) firstBlockAtTime(ctx context.Context, timestamp uint64, head *types.Header) (*hexutil.Uint64, error) {
+	if head.Time < timestamp {
+		return nil, nil
+	}
+	// Invariant: the block at [hi] has a timestamp of at least [timestamp].
+	lo, hi := uint64(0), head.Number.Uint64()
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		header, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(mid))
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, nil
+		}
+		if header.Time >= timestamp {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	number := hexutil.Uint64(hi)
+	return &number, nil
+}
@@ -51,7 +51,9 @@ import (
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/internal/blocktest"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
 	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ava-labs/subnet-evm/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -598,7 +600,7 @@ func (b testBackend) BadBlocks() ([]*types.Block, []*core.BadBlockReason) { retu
 func (b testBackend) EstimateBaseFee(ctx context.Context) (*big.Int, error) {
 	panic("implement me")
 }
-func (b testBackend) LastAcceptedBlock() *types.Block { panic("implement me") }
+func (b testBackend) LastAcceptedBlock() *types.Block { return b.chain.LastAcceptedBlock() }
 func (b testBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	panic("implement me")
 }
@@ -916,6 +918,287 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestCallMany(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		genBlocks = 10
+		// PUSH1 0 PUSH1 0 LOG0 STOP
+		loggerCode = hex2Bytes("60006000a000")
+		// NUMBER PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+		numberCode = hex2Bytes("4360005260206000f3")
+	)
+	api := NewBlockChainAPI(newTestBackend(t, genBlocks, genesis, dummy.NewCoinbaseFaker(), func(i int, b *core.BlockGen) {}))
+	randomAccounts := newAccounts(3)
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	// The second transfer can only succeed if the first one is applied.
+	calls := []CallManyArgs{
+		{TransactionArgs: TransactionArgs{From: &accounts[0].addr, To: &randomAccounts[0].addr, Value: (*hexutil.Big)(big.NewInt(1000))}},
+		{TransactionArgs: TransactionArgs{From: &randomAccounts[0].addr, To: &randomAccounts[1].addr, Value: (*hexutil.Big)(big.NewInt(1000))}},
+		{
+			TransactionArgs: TransactionArgs{From: &accounts[1].addr, To: &randomAccounts[2].addr},
+			StateOverrides:  &StateOverride{randomAccounts[2].addr: OverrideAccount{Code: loggerCode}},
+		},
+	}
+	results, err := api.CallMany(context.Background(), calls, &latest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("expected %d results, got %d", len(calls), len(results))
+	}
+	for i, result := range results {
+		if result.Err != "" {
+			t.Fatalf("call %d: unexpected error %s", i, result.Err)
+		}
+		if result.GasUsed == 0 {
+			t.Fatalf("call %d: expected gas to be used", i)
+		}
+	}
+	if len(results[0].Logs) != 0 || len(results[2].Logs) != 1 {
+		t.Fatalf("unexpected logs %v, %v", results[0].Logs, results[2].Logs)
+	}
+	if results[2].Logs[0].Address != randomAccounts[2].addr || results[2].Logs[0].BlockNumber != uint64(genBlocks) {
+		t.Fatalf("unexpected log %v", results[2].Logs[0])
+	}
+
+	// Without the first transfer, the second one is invalid.
+	if _, err := api.CallMany(context.Background(), calls[1:], &latest, nil); !errors.Is(err, core.ErrInsufficientFunds) {
+		t.Fatalf("expected %v, got %v", core.ErrInsufficientFunds, err)
+	}
+
+	// Every simulated block builds on the state and header of the previous one.
+	blocks, err := api.SimulateV1(context.Background(), SimulateOpts{
+		BlockStateCalls: []SimulateBlock{
+			{Calls: []TransactionArgs{{From: &accounts[0].addr, To: &randomAccounts[0].addr, Value: (*hexutil.Big)(big.NewInt(1000))}}},
+			{
+				StateOverrides: &StateOverride{randomAccounts[2].addr: OverrideAccount{Code: numberCode}},
+				Calls: []TransactionArgs{
+					{From: &randomAccounts[0].addr, To: &randomAccounts[1].addr, Value: (*hexutil.Big)(big.NewInt(1000))},
+					{From: &accounts[1].addr, To: &randomAccounts[2].addr},
+				},
+			},
+		},
+	}, &latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+	if blocks[1].Number != hexutil.Uint64(genBlocks+2) || blocks[1].Timestamp <= blocks[0].Timestamp {
+		t.Fatalf("unexpected simulated block %d at %d", blocks[1].Number, blocks[1].Timestamp)
+	}
+	if blocks[1].GasUsed != blocks[1].Calls[0].GasUsed+blocks[1].Calls[1].GasUsed {
+		t.Fatalf("unexpected block gas used %d", blocks[1].GasUsed)
+	}
+	if have, want := new(big.Int).SetBytes(blocks[1].Calls[1].ReturnData), big.NewInt(int64(genBlocks+2)); have.Cmp(want) != 0 {
+		t.Fatalf("unexpected block number in call %d, want %d", have, want)
+	}
+	// The gas limit and base fee are derived from the fee config like for a real block.
+	head, err := api.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, baseFee, err := dummy.CalcBaseFee(genesis.Config, genesis.Config.FeeConfig, head, uint64(blocks[0].Timestamp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks[0].BaseFee.ToInt().Cmp(baseFee) != 0 {
+		t.Fatalf("unexpected base fee %d, want %d", blocks[0].BaseFee.ToInt(), baseFee)
+	}
+	if want := genesis.Config.FeeConfig.GasLimit.Uint64(); uint64(blocks[0].GasLimit) != want {
+		t.Fatalf("unexpected gas limit %d, want %d", blocks[0].GasLimit, want)
+	}
+
+	// The calls of a simulated block cannot use more gas than its gas limit.
+	overLimit := hexutil.Uint64(genesis.Config.FeeConfig.GasLimit.Uint64() + 1)
+	_, err = api.SimulateV1(context.Background(), SimulateOpts{
+		BlockStateCalls: []SimulateBlock{
+			{Calls: []TransactionArgs{{From: &accounts[0].addr, To: &randomAccounts[0].addr, Gas: &overLimit}}},
+		},
+	}, &latest)
+	if !errors.Is(err, core.ErrGasLimitReached) {
+		t.Fatalf("expected %v, got %v", core.ErrGasLimitReached, err)
+	}
+
+	// Simulated blocks may not go back in time.
+	backwards := hexutil.Uint64(0)
+	_, err = api.SimulateV1(context.Background(), SimulateOpts{
+		BlockStateCalls: []SimulateBlock{{BlockOverrides: &BlockOverrides{Time: &backwards}}},
+	}, &latest)
+	if err == nil {
+		t.Fatal("expected error for block timestamp before parent")
+	}
+
+	// The gas cap limits the gas used by all the calls together.
+	// JUMPDEST PUSH1 0 JUMP
+	loopCode := hex2Bytes("5b600056")
+	loop := []CallManyArgs{
+		{
+			TransactionArgs: TransactionArgs{From: &accounts[0].addr, To: &randomAccounts[2].addr},
+			StateOverrides:  &StateOverride{randomAccounts[2].addr: OverrideAccount{Code: loopCode}},
+		},
+		{TransactionArgs: TransactionArgs{From: &accounts[0].addr, To: &randomAccounts[2].addr}},
+	}
+	if _, err := api.CallMany(context.Background(), loop[:1], &latest, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.CallMany(context.Background(), loop, &latest, nil); err == nil {
+		t.Fatal("expected error for calls exceeding the gas cap")
+	}
+	_, err = api.SimulateV1(context.Background(), SimulateOpts{
+		BlockStateCalls: []SimulateBlock{
+			{StateOverrides: loop[0].StateOverrides, Calls: []TransactionArgs{loop[0].TransactionArgs}},
+			{Calls: []TransactionArgs{loop[1].TransactionArgs}},
+			{Calls: []TransactionArgs{loop[1].TransactionArgs}},
+		},
+	}, &latest)
+	if err == nil {
+		t.Fatal("expected error for simulated blocks exceeding the gas cap")
+	}
+
+	if _, err := api.CallMany(context.Background(), make([]CallManyArgs, maxCalls+1), &latest, nil); !errors.Is(err, errTooManyCalls) {
+		t.Fatalf("expected %v, got %v", errTooManyCalls, err)
+	}
+	_, err = api.SimulateV1(context.Background(), SimulateOpts{
+		BlockStateCalls: []SimulateBlock{{Calls: make([]TransactionArgs, maxCalls)}, {Calls: make([]TransactionArgs, 1)}},
+	}, &latest)
+	if !errors.Is(err, errTooManyCalls) {
+		t.Fatalf("expected %v, got %v", errTooManyCalls, err)
+	}
+}
+
+func TestSimulateV1Upgrades(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		config   = *params.TestChainConfig
+		// NUMBER PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+		numberCode = hex2Bytes("4360005260206000f3")
+	)
+	config.UpgradeConfig = params.UpgradeConfig{
+		StateUpgrades: []params.StateUpgrade{{
+			BlockTimestamp:       utils.NewUint64(1000),
+			StateUpgradeAccounts: map[common.Address]params.StateUpgradeAccount{accounts[1].addr: {Code: *numberCode}},
+		}},
+	}
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc:  core.GenesisAlloc{accounts[0].addr: {Balance: big.NewInt(params.Ether)}},
+	}
+	// Blocks are generated 10 seconds apart, so the upgrade is not active yet.
+	api := NewBlockChainAPI(newTestBackend(t, 10, genesis, dummy.NewCoinbaseFaker(), func(i int, b *core.BlockGen) {}))
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	call := TransactionArgs{From: &accounts[0].addr, To: &accounts[1].addr}
+	upgradeTime := hexutil.Uint64(1000)
+	blocks, err := api.SimulateV1(context.Background(), SimulateOpts{
+		BlockStateCalls: []SimulateBlock{
+			{Calls: []TransactionArgs{call}},
+			{BlockOverrides: &BlockOverrides{Time: &upgradeTime}, Calls: []TransactionArgs{call}},
+		},
+	}, &latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks[0].Calls[0].ReturnData) != 0 {
+		t.Fatalf("unexpected return data before the upgrade: %x", blocks[0].Calls[0].ReturnData)
+	}
+	if have := new(big.Int).SetBytes(blocks[1].Calls[0].ReturnData); have.Uint64() != uint64(blocks[1].Number) {
+		t.Fatalf("unexpected return data after the upgrade: %d", have)
+	}
+}
+
+func TestGetUpgradeTimeline(t *testing.T) {
+	t.Parallel()
+	config := *params.TestChainConfig
+	config.UpgradeConfig = params.UpgradeConfig{
+		StateUpgrades: []params.StateUpgrade{
+			{BlockTimestamp: utils.NewUint64(25)},
+			{BlockTimestamp: utils.NewUint64(1000)},
+		},
+	}
+	genesis := &core.Genesis{Config: &config}
+	// Blocks are generated 10 seconds apart.
+	api := NewBlockChainAPI(newTestBackend(t, 10, genesis, dummy.NewCoinbaseFaker(), func(i int, b *core.BlockGen) {}))
+
+	timeline, err := api.GetUpgradeTimeline(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var upgrades []UpgradeTimelineEntry
+	for _, entry := range timeline {
+		if entry.Kind == params.StateUpgradeKind {
+			upgrades = append(upgrades, entry)
+		}
+	}
+	if len(upgrades) != 2 {
+		t.Fatalf("expected 2 state upgrades, got %d", len(upgrades))
+	}
+	if upgrades[0].ActivationBlock == nil || *upgrades[0].ActivationBlock != 3 {
+		t.Fatalf("expected state upgrade to activate at block 3, got %v", upgrades[0].ActivationBlock)
+	}
+	if upgrades[1].ActivationBlock != nil {
+		t.Fatalf("expected pending state upgrade, got activation at %d", *upgrades[1].ActivationBlock)
+	}
+}
+
+func TestGetAllowListMembers(t *testing.T) {
+	t.Parallel()
+	var (
+		admin   = common.Address{1}
+		enabled = []common.Address{{2}, {3}}
+		config  = *params.TestChainConfig
+		latest  = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	txAllowListConfig := txallowlist.NewConfig(utils.NewUint64(0), []common.Address{admin}, enabled, nil)
+	txAllowListConfig.EnumerableMembers = true
+	config.GenesisPrecompiles = params.Precompiles{txallowlist.ConfigKey: txAllowListConfig}
+	genesis := &core.Genesis{Config: &config}
+	api := NewBlockChainAPI(newTestBackend(t, 1, genesis, dummy.NewCoinbaseFaker(), func(i int, b *core.BlockGen) {}))
+
+	res, err := api.GetAllowListMembers(context.Background(), txallowlist.ContractAddress, latest, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, &AllowListMembersResult{
+		Admins:       []common.Address{admin},
+		Managers:     []common.Address{},
+		Enabled:      enabled,
+		AdminCount:   1,
+		ManagerCount: 0,
+		EnabledCount: 2,
+	}, res)
+
+	// The members of each role are paginated.
+	offset, limit := hexutil.Uint64(1), hexutil.Uint64(1)
+	res, err = api.GetAllowListMembers(context.Background(), txallowlist.ContractAddress, latest, &offset, &limit)
+	require.NoError(t, err)
+	require.Equal(t, &AllowListMembersResult{
+		Admins:       []common.Address{},
+		Managers:     []common.Address{},
+		Enabled:      enabled[1:],
+		AdminCount:   1,
+		ManagerCount: 0,
+		EnabledCount: 2,
+	}, res)
+
+	limit = maxAllowListMembers + 1
+	_, err = api.GetAllowListMembers(context.Background(), txallowlist.ContractAddress, latest, nil, &limit)
+	require.ErrorContains(t, err, "exceeds maximum")
+
+	// Precompiles without the index are rejected.
+	_, err = api.GetAllowListMembers(context.Background(), common.Address{4}, latest, nil, nil)
+	require.ErrorContains(t, err, "enumerable members are not enabled")
+}
+
 type account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
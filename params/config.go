@@ -785,3 +785,9 @@ func (c *ChainConfig) GetFeeConfig() commontype.FeeConfig {
 func (c *ChainConfig) AllowedFeeRecipients() bool {
 	return c.AllowFeeRecipients
 }
+
+// GetChainID returns the chain ID used for replay protection.
+// Implements precompile.ChainConfig interface.
+func (c *ChainConfig) GetChainID() *big.Int {
+	return c.ChainID
+}
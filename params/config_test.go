@@ -37,6 +37,7 @@ import (
 	"github.com/ava-labs/subnet-evm/precompile/contracts/nativeminter"
 	"github.com/ava-labs/subnet-evm/precompile/contracts/rewardmanager"
 	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ava-labs/subnet-evm/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
@@ -257,6 +258,37 @@ func TestActivePrecompiles(t *testing.T) {
 	require.False(t, rules1.IsPrecompileEnabled(nativeminter.Module.Address))
 }
 
+func TestActivePrecompileGasCosts(t *testing.T) {
+	overridden := nativeminter.NewConfig(utils.NewUint64(2), nil, nil, nil, nil)
+	overridden.GasCosts = map[string]uint64{nativeminter.MintGasCostKey: 2 * nativeminter.MintGasCost}
+	config := ChainConfig{
+		UpgradeConfig: UpgradeConfig{
+			PrecompileUpgrades: []PrecompileUpgrade{
+				{nativeminter.NewConfig(utils.NewUint64(0), nil, nil, nil, nil)},
+				{nativeminter.NewDisableConfig(utils.NewUint64(1))},
+				{overridden},
+			},
+		},
+	}
+	require.NoError(t, config.verifyPrecompileUpgrades())
+
+	gasCost := func(timestamp uint64) uint64 {
+		active := config.GetActivePrecompileConfig(nativeminter.ContractAddress, timestamp)
+		return active.(precompileconfig.GasConfig).GetGasCost(nativeminter.MintGasCostKey, nativeminter.MintGasCost)
+	}
+	require.Equal(t, uint64(nativeminter.MintGasCost), gasCost(0))
+	require.Equal(t, uint64(2*nativeminter.MintGasCost), gasCost(2))
+
+	// The overrides are exposed along with the active precompile configs.
+	result, err := json.Marshal(config.EnabledStatefulPrecompiles(2))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"contractNativeMinterConfig":{"blockTimestamp":2,"gasCosts":{"mintNativeCoin":60000}}}`, string(result))
+
+	// Overrides out of bounds are rejected.
+	overridden.GasCosts[nativeminter.MintGasCostKey] = 1
+	require.ErrorContains(t, config.verifyPrecompileUpgrades(), "out of bounds")
+}
+
 func TestChainConfigMarshalWithUpgrades(t *testing.T) {
 	config := ChainConfigWithUpgradesJSON{
 		ChainConfig: ChainConfig{
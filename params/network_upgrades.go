@@ -26,6 +26,10 @@ type NetworkUpgrades struct {
 	DurangoTimestamp *uint64 `json:"durangoTimestamp,omitempty"`
 	// Placeholder for EUpgradeTimestamp
 	EUpgradeTimestamp *uint64 `json:"eUpgradeTimestamp,omitempty"`
+	// BLS12381 activates the EIP-2537 BLS12-381 curve operation precompiles.
+	// Unlike the other network upgrades, it is optional and independent of them:
+	// it has no default and is never activated if left nil.
+	BLS12381Timestamp *uint64 `json:"bls12381Timestamp,omitempty"`
 }
 
 func (n *NetworkUpgrades) Equal(other *NetworkUpgrades) bool {
@@ -42,9 +46,14 @@ func (n *NetworkUpgrades) CheckNetworkUpgradesCompatible(newcfg *NetworkUpgrades
 	if isForkTimestampIncompatible(n.EUpgradeTimestamp, newcfg.EUpgradeTimestamp, time) {
 		return newTimestampCompatError("EUpgrade fork block timestamp", n.EUpgradeTimestamp, newcfg.EUpgradeTimestamp)
 	}
+	if isForkTimestampIncompatible(n.BLS12381Timestamp, newcfg.BLS12381Timestamp, time) {
+		return newTimestampCompatError("BLS12381 fork block timestamp", n.BLS12381Timestamp, newcfg.BLS12381Timestamp)
+	}
 	return nil
 }
 
+// forkOrder returns the network upgrades that must be activated in order.
+// BLS12381 is excluded since it is independent of the other upgrades.
 func (n *NetworkUpgrades) forkOrder() []fork {
 	return []fork{
 		{name: "subnetEVMTimestamp", timestamp: n.SubnetEVMTimestamp},
@@ -98,6 +107,9 @@ func (n *NetworkUpgrades) Override(o *NetworkUpgrades) {
 	if o.EUpgradeTimestamp != nil {
 		n.EUpgradeTimestamp = o.EUpgradeTimestamp
 	}
+	if o.BLS12381Timestamp != nil {
+		n.BLS12381Timestamp = o.BLS12381Timestamp
+	}
 }
 
 // IsSubnetEVM returns whether [time] represents a block
@@ -118,11 +130,18 @@ func (n *NetworkUpgrades) IsEUpgrade(time uint64) bool {
 	return utils.IsTimestampForked(n.EUpgradeTimestamp, time)
 }
 
+// IsBLS12381 returns whether [time] represents a block
+// with a timestamp after the BLS12381 upgrade time.
+func (n *NetworkUpgrades) IsBLS12381(time uint64) bool {
+	return utils.IsTimestampForked(n.BLS12381Timestamp, time)
+}
+
 func (n *NetworkUpgrades) Description() string {
 	var banner string
 	banner += fmt.Sprintf(" - SubnetEVM Timestamp:          @%-10v (https://github.com/ava-labs/avalanchego/releases/tag/v1.10.0)\n", ptrToString(n.SubnetEVMTimestamp))
 	banner += fmt.Sprintf(" - Durango Timestamp:            @%-10v (https://github.com/ava-labs/avalanchego/releases/tag/v1.11.0)\n", ptrToString(n.DurangoTimestamp))
 	banner += fmt.Sprintf(" - EUpgrade Timestamp:           @%-10v (https://github.com/ava-labs/avalanchego/releases/tag/v1.12.0)\n", ptrToString(n.EUpgradeTimestamp))
+	banner += fmt.Sprintf(" - BLS12381 Timestamp:           @%-10v (https://eips.ethereum.org/EIPS/eip-2537)\n", ptrToString(n.BLS12381Timestamp))
 	return banner
 }
 
@@ -130,6 +149,7 @@ type AvalancheRules struct {
 	IsSubnetEVM bool
 	IsDurango   bool
 	IsEUpgrade  bool
+	IsBLS12381  bool
 }
 
 func (n *NetworkUpgrades) GetAvalancheRules(time uint64) AvalancheRules {
@@ -137,6 +157,7 @@ func (n *NetworkUpgrades) GetAvalancheRules(time uint64) AvalancheRules {
 		IsSubnetEVM: n.IsSubnetEVM(time),
 		IsDurango:   n.IsDurango(time),
 		IsEUpgrade:  n.IsEUpgrade(time),
+		IsBLS12381:  n.IsBLS12381(time),
 	}
 }
 
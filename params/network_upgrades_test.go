@@ -281,3 +281,25 @@ func TestForkOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestBLS12381Upgrade(t *testing.T) {
+	require := require.New(t)
+
+	upgrades := &NetworkUpgrades{}
+	upgrades.setDefaults(constants.MainnetID)
+	require.Nil(upgrades.BLS12381Timestamp, "BLS12381 should not have a default")
+	require.NoError(upgrades.verifyNetworkUpgrades(constants.MainnetID))
+	require.False(upgrades.GetAvalancheRules(1000).IsBLS12381)
+
+	upgrades.Override(&NetworkUpgrades{BLS12381Timestamp: utils.NewUint64(5)})
+	require.NoError(upgrades.verifyNetworkUpgrades(constants.MainnetID))
+	require.NoError(checkForks(upgrades.forkOrder(), false))
+	require.False(upgrades.GetAvalancheRules(4).IsBLS12381)
+	require.True(upgrades.GetAvalancheRules(5).IsBLS12381)
+
+	// Rescheduling an activated upgrade is incompatible.
+	rescheduled := *upgrades
+	rescheduled.BLS12381Timestamp = utils.NewUint64(10)
+	require.Nil(upgrades.CheckNetworkUpgradesCompatible(&rescheduled, 4))
+	require.NotNil(upgrades.CheckNetworkUpgradesCompatible(&rescheduled, 5))
+}
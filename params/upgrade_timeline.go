@@ -0,0 +1,130 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/precompile/modules"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Kinds of entries in the upgrade timeline of a chain.
+const (
+	NetworkUpgradeKind         = "networkUpgrade"
+	PrecompileActivationKind   = "precompileActivation"
+	PrecompileDeactivationKind = "precompileDeactivation"
+	StateUpgradeKind           = "stateUpgrade"
+)
+
+// UpgradeTimelineEntry describes an upgrade that activates at [Timestamp].
+type UpgradeTimelineEntry struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Timestamp uint64 `json:"timestamp"`
+
+	// Precompile is the address of the precompile activated or deactivated.
+	Precompile *common.Address `json:"precompile,omitempty"`
+	// Config is the configuration of the activated precompile.
+	Config precompileconfig.Config `json:"config,omitempty"`
+	// Accounts are the accounts modified by a state upgrade.
+	Accounts []common.Address `json:"accounts,omitempty"`
+}
+
+// UpgradeTimeline returns the network upgrades, precompile activations and
+// deactivations, and state upgrades scheduled by the chain config, ordered by
+// their activation timestamps. Upgrades that activate at the same timestamp
+// are ordered as network upgrades, precompile changes, and state upgrades.
+func (c *ChainConfig) UpgradeTimeline() []UpgradeTimelineEntry {
+	var entries []UpgradeTimelineEntry
+	for _, fork := range c.forkOrder() {
+		if fork.timestamp == nil {
+			continue
+		}
+		entries = append(entries, UpgradeTimelineEntry{
+			Kind:      NetworkUpgradeKind,
+			Name:      strings.TrimSuffix(fork.name, "Timestamp"),
+			Timestamp: *fork.timestamp,
+		})
+	}
+	if c.CancunTime != nil {
+		entries = append(entries, UpgradeTimelineEntry{
+			Kind:      NetworkUpgradeKind,
+			Name:      "cancun",
+			Timestamp: *c.CancunTime,
+		})
+	}
+	if c.BLS12381Timestamp != nil {
+		entries = append(entries, UpgradeTimelineEntry{
+			Kind:      NetworkUpgradeKind,
+			Name:      "bls12381",
+			Timestamp: *c.BLS12381Timestamp,
+		})
+	}
+
+	keys := make([]string, 0, len(c.GenesisPrecompiles))
+	for key := range c.GenesisPrecompiles {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if entry, ok := precompileTimelineEntry(c.GenesisPrecompiles[key]); ok {
+			entries = append(entries, entry)
+		}
+	}
+	for _, upgrade := range c.PrecompileUpgrades {
+		if entry, ok := precompileTimelineEntry(upgrade.Config); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	for i, upgrade := range c.StateUpgrades {
+		if upgrade.BlockTimestamp == nil {
+			continue
+		}
+		accounts := make([]common.Address, 0, len(upgrade.StateUpgradeAccounts))
+		for account := range upgrade.StateUpgradeAccounts {
+			accounts = append(accounts, account)
+		}
+		slices.SortFunc(accounts, func(a, b common.Address) int { return a.Cmp(b) })
+		entries = append(entries, UpgradeTimelineEntry{
+			Kind:      StateUpgradeKind,
+			Name:      fmt.Sprintf("stateUpgrade[%d]", i),
+			Timestamp: *upgrade.BlockTimestamp,
+			Accounts:  accounts,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+	return entries
+}
+
+// precompileTimelineEntry returns the timeline entry of a precompile
+// activation or deactivation, or false if [config] is never applied.
+func precompileTimelineEntry(config precompileconfig.Config) (UpgradeTimelineEntry, bool) {
+	if config == nil || config.Timestamp() == nil {
+		return UpgradeTimelineEntry{}, false
+	}
+	entry := UpgradeTimelineEntry{
+		Kind:      PrecompileActivationKind,
+		Name:      config.Key(),
+		Timestamp: *config.Timestamp(),
+		Config:    config,
+	}
+	if config.IsDisabled() {
+		entry.Kind = PrecompileDeactivationKind
+		entry.Config = nil
+	}
+	if module, ok := modules.GetPrecompileModule(config.Key()); ok {
+		address := module.Address
+		entry.Precompile = &address
+	}
+	return entry, true
+}
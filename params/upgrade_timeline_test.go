@@ -0,0 +1,63 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeTimeline(t *testing.T) {
+	admins := []common.Address{{1}}
+	chainConfig := *TestChainConfig
+	chainConfig.CancunTime = nil
+	chainConfig.NetworkUpgrades = NetworkUpgrades{
+		SubnetEVMTimestamp: utils.NewUint64(0),
+		DurangoTimestamp:   utils.NewUint64(10),
+	}
+	chainConfig.GenesisPrecompiles = Precompiles{
+		txallowlist.ConfigKey: txallowlist.NewConfig(utils.NewUint64(5), admins, nil, nil),
+	}
+	chainConfig.UpgradeConfig = UpgradeConfig{
+		PrecompileUpgrades: []PrecompileUpgrade{
+			{Config: txallowlist.NewDisableConfig(utils.NewUint64(20))},
+		},
+		StateUpgrades: []StateUpgrade{
+			{
+				BlockTimestamp: utils.NewUint64(10),
+				StateUpgradeAccounts: map[common.Address]StateUpgradeAccount{
+					{2}: {},
+					{1}: {},
+				},
+			},
+		},
+	}
+
+	timeline := chainConfig.UpgradeTimeline()
+	type entry struct {
+		kind      string
+		name      string
+		timestamp uint64
+	}
+	var entries []entry
+	for _, upgrade := range timeline {
+		entries = append(entries, entry{upgrade.Kind, upgrade.Name, upgrade.Timestamp})
+	}
+	require.Equal(t, []entry{
+		{NetworkUpgradeKind, "subnetEVM", 0},
+		{PrecompileActivationKind, txallowlist.ConfigKey, 5},
+		{NetworkUpgradeKind, "durango", 10},
+		{StateUpgradeKind, "stateUpgrade[0]", 10},
+		{PrecompileDeactivationKind, txallowlist.ConfigKey, 20},
+	}, entries)
+
+	require.Equal(t, txallowlist.ContractAddress, *timeline[1].Precompile)
+	require.NotNil(t, timeline[1].Config)
+	require.Nil(t, timeline[4].Config)
+	require.Equal(t, []common.Address{{1}, {2}}, timeline[3].Accounts)
+}
@@ -5,13 +5,22 @@ package evm
 
 import (
 	"fmt"
+	"math/big"
 	"net/http"
+	"os"
+	"runtime"
 
 	"github.com/ava-labs/avalanchego/api"
+	avalancheJSON "github.com/ava-labs/avalanchego/utils/json"
 	"github.com/ava-labs/avalanchego/utils/profiler"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// defaultVerifyStateMaxMismatches is the number of mismatches reported by
+// VerifyState if no limit is given.
+const defaultVerifyStateMaxMismatches = 1000
+
 // Admin is the API service for admin API calls
 type Admin struct {
 	vm       *VM
@@ -89,3 +98,152 @@ func (p *Admin) GetVMConfig(_ *http.Request, _ *struct{}, reply *ConfigReply) er
 	reply.Config = &p.vm.config
 	return nil
 }
+
+type ExportStateSyncArchiveArgs struct {
+	// Path is the file the archive is written to
+	Path string `json:"path"`
+	// Height of the state summary to export, or 0 for the latest one
+	Height uint64 `json:"height"`
+}
+
+// ExportStateSyncArchive writes a state sync archive that other nodes can be
+// bootstrapped from with the state-sync-archive config option.
+func (p *Admin) ExportStateSyncArchive(r *http.Request, args *ExportStateSyncArchiveArgs, _ *api.EmptyReply) error {
+	log.Info("Admin: ExportStateSyncArchive called", "path", args.Path, "height", args.Height)
+
+	if args.Path == "" {
+		return fmt.Errorf("path must be specified")
+	}
+	// Write to a temporary file so that a partial archive is never left at
+	// [args.Path].
+	tmpPath := args.Path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+	defer f.Close()
+
+	if err := p.vm.StateSyncServer.ExportArchive(r.Context(), f, args.Height); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, args.Path)
+}
+
+type OfflinePruningProgressReply struct {
+	// Phase is one of idle, bloom, prune, compact or done
+	Phase string `json:"phase"`
+	// Percent of the current phase that is complete
+	Percent avalancheJSON.Float64 `json:"percent"`
+	// Deleted is the number of state entries deleted
+	Deleted avalancheJSON.Uint64 `json:"deleted"`
+	// Size is the number of bytes of state entries deleted
+	Size avalancheJSON.Uint64 `json:"size"`
+	// Elapsed is the time spent in the current phase
+	Elapsed string `json:"elapsed"`
+	// ETA is the estimated time left in the current phase
+	ETA string `json:"eta"`
+}
+
+// GetOfflinePruningProgress returns the progress of the offline pruning run
+// performed when the node started. Since the node only serves API calls once
+// it has started, this reports the final state of the run; its live progress
+// is logged.
+func (p *Admin) GetOfflinePruningProgress(_ *http.Request, _ *struct{}, reply *OfflinePruningProgressReply) error {
+	report := p.vm.eth.OfflinePruningProgress()
+	reply.Phase = string(report.Phase)
+	reply.Percent = avalancheJSON.Float64(report.Complete * 100)
+	reply.Deleted = avalancheJSON.Uint64(report.Deleted)
+	reply.Size = avalancheJSON.Uint64(report.Size)
+	reply.Elapsed = common.PrettyDuration(report.Elapsed).String()
+	reply.ETA = common.PrettyDuration(report.ETA).String()
+	return nil
+}
+
+type VerifyStateArgs struct {
+	// Height of the block whose state is verified, or 0 for the last accepted
+	// block. The snapshot of the block must be available.
+	Height uint64 `json:"height"`
+	// Parallelism is the number of goroutines verifying the state, defaults
+	// to the number of CPUs
+	Parallelism int `json:"parallelism"`
+	// MaxMismatches is the number of mismatches reported, defaults to 1000
+	MaxMismatches int `json:"maxMismatches"`
+	// ExpectedSupply is the decimal total native supply the state is expected
+	// to have, if it is not empty
+	ExpectedSupply string `json:"expectedSupply"`
+}
+
+type StateMismatch struct {
+	Account common.Hash  `json:"account"`
+	Slot    *common.Hash `json:"slot,omitempty"`
+	Reason  string       `json:"reason"`
+}
+
+type VerifyStateReply struct {
+	BlockNumber avalancheJSON.Uint64 `json:"blockNumber"`
+	Root        common.Hash          `json:"root"`
+	Accounts    avalancheJSON.Uint64 `json:"accounts"`
+	Slots       avalancheJSON.Uint64 `json:"slots"`
+	Supply      string               `json:"supply"`
+	Mismatches  []StateMismatch      `json:"mismatches"`
+	Truncated   bool                 `json:"truncated"`
+}
+
+// VerifyState verifies the snapshot of a block against its state trie, and
+// reports the accounts and storage slots that differ or are inconsistent. It
+// fails if the snapshot is modified by accepting blocks while it runs.
+func (p *Admin) VerifyState(r *http.Request, args *VerifyStateArgs, reply *VerifyStateReply) error {
+	log.Info("Admin: VerifyState called", "height", args.Height, "parallelism", args.Parallelism)
+
+	var expectedSupply *big.Int
+	if args.ExpectedSupply != "" {
+		var ok bool
+		if expectedSupply, ok = new(big.Int).SetString(args.ExpectedSupply, 10); !ok {
+			return fmt.Errorf("invalid expected supply %q", args.ExpectedSupply)
+		}
+	}
+	snaps := p.vm.blockChain.Snapshots()
+	if snaps == nil {
+		return fmt.Errorf("snapshots are disabled")
+	}
+	block := p.vm.blockChain.LastAcceptedBlock()
+	if args.Height != 0 {
+		block = p.vm.blockChain.GetBlockByNumber(args.Height)
+		if block == nil {
+			return fmt.Errorf("block %d not found", args.Height)
+		}
+	}
+	parallelism := args.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.NumCPU()
+	}
+	maxMismatches := args.MaxMismatches
+	if maxMismatches <= 0 {
+		maxMismatches = defaultVerifyStateMaxMismatches
+	}
+
+	result, err := snaps.VerifyWithTrie(r.Context(), block.Root(), parallelism, maxMismatches)
+	if err != nil {
+		return fmt.Errorf("failed to verify state of block %d: %w", block.NumberU64(), err)
+	}
+	reply.BlockNumber = avalancheJSON.Uint64(block.NumberU64())
+	reply.Root = result.Root
+	reply.Accounts = avalancheJSON.Uint64(result.Accounts)
+	reply.Slots = avalancheJSON.Uint64(result.Slots)
+	reply.Supply = result.Supply.String()
+	reply.Truncated = result.Truncated
+	reply.Mismatches = make([]StateMismatch, 0, len(result.Mismatches))
+	for _, mismatch := range result.Mismatches {
+		reply.Mismatches = append(reply.Mismatches, StateMismatch(mismatch))
+	}
+	if expectedSupply != nil && expectedSupply.Cmp(result.Supply) != 0 {
+		reply.Mismatches = append(reply.Mismatches, StateMismatch{
+			Reason: fmt.Sprintf("total supply mismatch: have %s, expected %s", result.Supply, expectedSupply),
+		})
+	}
+	return nil
+}
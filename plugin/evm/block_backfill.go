@@ -0,0 +1,279 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	statesyncclient "github.com/ava-labs/subnet-evm/sync/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// backfillBlocksPerRequest is the number of blocks requested from peers at a
+// time, and the number of blocks written to disk in a single batch.
+const backfillBlocksPerRequest = 32
+
+var (
+	errNoBlockBackfill       = errors.New("no historical block backfill is running")
+	errBackfillGenesisHash   = errors.New("backfilled blocks do not connect to the genesis block")
+	errBackfillMissingHeader = errors.New("missing header of backfilled block")
+)
+
+// BlockBackfillStatus describes the progress of the historical block backfill
+type BlockBackfillStatus struct {
+	// SyncHeight is the height of the state synced block the backfill started from
+	SyncHeight uint64 `json:"syncHeight"`
+	// LowestHeight is the height of the lowest block with its body and receipts on disk
+	LowestHeight uint64 `json:"lowestHeight"`
+	// BlocksFetched and ReceiptsFetched count the blocks and the receipts
+	// retrieved from peers since the node started
+	BlocksFetched   uint64 `json:"blocksFetched"`
+	ReceiptsFetched uint64 `json:"receiptsFetched"`
+	Done            bool   `json:"done"`
+	Error           string `json:"error,omitempty"`
+}
+
+type blockBackfillerConfig struct {
+	client  statesyncclient.Client
+	chaindb ethdb.Database
+	// indexTxs indicates whether tx lookup entries should be written for the
+	// backfilled blocks
+	indexTxs bool
+}
+
+// blockBackfiller downloads the blocks and receipts below the state synced
+// block from peers, so that a state synced node eventually serves the same
+// historical data as a node that bootstrapped from genesis.
+//
+// The hash chain is verified from the state synced block back to genesis: each
+// block must be the parent of the previously written one and its receipts
+// must match the receipt root of its header. Progress is persisted after every
+// batch so the backfill resumes where it stopped after a restart.
+type blockBackfiller struct {
+	client   statesyncclient.Client
+	chaindb  ethdb.Database
+	indexTxs bool
+
+	lock   sync.RWMutex
+	status BlockBackfillStatus
+
+	lowestHeight    metrics.Gauge
+	blocksFetched   metrics.Counter
+	receiptsFetched metrics.Counter
+}
+
+func newBlockBackfiller(config *blockBackfillerConfig) *blockBackfiller {
+	return &blockBackfiller{
+		client:          config.client,
+		chaindb:         config.chaindb,
+		indexTxs:        config.indexTxs,
+		lowestHeight:    metrics.GetOrRegisterGauge("block_backfill_lowest_height", nil),
+		blocksFetched:   metrics.GetOrRegisterCounter("block_backfill_blocks_fetched", nil),
+		receiptsFetched: metrics.GetOrRegisterCounter("block_backfill_receipts_fetched", nil),
+	}
+}
+
+// Status returns the current progress of the backfill
+func (b *blockBackfiller) Status() BlockBackfillStatus {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.status
+}
+
+// Run backfills blocks and receipts below the latest state synced block down
+// to genesis, returning when done, on the first error, or when [ctx] is
+// cancelled. Requests to peers are retried until [ctx] is cancelled, so peers
+// that do not serve receipts only slow the backfill down.
+func (b *blockBackfiller) Run(ctx context.Context) error {
+	err := b.run(ctx)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err != nil {
+		b.status.Error = err.Error()
+	}
+	return err
+}
+
+func (b *blockBackfiller) run(ctx context.Context) error {
+	syncHeight := rawdb.GetLatestSyncPerformed(b.chaindb)
+	genesisHash := rawdb.ReadCanonicalHash(b.chaindb, 0)
+
+	// The state synced block has neither been executed nor received from
+	// peers with its receipts, so the backfill starts from it (inclusive).
+	nextHash := rawdb.ReadCanonicalHash(b.chaindb, syncHeight)
+	nextHeight := syncHeight
+	progressHeight, lowestHash, err := rawdb.ReadBlockBackfillProgress(b.chaindb)
+	if err != nil {
+		return fmt.Errorf("failed to read block backfill progress: %w", err)
+	}
+	if progressHeight == syncHeight && lowestHash != (common.Hash{}) {
+		number := rawdb.ReadHeaderNumber(b.chaindb, lowestHash)
+		if number == nil {
+			return fmt.Errorf("%w: %s", errBackfillMissingHeader, lowestHash)
+		}
+		header := rawdb.ReadHeader(b.chaindb, lowestHash, *number)
+		if header == nil {
+			return fmt.Errorf("%w: %s", errBackfillMissingHeader, lowestHash)
+		}
+		nextHash, nextHeight = header.ParentHash, *number-1
+	}
+	b.updateStatus(func(status *BlockBackfillStatus) {
+		status.SyncHeight = syncHeight
+		status.LowestHeight = nextHeight + 1
+	})
+	log.Info("starting historical block backfill", "syncHeight", syncHeight, "nextHeight", nextHeight)
+
+	for nextHeight > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		blocks, err := b.getBlocks(ctx, nextHash, nextHeight)
+		if err != nil {
+			return err
+		}
+		if err := b.writeBlocks(ctx, syncHeight, blocks); err != nil {
+			return err
+		}
+
+		lowest := blocks[len(blocks)-1]
+		nextHash, nextHeight = lowest.ParentHash(), lowest.NumberU64()-1
+		b.lowestHeight.Update(int64(lowest.NumberU64()))
+		b.updateStatus(func(status *BlockBackfillStatus) {
+			status.LowestHeight = lowest.NumberU64()
+		})
+		log.Debug("backfilled historical blocks", "lowestHeight", lowest.NumberU64(), "syncHeight", syncHeight)
+	}
+	if nextHash != genesisHash {
+		return fmt.Errorf("%w: (got %s) (expected %s)", errBackfillGenesisHash, nextHash, genesisHash)
+	}
+
+	b.updateStatus(func(status *BlockBackfillStatus) {
+		status.Done = true
+	})
+	log.Info("completed historical block backfill", "syncHeight", syncHeight)
+	return nil
+}
+
+// getBlocks returns up to [backfillBlocksPerRequest] consecutive blocks in
+// descending order, starting from [hash] at [height] and never going below
+// height 1. Blocks already on disk are not requested from peers.
+func (b *blockBackfiller) getBlocks(ctx context.Context, hash common.Hash, height uint64) ([]*types.Block, error) {
+	parents := uint64(backfillBlocksPerRequest)
+	if parents > height {
+		parents = height
+	}
+
+	blocks := make([]*types.Block, 0, parents)
+	for uint64(len(blocks)) < parents {
+		block := rawdb.ReadBlock(b.chaindb, hash, height)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+		hash, height = block.ParentHash(), height-1
+	}
+	if len(blocks) > 0 {
+		return blocks, nil
+	}
+
+	// [GetBlocks] verifies the returned blocks form a hash chain starting at [hash]
+	blocks, err := b.client.GetBlocks(ctx, hash, height, uint16(parents))
+	if err != nil {
+		return nil, err
+	}
+	b.blocksFetched.Inc(int64(len(blocks)))
+	b.updateStatus(func(status *BlockBackfillStatus) {
+		status.BlocksFetched += uint64(len(blocks))
+	})
+	return blocks, nil
+}
+
+// writeBlocks fetches the receipts missing for [blocks] and writes the blocks,
+// their receipts, their tx lookup entries if enabled and the backfill progress
+// to disk in a single batch.
+func (b *blockBackfiller) writeBlocks(ctx context.Context, syncHeight uint64, blocks []*types.Block) error {
+	batch := b.chaindb.NewBatch()
+
+	// The tx index tail is the oldest block whose txs are indexed, if the
+	// blocks above the state synced block were indexed from a tail. Blocks are
+	// only indexed when extending the index downwards from it, as the lookup
+	// entries below the tail are expected to be missing.
+	txIndexTail := rawdb.ReadTxIndexTail(b.chaindb)
+	movedTxIndexTail := false
+
+	missingReceipts := make([]*types.Block, 0, len(blocks))
+	for _, block := range blocks {
+		number := block.NumberU64()
+		if !rawdb.HasHeader(b.chaindb, block.Hash(), number) {
+			rawdb.WriteBlock(batch, block)
+			rawdb.WriteCanonicalHash(batch, block.Hash(), number)
+		}
+		if !rawdb.HasReceipts(b.chaindb, block.Hash(), number) {
+			missingReceipts = append(missingReceipts, block)
+		}
+		if !b.indexTxs {
+			continue
+		}
+		switch {
+		case txIndexTail == nil || number >= *txIndexTail:
+			rawdb.WriteTxLookupEntriesByBlock(batch, block)
+		case number+1 == *txIndexTail:
+			rawdb.WriteTxLookupEntriesByBlock(batch, block)
+			// The genesis block has no txs, so the whole chain is indexed
+			// once block 1 is.
+			if number == 1 {
+				number = 0
+			}
+			txIndexTail, movedTxIndexTail = &number, true
+		}
+	}
+	if movedTxIndexTail {
+		rawdb.WriteTxIndexTail(batch, *txIndexTail)
+	}
+
+	// Peers may return receipts for a prefix of the requested blocks only.
+	for len(missingReceipts) > 0 {
+		end := len(missingReceipts)
+		if end > message.MaxReceiptsHashesPerRequest {
+			end = message.MaxReceiptsHashesPerRequest
+		}
+		receipts, err := b.client.GetReceipts(ctx, missingReceipts[:end])
+		if err != nil {
+			return err
+		}
+		numReceipts := 0
+		for i, blockReceipts := range receipts {
+			block := missingReceipts[i]
+			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), blockReceipts)
+			numReceipts += len(blockReceipts)
+		}
+		b.receiptsFetched.Inc(int64(numReceipts))
+		b.updateStatus(func(status *BlockBackfillStatus) {
+			status.ReceiptsFetched += uint64(numReceipts)
+		})
+		missingReceipts = missingReceipts[len(receipts):]
+	}
+
+	if err := rawdb.WriteBlockBackfillProgress(batch, syncHeight, blocks[len(blocks)-1].Hash()); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (b *blockBackfiller) updateStatus(update func(status *BlockBackfillStatus)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	update(&b.status)
+}
@@ -0,0 +1,257 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	statesyncclient "github.com/ava-labs/subnet-evm/sync/client"
+	"github.com/ava-labs/subnet-evm/sync/handlers"
+	handlerstats "github.com/ava-labs/subnet-evm/sync/handlers/stats"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/stretchr/testify/require"
+)
+
+type blockBackfillTest struct {
+	gspec    *core.Genesis
+	blocks   []*types.Block
+	receipts []types.Receipts
+	client   *statesyncclient.MockClient
+}
+
+// newBlockBackfillTest generates a chain of [numBlocks] blocks and returns a
+// mock client serving its blocks and receipts.
+func newBlockBackfillTest(t *testing.T, numBlocks int) *blockBackfillTest {
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{testEthAddrs[0]: {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))}},
+	}
+	signer := types.LatestSigner(gspec.Config)
+	serverDB, blocks, receipts, err := core.GenerateChainWithGenesis(gspec, dummy.NewETHFaker(), numBlocks, 10, func(i int, b *core.BlockGen) {
+		for j := 0; j < i%3; j++ {
+			tx, err := types.SignTx(types.NewTransaction(b.TxNonce(testEthAddrs[0]), testEthAddrs[1], big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, testKeys[0])
+			require.NoError(t, err)
+			b.AddTx(tx)
+		}
+	})
+	require.NoError(t, err)
+	for i, block := range blocks {
+		rawdb.WriteBlock(serverDB, block)
+		rawdb.WriteCanonicalHash(serverDB, block.Hash(), block.NumberU64())
+		rawdb.WriteReceipts(serverDB, block.Hash(), block.NumberU64(), receipts[i])
+	}
+
+	blockProvider := &handlers.TestBlockProvider{
+		GetBlockFn: func(hash common.Hash, number uint64) *types.Block {
+			return rawdb.ReadBlock(serverDB, hash, number)
+		},
+	}
+	client := statesyncclient.NewMockClient(
+		message.Codec,
+		nil,
+		nil,
+		handlers.NewBlockRequestHandler(blockProvider, message.Codec, handlerstats.NewNoopHandlerStats()),
+		handlers.NewReceiptsRequestHandler(serverDB, message.Codec, handlerstats.NewNoopHandlerStats()),
+	)
+	return &blockBackfillTest{
+		gspec:    gspec,
+		blocks:   blocks,
+		receipts: receipts,
+		client:   client,
+	}
+}
+
+// newStateSyncedDB returns a database as left by state syncing to
+// [syncHeight], with the blocks above [syncHeight]-[parents] and without any
+// receipts.
+func (test *blockBackfillTest) newStateSyncedDB(t *testing.T, syncHeight uint64, parents int) ethdb.Database {
+	db := rawdb.NewMemoryDatabase()
+	test.gspec.MustCommit(db, trie.NewDatabase(db, nil))
+	for _, block := range test.blocks[int(syncHeight)-parents : syncHeight] {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	require.NoError(t, rawdb.WriteSyncPerformed(db, syncHeight))
+	return db
+}
+
+func (test *blockBackfillTest) requireBackfilled(t *testing.T, db ethdb.Database, syncHeight uint64, indexTxs bool) {
+	for i, block := range test.blocks[:syncHeight] {
+		require.Equal(t, block.Hash(), rawdb.ReadCanonicalHash(db, block.NumberU64()))
+		require.NotNil(t, rawdb.ReadBlock(db, block.Hash(), block.NumberU64()))
+		receipts := rawdb.ReadRawReceipts(db, block.Hash(), block.NumberU64())
+		require.Len(t, receipts, len(test.receipts[i]))
+		for j, receipt := range receipts {
+			require.Equal(t, test.receipts[i][j].CumulativeGasUsed, receipt.CumulativeGasUsed)
+		}
+		for _, tx := range block.Transactions() {
+			if indexTxs {
+				require.NotNil(t, rawdb.ReadTxLookupEntry(db, tx.Hash()))
+			} else {
+				require.Nil(t, rawdb.ReadTxLookupEntry(db, tx.Hash()))
+			}
+		}
+	}
+	progressHeight, lowestHash, err := rawdb.ReadBlockBackfillProgress(db)
+	require.NoError(t, err)
+	require.Equal(t, syncHeight, progressHeight)
+	require.Equal(t, test.blocks[0].Hash(), lowestHash)
+}
+
+func TestBlockBackfill(t *testing.T) {
+	const (
+		numBlocks  = 100
+		syncHeight = 80
+	)
+	test := newBlockBackfillTest(t, numBlocks)
+
+	for name, indexTxs := range map[string]bool{
+		"index txs":       true,
+		"do not index tx": false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := test.newStateSyncedDB(t, syncHeight, 10)
+			backfiller := newBlockBackfiller(&blockBackfillerConfig{
+				client:   test.client,
+				chaindb:  db,
+				indexTxs: indexTxs,
+			})
+			require.NoError(t, backfiller.Run(context.Background()))
+			test.requireBackfilled(t, db, syncHeight, indexTxs)
+
+			status := backfiller.Status()
+			require.True(t, status.Done)
+			require.Empty(t, status.Error)
+			require.EqualValues(t, syncHeight, status.SyncHeight)
+			require.EqualValues(t, 1, status.LowestHeight)
+			// the 10 blocks fetched during state sync are not requested again
+			require.EqualValues(t, syncHeight-10, status.BlocksFetched)
+		})
+	}
+}
+
+func TestBlockBackfillTxIndexTail(t *testing.T) {
+	const (
+		numBlocks  = 100
+		syncHeight = 80
+	)
+	test := newBlockBackfillTest(t, numBlocks)
+
+	tests := []struct {
+		name         string
+		tail         uint64
+		expectedTail uint64
+	}{
+		{name: "tail above the state synced block", tail: syncHeight + 1, expectedTail: 0},
+		{name: "gap below the tail", tail: syncHeight + 5, expectedTail: syncHeight + 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := test.newStateSyncedDB(t, syncHeight, 10)
+			rawdb.WriteTxIndexTail(db, tt.tail)
+			backfiller := newBlockBackfiller(&blockBackfillerConfig{
+				client:   test.client,
+				chaindb:  db,
+				indexTxs: true,
+			})
+			require.NoError(t, backfiller.Run(context.Background()))
+
+			tail := rawdb.ReadTxIndexTail(db)
+			require.NotNil(t, tail)
+			require.Equal(t, tt.expectedTail, *tail)
+			// Only the blocks at or above the tail are indexed.
+			for _, block := range test.blocks[:syncHeight] {
+				for _, tx := range block.Transactions() {
+					require.Equal(t, block.NumberU64() >= tt.expectedTail, rawdb.ReadTxLookupEntry(db, tx.Hash()) != nil, "block %d", block.NumberU64())
+				}
+			}
+		})
+	}
+}
+
+func TestBlockBackfillResume(t *testing.T) {
+	const (
+		numBlocks  = 100
+		syncHeight = 80
+		resumeFrom = 50
+	)
+	test := newBlockBackfillTest(t, numBlocks)
+	db := test.newStateSyncedDB(t, syncHeight, 10)
+
+	// simulate an interrupted backfill that wrote the blocks above [resumeFrom]
+	for i, block := range test.blocks[resumeFrom-1 : syncHeight] {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), test.receipts[resumeFrom-1+i])
+	}
+	require.NoError(t, rawdb.WriteBlockBackfillProgress(db, syncHeight, test.blocks[resumeFrom-1].Hash()))
+
+	backfiller := newBlockBackfiller(&blockBackfillerConfig{
+		client:  test.client,
+		chaindb: db,
+	})
+	require.NoError(t, backfiller.Run(context.Background()))
+	test.requireBackfilled(t, db, syncHeight, false)
+
+	status := backfiller.Status()
+	require.True(t, status.Done)
+	require.EqualValues(t, resumeFrom-1, status.BlocksFetched)
+}
+
+func TestBlockBackfillProgressOfPreviousSync(t *testing.T) {
+	const (
+		numBlocks  = 100
+		syncHeight = 80
+	)
+	test := newBlockBackfillTest(t, numBlocks)
+	db := test.newStateSyncedDB(t, syncHeight, 10)
+
+	// progress recorded for an older state sync must be ignored
+	require.NoError(t, rawdb.WriteBlockBackfillProgress(db, syncHeight-20, test.blocks[0].Hash()))
+
+	backfiller := newBlockBackfiller(&blockBackfillerConfig{
+		client:  test.client,
+		chaindb: db,
+	})
+	require.NoError(t, backfiller.Run(context.Background()))
+	test.requireBackfilled(t, db, syncHeight, false)
+}
+
+func TestBlockBackfillGenesisMismatch(t *testing.T) {
+	const (
+		numBlocks  = 40
+		syncHeight = 30
+	)
+	test := newBlockBackfillTest(t, numBlocks)
+
+	// commit a different genesis than the chain was generated from
+	db := rawdb.NewMemoryDatabase()
+	otherGenesis := &core.Genesis{Config: params.TestChainConfig}
+	otherGenesis.MustCommit(db, trie.NewDatabase(db, nil))
+	syncBlock := test.blocks[syncHeight-1]
+	rawdb.WriteBlock(db, syncBlock)
+	rawdb.WriteCanonicalHash(db, syncBlock.Hash(), syncBlock.NumberU64())
+	require.NoError(t, rawdb.WriteSyncPerformed(db, syncHeight))
+
+	backfiller := newBlockBackfiller(&blockBackfillerConfig{
+		client:  test.client,
+		chaindb: db,
+	})
+	err := backfiller.Run(context.Background())
+	require.ErrorIs(t, err, errBackfillGenesisHash)
+
+	status := backfiller.Status()
+	require.False(t, status.Done)
+	require.Equal(t, err.Error(), status.Error)
+}
@@ -0,0 +1,109 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/blockhistory"
+	statesyncclient "github.com/ava-labs/subnet-evm/sync/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// blockHistoryConfigured returns whether the block history precompile is
+// configured to activate at any time in [config].
+func blockHistoryConfigured(config *params.ChainConfig) bool {
+	configs := config.GetActivatingPrecompileConfigs(blockhistory.ContractAddress, nil, math.MaxUint64, config.PrecompileUpgrades)
+	return len(configs) > 0
+}
+
+// stateSyncParents returns the number of parents of the summary block that
+// state sync fetches. If the block history precompile is configured, the
+// headers of its history window must be available to every node for its calls
+// to be deterministic, so the whole window is fetched.
+func stateSyncParents(config *params.ChainConfig) int {
+	if blockHistoryConfigured(config) {
+		return blockhistory.HistoryWindow + 1
+	}
+	return parentsToGet
+}
+
+// missingHistoryWindow returns the hash and height of the highest ancestor of
+// [head] within [window] blocks of its child whose header or canonical hash is
+// missing from [db], and false if none is.
+func missingHistoryWindow(db ethdb.Reader, head *types.Header, window uint64) (common.Hash, uint64, bool) {
+	var lowest uint64
+	if number := head.Number.Uint64() + 1; number > window {
+		lowest = number - window
+	}
+	hash, number := head.ParentHash, head.Number.Uint64()
+	for number > lowest {
+		number--
+		header := rawdb.ReadHeader(db, hash, number)
+		if header == nil || rawdb.ReadCanonicalHash(db, number) != hash {
+			return hash, number, true
+		}
+		hash = header.ParentHash
+	}
+	return common.Hash{}, 0, false
+}
+
+// fillHistoryWindow fetches from peers the headers of the ancestors of [head]
+// within [window] blocks of its child that are missing from [db]. Nodes that
+// state synced before the block history precompile was configured only have
+// the [parentsToGet] parents of their summary block. Only headers are
+// written, so bodies below the block history are not restored.
+func fillHistoryWindow(ctx context.Context, client statesyncclient.Client, db ethdb.Database, head *types.Header, window uint64) error {
+	hash, number, missing := missingHistoryWindow(db, head, window)
+	if !missing {
+		return nil
+	}
+	var lowest uint64
+	if next := head.Number.Uint64() + 1; next > window {
+		lowest = next - window
+	}
+	log.Info("fetching missing headers of the block history window", "from", number, "to", lowest)
+
+	batch := db.NewBatch()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		parents := uint64(backfillBlocksPerRequest)
+		if remaining := number - lowest + 1; parents > remaining {
+			parents = remaining
+		}
+		// [GetBlocks] verifies the returned blocks form a hash chain starting at [hash]
+		blocks, err := client.GetBlocks(ctx, hash, number, uint16(parents))
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			rawdb.WriteHeader(batch, block.Header())
+			rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
+		}
+		lowestBlock := blocks[len(blocks)-1]
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if lowestBlock.NumberU64() <= lowest {
+			break
+		}
+		hash, number = lowestBlock.ParentHash(), lowestBlock.NumberU64()-1
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("fetched missing headers of the block history window", "lowest", lowest)
+	return nil
+}
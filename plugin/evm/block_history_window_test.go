@@ -0,0 +1,71 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/blockhistory"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSyncParents(t *testing.T) {
+	config := *params.TestChainConfig
+	require.Equal(t, parentsToGet, stateSyncParents(&config))
+
+	// A precompile scheduled in the future already requires the window.
+	config.UpgradeConfig.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Config: blockhistory.NewConfig(utils.NewUint64(1000))},
+	}
+	require.Equal(t, blockhistory.HistoryWindow+1, stateSyncParents(&config))
+
+	config = *params.TestChainConfig
+	config.GenesisPrecompiles = params.Precompiles{
+		blockhistory.ConfigKey: blockhistory.NewConfig(utils.NewUint64(0)),
+	}
+	require.Equal(t, blockhistory.HistoryWindow+1, stateSyncParents(&config))
+}
+
+func TestFillHistoryWindow(t *testing.T) {
+	const (
+		numBlocks  = 100
+		syncHeight = 80
+		parents    = 10
+	)
+	tests := []struct {
+		name   string
+		window uint64
+		lowest uint64
+	}{
+		{name: "within synced parents", window: parents, lowest: syncHeight + 1 - parents},
+		{name: "below synced parents", window: 50, lowest: syncHeight + 1 - 50},
+		{name: "down to genesis", window: 1000, lowest: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			backfillTest := newBlockBackfillTest(t, numBlocks)
+			db := backfillTest.newStateSyncedDB(t, syncHeight, parents)
+			head := backfillTest.blocks[syncHeight-1].Header()
+
+			require.NoError(fillHistoryWindow(context.Background(), backfillTest.client, db, head, test.window))
+			_, _, missing := missingHistoryWindow(db, head, test.window)
+			require.False(missing)
+			for _, block := range backfillTest.blocks[:syncHeight-1] {
+				number := block.NumberU64()
+				inWindow := number >= test.lowest
+				require.Equal(inWindow || number > syncHeight-parents, rawdb.HasHeader(db, block.Hash(), number), "header %d", number)
+				if number <= syncHeight-parents {
+					// Only the headers are fetched.
+					require.False(rawdb.HasBody(db, block.Hash(), number), "body %d", number)
+				}
+			}
+		})
+	}
+}
@@ -6,10 +6,13 @@ package evm
 import (
 	"encoding/json"
 	"fmt"
+	"net/netip"
 	"time"
 
 	"github.com/ava-labs/subnet-evm/core/txpool/legacypool"
 	"github.com/ava-labs/subnet-evm/eth"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/rpc"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/spf13/cast"
@@ -33,6 +36,8 @@ const (
 	defaultWsCpuRefillRate                            = 0 // Default to no maximum WS CPU usage
 	defaultWsCpuMaxStored                             = 0 // Default to no maximum WS CPU usage
 	defaultMaxBlocksPerRequest                        = 0 // Default to no maximum on the number of blocks per getLogs request
+	defaultMaxSubscriptionReplayBlocks                = 4096
+	defaultAPIRateLimitMaxClients                     = 10_000
 	defaultContinuousProfilerFrequency                = 15 * time.Minute
 	defaultContinuousProfilerMaxFiles                 = 5
 	defaultPushGossipPercentStake                     = .9
@@ -44,13 +49,16 @@ const (
 	defaultPullGossipFrequency                        = 1 * time.Second
 	defaultRegossipFrequency                          = 30 * time.Second
 	defaultOfflinePruningBloomFilterSize       uint64 = 512 // Default size (MB) for the offline pruner to use
+	defaultOfflinePruningParallelism                  = 16
 	defaultLogLevel                                   = "info"
 	defaultLogJSONFormat                              = false
 	defaultMaxOutboundActiveRequests                  = 16
 	defaultMaxOutboundActiveCrossChainRequests        = 64
 	defaultPopulateMissingTriesParallelism            = 1024
 	defaultStateSyncServerTrieCache                   = 64 // MB
+	defaultStateSyncServerValidatorReserve            = 0.5
 	defaultAcceptedCacheSize                          = 32 // blocks
+	defaultStateDiffExportFileSize             uint64 = 256 * 1024 * 1024
 
 	// defaultStateSyncMinBlocks is the minimum number of blocks the blockchain
 	// should be ahead of local last accepted to perform state sync.
@@ -145,10 +153,21 @@ type Config struct {
 	WSCPURefillRate          Duration      `json:"ws-cpu-refill-rate"`
 	WSCPUMaxStored           Duration      `json:"ws-cpu-max-stored"`
 	MaxBlocksPerRequest      int64         `json:"api-max-blocks-per-request"`
+	MaxSubscriptionReplay    uint64        `json:"api-max-subscription-replay-blocks"`
 	AllowUnfinalizedQueries  bool          `json:"allow-unfinalized-queries"`
+	AllowProcessingQueries   bool          `json:"allow-processing-queries"`
 	AllowUnprotectedTxs      bool          `json:"allow-unprotected-txs"`
 	AllowUnprotectedTxHashes []common.Hash `json:"allow-unprotected-tx-hashes"`
 
+	// RPC Rate Limit Settings
+	APIRateLimitClientHeader   string                   `json:"api-rate-limit-client-header"`   // HTTP header identifying clients (e.g. an API key). Only honored for known keys and from trusted proxies. Other clients are identified by IP.
+	APIRateLimitKeys           map[string]string        `json:"api-rate-limit-keys"`            // Names of known clients mapped to their keys. Their calls are reported in metrics under their names.
+	APIRateLimitTrustedProxies []string                 `json:"api-rate-limit-trusted-proxies"` // IP addresses or CIDR ranges of proxies trusted to set the client header and X-Forwarded-For
+	APIRateLimitMaxClients     int                      `json:"api-rate-limit-max-clients"`     // Number of clients whose rate limits are tracked
+	APIIPRateLimit             rpc.RateLimit            `json:"api-ip-rate-limit"`              // Limits the calls from each IP address across all methods, including those of clients with a key
+	APIRateLimit               rpc.RateLimit            `json:"api-rate-limit"`                 // Limits the calls of each client across all methods
+	APIMethodRateLimits        map[string]rpc.RateLimit `json:"api-method-rate-limits"`         // Limits the calls of each client per method
+
 	// Keystore Settings
 	KeystoreDirectory             string `json:"keystore-directory"` // both absolute and relative supported
 	KeystoreExternalSigner        string `json:"keystore-external-signer"`
@@ -176,6 +195,7 @@ type Config struct {
 	OfflinePruning                bool   `json:"offline-pruning-enabled"`
 	OfflinePruningBloomFilterSize uint64 `json:"offline-pruning-bloom-filter-size"`
 	OfflinePruningDataDirectory   string `json:"offline-pruning-data-directory"`
+	OfflinePruningParallelism     int    `json:"offline-pruning-parallelism"`
 
 	// VM2VM network
 	MaxOutboundActiveRequests           int64 `json:"max-outbound-active-requests"`
@@ -186,13 +206,33 @@ type Config struct {
 	StateSyncSkipResume      bool   `json:"state-sync-skip-resume"` // Forces state sync to use the highest available summary block
 	StateSyncServerTrieCache int    `json:"state-sync-server-trie-cache"`
 	StateSyncIDs             string `json:"state-sync-ids"`
-	StateSyncCommitInterval  uint64 `json:"state-sync-commit-interval"`
+	StateSyncCommitInterval  uint64 `json:"state-sync-commit-interval"` // Interval of the summaries served to peers, 0 disables serving state sync
 	StateSyncMinBlocks       uint64 `json:"state-sync-min-blocks"`
 	StateSyncRequestSize     uint16 `json:"state-sync-request-size"`
+	StateSyncBackfillEnabled bool   `json:"state-sync-backfill-enabled"` // Downloads blocks and receipts below the state synced block in the background
+	StateSyncArchive         string `json:"state-sync-archive"`          // Path of a state sync archive to bootstrap from instead of syncing from peers
+
+	// Budgets in bytes of the sync requests served to peers. A zero bandwidth
+	// disables the budget, and a zero burst defaults to the bandwidth.
+	StateSyncServerPeerBandwidth uint64 `json:"state-sync-server-peer-bandwidth"` // Bytes per second served to each peer
+	StateSyncServerPeerBurst     uint64 `json:"state-sync-server-peer-burst"`
+	StateSyncServerBandwidth     uint64 `json:"state-sync-server-bandwidth"` // Bytes per second served to all peers
+	StateSyncServerBurst         uint64 `json:"state-sync-server-burst"`
+	// Fraction of the bandwidth served to all peers that is reserved for validators
+	StateSyncServerValidatorReserve float64 `json:"state-sync-server-validator-reserve"`
 
 	// Database Settings
 	InspectDatabase bool `json:"inspect-database"` // Inspects the database on startup if enabled.
 
+	// Freezer Settings
+	// Accepted blocks and receipts older than FreezerThreshold blocks are moved
+	// from the key-value database to append-only files in FreezerDataDirectory.
+	// Once blocks have been frozen, the freezer must remain enabled with the
+	// same directory for them to be served.
+	FreezerEnabled       bool   `json:"freezer-enabled"`
+	FreezerThreshold     uint64 `json:"freezer-threshold"`
+	FreezerDataDirectory string `json:"freezer-data-directory"`
+
 	// SkipUpgradeCheck disables checking that upgrades must take place before the last
 	// accepted block. Skipping this check is useful when a node operator does not update
 	// their node before the network upgrade and their node accepts blocks that have
@@ -219,6 +259,34 @@ type Config struct {
 	// TxLookupLimit can be still used to control unindexing old transactions.
 	SkipTxIndexing bool `json:"skip-tx-indexing"`
 
+	// BlockHistory is the maximum number of blocks from head whose bodies and
+	// receipts are reserved. Headers are always kept:
+	//  * 0:   means no limit
+	//  * N:   means N block limit [HEAD-N+1, HEAD] and delete older bodies and receipts
+	// Serving state sync requires the blocks since the parents of the latest
+	// summary, so N must be at least the state sync commit interval plus the
+	// number of parents fetched by state sync, unless serving is disabled.
+	BlockHistory uint64 `json:"block-history"`
+
+	// LogIndexingEnabled maintains an (address, topic0, block) index of accepted
+	// logs, which is backfilled in the background for blocks accepted before it
+	// was enabled. Log queries filtering on addresses use the index for the
+	// range it covers instead of scanning bloom bits block by block.
+	//
+	// This is particularly useful for wide-range eth_getLogs on RPC nodes.
+	LogIndexingEnabled bool `json:"log-indexing-enabled"`
+
+	// StateDiffExportDir is the directory to which the state diff of each
+	// accepted block is written as a line of JSON, with the account, code and
+	// storage changes it made. Blocks processed before the export was enabled
+	// have no known diff and are written with "missing" set instead. Files are
+	// rotated once they exceed StateDiffExportFileSize bytes, and the oldest
+	// files beyond StateDiffExportMaxFiles are removed (0 keeps all of them).
+	// An empty directory disables the export.
+	StateDiffExportDir      string `json:"state-diff-export-dir"`
+	StateDiffExportFileSize uint64 `json:"state-diff-export-file-size"`
+	StateDiffExportMaxFiles int    `json:"state-diff-export-max-files"`
+
 	// WarpOffChainMessages encodes off-chain messages (unrelated to any on-chain event ie. block or AddressedCall)
 	// that the node should be willing to sign.
 	// Note: only supports AddressedCall payloads as defined here:
@@ -232,7 +300,10 @@ func (c Config) EthAPIs() []string {
 }
 
 func (c Config) EthBackendSettings() eth.Settings {
-	return eth.Settings{MaxBlocksPerRequest: c.MaxBlocksPerRequest}
+	return eth.Settings{
+		MaxBlocksPerRequest:         c.MaxBlocksPerRequest,
+		MaxSubscriptionReplayBlocks: c.MaxSubscriptionReplay,
+	}
 }
 
 func (c *Config) SetDefaults() {
@@ -253,6 +324,8 @@ func (c *Config) SetDefaults() {
 	c.WSCPURefillRate.Duration = defaultWsCpuRefillRate
 	c.WSCPUMaxStored.Duration = defaultWsCpuMaxStored
 	c.MaxBlocksPerRequest = defaultMaxBlocksPerRequest
+	c.MaxSubscriptionReplay = defaultMaxSubscriptionReplayBlocks
+	c.APIRateLimitMaxClients = defaultAPIRateLimitMaxClients
 	c.ContinuousProfilerFrequency.Duration = defaultContinuousProfilerFrequency
 	c.ContinuousProfilerMaxFiles = defaultContinuousProfilerMaxFiles
 	c.Pruning = defaultPruningEnabled
@@ -273,17 +346,21 @@ func (c *Config) SetDefaults() {
 	c.PullGossipFrequency.Duration = defaultPullGossipFrequency
 	c.RegossipFrequency.Duration = defaultRegossipFrequency
 	c.OfflinePruningBloomFilterSize = defaultOfflinePruningBloomFilterSize
+	c.OfflinePruningParallelism = defaultOfflinePruningParallelism
+	c.FreezerThreshold = params.FullImmutabilityThreshold
 	c.LogLevel = defaultLogLevel
 	c.LogJSONFormat = defaultLogJSONFormat
 	c.MaxOutboundActiveRequests = defaultMaxOutboundActiveRequests
 	c.MaxOutboundActiveCrossChainRequests = defaultMaxOutboundActiveCrossChainRequests
 	c.PopulateMissingTriesParallelism = defaultPopulateMissingTriesParallelism
 	c.StateSyncServerTrieCache = defaultStateSyncServerTrieCache
+	c.StateSyncServerValidatorReserve = defaultStateSyncServerValidatorReserve
 	c.StateSyncCommitInterval = defaultSyncableCommitInterval
 	c.StateSyncMinBlocks = defaultStateSyncMinBlocks
 	c.StateSyncRequestSize = defaultStateSyncRequestSize
 	c.AllowUnprotectedTxHashes = defaultAllowUnprotectedTxHashes
 	c.AcceptedCacheSize = defaultAcceptedCacheSize
+	c.StateDiffExportFileSize = defaultStateDiffExportFileSize
 }
 
 func (d *Duration) UnmarshalJSON(data []byte) (err error) {
@@ -317,17 +394,105 @@ func (c *Config) Validate() error {
 	if !c.Pruning && c.OfflinePruning {
 		return fmt.Errorf("cannot run offline pruning while pruning is disabled")
 	}
+	if c.OfflinePruning && c.OfflinePruningParallelism < 1 {
+		return fmt.Errorf("cannot run offline pruning without at least one worker (parallelism: %d)", c.OfflinePruningParallelism)
+	}
+	if c.StateSyncServerValidatorReserve < 0 || c.StateSyncServerValidatorReserve >= 1 {
+		return fmt.Errorf("state sync server validator reserve must be in [0, 1) (reserve: %f)", c.StateSyncServerValidatorReserve)
+	}
+	if c.StateDiffExportDir != "" && c.StateDiffExportMaxFiles < 0 {
+		return fmt.Errorf("state-diff-export-max-files is %d but must be non-negative", c.StateDiffExportMaxFiles)
+	}
+	if c.FreezerEnabled && c.FreezerDataDirectory == "" {
+		return fmt.Errorf("cannot enable freezer without a freezer data directory")
+	}
 	// If pruning is enabled, the commit interval must be non-zero so the node commits state tries every CommitInterval blocks.
 	if c.Pruning && c.CommitInterval == 0 {
 		return fmt.Errorf("cannot use commit interval of 0 with pruning enabled")
 	}
+	if c.BlockHistory != 0 {
+		// On startup, state is re-generated from blocks accepted since the last
+		// committed trie, so their bodies must be retained.
+		if c.Pruning && c.BlockHistory < 2*c.CommitInterval {
+			return fmt.Errorf("block-history (%d) must be at least twice the commit interval (%d) with pruning enabled", c.BlockHistory, c.CommitInterval)
+		}
+		if c.FreezerEnabled {
+			return fmt.Errorf("cannot enable freezer with block-history set")
+		}
+		if c.StateSyncBackfillEnabled {
+			return fmt.Errorf("cannot enable state sync backfill with block-history set")
+		}
+	}
 
 	if c.PushGossipPercentStake < 0 || c.PushGossipPercentStake > 1 {
 		return fmt.Errorf("push-gossip-percent-stake is %f but must be in the range [0, 1]", c.PushGossipPercentStake)
 	}
+	if c.APIIPRateLimit.Rate < 0 {
+		return fmt.Errorf("api-ip-rate-limit rate is %f but must be non-negative", c.APIIPRateLimit.Rate)
+	}
+	if c.APIRateLimit.Rate < 0 {
+		return fmt.Errorf("api-rate-limit rate is %f but must be non-negative", c.APIRateLimit.Rate)
+	}
+	for method, limit := range c.APIMethodRateLimits {
+		if limit.Rate < 0 {
+			return fmt.Errorf("api-method-rate-limits rate for %s is %f but must be non-negative", method, limit.Rate)
+		}
+	}
+	names := make(map[string]string, len(c.APIRateLimitKeys))
+	for name, key := range c.APIRateLimitKeys {
+		if key == "" {
+			return fmt.Errorf("api-rate-limit-keys key for %s is empty", name)
+		}
+		if other, ok := names[key]; ok {
+			return fmt.Errorf("api-rate-limit-keys clients %s and %s have the same key", other, name)
+		}
+		names[key] = name
+	}
+	if _, err := c.RateLimiterConfig(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateStateSyncHistory checks that the block history covers the blocks
+// served to peers state syncing, which fetch [parents] parents of the summary
+// block. The latest summary is up to a commit interval below head.
+func (c *Config) ValidateStateSyncHistory(parents int) error {
+	if c.BlockHistory == 0 || c.StateSyncCommitInterval == 0 {
+		return nil
+	}
+	if c.BlockHistory < c.StateSyncCommitInterval+uint64(parents) {
+		return fmt.Errorf("block-history (%d) must be at least the state sync commit interval (%d) plus %d with the state sync server enabled", c.BlockHistory, c.StateSyncCommitInterval, parents)
+	}
 	return nil
 }
 
+// RateLimiterConfig returns the limits applied to the calls of every RPC
+// client.
+func (c *Config) RateLimiterConfig() (rpc.RateLimiterConfig, error) {
+	trustedProxies := make([]netip.Prefix, 0, len(c.APIRateLimitTrustedProxies))
+	for _, proxy := range c.APIRateLimitTrustedProxies {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(proxy)
+			if addrErr != nil {
+				return rpc.RateLimiterConfig{}, fmt.Errorf("invalid api-rate-limit-trusted-proxies entry %q: %w", proxy, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		trustedProxies = append(trustedProxies, prefix.Masked())
+	}
+	return rpc.RateLimiterConfig{
+		ClientHeader:   c.APIRateLimitClientHeader,
+		Keys:           c.APIRateLimitKeys,
+		TrustedProxies: trustedProxies,
+		MaxClients:     c.APIRateLimitMaxClients,
+		IP:             c.APIIPRateLimit,
+		Client:         c.APIRateLimit,
+		Methods:        c.APIMethodRateLimits,
+	}, nil
+}
+
 func (c *Config) Deprecate() string {
 	msg := ""
 	// Deprecate the old config options and set the new ones.
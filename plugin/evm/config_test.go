@@ -6,9 +6,11 @@ package evm
 import (
 	"encoding/json"
 	"fmt"
+	"net/netip"
 	"testing"
 	"time"
 
+	"github.com/ava-labs/subnet-evm/precompile/contracts/blockhistory"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
@@ -120,3 +122,86 @@ func TestUnmarshalConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateStateSyncHistory(t *testing.T) {
+	tests := []struct {
+		name                    string
+		blockHistory            uint64
+		stateSyncCommitInterval uint64
+		parents                 int
+		expectedErr             bool
+	}{
+		{"no block history", 0, defaultSyncableCommitInterval, parentsToGet, false},
+		{"covers summaries", defaultSyncableCommitInterval + parentsToGet, defaultSyncableCommitInterval, parentsToGet, false},
+		{"shorter than summaries", defaultSyncableCommitInterval + parentsToGet - 1, defaultSyncableCommitInterval, parentsToGet, true},
+		{"shorter than parents", parentsToGet, 1, parentsToGet, true},
+		{"shorter than history window", defaultSyncableCommitInterval + parentsToGet, defaultSyncableCommitInterval, blockhistory.HistoryWindow + 1, true},
+		{"state sync server disabled", 8, 0, parentsToGet, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			c.BlockHistory = tt.blockHistory
+			c.StateSyncCommitInterval = tt.stateSyncCommitInterval
+			err := c.ValidateStateSyncHistory(tt.parents)
+			if tt.expectedErr {
+				assert.ErrorContains(t, err, "block-history")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRateLimiterConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		keys           map[string]string
+		trustedProxies []string
+		expected       []netip.Prefix
+		expectedErr    string
+	}{
+		{
+			name:           "addresses and ranges",
+			trustedProxies: []string{"10.0.0.1", "192.168.1.7/24", "::1"},
+			expected: []netip.Prefix{
+				netip.MustParsePrefix("10.0.0.1/32"),
+				netip.MustParsePrefix("192.168.1.0/24"),
+				netip.MustParsePrefix("::1/128"),
+			},
+		},
+		{
+			name:           "invalid proxy",
+			trustedProxies: []string{"proxy"},
+			expectedErr:    "api-rate-limit-trusted-proxies",
+		},
+		{
+			name:        "empty key",
+			keys:        map[string]string{"a": ""},
+			expectedErr: "api-rate-limit-keys key for a is empty",
+		},
+		{
+			name:        "duplicate key",
+			keys:        map[string]string{"a": "secret", "b": "secret"},
+			expectedErr: "the same key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			c.APIRateLimitKeys = tt.keys
+			c.APIRateLimitTrustedProxies = tt.trustedProxies
+			err := c.Validate()
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			rateLimiterConfig, err := c.RateLimiterConfig()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rateLimiterConfig.TrustedProxies)
+		})
+	}
+}
@@ -45,6 +45,11 @@ func init() {
 		c.RegisterType(BlockSignatureRequest{}),
 		c.RegisterType(SignatureResponse{}),
 
+		// Historical block backfill types, registered last so that the
+		// type IDs of the other messages are unchanged
+		c.RegisterType(ReceiptsRequest{}),
+		c.RegisterType(ReceiptsResponse{}),
+
 		Codec.RegisterCodec(Version, c),
 	)
 
@@ -142,6 +142,7 @@ type mockHandler struct {
 	handleStateTrieCalled,
 	handleBlockRequestCalled,
 	handleCodeRequestCalled,
+	handleReceiptsRequestCalled,
 	handleMessageSignatureCalled,
 	handleBlockSignatureCalled bool
 }
@@ -161,6 +162,11 @@ func (m *mockHandler) HandleCodeRequest(context.Context, ids.NodeID, uint32, Cod
 	return nil, nil
 }
 
+func (m *mockHandler) HandleReceiptsRequest(context.Context, ids.NodeID, uint32, ReceiptsRequest) ([]byte, error) {
+	m.handleReceiptsRequestCalled = true
+	return nil, nil
+}
+
 func (m *mockHandler) HandleMessageSignatureRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, signatureRequest MessageSignatureRequest) ([]byte, error) {
 	m.handleMessageSignatureCalled = true
 	return nil, nil
@@ -174,4 +180,5 @@ func (m *mockHandler) reset() {
 	m.handleStateTrieCalled = false
 	m.handleBlockRequestCalled = false
 	m.handleCodeRequestCalled = false
+	m.handleReceiptsRequestCalled = false
 }
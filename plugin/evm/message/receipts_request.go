@@ -0,0 +1,47 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MaxReceiptsHashesPerRequest is the maximum number of block hashes that may
+// be requested in a single ReceiptsRequest
+const MaxReceiptsHashesPerRequest = 64
+
+var _ Request = ReceiptsRequest{}
+
+// ReceiptsRequest is a request to retrieve the receipts of the blocks with the
+// specified hashes
+type ReceiptsRequest struct {
+	// BlockHashes is a list of block hashes
+	BlockHashes []common.Hash `serialize:"true"`
+}
+
+func (r ReceiptsRequest) String() string {
+	hashStrs := make([]string, len(r.BlockHashes))
+	for i, hash := range r.BlockHashes {
+		hashStrs[i] = hash.String()
+	}
+	return fmt.Sprintf("ReceiptsRequest(BlockHashes=%s)", strings.Join(hashStrs, ", "))
+}
+
+func (r ReceiptsRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID uint32, handler RequestHandler) ([]byte, error) {
+	return handler.HandleReceiptsRequest(ctx, nodeID, requestID, r)
+}
+
+// ReceiptsResponse is a response to a ReceiptsRequest
+// Each element in Receipts is the RLP encoding of the receipts (in storage
+// format) of the corresponding block in ReceiptsRequest.BlockHashes. It may
+// contain the receipts of only a prefix of the requested blocks.
+// handler: handlers.ReceiptsRequestHandler
+type ReceiptsResponse struct {
+	Receipts [][]byte `serialize:"true"`
+}
@@ -0,0 +1,59 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMarshalReceiptsRequest asserts that the structure or serialization logic hasn't changed, primarily to
+// ensure compatibility with the network.
+func TestMarshalReceiptsRequest(t *testing.T) {
+	receiptsRequest := ReceiptsRequest{
+		BlockHashes: []common.Hash{common.BytesToHash([]byte("some receipts pls"))},
+	}
+
+	base64ReceiptsRequest := "AAAAAAABAAAAAAAAAAAAAAAAAAAAc29tZSByZWNlaXB0cyBwbHM="
+
+	receiptsRequestBytes, err := Codec.Marshal(Version, receiptsRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, base64ReceiptsRequest, base64.StdEncoding.EncodeToString(receiptsRequestBytes))
+
+	var r ReceiptsRequest
+	_, err = Codec.Unmarshal(receiptsRequestBytes, &r)
+	assert.NoError(t, err)
+	assert.Equal(t, receiptsRequest.BlockHashes, r.BlockHashes)
+}
+
+// TestMarshalReceiptsResponse asserts that the structure or serialization logic hasn't changed, primarily to
+// ensure compatibility with the network.
+func TestMarshalReceiptsResponse(t *testing.T) {
+	receiptsResponse := ReceiptsResponse{
+		Receipts: [][]byte{{0xc0}, {0xc1, 0xc0}},
+	}
+
+	base64ReceiptsResponse := "AAAAAAACAAAAAcAAAAACwcA="
+
+	receiptsResponseBytes, err := Codec.Marshal(Version, receiptsResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, base64ReceiptsResponse, base64.StdEncoding.EncodeToString(receiptsResponseBytes))
+
+	var r ReceiptsResponse
+	_, err = Codec.Unmarshal(receiptsResponseBytes, &r)
+	assert.NoError(t, err)
+	assert.Equal(t, receiptsResponse.Receipts, r.Receipts)
+}
+
+func TestReceiptsRequestHandle(t *testing.T) {
+	mockRequestHandler := &mockHandler{}
+	_, _ = ReceiptsRequest{}.Handle(context.Background(), ids.GenerateTestNodeID(), 1, mockRequestHandler)
+	assert.True(t, mockRequestHandler.handleReceiptsRequestCalled)
+	assert.False(t, mockRequestHandler.handleBlockRequestCalled)
+}
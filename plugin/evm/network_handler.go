@@ -24,36 +24,54 @@ type networkHandler struct {
 	stateTrieLeafsRequestHandler *syncHandlers.LeafsRequestHandler
 	blockRequestHandler          *syncHandlers.BlockRequestHandler
 	codeRequestHandler           *syncHandlers.CodeRequestHandler
+	receiptsRequestHandler       *syncHandlers.ReceiptsRequestHandler
 	signatureRequestHandler      *warpHandlers.SignatureRequestHandler
+	bandwidthLimiter             *syncHandlers.BandwidthLimiter
 }
 
 // newNetworkHandler constructs the handler for serving network requests.
 func newNetworkHandler(
 	provider syncHandlers.SyncDataProvider,
-	diskDB ethdb.KeyValueReader,
+	diskDB ethdb.Reader,
 	evmTrieDB *trie.Database,
 	warpBackend warp.Backend,
 	networkCodec codec.Manager,
+	bandwidthConfig syncHandlers.BandwidthConfig,
+	validators syncHandlers.ValidatorSet,
 ) message.RequestHandler {
 	syncStats := syncStats.NewHandlerStats(metrics.Enabled)
 	return &networkHandler{
 		stateTrieLeafsRequestHandler: syncHandlers.NewLeafsRequestHandler(evmTrieDB, provider, networkCodec, syncStats),
 		blockRequestHandler:          syncHandlers.NewBlockRequestHandler(provider, networkCodec, syncStats),
 		codeRequestHandler:           syncHandlers.NewCodeRequestHandler(diskDB, networkCodec, syncStats),
+		receiptsRequestHandler:       syncHandlers.NewReceiptsRequestHandler(diskDB, networkCodec, syncStats),
 		signatureRequestHandler:      warpHandlers.NewSignatureRequestHandler(warpBackend, networkCodec),
+		bandwidthLimiter:             syncHandlers.NewBandwidthLimiter(bandwidthConfig, validators, syncStats),
 	}
 }
 
 func (n networkHandler) HandleStateTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest message.LeafsRequest) ([]byte, error) {
-	return n.stateTrieLeafsRequestHandler.OnLeafsRequest(ctx, nodeID, requestID, leafsRequest)
+	return n.bandwidthLimiter.Serve(ctx, nodeID, requestID, func() ([]byte, error) {
+		return n.stateTrieLeafsRequestHandler.OnLeafsRequest(ctx, nodeID, requestID, leafsRequest)
+	})
 }
 
 func (n networkHandler) HandleBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, blockRequest message.BlockRequest) ([]byte, error) {
-	return n.blockRequestHandler.OnBlockRequest(ctx, nodeID, requestID, blockRequest)
+	return n.bandwidthLimiter.Serve(ctx, nodeID, requestID, func() ([]byte, error) {
+		return n.blockRequestHandler.OnBlockRequest(ctx, nodeID, requestID, blockRequest)
+	})
 }
 
 func (n networkHandler) HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest message.CodeRequest) ([]byte, error) {
-	return n.codeRequestHandler.OnCodeRequest(ctx, nodeID, requestID, codeRequest)
+	return n.bandwidthLimiter.Serve(ctx, nodeID, requestID, func() ([]byte, error) {
+		return n.codeRequestHandler.OnCodeRequest(ctx, nodeID, requestID, codeRequest)
+	})
+}
+
+func (n networkHandler) HandleReceiptsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, receiptsRequest message.ReceiptsRequest) ([]byte, error) {
+	return n.bandwidthLimiter.Serve(ctx, nodeID, requestID, func() ([]byte, error) {
+		return n.receiptsRequestHandler.OnReceiptsRequest(ctx, nodeID, requestID, receiptsRequest)
+	})
 }
 
 func (n networkHandler) HandleMessageSignatureRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, messageSignatureRequest message.MessageSignatureRequest) ([]byte, error) {
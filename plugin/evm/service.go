@@ -36,3 +36,17 @@ func (api *SnowmanAPI) IssueBlock(ctx context.Context) error {
 	api.vm.builder.signalTxsReady()
 	return nil
 }
+
+// BlockBackfillAPI exposes the progress of the historical block backfill
+// performed after state sync
+type BlockBackfillAPI struct{ vm *VM }
+
+// Status returns the progress of the historical block backfill
+func (api *BlockBackfillAPI) Status(ctx context.Context) (*BlockBackfillStatus, error) {
+	backfiller := api.vm.blockBackfiller.Get()
+	if backfiller == nil {
+		return nil, errNoBlockBackfill
+	}
+	status := backfiller.Status()
+	return &status, nil
+}
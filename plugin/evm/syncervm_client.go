@@ -6,6 +6,7 @@ package evm
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 
 	"github.com/ava-labs/avalanchego/database"
@@ -27,7 +28,8 @@ import (
 )
 
 const (
-	// State sync fetches [parentsToGet] parents of the block it syncs to.
+	// State sync fetches [parentsToGet] parents of the block it syncs to,
+	// unless the block history precompile is configured (see stateSyncParents).
 	// The last 256 block hashes are necessary to support the BLOCKHASH opcode.
 	parentsToGet = 256
 )
@@ -85,6 +87,7 @@ type StateSyncClient interface {
 
 	// additional methods required by the evm package
 	ClearOngoingSummary() error
+	ImportArchive(ctx context.Context, path string) error
 	Shutdown() error
 	Error() error
 }
@@ -136,6 +139,54 @@ func (client *stateSyncerClient) ClearOngoingSummary() error {
 	return nil
 }
 
+// ImportArchive bootstraps the chain from the state sync archive at [path],
+// verifying its blocks and state tries the same way as state sync from peers
+// does. The archive is skipped if its summary is not above the last accepted
+// block. The import is cancelled if [ctx] is cancelled or on [Shutdown].
+func (client *stateSyncerClient) ImportArchive(ctx context.Context, path string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client.cancel = cancel
+	client.wg.Add(1) // track the import so we can wait for it on shutdown
+	defer client.wg.Done()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open state sync archive: %w", err)
+	}
+	defer f.Close()
+
+	reader, err := statesync.NewArchiveReader(f)
+	if err != nil {
+		return err
+	}
+	summary := reader.Summary()
+	if summary.Height() <= client.lastAcceptedHeight {
+		log.Info(
+			"last accepted not behind state sync archive, skipping import",
+			"lastAccepted", client.lastAcceptedHeight,
+			"archiveHeight", summary.Height(),
+		)
+		return nil
+	}
+
+	// The snapshot is rebuilt from the archive, see [acceptSyncSummary].
+	<-snapshot.WipeSnapshot(client.chaindb, true)
+	snapshot.ResetSnapshotGeneration(client.chaindb)
+
+	log.Info("Importing state sync archive", "path", path, "summary", summary)
+	if err := reader.Import(ctx, client.chaindb, ethdb.IdealBatchSize); err != nil {
+		return fmt.Errorf("failed to import state sync archive: %w", err)
+	}
+	client.syncSummary = summary
+	if err := client.finishSync(); err != nil {
+		return err
+	}
+	client.lastAcceptedHeight = summary.Height()
+	log.Info("Imported state sync archive", "summary", summary)
+	return nil
+}
+
 // ParseStateSummary parses [summaryBytes] to [commonEng.Summary]
 func (client *stateSyncerClient) ParseStateSummary(_ context.Context, summaryBytes []byte) (block.StateSummary, error) {
 	return message.NewSyncSummaryFromBytes(summaryBytes, client.acceptSyncSummary)
@@ -144,7 +195,7 @@ func (client *stateSyncerClient) ParseStateSummary(_ context.Context, summaryByt
 // stateSync blockingly performs the state sync for the EVM state and the atomic state
 // to [client.syncSummary]. returns an error if one occurred.
 func (client *stateSyncerClient) stateSync(ctx context.Context) error {
-	if err := client.syncBlocks(ctx, client.syncSummary.BlockHash, client.syncSummary.BlockNumber, parentsToGet); err != nil {
+	if err := client.syncBlocks(ctx, client.syncSummary.BlockHash, client.syncSummary.BlockNumber, stateSyncParents(client.chain.BlockChain().Config())); err != nil {
 		return err
 	}
 
@@ -262,6 +313,12 @@ func (client *stateSyncerClient) syncBlocks(ctx context.Context, fromHash common
 			nextHash = block.ParentHash()
 			nextHeight--
 		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
 		log.Info("fetching blocks from peer", "remaining", i+1, "total", parentsToGet)
 	}
 	log.Info("fetched blocks from peer", "total", parentsToGet)
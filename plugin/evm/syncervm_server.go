@@ -6,12 +6,15 @@ package evm
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 
 	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/sync/statesync"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -19,6 +22,7 @@ type stateSyncServerConfig struct {
 	Chain *core.BlockChain
 
 	// SyncableInterval is the interval at which blocks are eligible to provide syncable block summaries.
+	// If zero, no summary is served.
 	SyncableInterval uint64
 }
 
@@ -31,6 +35,9 @@ type stateSyncServer struct {
 type StateSyncServer interface {
 	GetLastStateSummary(context.Context) (block.StateSummary, error)
 	GetStateSummary(context.Context, uint64) (block.StateSummary, error)
+
+	// additional methods required by the evm package
+	ExportArchive(ctx context.Context, w io.Writer, height uint64) error
 }
 
 func NewStateSyncServer(config *stateSyncServerConfig) StateSyncServer {
@@ -63,6 +70,9 @@ func (server *stateSyncServer) stateSummaryAtHeight(height uint64) (message.Sync
 // that is divisible by [syncableInterval]
 // If no summary is available, [database.ErrNotFound] must be returned.
 func (server *stateSyncServer) GetLastStateSummary(context.Context) (block.StateSummary, error) {
+	if server.syncableInterval == 0 {
+		return nil, database.ErrNotFound
+	}
 	lastHeight := server.chain.LastAcceptedBlock().NumberU64()
 	lastSyncSummaryNumber := lastHeight - lastHeight%server.syncableInterval
 
@@ -79,6 +89,9 @@ func (server *stateSyncServer) GetLastStateSummary(context.Context) (block.State
 // to the provided [height] if the node can serve state sync data for that key.
 // If not, [database.ErrNotFound] must be returned.
 func (server *stateSyncServer) GetStateSummary(_ context.Context, height uint64) (block.StateSummary, error) {
+	if server.syncableInterval == 0 {
+		return nil, database.ErrNotFound
+	}
 	summaryBlock := server.chain.GetBlockByNumber(height)
 	if summaryBlock == nil ||
 		summaryBlock.NumberU64() > server.chain.LastAcceptedBlock().NumberU64() ||
@@ -95,3 +108,45 @@ func (server *stateSyncServer) GetStateSummary(_ context.Context, height uint64)
 	log.Debug("Serving syncable block at requested height", "height", height, "summary", summary)
 	return summary, nil
 }
+
+// ExportArchive writes a state sync archive of the summary at [height] to [w],
+// or of the latest summary if [height] is 0. The archive holds the same data
+// a node would fetch from peers when state syncing to that summary.
+func (server *stateSyncServer) ExportArchive(ctx context.Context, w io.Writer, height uint64) error {
+	var (
+		summary block.StateSummary
+		err     error
+	)
+	if height == 0 {
+		summary, err = server.GetLastStateSummary(ctx)
+	} else {
+		summary, err = server.GetStateSummary(ctx, height)
+	}
+	if err != nil {
+		return fmt.Errorf("no state summary available at height %d: %w", height, err)
+	}
+	syncSummary, ok := summary.(message.SyncSummary)
+	if !ok {
+		return fmt.Errorf("unexpected state summary type %T", summary)
+	}
+
+	// Include the summary block and the parents state sync fetches from peers.
+	parents := stateSyncParents(server.chain.Config())
+	blocks := make([]*types.Block, 0, parents+1)
+	hash, number := syncSummary.BlockHash, syncSummary.BlockNumber
+	for len(blocks) <= parents {
+		blk := server.chain.GetBlock(hash, number)
+		if blk == nil {
+			return fmt.Errorf("block not found for height (%d), hash (%s)", number, hash)
+		}
+		blocks = append(blocks, blk)
+		if number == 0 {
+			break
+		}
+		hash, number = blk.ParentHash(), number-1
+	}
+
+	log.Info("Exporting state sync archive", "summary", syncSummary)
+	stateCache := server.chain.StateCache()
+	return statesync.ExportArchive(ctx, w, syncSummary, blocks, stateCache.TrieDB(), stateCache.DiskDB())
+}
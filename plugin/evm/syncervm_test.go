@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -15,6 +18,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
@@ -604,3 +608,60 @@ func assertSyncPerformedHeights(t *testing.T, db ethdb.Iteratee, expected map[ui
 	require.NoError(t, it.Error())
 	require.Equal(t, expected, found)
 }
+
+func TestStateSyncArchiveImport(t *testing.T) {
+	require := require.New(t)
+	_, serverVM, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	t.Cleanup(func() {
+		require.NoError(serverVM.Shutdown(context.Background()))
+	})
+	generateAndAcceptBlocks(t, serverVM, parentsToGet+44, func(i int, gen *core.BlockGen) {
+		b, err := predicate.NewResults().Bytes()
+		require.NoError(err)
+		gen.AppendExtra(b)
+
+		tx := types.NewTransaction(gen.TxNonce(testEthAddrs[0]), testEthAddrs[1], common.Big1, params.TxGas, big.NewInt(testMinGasPrice), nil)
+		signedTx, err := types.SignTx(tx, types.NewEIP155Signer(serverVM.chainConfig.ChainID), testKeys[0])
+		require.NoError(err)
+		gen.AddTx(signedTx)
+	}, nil)
+	serverVM.StateSyncServer.(*stateSyncServer).syncableInterval = 256
+
+	archivePath := filepath.Join(t.TempDir(), "archive")
+	admin := NewAdminService(serverVM, t.TempDir())
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	require.NoError(admin.ExportStateSyncArchive(request, &ExportStateSyncArchiveArgs{Path: archivePath}, &api.EmptyReply{}))
+	summary, err := serverVM.GetLastStateSummary(context.Background())
+	require.NoError(err)
+
+	// initialise [syncerVM] from the archive
+	configJSON := fmt.Sprintf(`{"state-sync-archive": %q}`, archivePath)
+	_, syncerVM, _, _ := GenesisVM(t, false, genesisJSONLatest, configJSON, "")
+	t.Cleanup(func() {
+		require.NoError(syncerVM.Shutdown(context.Background()))
+	})
+	require.Equal(summary.Height(), syncerVM.LastAcceptedBlock().Height())
+	require.Equal(serverVM.blockChain.GetBlockByNumber(summary.Height()).Hash(), syncerVM.blockChain.LastAcceptedBlock().Hash())
+	require.True(syncerVM.blockChain.HasState(syncerVM.blockChain.LastAcceptedBlock().Root()), "unavailable state for last accepted block")
+	assertSyncPerformedHeights(t, syncerVM.chaindb, map[uint64]struct{}{summary.Height(): {}})
+	for i := uint64(0); i <= summary.Height(); i++ {
+		require.Equal(serverVM.blockChain.GetBlockByNumber(i).Hash(), rawdb.ReadCanonicalHash(syncerVM.chaindb, i))
+	}
+
+	serverState, err := serverVM.blockChain.StateAt(syncerVM.blockChain.LastAcceptedBlock().Root())
+	require.NoError(err)
+	syncerState, err := syncerVM.blockChain.State()
+	require.NoError(err)
+	require.Equal(serverState.GetBalance(testEthAddrs[1]), syncerState.GetBalance(testEthAddrs[1]))
+
+	// The import stops once its context is cancelled.
+	_, cancelledVM, _, _ := GenesisVM(t, false, genesisJSONLatest, "", "")
+	t.Cleanup(func() {
+		require.NoError(cancelledVM.Shutdown(context.Background()))
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = cancelledVM.StateSyncClient.ImportArchive(ctx, archivePath)
+	require.ErrorIs(err, context.Canceled)
+	require.Zero(cancelledVM.LastAcceptedBlock().Height())
+}
@@ -35,11 +35,13 @@ import (
 	"github.com/ava-labs/subnet-evm/params"
 	"github.com/ava-labs/subnet-evm/peer"
 	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/blockhistory"
 	"github.com/ava-labs/subnet-evm/trie/triedb/hashdb"
 
 	"github.com/ava-labs/subnet-evm/rpc"
 	statesyncclient "github.com/ava-labs/subnet-evm/sync/client"
 	"github.com/ava-labs/subnet-evm/sync/client/stats"
+	syncHandlers "github.com/ava-labs/subnet-evm/sync/handlers"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ava-labs/subnet-evm/warp"
 	warpValidators "github.com/ava-labs/subnet-evm/warp/validators"
@@ -237,6 +239,13 @@ type VM struct {
 	StateSyncServer
 	StateSyncClient
 
+	// blockBackfiller downloads historical blocks and receipts below the
+	// state synced block, set once normal operations start
+	blockBackfiller avalancheUtils.Atomic[*blockBackfiller]
+	// historyWindowOnce starts fetching the missing headers of the block
+	// history window once bootstrapping starts
+	historyWindowOnce sync.Once
+
 	// Avalanche Warp Messaging backend
 	// Used to serve BLS signatures of warp messages over RPC
 	warpBackend warp.Backend
@@ -250,7 +259,7 @@ type VM struct {
 
 // Initialize implements the snowman.ChainVM interface
 func (vm *VM) Initialize(
-	_ context.Context,
+	ctx context.Context,
 	chainCtx *snow.Context,
 	db database.Database,
 	genesisBytes []byte,
@@ -306,7 +315,15 @@ func (vm *VM) Initialize(
 	vm.shutdownChan = make(chan struct{}, 1)
 	// Use NewNested rather than New so that the structure of the database
 	// remains the same regardless of the provided baseDB type.
-	vm.chaindb = rawdb.NewDatabase(Database{prefixdb.NewNested(ethDBPrefix, db)})
+	kvdb := Database{prefixdb.NewNested(ethDBPrefix, db)}
+	if vm.config.FreezerEnabled {
+		vm.chaindb, err = rawdb.NewDatabaseWithFreezer(kvdb, vm.config.FreezerDataDirectory, "eth/db/chaindata/", false, vm.config.FreezerThreshold)
+		if err != nil {
+			return fmt.Errorf("failed to open freezer: %w", err)
+		}
+	} else {
+		vm.chaindb = rawdb.NewDatabase(kvdb)
+	}
 	vm.db = versiondb.New(db)
 	vm.acceptedBlockDB = prefixdb.New(acceptedPrefix, vm.db)
 	vm.metadataDB = prefixdb.New(metadataPrefix, vm.db)
@@ -409,6 +426,7 @@ func (vm *VM) Initialize(
 	vm.ethConfig.TxPool.Lifetime = vm.config.TxPoolLifetime.Duration
 
 	vm.ethConfig.AllowUnfinalizedQueries = vm.config.AllowUnfinalizedQueries
+	vm.ethConfig.AllowProcessingQueries = vm.config.AllowProcessingQueries
 	vm.ethConfig.AllowUnprotectedTxs = vm.config.AllowUnprotectedTxs
 	vm.ethConfig.AllowUnprotectedTxHashes = vm.config.AllowUnprotectedTxHashes
 	vm.ethConfig.Preimages = vm.config.Preimages
@@ -428,11 +446,17 @@ func (vm *VM) Initialize(
 	vm.ethConfig.OfflinePruning = vm.config.OfflinePruning
 	vm.ethConfig.OfflinePruningBloomFilterSize = vm.config.OfflinePruningBloomFilterSize
 	vm.ethConfig.OfflinePruningDataDirectory = vm.config.OfflinePruningDataDirectory
+	vm.ethConfig.OfflinePruningParallelism = vm.config.OfflinePruningParallelism
 	vm.ethConfig.CommitInterval = vm.config.CommitInterval
 	vm.ethConfig.SkipUpgradeCheck = vm.config.SkipUpgradeCheck
 	vm.ethConfig.AcceptedCacheSize = vm.config.AcceptedCacheSize
 	vm.ethConfig.TransactionHistory = vm.config.TransactionHistory
 	vm.ethConfig.SkipTxIndexing = vm.config.SkipTxIndexing
+	vm.ethConfig.BlockHistory = vm.config.BlockHistory
+	vm.ethConfig.LogIndexing = vm.config.LogIndexingEnabled
+	vm.ethConfig.StateDiffDir = vm.config.StateDiffExportDir
+	vm.ethConfig.StateDiffFileSize = vm.config.StateDiffExportFileSize
+	vm.ethConfig.StateDiffMaxFiles = vm.config.StateDiffExportMaxFiles
 
 	// Create directory for offline pruning
 	if len(vm.ethConfig.OfflinePruningDataDirectory) != 0 {
@@ -454,6 +478,9 @@ func (vm *VM) Initialize(
 
 	vm.chainConfig = g.Config
 	vm.networkID = vm.ethConfig.NetworkId
+	if err := vm.config.ValidateStateSyncHistory(stateSyncParents(vm.chainConfig)); err != nil {
+		return err
+	}
 
 	// create genesisHash after applying upgradeBytes in case
 	// upgradeBytes modifies genesis.
@@ -514,7 +541,13 @@ func (vm *VM) Initialize(
 	go vm.ctx.Log.RecoverAndPanic(vm.startContinuousProfiler)
 
 	vm.initializeStateSyncServer()
-	return vm.initializeStateSyncClient(lastAcceptedHeight)
+	if err := vm.initializeStateSyncClient(lastAcceptedHeight); err != nil {
+		return err
+	}
+	if vm.config.StateSyncArchive != "" {
+		return vm.StateSyncClient.ImportArchive(ctx, vm.config.StateSyncArchive)
+	}
+	return nil
 }
 
 func (vm *VM) initializeMetrics() error {
@@ -674,12 +707,14 @@ func (vm *VM) SetState(_ context.Context, state snow.State) error {
 		// Ensure snapshots are initialized before bootstrapping (i.e., if state sync is skipped).
 		// Note calling this function has no effect if snapshots are already initialized.
 		vm.blockChain.InitializeSnapshots()
+		vm.historyWindowOnce.Do(vm.initBlockHistoryWindow)
 		return nil
 	case snow.NormalOp:
 		// Initialize goroutines related to block building once we enter normal operation as there is no need to handle mempool gossip before this point.
 		if err := vm.initBlockBuilding(); err != nil {
 			return fmt.Errorf("failed to initialize block building: %w", err)
 		}
+		vm.initBlockBackfill()
 		vm.bootstrapped = true
 		return nil
 	default:
@@ -791,6 +826,90 @@ func (vm *VM) initBlockBuilding() error {
 	return nil
 }
 
+// initBlockBackfill starts backfilling historical blocks and receipts below
+// the state synced block in the background, if enabled and state sync was
+// performed.
+func (vm *VM) initBlockBackfill() {
+	if !vm.config.StateSyncBackfillEnabled || vm.blockBackfiller.Get() != nil {
+		return
+	}
+	if rawdb.GetLatestSyncPerformed(vm.chaindb) == 0 {
+		return
+	}
+
+	backfiller := newBlockBackfiller(&blockBackfillerConfig{
+		client: statesyncclient.NewClient(
+			&statesyncclient.ClientConfig{
+				NetworkClient: vm.client,
+				Codec:         vm.networkCodec,
+				Stats:         stats.NewClientSyncerStats(),
+				BlockParser:   vm,
+			},
+		),
+		chaindb:  vm.chaindb,
+		indexTxs: !vm.config.SkipTxIndexing && vm.config.TransactionHistory == 0,
+	})
+	vm.blockBackfiller.Set(backfiller)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	vm.shutdownWg.Add(2)
+	go func() {
+		defer vm.shutdownWg.Done()
+		select {
+		case <-vm.shutdownChan:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	go func() {
+		defer vm.shutdownWg.Done()
+		defer cancel()
+		if err := backfiller.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Error("historical block backfill failed", "err", err)
+		}
+	}()
+}
+
+// initBlockHistoryWindow fetches the headers of the block history window
+// missing below the last accepted block in the background, if the block
+// history precompile is configured. Calls to the precompile for missing
+// headers fail until they are fetched.
+func (vm *VM) initBlockHistoryWindow() {
+	if !blockHistoryConfigured(vm.chainConfig) {
+		return
+	}
+	head := vm.blockChain.LastAcceptedBlock().Header()
+	if _, _, missing := missingHistoryWindow(vm.chaindb, head, blockhistory.HistoryWindow); !missing {
+		return
+	}
+	client := statesyncclient.NewClient(
+		&statesyncclient.ClientConfig{
+			NetworkClient: vm.client,
+			Codec:         vm.networkCodec,
+			Stats:         stats.NewClientSyncerStats(),
+			BlockParser:   vm,
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	vm.shutdownWg.Add(2)
+	go func() {
+		defer vm.shutdownWg.Done()
+		select {
+		case <-vm.shutdownChan:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	go func() {
+		defer vm.shutdownWg.Done()
+		defer cancel()
+		if err := fillHistoryWindow(ctx, client, vm.chaindb, head, blockhistory.HistoryWindow); err != nil && ctx.Err() == nil {
+			log.Error("failed to fetch the headers of the block history window", "err", err)
+		}
+	}()
+}
+
 // setAppRequestHandlers sets the request handlers for the VM to serve state sync
 // requests.
 func (vm *VM) setAppRequestHandlers() {
@@ -806,7 +925,14 @@ func (vm *VM) setAppRequestHandlers() {
 		},
 	)
 
-	networkHandler := newNetworkHandler(vm.blockChain, vm.chaindb, evmTrieDB, vm.warpBackend, vm.networkCodec)
+	bandwidthConfig := syncHandlers.BandwidthConfig{
+		PeerRate:         vm.config.StateSyncServerPeerBandwidth,
+		PeerBurst:        vm.config.StateSyncServerPeerBurst,
+		TotalRate:        vm.config.StateSyncServerBandwidth,
+		TotalBurst:       vm.config.StateSyncServerBurst,
+		ValidatorReserve: vm.config.StateSyncServerValidatorReserve,
+	}
+	networkHandler := newNetworkHandler(vm.blockChain, vm.chaindb, evmTrieDB, vm.warpBackend, vm.networkCodec, bandwidthConfig, vm.validators)
 	vm.Network.SetRequestHandler(networkHandler)
 }
 
@@ -998,6 +1124,13 @@ func newHandler(name string, service interface{}) (http.Handler, error) {
 // CreateHandlers makes new http handlers that can handle API calls
 func (vm *VM) CreateHandlers(context.Context) (map[string]http.Handler, error) {
 	handler := rpc.NewServer(vm.config.APIMaxDuration.Duration)
+	rateLimiterConfig, err := vm.config.RateLimiterConfig()
+	if err != nil {
+		return nil, err
+	}
+	if rateLimiterConfig.Enabled() {
+		handler.SetRateLimiter(rpc.NewRateLimiter(rateLimiterConfig))
+	}
 	enabledAPIs := vm.config.EthAPIs()
 	if err := attachEthService(handler, vm.eth.APIs(), enabledAPIs); err != nil {
 		return nil, err
@@ -1024,6 +1157,13 @@ func (vm *VM) CreateHandlers(context.Context) (map[string]http.Handler, error) {
 		enabledAPIs = append(enabledAPIs, "snowman")
 	}
 
+	if vm.config.StateSyncBackfillEnabled {
+		if err := handler.RegisterName("backfill", &BlockBackfillAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "backfill")
+	}
+
 	if vm.config.WarpAPIEnabled {
 		validatorsState := warpValidators.NewState(vm.ctx)
 		if err := handler.RegisterName("warp", warp.NewAPI(vm.ctx.NetworkID, vm.ctx.SubnetID, vm.ctx.ChainID, validatorsState, vm.warpBackend, vm.client)); err != nil {
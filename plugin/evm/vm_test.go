@@ -11,6 +11,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -46,6 +48,7 @@ import (
 	"github.com/ava-labs/subnet-evm/consensus/dummy"
 	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/txpool"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/eth"
@@ -58,6 +61,7 @@ import (
 	"github.com/ava-labs/subnet-evm/precompile/contracts/feemanager"
 	"github.com/ava-labs/subnet-evm/precompile/contracts/rewardmanager"
 	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ava-labs/subnet-evm/predicate"
 	"github.com/ava-labs/subnet-evm/rpc"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ava-labs/subnet-evm/utils"
@@ -1915,6 +1919,67 @@ func TestLastAcceptedBlockNumberAllow(t *testing.T) {
 	}
 }
 
+func TestProcessingBlockQueries(t *testing.T) {
+	require := require.New(t)
+	issuer, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	tx := types.NewTransaction(uint64(0), testEthAddrs[1], firstTxAmount, 21000, big.NewInt(testMinGasPrice), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	require.NoError(err)
+	for _, err := range vm.txPool.AddRemotesSync([]*types.Transaction{signedTx}) {
+		require.NoError(err)
+	}
+
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	ethBlock := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+
+	var (
+		ctx    = context.Background()
+		api    = eth.NewEthereumAPI(vm.eth)
+		byHash = rpc.BlockNumberOrHashWithHash(ethBlock.Hash(), false)
+		parent = vm.blockChain.LastAcceptedBlock()
+	)
+	acceptedState, err := vm.blockChain.StateAt(parent.Root())
+	require.NoError(err)
+	expected := new(big.Int).Add(acceptedState.GetBalance(testEthAddrs[1]), firstTxAmount)
+
+	_, _, err = vm.eth.APIBackend.StateAndHeaderByNumberOrHash(ctx, byHash)
+	require.ErrorIs(err, eth.ErrUnfinalizedData)
+	_, err = api.GetProcessingBlocks()
+	require.ErrorContains(err, "processing block queries are disabled")
+
+	vm.eth.APIBackend.SetAllowProcessingQueries(true)
+
+	state, header, err := vm.eth.APIBackend.StateAndHeaderByNumberOrHash(ctx, byHash)
+	require.NoError(err)
+	require.Equal(ethBlock.Hash(), header.Hash())
+	require.Equal(expected, state.GetBalance(testEthAddrs[1]))
+
+	processing, err := api.GetProcessingBlocks()
+	require.NoError(err)
+	require.Len(processing, 1)
+	require.Equal(ethBlock.Hash(), processing[0].Hash)
+	require.Equal(ethBlock.ParentHash(), processing[0].ParentHash)
+	require.True(processing[0].Preferred)
+
+	// Unfinalized queries by number are still disallowed.
+	_, err = vm.eth.APIBackend.BlockByNumber(ctx, rpc.BlockNumber(blk.Height()))
+	require.ErrorIs(err, eth.ErrUnfinalizedData)
+
+	require.NoError(vm.SetPreference(ctx, blk.ID()))
+	require.NoError(blk.Accept(ctx))
+	processing, err = api.GetProcessingBlocks()
+	require.NoError(err)
+	require.Empty(processing)
+}
+
 func TestConfigureLogLevel(t *testing.T) {
 	configTests := []struct {
 		name                     string
@@ -3300,3 +3365,146 @@ func TestParentBeaconRootBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestFreezer(t *testing.T) {
+	require := require.New(t)
+	configJSON := fmt.Sprintf(`{"freezer-enabled": true, "freezer-threshold": 5, "freezer-data-directory": %q}`, t.TempDir())
+	_, vm, _, _ := GenesisVM(t, true, genesisJSONLatest, configJSON, "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	var receipts []types.Receipts
+	generateAndAcceptBlocks(t, vm, 20, func(_ int, gen *core.BlockGen) {
+		b, err := predicate.NewResults().Bytes()
+		require.NoError(err)
+		gen.AppendExtra(b)
+
+		tx := types.NewTransaction(gen.TxNonce(testEthAddrs[0]), testEthAddrs[1], common.Big1, params.TxGas, big.NewInt(testMinGasPrice), nil)
+		signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+		require.NoError(err)
+		gen.AddTx(signedTx)
+	}, func(block *types.Block) {
+		receipts = append(receipts, vm.blockChain.GetReceiptsByHash(block.Hash()))
+	})
+
+	freezer, ok := vm.chaindb.(interface{ Freeze(threshold uint64) error })
+	require.True(ok)
+	require.NoError(freezer.Freeze(5))
+	frozen, err := vm.chaindb.Ancients()
+	require.NoError(err)
+	require.EqualValues(16, frozen)
+
+	for i := uint64(1); i <= 20; i++ {
+		hash := rawdb.ReadCanonicalHash(vm.chaindb, i)
+		block := rawdb.ReadBlock(vm.chaindb, hash, i)
+		require.NotNil(block)
+		blockReceipts := rawdb.ReadReceipts(vm.chaindb, hash, i, block.Time(), vm.chainConfig)
+		require.Len(blockReceipts, 1)
+		require.Equal(receipts[i-1][0].TxHash, blockReceipts[0].TxHash)
+	}
+}
+
+func TestBlockHistory(t *testing.T) {
+	require := require.New(t)
+	configJSON := `{"pruning-enabled": true, "commit-interval": 4, "block-history": 8, "state-sync-commit-interval": 0}`
+	_, vm, _, _ := GenesisVM(t, true, genesisJSONLatest, configJSON, "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	var blocks []*types.Block
+	generateAndAcceptBlocks(t, vm, 20, func(_ int, gen *core.BlockGen) {
+		b, err := predicate.NewResults().Bytes()
+		require.NoError(err)
+		gen.AppendExtra(b)
+
+		tx := types.NewTransaction(gen.TxNonce(testEthAddrs[0]), testEthAddrs[1], common.Big1, params.TxGas, big.NewInt(testMinGasPrice), nil)
+		signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+		require.NoError(err)
+		gen.AddTx(signedTx)
+	}, func(block *types.Block) {
+		blocks = append(blocks, block)
+	})
+
+	// Blocks [13, 20] are retained
+	require.Eventually(func() bool { return vm.blockChain.HistoryTail() == 13 }, 30*time.Second, 100*time.Millisecond)
+	require.EqualValues(13, eth.NewDebugAPI(vm.eth).GetAccessibleHistory())
+
+	ctx := context.Background()
+	requirePruned := func(err error) {
+		var rpcErr rpc.Error
+		require.ErrorAs(err, &rpcErr)
+		require.Equal(rpc.ErrcodePrunedHistory, rpcErr.ErrorCode())
+	}
+	pruned := blocks[11]
+	_, err := vm.eth.APIBackend.BlockByNumber(ctx, rpc.BlockNumber(pruned.NumberU64()))
+	requirePruned(err)
+	_, err = vm.eth.APIBackend.BlockByHash(ctx, pruned.Hash())
+	requirePruned(err)
+	_, err = vm.eth.APIBackend.GetReceipts(ctx, pruned.Hash())
+	requirePruned(err)
+	_, err = vm.eth.APIBackend.GetLogs(ctx, pruned.Hash(), pruned.NumberU64())
+	requirePruned(err)
+
+	header, err := vm.eth.APIBackend.HeaderByNumber(ctx, rpc.BlockNumber(pruned.NumberU64()))
+	require.NoError(err)
+	require.Equal(pruned.Hash(), header.Hash())
+
+	retained := blocks[12]
+	block, err := vm.eth.APIBackend.BlockByNumber(ctx, rpc.BlockNumber(retained.NumberU64()))
+	require.NoError(err)
+	require.Equal(retained.Hash(), block.Hash())
+	receipts, err := vm.eth.APIBackend.GetReceipts(ctx, retained.Hash())
+	require.NoError(err)
+	require.Len(receipts, 1)
+
+	genesis, err := vm.eth.APIBackend.BlockByNumber(ctx, 0)
+	require.NoError(err)
+	require.NotNil(genesis)
+}
+
+func TestAdminVerifyState(t *testing.T) {
+	require := require.New(t)
+	_, vm, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	generateAndAcceptBlocks(t, vm, 5, func(_ int, gen *core.BlockGen) {
+		b, err := predicate.NewResults().Bytes()
+		require.NoError(err)
+		gen.AppendExtra(b)
+
+		tx := types.NewTransaction(gen.TxNonce(testEthAddrs[0]), testEthAddrs[1], common.Big1, params.TxGas, big.NewInt(testMinGasPrice), nil)
+		signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+		require.NoError(err)
+		gen.AddTx(signedTx)
+	}, nil)
+
+	admin := NewAdminService(vm, t.TempDir())
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	reply := &VerifyStateReply{}
+	require.NoError(admin.VerifyState(req, &VerifyStateArgs{}, reply))
+	require.EqualValues(5, reply.BlockNumber)
+	require.Equal(vm.blockChain.LastAcceptedBlock().Root(), reply.Root)
+	require.NotZero(reply.Accounts)
+	require.Empty(reply.Mismatches)
+
+	// Fees are paid to the coinbase, so the supply is unchanged since genesis.
+	genesis := &core.Genesis{}
+	require.NoError(json.Unmarshal([]byte(genesisJSONLatest), genesis))
+	expectedSupply := new(big.Int)
+	for _, account := range genesis.Alloc {
+		expectedSupply.Add(expectedSupply, account.Balance)
+	}
+	require.Equal(expectedSupply.String(), reply.Supply)
+
+	reply = &VerifyStateReply{}
+	require.NoError(admin.VerifyState(req, &VerifyStateArgs{ExpectedSupply: "1"}, reply))
+	require.Len(reply.Mismatches, 1)
+	require.Contains(reply.Mismatches[0].Reason, "total supply mismatch")
+
+	reply = &VerifyStateReply{}
+	require.ErrorContains(admin.VerifyState(req, &VerifyStateArgs{Height: 2}, reply), "not available")
+}
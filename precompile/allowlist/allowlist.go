@@ -10,6 +10,7 @@ import (
 	"math/big"
 
 	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -25,6 +26,13 @@ const (
 	allowListInputLen = common.HashLength
 )
 
+// Names of the allow list gas costs, which can be overridden by the configs of
+// the precompiles using the allow list.
+const (
+	ReadAllowListGasCostKey   = "readAllowList"
+	ModifyAllowListGasCostKey = "modifyAllowList"
+)
+
 var (
 	// Error returned when an invalid write is attempted
 	ErrCannotModifyAllowList = errors.New("cannot modify allow list")
@@ -34,6 +42,12 @@ var (
 	AllowListRawABI string
 
 	AllowListABI = contract.ParseABI(AllowListRawABI)
+
+	// GasSchedule contains the gas costs of the allow list operations.
+	GasSchedule = precompileconfig.GasSchedule{
+		ReadAllowListGasCostKey:   precompileconfig.NewGasCost(ReadAllowListGasCost),
+		ModifyAllowListGasCostKey: precompileconfig.NewGasCost(ModifyAllowListGasCost),
+	}
 )
 
 // GetAllowListStatus returns the allow list role of [address] for the precompile
@@ -55,6 +69,10 @@ func SetAllowListRole(stateDB contract.StateDB, precompileAddr, address common.A
 	// and [addressKey] hash. It means that any reusage of the [addressKey] for different value
 	// conflicts with the same slot [role] is stored.
 	// Precompile implementations must use a different key than [addressKey]
+	if IsEnumerableMembersEnabled(stateDB, precompileAddr) {
+		oldRole := Role(stateDB.GetState(precompileAddr, addressKey))
+		updateRoleMembers(stateDB, precompileAddr, address, oldRole, role)
+	}
 	stateDB.SetState(precompileAddr, addressKey, role.Hash())
 }
 
@@ -84,7 +102,8 @@ func UnpackModifyAllowListInput(input []byte, r Role, useStrictMode bool) (commo
 // This execution function is speciifc to [precompileAddr].
 func createAllowListRoleSetter(precompileAddr common.Address, role Role) contract.RunStatefulPrecompileFunc {
 	return func(evm contract.AccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-		if remainingGas, err = contract.DeductGas(suppliedGas, ModifyAllowListGasCost); err != nil {
+		gasCost := contract.GetGasCost(evm, precompileAddr, ModifyAllowListGasCostKey, ModifyAllowListGasCost)
+		if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 			return nil, 0, err
 		}
 
@@ -109,6 +128,11 @@ func createAllowListRoleSetter(precompileAddr common.Address, role Role) contrac
 		if !callerStatus.CanModify(modifyStatus, role) {
 			return nil, remainingGas, fmt.Errorf("%w: modify address: %s, from role: %s, to role: %s", ErrCannotModifyAllowList, callerAddr, modifyStatus, role)
 		}
+		if IsEnumerableMembersEnabled(stateDB, precompileAddr) {
+			if remainingGas, err = contract.DeductGas(remainingGas, ModifyEnumerableMembersGasCost); err != nil {
+				return nil, 0, err
+			}
+		}
 		if contract.IsDurangoActivated(evm) {
 			if remainingGas, err = contract.DeductGas(remainingGas, AllowListEventGasCost); err != nil {
 				return nil, 0, err
@@ -155,7 +179,8 @@ func PackReadAllowListOutput(roleNumber *big.Int) ([]byte, error) {
 // designated role of that address
 func createReadAllowList(precompileAddr common.Address) contract.RunStatefulPrecompileFunc {
 	return func(evm contract.AccessibleState, callerAddr common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-		if remainingGas, err = contract.DeductGas(suppliedGas, ReadAllowListGasCost); err != nil {
+		gasCost := contract.GetGasCost(evm, precompileAddr, ReadAllowListGasCostKey, ReadAllowListGasCost)
+		if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 			return nil, 0, err
 		}
 
@@ -201,6 +226,10 @@ func CreateAllowListFunctions(precompileAddr common.Address) []*contract.Statefu
 			fn = contract.NewStatefulPrecompileFunction(method.ID, createAllowListRoleSetter(precompileAddr, NoRole))
 		} else if managerFnName, _ := ManagerRole.GetSetterFunctionName(); name == managerFnName {
 			fn = contract.NewStatefulPrecompileFunctionWithActivator(method.ID, createAllowListRoleSetter(precompileAddr, ManagerRole), contract.IsDurangoActivated)
+		} else if name == "getRoleMemberCount" {
+			fn = contract.NewStatefulPrecompileFunctionWithActivator(method.ID, createGetRoleMemberCount(precompileAddr), createEnumerableMembersActivator(precompileAddr))
+		} else if name == "getRoleMembers" {
+			fn = contract.NewStatefulPrecompileFunctionWithActivator(method.ID, createGetRoleMembers(precompileAddr), createEnumerableMembersActivator(precompileAddr))
 		} else {
 			panic(fmt.Sprintf("unexpected method name: %s", name))
 		}
@@ -18,11 +18,20 @@ type AllowListConfig struct {
 	AdminAddresses   []common.Address `json:"adminAddresses,omitempty"`   // initial admin addresses
 	ManagerAddresses []common.Address `json:"managerAddresses,omitempty"` // initial manager addresses
 	EnabledAddresses []common.Address `json:"enabledAddresses,omitempty"` // initial enabled addresses
+
+	// EnumerableMembers enables the index of the addresses of each role, which
+	// can be read with getRoleMemberCount and getRoleMembers.
+	EnumerableMembers bool `json:"enumerableMembers,omitempty"`
 }
 
 // Configure initializes the address space of [precompileAddr] by initializing the role of each of
 // the addresses in [AllowListAdmins].
 func (c *AllowListConfig) Configure(chainConfig precompileconfig.ChainConfig, precompileAddr common.Address, state contract.StateDB, blockContext contract.ConfigurationBlockContext) error {
+	// The index must be enabled before any role is assigned so that it
+	// contains all the addresses configured below.
+	if c.EnumerableMembers {
+		EnableEnumerableMembers(state, precompileAddr)
+	}
 	for _, enabledAddr := range c.EnabledAddresses {
 		SetAllowListRole(state, precompileAddr, enabledAddr, EnabledRole)
 	}
@@ -43,7 +52,8 @@ func (c *AllowListConfig) Equal(other *AllowListConfig) bool {
 		return false
 	}
 
-	return areEqualAddressLists(c.AdminAddresses, other.AdminAddresses) &&
+	return c.EnumerableMembers == other.EnumerableMembers &&
+		areEqualAddressLists(c.AdminAddresses, other.AdminAddresses) &&
 		areEqualAddressLists(c.ManagerAddresses, other.ManagerAddresses) &&
 		areEqualAddressLists(c.EnabledAddresses, other.EnabledAddresses)
 }
@@ -0,0 +1,258 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package allowlist
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The enumerable members index keeps, for every role other than NoRole, the
+// list of addresses holding that role in the storage of the precompile:
+//
+//	keccak256("allowlist.roleMembers", role)        => number of members
+//	keccak256(keccak256("allowlist.roleMembers", role), i) => i-th member
+//	keccak256("allowlist.memberIndex", address)     => index of address + 1
+//
+// Since an address holds a single role, its index always refers to the list of
+// its current role. Members are removed by moving the last member of the list
+// into the freed slot, so the order of the members is not preserved.
+//
+// The index is opt-in: it is only maintained for precompiles that were
+// configured with [AllowListConfig.EnumerableMembers], because it changes the storage written
+// by (and the gas charged to) the role setters. Existing precompiles can adopt
+// it by being disabled and enabled again with the option set.
+
+const (
+	// ModifyEnumerableMembersGasCost is the additional gas cost of changing the role of an
+	// address when the enumerable members index is enabled. Moving an address between
+	// two lists writes at most 6 slots.
+	ModifyEnumerableMembersGasCost = 6 * contract.WriteGasCostPerSlot
+	// ReadRoleMemberGasCost is the gas cost of reading a single member of a role.
+	ReadRoleMemberGasCost = contract.ReadGasCostPerSlot
+)
+
+var (
+	enumerableMembersKey = crypto.Keccak256Hash([]byte("allowlist.enumerableMembers"))
+	roleMembersPrefix    = []byte("allowlist.roleMembers")
+	memberIndexPrefix    = []byte("allowlist.memberIndex")
+
+	enumerableMembersEnabled = common.BigToHash(common.Big1)
+
+	// EnumerableMembersFunctions are the names of the allow list functions that are
+	// only available when the enumerable members index is enabled.
+	EnumerableMembersFunctions = []string{"getRoleMemberCount", "getRoleMembers"}
+)
+
+// roleMembersKey returns the storage key of the number of members of [role].
+// The members themselves are stored at the keys returned by [roleMemberKey].
+func roleMembersKey(role Role) common.Hash {
+	return crypto.Keccak256Hash(roleMembersPrefix, role.Bytes())
+}
+
+func roleMemberKey(membersKey common.Hash, index uint64) common.Hash {
+	return crypto.Keccak256Hash(membersKey.Bytes(), binary.BigEndian.AppendUint64(nil, index))
+}
+
+func memberIndexKey(address common.Address) common.Hash {
+	return crypto.Keccak256Hash(memberIndexPrefix, address.Bytes())
+}
+
+// EnableEnumerableMembers enables the enumerable members index of the precompile
+// at [precompileAddr]. It must be called before any role is assigned.
+func EnableEnumerableMembers(stateDB contract.StateDB, precompileAddr common.Address) {
+	stateDB.SetState(precompileAddr, enumerableMembersKey, enumerableMembersEnabled)
+}
+
+// IsEnumerableMembersEnabled returns true if the precompile at [precompileAddr]
+// maintains the enumerable members index.
+func IsEnumerableMembersEnabled(stateDB contract.StateDB, precompileAddr common.Address) bool {
+	return stateDB.GetState(precompileAddr, enumerableMembersKey) == enumerableMembersEnabled
+}
+
+// GetRoleMemberCount returns the number of addresses with [role] for the precompile at
+// [precompileAddr]. It always returns 0 if the enumerable members index is disabled.
+func GetRoleMemberCount(stateDB contract.StateDB, precompileAddr common.Address, role Role) uint64 {
+	return stateDB.GetState(precompileAddr, roleMembersKey(role)).Big().Uint64()
+}
+
+// GetRoleMembers returns at most [limit] addresses with [role] for the precompile at
+// [precompileAddr], starting from the [offset]-th member.
+func GetRoleMembers(stateDB contract.StateDB, precompileAddr common.Address, role Role, offset uint64, limit uint64) []common.Address {
+	count := GetRoleMemberCount(stateDB, precompileAddr, role)
+	if offset >= count {
+		return []common.Address{}
+	}
+	limit = min(limit, count-offset)
+	membersKey := roleMembersKey(role)
+	members := make([]common.Address, limit)
+	for i := range members {
+		members[i] = common.BytesToAddress(stateDB.GetState(precompileAddr, roleMemberKey(membersKey, offset+uint64(i))).Bytes())
+	}
+	return members
+}
+
+// addRoleMember appends [address] to the members of [role].
+func addRoleMember(stateDB contract.StateDB, precompileAddr common.Address, role Role, address common.Address) {
+	membersKey := roleMembersKey(role)
+	count := GetRoleMemberCount(stateDB, precompileAddr, role)
+	stateDB.SetState(precompileAddr, roleMemberKey(membersKey, count), common.BytesToHash(address.Bytes()))
+	stateDB.SetState(precompileAddr, memberIndexKey(address), common.BigToHash(new(big.Int).SetUint64(count+1)))
+	stateDB.SetState(precompileAddr, membersKey, common.BigToHash(new(big.Int).SetUint64(count+1)))
+}
+
+// removeRoleMember removes [address] from the members of [role] by replacing it
+// with the last member.
+func removeRoleMember(stateDB contract.StateDB, precompileAddr common.Address, role Role, address common.Address) {
+	indexKey := memberIndexKey(address)
+	index := stateDB.GetState(precompileAddr, indexKey).Big().Uint64()
+	if index == 0 {
+		// [address] was assigned [role] before the index was enabled.
+		return
+	}
+	membersKey := roleMembersKey(role)
+	last := GetRoleMemberCount(stateDB, precompileAddr, role) - 1
+	lastKey := roleMemberKey(membersKey, last)
+	if index-1 != last {
+		lastMember := stateDB.GetState(precompileAddr, lastKey)
+		stateDB.SetState(precompileAddr, roleMemberKey(membersKey, index-1), lastMember)
+		stateDB.SetState(precompileAddr, memberIndexKey(common.BytesToAddress(lastMember.Bytes())), common.BigToHash(new(big.Int).SetUint64(index)))
+	}
+	stateDB.SetState(precompileAddr, lastKey, common.Hash{})
+	stateDB.SetState(precompileAddr, indexKey, common.Hash{})
+	stateDB.SetState(precompileAddr, membersKey, common.BigToHash(new(big.Int).SetUint64(last)))
+}
+
+// updateRoleMembers moves [address] from the members of [oldRole] to the members of [newRole].
+func updateRoleMembers(stateDB contract.StateDB, precompileAddr common.Address, address common.Address, oldRole Role, newRole Role) {
+	if oldRole == newRole {
+		return
+	}
+	if !oldRole.IsNoRole() {
+		removeRoleMember(stateDB, precompileAddr, oldRole, address)
+	}
+	if !newRole.IsNoRole() {
+		addRoleMember(stateDB, precompileAddr, newRole, address)
+	}
+}
+
+// PackGetRoleMemberCount packs [role] into the input data to the getRoleMemberCount function
+func PackGetRoleMemberCount(role Role) ([]byte, error) {
+	return AllowListABI.Pack("getRoleMemberCount", role.Big())
+}
+
+// UnpackGetRoleMemberCountInput attempts to unpack [input] into the role argument of getRoleMemberCount
+func UnpackGetRoleMemberCountInput(input []byte) (Role, error) {
+	res, err := AllowListABI.UnpackInput("getRoleMemberCount", input, false)
+	if err != nil {
+		return Role{}, err
+	}
+	return FromBig(*abi.ConvertType(res[0], new(*big.Int)).(**big.Int))
+}
+
+func PackGetRoleMemberCountOutput(count *big.Int) ([]byte, error) {
+	return AllowListABI.PackOutput("getRoleMemberCount", count)
+}
+
+// GetRoleMembersInput is the input of the getRoleMembers function.
+type GetRoleMembersInput struct {
+	Role   *big.Int
+	Offset *big.Int
+	Limit  *big.Int
+}
+
+// PackGetRoleMembers packs [role], [offset] and [limit] into the input data to the getRoleMembers function
+func PackGetRoleMembers(role Role, offset *big.Int, limit *big.Int) ([]byte, error) {
+	return AllowListABI.Pack("getRoleMembers", role.Big(), offset, limit)
+}
+
+// UnpackGetRoleMembersInput attempts to unpack [input] as GetRoleMembersInput
+func UnpackGetRoleMembersInput(input []byte) (GetRoleMembersInput, error) {
+	inputStruct := GetRoleMembersInput{}
+	err := AllowListABI.UnpackInputIntoInterface(&inputStruct, "getRoleMembers", input, false)
+	return inputStruct, err
+}
+
+func PackGetRoleMembersOutput(members []common.Address) ([]byte, error) {
+	return AllowListABI.PackOutput("getRoleMembers", members)
+}
+
+// createGetRoleMemberCount returns an execution function that returns the number of addresses
+// with the given role for the given [precompileAddr].
+func createGetRoleMemberCount(precompileAddr common.Address) contract.RunStatefulPrecompileFunc {
+	return func(evm contract.AccessibleState, callerAddr common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		gasCost := contract.GetGasCost(evm, precompileAddr, ReadAllowListGasCostKey, ReadAllowListGasCost)
+		if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
+			return nil, 0, err
+		}
+
+		role, err := UnpackGetRoleMemberCountInput(input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		count := GetRoleMemberCount(evm.GetStateDB(), precompileAddr, role)
+		packedOutput, err := PackGetRoleMemberCountOutput(new(big.Int).SetUint64(count))
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		return packedOutput, remainingGas, nil
+	}
+}
+
+// createGetRoleMembers returns an execution function that returns a page of the addresses
+// with the given role for the given [precompileAddr]. Each returned address costs
+// [ReadRoleMemberGasCost] on top of the cost of reading the number of members.
+func createGetRoleMembers(precompileAddr common.Address) contract.RunStatefulPrecompileFunc {
+	return func(evm contract.AccessibleState, callerAddr common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		gasCost := contract.GetGasCost(evm, precompileAddr, ReadAllowListGasCostKey, ReadAllowListGasCost)
+		if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
+			return nil, 0, err
+		}
+
+		inputStruct, err := UnpackGetRoleMembersInput(input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		role, err := FromBig(inputStruct.Role)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		stateDB := evm.GetStateDB()
+		count := GetRoleMemberCount(stateDB, precompileAddr, role)
+		var offset, limit uint64
+		if inputStruct.Offset.IsUint64() && inputStruct.Offset.Uint64() < count {
+			offset = inputStruct.Offset.Uint64()
+			limit = count - offset
+			if inputStruct.Limit.IsUint64() {
+				limit = min(limit, inputStruct.Limit.Uint64())
+			}
+		}
+		// [limit] is at most the number of members, so this cannot overflow.
+		if remainingGas, err = contract.DeductGas(remainingGas, limit*ReadRoleMemberGasCost); err != nil {
+			return nil, 0, err
+		}
+
+		packedOutput, err := PackGetRoleMembersOutput(GetRoleMembers(stateDB, precompileAddr, role, offset, limit))
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		return packedOutput, remainingGas, nil
+	}
+}
+
+// createEnumerableMembersActivator returns an activation function that enables the
+// enumeration functions only if the precompile at [precompileAddr] maintains the
+// enumerable members index.
+func createEnumerableMembersActivator(precompileAddr common.Address) contract.ActivationFunc {
+	return func(evm contract.AccessibleState) bool {
+		return IsEnumerableMembersEnabled(evm.GetStateDB(), precompileAddr)
+	}
+}
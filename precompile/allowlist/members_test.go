@@ -0,0 +1,49 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package allowlist
+
+import (
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnumerableMembers(t *testing.T) {
+	require := require.New(t)
+	stateDB := state.NewTestStateDB(t)
+	addrs := []common.Address{{1}, {2}, {3}, {4}}
+
+	// Roles assigned before the index is enabled are not enumerated.
+	SetAllowListRole(stateDB, dummyAddr, addrs[0], AdminRole)
+	require.Zero(GetRoleMemberCount(stateDB, dummyAddr, AdminRole))
+
+	EnableEnumerableMembers(stateDB, dummyAddr)
+	for _, addr := range addrs[1:] {
+		SetAllowListRole(stateDB, dummyAddr, addr, EnabledRole)
+	}
+	require.Equal(addrs[1:], GetRoleMembers(stateDB, dummyAddr, EnabledRole, 0, 10))
+	require.Equal(addrs[2:3], GetRoleMembers(stateDB, dummyAddr, EnabledRole, 1, 1))
+	require.Empty(GetRoleMembers(stateDB, dummyAddr, EnabledRole, 3, 10))
+
+	// Setting the same role again does not duplicate the member.
+	SetAllowListRole(stateDB, dummyAddr, addrs[3], EnabledRole)
+	require.Equal(uint64(3), GetRoleMemberCount(stateDB, dummyAddr, EnabledRole))
+
+	// Removing a member moves the last member into its slot.
+	SetAllowListRole(stateDB, dummyAddr, addrs[1], ManagerRole)
+	require.Equal([]common.Address{addrs[3], addrs[2]}, GetRoleMembers(stateDB, dummyAddr, EnabledRole, 0, 10))
+	require.Equal([]common.Address{addrs[1]}, GetRoleMembers(stateDB, dummyAddr, ManagerRole, 0, 10))
+
+	SetAllowListRole(stateDB, dummyAddr, addrs[2], NoRole)
+	require.Equal([]common.Address{addrs[3]}, GetRoleMembers(stateDB, dummyAddr, EnabledRole, 0, 10))
+	SetAllowListRole(stateDB, dummyAddr, addrs[3], NoRole)
+	require.Empty(GetRoleMembers(stateDB, dummyAddr, EnabledRole, 0, 10))
+
+	// Removing a member that is not indexed leaves the index untouched.
+	SetAllowListRole(stateDB, dummyAddr, addrs[0], NoRole)
+	require.Zero(GetRoleMemberCount(stateDB, dummyAddr, AdminRole))
+	require.Equal([]common.Address{addrs[1]}, GetRoleMembers(stateDB, dummyAddr, ManagerRole, 0, 10))
+}
@@ -6,6 +6,7 @@
 package allowlist
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/ava-labs/subnet-evm/precompile/contract"
@@ -581,6 +582,152 @@ func AllowListTests(t testing.TB, module modules.Module) map[string]testutils.Pr
 				require.Equal(t, EnabledRole, GetAllowListStatus(state, contractAddress, TestNoRoleAddr))
 			},
 		},
+		"initial config sets enumerable members": {
+			Config: mkConfigWithAllowList(
+				module,
+				&AllowListConfig{
+					AdminAddresses:    []common.Address{TestAdminAddr},
+					EnabledAddresses:  []common.Address{TestNoRoleAddr, TestEnabledAddr},
+					EnumerableMembers: true,
+				},
+			),
+			SuppliedGas: 0,
+			ReadOnly:    false,
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.True(t, IsEnumerableMembersEnabled(state, contractAddress))
+				require.Equal(t, []common.Address{TestAdminAddr}, GetRoleMembers(state, contractAddress, AdminRole, 0, 10))
+				require.Equal(t, []common.Address{TestNoRoleAddr, TestEnabledAddr}, GetRoleMembers(state, contractAddress, EnabledRole, 0, 10))
+				require.Zero(t, GetRoleMemberCount(state, contractAddress, ManagerRole))
+			},
+		},
+		"admin set enabled to admin with enumerable members": {
+			Caller:     TestAdminAddr,
+			BeforeHook: SetDefaultEnumerableRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackModifyAllowList(TestEnabledAddr, AdminRole)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ModifyAllowListGasCost + ModifyEnumerableMembersGasCost + AllowListEventGasCost,
+			ReadOnly:    false,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.Equal(t, []common.Address{TestAdminAddr, TestEnabledAddr}, GetRoleMembers(state, contractAddress, AdminRole, 0, 10))
+				require.Zero(t, GetRoleMemberCount(state, contractAddress, EnabledRole))
+			},
+		},
+		"admin set no role with enumerable members": {
+			Caller:     TestAdminAddr,
+			BeforeHook: SetDefaultEnumerableRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackModifyAllowList(TestManagerAddr, NoRole)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ModifyAllowListGasCost + ModifyEnumerableMembersGasCost + AllowListEventGasCost,
+			ReadOnly:    false,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.Zero(t, GetRoleMemberCount(state, contractAddress, ManagerRole))
+				require.Equal(t, []common.Address{TestAdminAddr}, GetRoleMembers(state, contractAddress, AdminRole, 0, 10))
+			},
+		},
+		"admin set enabled with enumerable members insufficient gas": {
+			Caller:     TestAdminAddr,
+			BeforeHook: SetDefaultEnumerableRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackModifyAllowList(TestNoRoleAddr, EnabledRole)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ModifyAllowListGasCost + ModifyEnumerableMembersGasCost + AllowListEventGasCost - 1,
+			ReadOnly:    false,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"get role member count": {
+			Caller:     TestNoRoleAddr,
+			BeforeHook: SetDefaultEnumerableRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackGetRoleMemberCount(AdminRole)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ReadAllowListGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackGetRoleMemberCountOutput(common.Big1)
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"get role members": {
+			Caller:     TestNoRoleAddr,
+			BeforeHook: SetDefaultEnumerableRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackGetRoleMembers(EnabledRole, common.Big0, common.Big2)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ReadAllowListGasCost + ReadRoleMemberGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackGetRoleMembersOutput([]common.Address{TestEnabledAddr})
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"get role members out of range": {
+			Caller:     TestNoRoleAddr,
+			BeforeHook: SetDefaultEnumerableRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackGetRoleMembers(EnabledRole, common.Big2, common.Big2)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ReadAllowListGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackGetRoleMembersOutput([]common.Address{})
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"get role members insufficient gas": {
+			Caller:     TestNoRoleAddr,
+			BeforeHook: SetDefaultEnumerableRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackGetRoleMembers(EnabledRole, common.Big0, common.Big2)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ReadAllowListGasCost + ReadRoleMemberGasCost - 1,
+			ReadOnly:    true,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"get role members invalid role": {
+			Caller:     TestNoRoleAddr,
+			BeforeHook: SetDefaultEnumerableRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := AllowListABI.Pack("getRoleMembers", big.NewInt(4), common.Big0, common.Big2)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ReadAllowListGasCost,
+			ReadOnly:    true,
+			ExpectedErr: ErrInvalidRole.Error(),
+		},
+		"get role members without enumerable members": {
+			Caller:     TestNoRoleAddr,
+			BeforeHook: SetDefaultRoles(contractAddress),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackGetRoleMembers(EnabledRole, common.Big0, common.Big2)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: 0,
+			ReadOnly:    true,
+			ExpectedErr: "invalid non-activated function selector",
+		},
 		"admin set admin pre-Durango": {
 			Caller:     TestAdminAddr,
 			BeforeHook: SetDefaultRoles(contractAddress),
@@ -667,6 +814,15 @@ func SetDefaultRoles(contractAddress common.Address) func(t testing.TB, state co
 	}
 }
 
+// SetDefaultEnumerableRoles returns a BeforeHook that enables the enumerable members
+// index and then sets the same roles as [SetDefaultRoles].
+func SetDefaultEnumerableRoles(contractAddress common.Address) func(t testing.TB, state contract.StateDB) {
+	return func(t testing.TB, state contract.StateDB) {
+		EnableEnumerableMembers(state, contractAddress)
+		SetDefaultRoles(contractAddress)(t, state)
+	}
+}
+
 func RunPrecompileWithAllowListTests(t *testing.T, module modules.Module, newStateDB func(t testing.TB) contract.StateDB, contractTests map[string]testutils.PrecompileTest) {
 	t.Helper()
 	tests := AllowListTests(t, module)
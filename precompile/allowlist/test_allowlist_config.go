@@ -190,6 +190,16 @@ func AllowListConfigEqualTests(t testing.TB, module modules.Module) map[string]t
 			}),
 			Expected: false,
 		},
+		"allowlist different enumerable members": {
+			Config: mkConfigWithAllowList(module, &AllowListConfig{
+				AdminAddresses:    []common.Address{TestAdminAddr},
+				EnumerableMembers: true,
+			}),
+			Other: mkConfigWithAllowList(module, &AllowListConfig{
+				AdminAddresses: []common.Address{TestAdminAddr},
+			}),
+			Expected: false,
+		},
 		"allowlist same config": {
 			Config: mkConfigWithAllowList(module, &AllowListConfig{
 				AdminAddresses:   []common.Address{TestAdminAddr},
@@ -28,6 +28,7 @@ type StateDB interface {
 
 	GetBalance(common.Address) *big.Int
 	AddBalance(common.Address, *big.Int)
+	SubBalance(common.Address, *big.Int)
 
 	CreateAccount(common.Address)
 	Exist(common.Address) bool
@@ -62,6 +63,18 @@ type BlockContext interface {
 	// GetResults returns an arbitrary byte array result of verifying the predicates
 	// of the given transaction, precompile address pair.
 	GetPredicateResults(txHash common.Hash, precompileAddress common.Address) []byte
+	// GetAncestorHeader returns the header of the ancestor of the current block
+	// at [number], or nil if it is not an ancestor or is not available locally.
+	GetAncestorHeader(number uint64) *AncestorHeader
+}
+
+// AncestorHeader contains the fields of the header of an ancestor block
+// exposed to stateful precompiles.
+type AncestorHeader struct {
+	Hash    common.Hash
+	Time    uint64
+	BaseFee *big.Int // nil before the SubnetEVM upgrade
+	Root    common.Hash
 }
 
 type Configurator interface {
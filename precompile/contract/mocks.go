@@ -42,6 +42,20 @@ func (m *MockBlockContext) EXPECT() *MockBlockContextMockRecorder {
 	return m.recorder
 }
 
+// GetAncestorHeader mocks base method.
+func (m *MockBlockContext) GetAncestorHeader(arg0 uint64) *AncestorHeader {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAncestorHeader", arg0)
+	ret0, _ := ret[0].(*AncestorHeader)
+	return ret0
+}
+
+// GetAncestorHeader indicates an expected call of GetAncestorHeader.
+func (mr *MockBlockContextMockRecorder) GetAncestorHeader(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAncestorHeader", reflect.TypeOf((*MockBlockContext)(nil).GetAncestorHeader), arg0)
+}
+
 // GetPredicateResults mocks base method.
 func (m *MockBlockContext) GetPredicateResults(arg0 common.Hash, arg1 common.Address) []byte {
 	m.ctrl.T.Helper()
@@ -370,6 +384,18 @@ func (mr *MockStateDBMockRecorder) SetState(arg0, arg1, arg2 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetState", reflect.TypeOf((*MockStateDB)(nil).SetState), arg0, arg1, arg2)
 }
 
+// SubBalance mocks base method.
+func (m *MockStateDB) SubBalance(arg0 common.Address, arg1 *big.Int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubBalance", arg0, arg1)
+}
+
+// SubBalance indicates an expected call of SubBalance.
+func (mr *MockStateDBMockRecorder) SubBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubBalance", reflect.TypeOf((*MockStateDB)(nil).SubBalance), arg0, arg1)
+}
+
 // Snapshot mocks base method.
 func (m *MockStateDB) Snapshot() int {
 	m.ctrl.T.Helper()
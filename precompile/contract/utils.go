@@ -9,7 +9,9 @@ import (
 	"strings"
 
 	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -62,3 +64,18 @@ func ParseABI(rawABI string) abi.ABI {
 func IsDurangoActivated(evm AccessibleState) bool {
 	return evm.GetChainConfig().IsDurango(evm.GetBlockContext().Timestamp())
 }
+
+// GetGasCost returns the gas cost of the operation [name] of the precompile at
+// [address], as overridden by the config of the precompile active in the
+// current block, or [defaultCost] if it is not overridden.
+func GetGasCost(evm AccessibleState, address common.Address, name string, defaultCost uint64) uint64 {
+	getter, ok := evm.GetChainConfig().(precompileconfig.ActiveConfigGetter)
+	if !ok {
+		return defaultCost
+	}
+	config, ok := getter.GetActivePrecompileConfig(address, evm.GetBlockContext().Timestamp()).(precompileconfig.GasConfig)
+	if !ok {
+		return defaultCost
+	}
+	return config.GetGasCost(name, defaultCost)
+}
@@ -0,0 +1,230 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blockhistory
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+
+	_ "embed"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// HistoryWindow is the number of the most recent ancestors of the current
+	// block whose headers are served. If the precompile is configured, state
+	// sync fetches the blocks in this window along with the block it syncs to,
+	// and nodes fetch the headers missing from it when they start, so the
+	// result of a call only depends on the chain.
+	HistoryWindow = 8191
+
+	// Reading a header from the database is priced as reading a storage slot.
+	GetBlockHashGasCost   uint64 = contract.ReadGasCostPerSlot
+	GetBlockHeaderGasCost uint64 = contract.ReadGasCostPerSlot
+)
+
+var (
+	// ErrNotAncestor is returned when the requested block is not an ancestor
+	// of the current block.
+	ErrNotAncestor = errors.New("block is not an ancestor of the current block")
+	// ErrHistoryUnavailable is returned when the requested block is an
+	// ancestor of the current block older than the HistoryWindow.
+	ErrHistoryUnavailable = errors.New("block history is unavailable")
+	// ErrHistoryMissing is returned when the header of an ancestor within the
+	// HistoryWindow is not yet available to this node.
+	ErrHistoryMissing = errors.New("block history is missing")
+)
+
+// Singleton StatefulPrecompiledContract and signatures.
+var (
+	// BlockHistoryRawABI contains the raw ABI of BlockHistory contract.
+	//go:embed contract.abi
+	BlockHistoryRawABI string
+
+	BlockHistoryABI = contract.ParseABI(BlockHistoryRawABI)
+
+	BlockHistoryPrecompile = createBlockHistoryPrecompile()
+)
+
+type GetBlockHeaderOutput struct {
+	BlockHash common.Hash
+	Timestamp *big.Int
+	BaseFee   *big.Int
+	StateRoot common.Hash
+}
+
+// UnpackGetBlockHashInput attempts to unpack [input] into the *big.Int type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackGetBlockHashInput(input []byte) (*big.Int, error) {
+	res, err := BlockHistoryABI.UnpackInput("getBlockHash", input, false)
+	if err != nil {
+		return nil, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(*big.Int)).(**big.Int)
+	return unpacked, nil
+}
+
+// PackGetBlockHash packs [blockNumber] of type *big.Int into the appropriate arguments for getBlockHash.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGetBlockHash(blockNumber *big.Int) ([]byte, error) {
+	return BlockHistoryABI.Pack("getBlockHash", blockNumber)
+}
+
+// PackGetBlockHashOutput attempts to pack given blockHash of type common.Hash
+// to conform the ABI outputs.
+func PackGetBlockHashOutput(blockHash common.Hash) ([]byte, error) {
+	return BlockHistoryABI.PackOutput("getBlockHash", blockHash)
+}
+
+// UnpackGetBlockHashOutput attempts to unpack given [output] into the common.Hash type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackGetBlockHashOutput(output []byte) (common.Hash, error) {
+	res, err := BlockHistoryABI.Unpack("getBlockHash", output)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(common.Hash)).(*common.Hash)
+	return unpacked, nil
+}
+
+// getBlockHash returns the hash of the ancestor of the current block at the given height.
+func getBlockHash(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, GetBlockHashGasCost); err != nil {
+		return nil, 0, err
+	}
+	blockNumber, err := UnpackGetBlockHashInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	header, err := getAncestorHeader(accessibleState.GetBlockContext(), blockNumber)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	packedOutput, err := PackGetBlockHashOutput(header.Hash)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// UnpackGetBlockHeaderInput attempts to unpack [input] into the *big.Int type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackGetBlockHeaderInput(input []byte) (*big.Int, error) {
+	res, err := BlockHistoryABI.UnpackInput("getBlockHeader", input, false)
+	if err != nil {
+		return nil, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(*big.Int)).(**big.Int)
+	return unpacked, nil
+}
+
+// PackGetBlockHeader packs [blockNumber] of type *big.Int into the appropriate arguments for getBlockHeader.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGetBlockHeader(blockNumber *big.Int) ([]byte, error) {
+	return BlockHistoryABI.Pack("getBlockHeader", blockNumber)
+}
+
+// PackGetBlockHeaderOutput attempts to pack given [outputStruct] of type GetBlockHeaderOutput
+// to conform the ABI outputs.
+func PackGetBlockHeaderOutput(outputStruct GetBlockHeaderOutput) ([]byte, error) {
+	return BlockHistoryABI.PackOutput("getBlockHeader",
+		outputStruct.BlockHash,
+		outputStruct.Timestamp,
+		outputStruct.BaseFee,
+		outputStruct.StateRoot,
+	)
+}
+
+// UnpackGetBlockHeaderOutput attempts to unpack [output] as GetBlockHeaderOutput
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackGetBlockHeaderOutput(output []byte) (GetBlockHeaderOutput, error) {
+	outputStruct := GetBlockHeaderOutput{}
+	err := BlockHistoryABI.UnpackIntoInterface(&outputStruct, "getBlockHeader", output)
+	return outputStruct, err
+}
+
+// getBlockHeader returns the hash, timestamp, base fee and state root of the
+// ancestor of the current block at the given height. The base fee is 0 for
+// blocks before the SubnetEVM upgrade.
+func getBlockHeader(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, GetBlockHeaderGasCost); err != nil {
+		return nil, 0, err
+	}
+	blockNumber, err := UnpackGetBlockHeaderInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	header, err := getAncestorHeader(accessibleState.GetBlockContext(), blockNumber)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	output := GetBlockHeaderOutput{
+		BlockHash: header.Hash,
+		Timestamp: new(big.Int).SetUint64(header.Time),
+		BaseFee:   new(big.Int),
+		StateRoot: header.Root,
+	}
+	if header.BaseFee != nil {
+		output.BaseFee.Set(header.BaseFee)
+	}
+	packedOutput, err := PackGetBlockHeaderOutput(output)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// getAncestorHeader returns the header of the ancestor of the current block at
+// [blockNumber], or fails with ErrHistoryUnavailable if it is older than the
+// HistoryWindow. It fails with ErrHistoryMissing if the header is within the
+// window but this node has not fetched it yet.
+func getAncestorHeader(blockContext contract.BlockContext, blockNumber *big.Int) (*contract.AncestorHeader, error) {
+	current := blockContext.Number()
+	if !blockNumber.IsUint64() || blockNumber.Cmp(current) >= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrNotAncestor, blockNumber)
+	}
+	if number := current.Uint64(); number > HistoryWindow && blockNumber.Uint64() < number-HistoryWindow {
+		return nil, fmt.Errorf("%w: %d", ErrHistoryUnavailable, blockNumber)
+	}
+	header := blockContext.GetAncestorHeader(blockNumber.Uint64())
+	if header == nil {
+		// The call reverts on this node only, so the block fails verification
+		// here instead of crashing the node while the headers are fetched.
+		return nil, fmt.Errorf("%w: %d", ErrHistoryMissing, blockNumber)
+	}
+	return header, nil
+}
+
+// createBlockHistoryPrecompile returns a StatefulPrecompiledContract with the getters of the block history.
+func createBlockHistoryPrecompile() contract.StatefulPrecompiledContract {
+	var functions []*contract.StatefulPrecompileFunction
+
+	abiFunctionMap := map[string]contract.RunStatefulPrecompileFunc{
+		"getBlockHash":   getBlockHash,
+		"getBlockHeader": getBlockHeader,
+	}
+
+	for name, function := range abiFunctionMap {
+		method, ok := BlockHistoryABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, contract.NewStatefulPrecompileFunction(method.ID, function))
+	}
+	// Construct the contract with no fallback function.
+	statefulContract, err := contract.NewStatefulPrecompileContract(nil, functions)
+	if err != nil {
+		panic(err)
+	}
+	return statefulContract
+}
@@ -0,0 +1,129 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blockhistory
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/testutils"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBlockHistory(t *testing.T) {
+	ancestor := &contract.AncestorHeader{
+		Hash:    common.HexToHash("0x01"),
+		Time:    1000,
+		BaseFee: big.NewInt(25_000_000_000),
+		Root:    common.HexToHash("0x02"),
+	}
+	// The oldest block in the history window of block 10000 is 1809.
+	setupBlockContext := func(mbc *contract.MockBlockContext) {
+		mbc.EXPECT().Number().Return(big.NewInt(10000)).AnyTimes()
+		mbc.EXPECT().GetAncestorHeader(uint64(9000)).Return(ancestor).AnyTimes()
+		mbc.EXPECT().GetAncestorHeader(uint64(1809)).Return(ancestor).AnyTimes()
+	}
+	packGetBlockHash := func(blockNumber int64) func(t testing.TB) []byte {
+		return func(t testing.TB) []byte {
+			input, err := PackGetBlockHash(big.NewInt(blockNumber))
+			require.NoError(t, err)
+			return input
+		}
+	}
+	packGetBlockHeader := func(blockNumber int64) func(t testing.TB) []byte {
+		return func(t testing.TB) []byte {
+			input, err := PackGetBlockHeader(big.NewInt(blockNumber))
+			require.NoError(t, err)
+			return input
+		}
+	}
+
+	tests := map[string]testutils.PrecompileTest{
+		"getBlockHash": {
+			InputFn:           packGetBlockHash(9000),
+			SuppliedGas:       GetBlockHashGasCost,
+			ReadOnly:          true,
+			SetupBlockContext: setupBlockContext,
+			ExpectedRes: func() []byte {
+				output, err := PackGetBlockHashOutput(ancestor.Hash)
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"getBlockHash current block": {
+			InputFn:           packGetBlockHash(10000),
+			SuppliedGas:       GetBlockHashGasCost,
+			SetupBlockContext: setupBlockContext,
+			ExpectedErr:       ErrNotAncestor.Error(),
+		},
+		"getBlockHash unavailable history": {
+			InputFn:           packGetBlockHash(1808),
+			SuppliedGas:       GetBlockHashGasCost,
+			SetupBlockContext: setupBlockContext,
+			ExpectedErr:       ErrHistoryUnavailable.Error(),
+		},
+		"getBlockHash oldest in window": {
+			InputFn:           packGetBlockHash(1809),
+			SuppliedGas:       GetBlockHashGasCost,
+			ReadOnly:          true,
+			SetupBlockContext: setupBlockContext,
+			ExpectedRes: func() []byte {
+				output, err := PackGetBlockHashOutput(ancestor.Hash)
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"getBlockHash insufficient gas": {
+			InputFn:           packGetBlockHash(9000),
+			SuppliedGas:       GetBlockHashGasCost - 1,
+			SetupBlockContext: setupBlockContext,
+			ExpectedErr:       vmerrs.ErrOutOfGas.Error(),
+		},
+		"getBlockHeader": {
+			InputFn:           packGetBlockHeader(9000),
+			SuppliedGas:       GetBlockHeaderGasCost,
+			ReadOnly:          true,
+			SetupBlockContext: setupBlockContext,
+			ExpectedRes: func() []byte {
+				output, err := PackGetBlockHeaderOutput(GetBlockHeaderOutput{
+					BlockHash: ancestor.Hash,
+					Timestamp: big.NewInt(1000),
+					BaseFee:   ancestor.BaseFee,
+					StateRoot: ancestor.Root,
+				})
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"getBlockHeader future block": {
+			InputFn:           packGetBlockHeader(20000),
+			SuppliedGas:       GetBlockHeaderGasCost,
+			SetupBlockContext: setupBlockContext,
+			ExpectedErr:       ErrNotAncestor.Error(),
+		},
+		"getBlockHeader unavailable history": {
+			InputFn:           packGetBlockHeader(1808),
+			SuppliedGas:       GetBlockHeaderGasCost,
+			SetupBlockContext: setupBlockContext,
+			ExpectedErr:       ErrHistoryUnavailable.Error(),
+		},
+	}
+
+	testutils.RunPrecompileTests(t, Module, state.NewTestStateDB, tests)
+}
+
+func TestBlockHistoryMissingHeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	blockContext := contract.NewMockBlockContext(ctrl)
+	blockContext.EXPECT().Number().Return(big.NewInt(100)).AnyTimes()
+	blockContext.EXPECT().GetAncestorHeader(uint64(0)).Return(nil)
+
+	_, err := getAncestorHeader(blockContext, big.NewInt(0))
+	require.ErrorIs(t, err, ErrHistoryMissing)
+}
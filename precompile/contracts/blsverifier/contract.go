@@ -0,0 +1,331 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blsverifier
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+
+	_ "embed"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// Gas costs are priced as the final EIP-2537 schedule. Verifying a signature
+// checks 2 pairings, after hashing the message to G2, which maps 2 field
+// elements to G2 and adds the resulting points.
+const (
+	PairingBaseGasCost    uint64 = 37_700 // Base cost of a pairing check
+	GasCostPerPairing     uint64 = 32_600 // Cost of each pairing of a pairing check
+	HashToCurveGasCost    uint64 = 48_200 // Cost of hashing a message to G2
+	GasCostPerMessageWord uint64 = 12     // Cost of hashing each word of a message
+
+	// GasCostPerPublicKey is the cost of decompressing, validating and
+	// aggregating a public key. The subgroup check dominates, and benchmarks
+	// slightly slower than an ecrecover, priced at 3_000.
+	GasCostPerPublicKey uint64 = 4_000
+
+	// AggregatePublicKeysGasCost is the cost of aggregatePublicKeys, excluding
+	// the cost of its public keys.
+	AggregatePublicKeysGasCost uint64 = 2_000
+
+	// VerifyGasCost is the cost of verifying a signature, excluding the cost
+	// of its public keys and the hashing of its message.
+	VerifyGasCost uint64 = PairingBaseGasCost + 2*GasCostPerPairing + HashToCurveGasCost
+)
+
+var (
+	errNoPublicKeys      = errors.New("no public keys to aggregate")
+	errInvalidPublicKey  = errors.New("invalid public key")
+	errOverflowGasCost   = errors.New("overflow calculating BLS verification gas cost")
+	errInvalidVerifyData = errors.New("invalid BLS verification input")
+)
+
+// publicKeyInputSize is the minimum size of a compressed public key in the
+// ABI encoding of a bytes[]: its offset, its length and 2 words of data.
+const publicKeyInputSize = 4 * common.HashLength
+
+// Singleton StatefulPrecompiledContract and signatures.
+var (
+	// BLSVerifierRawABI contains the raw ABI of BLSVerifier contract.
+	//go:embed contract.abi
+	BLSVerifierRawABI string
+
+	BLSVerifierABI = contract.ParseABI(BLSVerifierRawABI)
+
+	BLSVerifierPrecompile = createBLSVerifierPrecompile()
+)
+
+type VerifyInput struct {
+	PublicKey []byte
+	Message   []byte
+	Signature []byte
+}
+
+type AggregateVerifyInput struct {
+	PublicKeys [][]byte
+	Message    []byte
+	Signature  []byte
+}
+
+// UnpackVerifyInput attempts to unpack [input] as VerifyInput
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackVerifyInput(input []byte) (VerifyInput, error) {
+	inputStruct := VerifyInput{}
+	err := BLSVerifierABI.UnpackInputIntoInterface(&inputStruct, "verify", input, false)
+	return inputStruct, err
+}
+
+// PackVerify packs [inputStruct] of type VerifyInput into the appropriate arguments for verify.
+// This function is mostly used for tests.
+func PackVerify(inputStruct VerifyInput) ([]byte, error) {
+	return BLSVerifierABI.Pack("verify", inputStruct.PublicKey, inputStruct.Message, inputStruct.Signature)
+}
+
+// PackVerifyOutput attempts to pack given valid of type bool
+// to conform the ABI outputs.
+func PackVerifyOutput(valid bool) ([]byte, error) {
+	return BLSVerifierABI.PackOutput("verify", valid)
+}
+
+// UnpackVerifyOutput attempts to unpack given [output] into the bool type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackVerifyOutput(output []byte) (bool, error) {
+	res, err := BLSVerifierABI.Unpack("verify", output)
+	if err != nil {
+		return false, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(bool)).(*bool)
+	return unpacked, nil
+}
+
+// verify returns whether [Signature] is a valid signature of [Message] by
+// [PublicKey]. Malformed public keys and signatures are reported as invalid.
+func verify(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, VerifyGasCost+GasCostPerPublicKey); err != nil {
+		return nil, 0, err
+	}
+	inputStruct, err := UnpackVerifyInput(input)
+	if err != nil {
+		return nil, remainingGas, fmt.Errorf("%w: %w", errInvalidVerifyData, err)
+	}
+	if remainingGas, err = deductMessageGas(remainingGas, inputStruct.Message); err != nil {
+		return nil, 0, err
+	}
+
+	valid := false
+	if publicKey, err := bls.PublicKeyFromCompressedBytes(inputStruct.PublicKey); err == nil {
+		valid = verifySignature(publicKey, inputStruct.Message, inputStruct.Signature)
+	}
+	packedOutput, err := PackVerifyOutput(valid)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// UnpackAggregateVerifyInput attempts to unpack [input] as AggregateVerifyInput
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackAggregateVerifyInput(input []byte) (AggregateVerifyInput, error) {
+	inputStruct := AggregateVerifyInput{}
+	err := BLSVerifierABI.UnpackInputIntoInterface(&inputStruct, "aggregateVerify", input, false)
+	return inputStruct, err
+}
+
+// PackAggregateVerify packs [inputStruct] of type AggregateVerifyInput into the appropriate arguments for aggregateVerify.
+// This function is mostly used for tests.
+func PackAggregateVerify(inputStruct AggregateVerifyInput) ([]byte, error) {
+	return BLSVerifierABI.Pack("aggregateVerify", inputStruct.PublicKeys, inputStruct.Message, inputStruct.Signature)
+}
+
+// PackAggregateVerifyOutput attempts to pack given valid of type bool
+// to conform the ABI outputs.
+func PackAggregateVerifyOutput(valid bool) ([]byte, error) {
+	return BLSVerifierABI.PackOutput("aggregateVerify", valid)
+}
+
+// UnpackAggregateVerifyOutput attempts to unpack given [output] into the bool type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackAggregateVerifyOutput(output []byte) (bool, error) {
+	res, err := BLSVerifierABI.Unpack("aggregateVerify", output)
+	if err != nil {
+		return false, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(bool)).(*bool)
+	return unpacked, nil
+}
+
+// aggregateVerify returns whether [Signature] is a valid aggregate signature of
+// [Message] by all of [PublicKeys], as warp verifies the signatures of its
+// validators. Malformed public keys and signatures are reported as invalid.
+func aggregateVerify(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, VerifyGasCost); err != nil {
+		return nil, 0, err
+	}
+	inputStruct, err := UnpackAggregateVerifyInput(input)
+	if err != nil {
+		return nil, remainingGas, fmt.Errorf("%w: %w", errInvalidVerifyData, err)
+	}
+	if remainingGas, err = deductPublicKeysGas(remainingGas, len(inputStruct.PublicKeys)); err != nil {
+		return nil, 0, err
+	}
+	if remainingGas, err = deductMessageGas(remainingGas, inputStruct.Message); err != nil {
+		return nil, 0, err
+	}
+
+	valid := false
+	if publicKey, err := aggregatePublicKeyBytes(inputStruct.PublicKeys); err == nil {
+		valid = verifySignature(publicKey, inputStruct.Message, inputStruct.Signature)
+	}
+	packedOutput, err := PackAggregateVerifyOutput(valid)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// UnpackAggregatePublicKeysInput attempts to unpack [input] into the [][]byte type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackAggregatePublicKeysInput(input []byte) ([][]byte, error) {
+	res, err := BLSVerifierABI.UnpackInput("aggregatePublicKeys", input, false)
+	if err != nil {
+		return nil, err
+	}
+	unpacked := *abi.ConvertType(res[0], new([][]byte)).(*[][]byte)
+	return unpacked, nil
+}
+
+// PackAggregatePublicKeys packs [publicKeys] of type [][]byte into the appropriate arguments for aggregatePublicKeys.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackAggregatePublicKeys(publicKeys [][]byte) ([]byte, error) {
+	return BLSVerifierABI.Pack("aggregatePublicKeys", publicKeys)
+}
+
+// PackAggregatePublicKeysOutput attempts to pack given aggregatePublicKey of type []byte
+// to conform the ABI outputs.
+func PackAggregatePublicKeysOutput(aggregatePublicKey []byte) ([]byte, error) {
+	return BLSVerifierABI.PackOutput("aggregatePublicKeys", aggregatePublicKey)
+}
+
+// UnpackAggregatePublicKeysOutput attempts to unpack given [output] into the []byte type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackAggregatePublicKeysOutput(output []byte) ([]byte, error) {
+	res, err := BLSVerifierABI.Unpack("aggregatePublicKeys", output)
+	if err != nil {
+		return nil, err
+	}
+	unpacked := *abi.ConvertType(res[0], new([]byte)).(*[]byte)
+	return unpacked, nil
+}
+
+// aggregatePublicKeys returns the compressed aggregate of the compressed
+// public keys of [input]. It fails if any of the public keys is malformed.
+//
+// The public keys are charged before decoding [input], for as many as it can
+// hold without sharing data between them. Keys sharing data are charged once
+// decoded.
+func aggregatePublicKeys(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, AggregatePublicKeysGasCost); err != nil {
+		return nil, 0, err
+	}
+	chargedPublicKeys := len(input) / publicKeyInputSize
+	if remainingGas, err = deductPublicKeysGas(remainingGas, chargedPublicKeys); err != nil {
+		return nil, 0, err
+	}
+	publicKeys, err := UnpackAggregatePublicKeysInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	if len(publicKeys) > chargedPublicKeys {
+		if remainingGas, err = deductPublicKeysGas(remainingGas, len(publicKeys)-chargedPublicKeys); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	publicKey, err := aggregatePublicKeyBytes(publicKeys)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	packedOutput, err := PackAggregatePublicKeysOutput(bls.PublicKeyToCompressedBytes(publicKey))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// aggregatePublicKeyBytes parses and aggregates the compressed [publicKeys].
+func aggregatePublicKeyBytes(publicKeys [][]byte) (*bls.PublicKey, error) {
+	if len(publicKeys) == 0 {
+		return nil, errNoPublicKeys
+	}
+	parsed := make([]*bls.PublicKey, len(publicKeys))
+	for i, publicKeyBytes := range publicKeys {
+		publicKey, err := bls.PublicKeyFromCompressedBytes(publicKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w at index %d: %w", errInvalidPublicKey, i, err)
+		}
+		parsed[i] = publicKey
+	}
+	return bls.AggregatePublicKeys(parsed)
+}
+
+// verifySignature returns whether [signatureBytes] is a valid signature of
+// [message] by [publicKey].
+func verifySignature(publicKey *bls.PublicKey, message []byte, signatureBytes []byte) bool {
+	signature, err := bls.SignatureFromBytes(signatureBytes)
+	if err != nil {
+		return false
+	}
+	return bls.Verify(publicKey, signature, message)
+}
+
+// deductPublicKeysGas deducts the cost of [numPublicKeys] public keys from [suppliedGas].
+func deductPublicKeysGas(suppliedGas uint64, numPublicKeys int) (uint64, error) {
+	gasCost, overflow := math.SafeMul(uint64(numPublicKeys), GasCostPerPublicKey)
+	if overflow {
+		return 0, errOverflowGasCost
+	}
+	return contract.DeductGas(suppliedGas, gasCost)
+}
+
+// deductMessageGas deducts the cost of hashing [message] from [suppliedGas].
+func deductMessageGas(suppliedGas uint64, message []byte) (uint64, error) {
+	words := (uint64(len(message)) + 31) / 32
+	gasCost, overflow := math.SafeMul(words, GasCostPerMessageWord)
+	if overflow {
+		return 0, errOverflowGasCost
+	}
+	return contract.DeductGas(suppliedGas, gasCost)
+}
+
+// createBLSVerifierPrecompile returns a StatefulPrecompiledContract with the BLS verification functions.
+func createBLSVerifierPrecompile() contract.StatefulPrecompiledContract {
+	var functions []*contract.StatefulPrecompileFunction
+
+	abiFunctionMap := map[string]contract.RunStatefulPrecompileFunc{
+		"aggregatePublicKeys": aggregatePublicKeys,
+		"aggregateVerify":     aggregateVerify,
+		"verify":              verify,
+	}
+
+	for name, function := range abiFunctionMap {
+		method, ok := BLSVerifierABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, contract.NewStatefulPrecompileFunction(method.ID, function))
+	}
+	// Construct the contract with no fallback function.
+	statefulContract, err := contract.NewStatefulPrecompileContract(nil, functions)
+	if err != nil {
+		panic(err)
+	}
+	return statefulContract
+}
@@ -0,0 +1,224 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blsverifier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/precompile/testutils"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newSecretKeys(t testing.TB, n int) []*bls.SecretKey {
+	secretKeys := make([]*bls.SecretKey, n)
+	for i := range secretKeys {
+		secretKey, err := bls.NewSecretKey()
+		require.NoError(t, err)
+		secretKeys[i] = secretKey
+	}
+	return secretKeys
+}
+
+func TestVerify(t *testing.T) {
+	message := []byte("hello")
+	secretKeys := newSecretKeys(t, 2)
+	publicKey := bls.PublicKeyToCompressedBytes(bls.PublicFromSecretKey(secretKeys[0]))
+	signature := bls.SignatureToBytes(bls.Sign(secretKeys[0], message))
+	otherSignature := bls.SignatureToBytes(bls.Sign(secretKeys[1], message))
+	gas := VerifyGasCost + GasCostPerPublicKey + GasCostPerMessageWord
+
+	packVerify := func(publicKey []byte, signature []byte) func(t testing.TB) []byte {
+		return func(t testing.TB) []byte {
+			input, err := PackVerify(VerifyInput{PublicKey: publicKey, Message: message, Signature: signature})
+			require.NoError(t, err)
+			return input
+		}
+	}
+	packOutput := func(valid bool) []byte {
+		output, err := PackVerifyOutput(valid)
+		require.NoError(t, err)
+		return output
+	}
+
+	tests := map[string]testutils.PrecompileTest{
+		"valid signature": {
+			InputFn:     packVerify(publicKey, signature),
+			SuppliedGas: gas,
+			ReadOnly:    true,
+			ExpectedRes: packOutput(true),
+		},
+		"signature of another key": {
+			InputFn:     packVerify(publicKey, otherSignature),
+			SuppliedGas: gas,
+			ExpectedRes: packOutput(false),
+		},
+		"malformed public key": {
+			InputFn:     packVerify(publicKey[1:], signature),
+			SuppliedGas: gas,
+			ExpectedRes: packOutput(false),
+		},
+		"malformed signature": {
+			InputFn:     packVerify(publicKey, signature[1:]),
+			SuppliedGas: gas,
+			ExpectedRes: packOutput(false),
+		},
+		"insufficient gas": {
+			InputFn:     packVerify(publicKey, signature),
+			SuppliedGas: gas - 1,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	}
+
+	testutils.RunPrecompileTests(t, Module, state.NewTestStateDB, tests)
+}
+
+func TestAggregateVerify(t *testing.T) {
+	message := []byte("hello")
+	secretKeys := newSecretKeys(t, 3)
+	publicKeys := make([][]byte, len(secretKeys))
+	signatures := make([]*bls.Signature, len(secretKeys))
+	for i, secretKey := range secretKeys {
+		publicKeys[i] = bls.PublicKeyToCompressedBytes(bls.PublicFromSecretKey(secretKey))
+		signatures[i] = bls.Sign(secretKey, message)
+	}
+	aggregateSignature, err := bls.AggregateSignatures(signatures)
+	require.NoError(t, err)
+	signature := bls.SignatureToBytes(aggregateSignature)
+	gas := func(numPublicKeys int) uint64 {
+		return VerifyGasCost + uint64(numPublicKeys)*GasCostPerPublicKey + GasCostPerMessageWord
+	}
+
+	packAggregateVerify := func(publicKeys [][]byte) func(t testing.TB) []byte {
+		return func(t testing.TB) []byte {
+			input, err := PackAggregateVerify(AggregateVerifyInput{PublicKeys: publicKeys, Message: message, Signature: signature})
+			require.NoError(t, err)
+			return input
+		}
+	}
+	packOutput := func(valid bool) []byte {
+		output, err := PackAggregateVerifyOutput(valid)
+		require.NoError(t, err)
+		return output
+	}
+
+	tests := map[string]testutils.PrecompileTest{
+		"valid aggregate signature": {
+			InputFn:     packAggregateVerify(publicKeys),
+			SuppliedGas: gas(3),
+			ReadOnly:    true,
+			ExpectedRes: packOutput(true),
+		},
+		"missing signer": {
+			InputFn:     packAggregateVerify(publicKeys[:2]),
+			SuppliedGas: gas(2),
+			ExpectedRes: packOutput(false),
+		},
+		"no public keys": {
+			InputFn:     packAggregateVerify(nil),
+			SuppliedGas: gas(0),
+			ExpectedRes: packOutput(false),
+		},
+		"malformed public key": {
+			InputFn:     packAggregateVerify([][]byte{publicKeys[0], publicKeys[1][1:], publicKeys[2]}),
+			SuppliedGas: gas(3),
+			ExpectedRes: packOutput(false),
+		},
+		"insufficient gas": {
+			InputFn:     packAggregateVerify(publicKeys),
+			SuppliedGas: gas(3) - 1,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	}
+
+	testutils.RunPrecompileTests(t, Module, state.NewTestStateDB, tests)
+}
+
+func TestAggregatePublicKeys(t *testing.T) {
+	secretKeys := newSecretKeys(t, 2)
+	publicKeys := make([]*bls.PublicKey, len(secretKeys))
+	publicKeyBytes := make([][]byte, len(secretKeys))
+	for i, secretKey := range secretKeys {
+		publicKeys[i] = bls.PublicFromSecretKey(secretKey)
+		publicKeyBytes[i] = bls.PublicKeyToCompressedBytes(publicKeys[i])
+	}
+	aggregatePublicKey, err := bls.AggregatePublicKeys(publicKeys)
+	require.NoError(t, err)
+	sharedAggregatePublicKey, err := bls.AggregatePublicKeys([]*bls.PublicKey{publicKeys[0], publicKeys[0], publicKeys[0]})
+	require.NoError(t, err)
+
+	// packSharedPublicKeys packs 3 public keys whose offsets all point to the
+	// data of the first public key.
+	packSharedPublicKeys := func(t testing.TB) []byte {
+		input := append([]byte{}, BLSVerifierABI.Methods["aggregatePublicKeys"].ID...)
+		for _, word := range []uint64{32, 3, 96, 96, 96, uint64(len(publicKeyBytes[0]))} {
+			input = append(input, common.BigToHash(new(big.Int).SetUint64(word)).Bytes()...)
+		}
+		return append(input, common.RightPadBytes(publicKeyBytes[0], 64)...)
+	}
+	packAggregatePublicKeys := func(publicKeys [][]byte) func(t testing.TB) []byte {
+		return func(t testing.TB) []byte {
+			input, err := PackAggregatePublicKeys(publicKeys)
+			require.NoError(t, err)
+			return input
+		}
+	}
+
+	tests := map[string]testutils.PrecompileTest{
+		"aggregate public keys": {
+			InputFn:     packAggregatePublicKeys(publicKeyBytes),
+			SuppliedGas: AggregatePublicKeysGasCost + 2*GasCostPerPublicKey,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackAggregatePublicKeysOutput(bls.PublicKeyToCompressedBytes(aggregatePublicKey))
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"no public keys": {
+			InputFn:     packAggregatePublicKeys(nil),
+			SuppliedGas: AggregatePublicKeysGasCost,
+			ExpectedErr: errNoPublicKeys.Error(),
+		},
+		"malformed public key": {
+			InputFn:     packAggregatePublicKeys([][]byte{publicKeyBytes[0], publicKeyBytes[1][1:]}),
+			SuppliedGas: AggregatePublicKeysGasCost + 2*GasCostPerPublicKey,
+			ExpectedErr: errInvalidPublicKey.Error(),
+		},
+		"insufficient gas": {
+			InputFn:     packAggregatePublicKeys(publicKeyBytes),
+			SuppliedGas: AggregatePublicKeysGasCost + 2*GasCostPerPublicKey - 1,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"insufficient gas before decoding": {
+			InputFn: func(t testing.TB) []byte {
+				input := append([]byte{}, BLSVerifierABI.Methods["aggregatePublicKeys"].ID...)
+				return append(input, make([]byte, 4*publicKeyInputSize)...)
+			},
+			SuppliedGas: AggregatePublicKeysGasCost + 3*GasCostPerPublicKey,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"shared public key data": {
+			InputFn:     packSharedPublicKeys,
+			SuppliedGas: AggregatePublicKeysGasCost + 3*GasCostPerPublicKey,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackAggregatePublicKeysOutput(bls.PublicKeyToCompressedBytes(sharedAggregatePublicKey))
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"shared public key data insufficient gas": {
+			InputFn:     packSharedPublicKeys,
+			SuppliedGas: AggregatePublicKeysGasCost + 3*GasCostPerPublicKey - 1,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	}
+
+	testutils.RunPrecompileTests(t, Module, state.NewTestStateDB, tests)
+}
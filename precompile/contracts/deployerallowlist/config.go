@@ -4,7 +4,11 @@
 package deployerallowlist
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
 	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -16,6 +20,56 @@ var _ precompileconfig.Config = &Config{}
 type Config struct {
 	allowlist.AllowListConfig
 	precompileconfig.Upgrade
+	precompileconfig.GasCostOverrides
+
+	// CodeHashRules enables the deployment of contracts with allowed code hashes
+	// by addresses without the enabled role, if not nil.
+	CodeHashRules *CodeHashRulesConfig `json:"codeHashRules,omitempty"`
+}
+
+// CodeHashRulesConfig specifies the initial code hashes of the contracts that can
+// be deployed by anyone. A contract can be deployed if either its init code hash
+// or its runtime code hash is allowed.
+type CodeHashRulesConfig struct {
+	AllowedInitCodeHashes    []common.Hash `json:"allowedInitCodeHashes,omitempty"`
+	AllowedRuntimeCodeHashes []common.Hash `json:"allowedRuntimeCodeHashes,omitempty"`
+}
+
+// Configure enables the code hash rules and allows the initial code hashes.
+func (c *CodeHashRulesConfig) Configure(state contract.StateDB) {
+	EnableCodeHashRules(state)
+	for _, codeHash := range c.AllowedInitCodeHashes {
+		SetAllowedCodeHash(state, InitCodeHash, codeHash, true)
+	}
+	for _, codeHash := range c.AllowedRuntimeCodeHashes {
+		SetAllowedCodeHash(state, RuntimeCodeHash, codeHash, true)
+	}
+}
+
+// Equal returns true iff [other] allows the same code hashes in the same order.
+func (c *CodeHashRulesConfig) Equal(other *CodeHashRulesConfig) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return slices.Equal(c.AllowedInitCodeHashes, other.AllowedInitCodeHashes) &&
+		slices.Equal(c.AllowedRuntimeCodeHashes, other.AllowedRuntimeCodeHashes)
+}
+
+// Verify returns an error if a code hash is allowed more than once.
+func (c *CodeHashRulesConfig) Verify() error {
+	for kind, codeHashes := range map[CodeHashKind][]common.Hash{
+		InitCodeHash:    c.AllowedInitCodeHashes,
+		RuntimeCodeHash: c.AllowedRuntimeCodeHashes,
+	} {
+		seen := make(map[common.Hash]struct{}, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			if _, ok := seen[codeHash]; ok {
+				return fmt.Errorf("duplicate %s code hash: %s", kind, codeHash)
+			}
+			seen[codeHash] = struct{}{}
+		}
+	}
+	return nil
 }
 
 // NewConfig returns a config for a network upgrade at [blockTimestamp] that enables
@@ -51,9 +105,17 @@ func (c *Config) Equal(cfg precompileconfig.Config) bool {
 	if !ok {
 		return false
 	}
-	return c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig)
+	return c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig) && c.GasCostOverrides.Equal(&other.GasCostOverrides) && c.CodeHashRules.Equal(other.CodeHashRules)
 }
 
 func (c *Config) Verify(chainConfig precompileconfig.ChainConfig) error {
+	if err := c.GasCostOverrides.Verify(GasSchedule); err != nil {
+		return err
+	}
+	if c.CodeHashRules != nil {
+		if err := c.CodeHashRules.Verify(); err != nil {
+			return err
+		}
+	}
 	return c.AllowListConfig.Verify(chainConfig, c.Upgrade)
 }
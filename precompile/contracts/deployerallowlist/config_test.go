@@ -15,7 +15,42 @@ import (
 )
 
 func TestVerify(t *testing.T) {
-	allowlist.VerifyPrecompileWithAllowListTests(t, Module, nil)
+	admins := []common.Address{allowlist.TestAdminAddr}
+	codeHash := common.HexToHash("0x1234")
+	tests := map[string]testutils.ConfigVerifyTest{
+		"valid code hash rules": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, nil, nil)
+				config.CodeHashRules = &CodeHashRulesConfig{
+					AllowedInitCodeHashes:    []common.Hash{codeHash},
+					AllowedRuntimeCodeHashes: []common.Hash{codeHash},
+				}
+				return config
+			}(),
+			ExpectedError: "",
+		},
+		"duplicate init code hash": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, nil, nil)
+				config.CodeHashRules = &CodeHashRulesConfig{
+					AllowedInitCodeHashes: []common.Hash{codeHash, codeHash},
+				}
+				return config
+			}(),
+			ExpectedError: "duplicate init code hash",
+		},
+		"duplicate runtime code hash": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, nil, nil)
+				config.CodeHashRules = &CodeHashRulesConfig{
+					AllowedRuntimeCodeHashes: []common.Hash{codeHash, codeHash},
+				}
+				return config
+			}(),
+			ExpectedError: "duplicate runtime code hash",
+		},
+	}
+	allowlist.VerifyPrecompileWithAllowListTests(t, Module, tests)
 }
 
 func TestEqual(t *testing.T) {
@@ -38,6 +73,28 @@ func TestEqual(t *testing.T) {
 			Other:    NewConfig(utils.NewUint64(4), admins, enableds, managers),
 			Expected: false,
 		},
+		"different code hash rules": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, enableds, managers)
+				config.CodeHashRules = &CodeHashRulesConfig{AllowedInitCodeHashes: []common.Hash{{1}}}
+				return config
+			}(),
+			Other: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, enableds, managers)
+				config.CodeHashRules = &CodeHashRulesConfig{AllowedRuntimeCodeHashes: []common.Hash{{1}}}
+				return config
+			}(),
+			Expected: false,
+		},
+		"nil and empty code hash rules": {
+			Config: NewConfig(utils.NewUint64(3), admins, enableds, managers),
+			Other: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, enableds, managers)
+				config.CodeHashRules = &CodeHashRulesConfig{}
+				return config
+			}(),
+			Expected: false,
+		},
 		"same config": {
 			Config:   NewConfig(utils.NewUint64(3), admins, enableds, managers),
 			Other:    NewConfig(utils.NewUint64(3), admins, enableds, managers),
@@ -4,13 +4,86 @@
 package deployerallowlist
 
 import (
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
 	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
+	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	SetAllowedCodeHashGasCost uint64 = contract.WriteGasCostPerSlot
+	IsAllowedCodeHashGasCost  uint64 = contract.ReadGasCostPerSlot
+)
+
+// Names of the gas costs of the precompile, which can be overridden by its
+// config in addition to the allow list gas costs.
+const (
+	SetAllowedCodeHashGasCostKey = "setAllowedCodeHash"
+	IsAllowedCodeHashGasCostKey  = "isAllowedCodeHash"
+)
+
+// GasSchedule contains the gas costs of the precompile operations, including
+// the allow list operations.
+var GasSchedule = allowlist.GasSchedule.With(precompileconfig.GasSchedule{
+	SetAllowedCodeHashGasCostKey: precompileconfig.NewGasCost(SetAllowedCodeHashGasCost),
+	IsAllowedCodeHashGasCostKey:  precompileconfig.NewGasCost(IsAllowedCodeHashGasCost),
+})
+
+var (
+	// Singleton StatefulPrecompiledContract for W/R access to the contract deployer allow list.
+	ContractDeployerAllowListPrecompile contract.StatefulPrecompiledContract = createContractDeployerAllowListPrecompile()
+
+	ErrCannotSetCodeHash = errors.New("non-admin cannot set allowed code hashes")
+
+	// ContractDeployerAllowListRawABI contains the raw ABI of ContractDeployerAllowList contract.
+	//go:embed contract.abi
+	ContractDeployerAllowListRawABI string
+
+	ContractDeployerAllowListABI = contract.ParseABI(ContractDeployerAllowListRawABI)
+
+	// Code hash rules are stored at keys that cannot collide with the roles
+	// of the allow list, which are stored at the address of each account.
+	codeHashRulesKey      = crypto.Keccak256Hash([]byte("deployerallowlist.codeHashRules"))
+	initCodeHashPrefix    = []byte("deployerallowlist.initCodeHash")
+	runtimeCodeHashPrefix = []byte("deployerallowlist.runtimeCodeHash")
+
+	codeHashAllowed = common.BigToHash(common.Big1)
 )
 
-// Singleton StatefulPrecompiledContract for W/R access to the contract deployer allow list.
-var ContractDeployerAllowListPrecompile contract.StatefulPrecompiledContract = allowlist.CreateAllowListPrecompile(ContractAddress)
+// CodeHashKind distinguishes the rules on init code hashes from the rules on
+// runtime code hashes.
+type CodeHashKind int
+
+const (
+	InitCodeHash CodeHashKind = iota
+	RuntimeCodeHash
+)
+
+func (k CodeHashKind) String() string {
+	switch k {
+	case InitCodeHash:
+		return "init"
+	case RuntimeCodeHash:
+		return "runtime"
+	default:
+		return "unknown"
+	}
+}
+
+func (k CodeHashKind) key(codeHash common.Hash) common.Hash {
+	prefix := initCodeHashPrefix
+	if k == RuntimeCodeHash {
+		prefix = runtimeCodeHashPrefix
+	}
+	return crypto.Keccak256Hash(prefix, codeHash.Bytes())
+}
 
 // GetContractDeployerAllowListStatus returns the role of [address] for the contract deployer
 // allow list.
@@ -24,3 +97,170 @@ func GetContractDeployerAllowListStatus(stateDB contract.StateDB, address common
 func SetContractDeployerAllowListStatus(stateDB contract.StateDB, address common.Address, role allowlist.Role) {
 	allowlist.SetAllowListRole(stateDB, ContractAddress, address, role)
 }
+
+// EnableCodeHashRules enables the code hash rules of the contract deployer allow list.
+func EnableCodeHashRules(stateDB contract.StateDB) {
+	stateDB.SetState(ContractAddress, codeHashRulesKey, codeHashAllowed)
+}
+
+// IsCodeHashRulesEnabled returns true if addresses without the enabled role can
+// deploy contracts with allowed code hashes.
+func IsCodeHashRulesEnabled(stateDB contract.StateDB) bool {
+	return stateDB.GetState(ContractAddress, codeHashRulesKey) == codeHashAllowed
+}
+
+// IsAllowedCodeHash returns true if anyone can deploy contracts with the [kind] code hash [codeHash].
+func IsAllowedCodeHash(stateDB contract.StateDB, kind CodeHashKind, codeHash common.Hash) bool {
+	return stateDB.GetState(ContractAddress, kind.key(codeHash)) == codeHashAllowed
+}
+
+// SetAllowedCodeHash allows or disallows anyone to deploy contracts with the [kind] code hash [codeHash].
+func SetAllowedCodeHash(stateDB contract.StateDB, kind CodeHashKind, codeHash common.Hash, allowed bool) {
+	value := common.Hash{}
+	if allowed {
+		value = codeHashAllowed
+	}
+	stateDB.SetState(ContractAddress, kind.key(codeHash), value)
+}
+
+// functionNames returns the names of the setter and getter of the [kind] code hash rules.
+func (k CodeHashKind) functionNames() (setter string, getter string) {
+	if k == RuntimeCodeHash {
+		return "setAllowedRuntimeCodeHash", "isAllowedRuntimeCodeHash"
+	}
+	return "setAllowedInitCodeHash", "isAllowedInitCodeHash"
+}
+
+// PackSetAllowedCodeHash packs [codeHash] and [allowed] into the input data to the setter of the [kind] code hash rules.
+func PackSetAllowedCodeHash(kind CodeHashKind, codeHash common.Hash, allowed bool) ([]byte, error) {
+	setter, _ := kind.functionNames()
+	return ContractDeployerAllowListABI.Pack(setter, codeHash, allowed)
+}
+
+// UnpackSetAllowedCodeHashInput attempts to unpack [input] into the arguments to the setter of the [kind] code hash rules.
+func UnpackSetAllowedCodeHashInput(kind CodeHashKind, input []byte) (common.Hash, bool, error) {
+	setter, _ := kind.functionNames()
+	res, err := ContractDeployerAllowListABI.UnpackInput(setter, input, false)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	codeHash := *abi.ConvertType(res[0], new([32]byte)).(*[32]byte)
+	allowed := *abi.ConvertType(res[1], new(bool)).(*bool)
+	return codeHash, allowed, nil
+}
+
+// PackIsAllowedCodeHash packs [codeHash] into the input data to the getter of the [kind] code hash rules.
+func PackIsAllowedCodeHash(kind CodeHashKind, codeHash common.Hash) ([]byte, error) {
+	_, getter := kind.functionNames()
+	return ContractDeployerAllowListABI.Pack(getter, codeHash)
+}
+
+// UnpackIsAllowedCodeHashInput attempts to unpack [input] into the argument to the getter of the [kind] code hash rules.
+func UnpackIsAllowedCodeHashInput(kind CodeHashKind, input []byte) (common.Hash, error) {
+	_, getter := kind.functionNames()
+	res, err := ContractDeployerAllowListABI.UnpackInput(getter, input, false)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return *abi.ConvertType(res[0], new([32]byte)).(*[32]byte), nil
+}
+
+// PackIsAllowedCodeHashOutput attempts to pack [allowed] to conform the ABI outputs of the getter of the [kind] code hash rules.
+func PackIsAllowedCodeHashOutput(kind CodeHashKind, allowed bool) ([]byte, error) {
+	_, getter := kind.functionNames()
+	return ContractDeployerAllowListABI.PackOutput(getter, allowed)
+}
+
+// createSetAllowedCodeHash returns an execution function that allows admins to
+// set the [kind] code hash rules.
+func createSetAllowedCodeHash(kind CodeHashKind) contract.RunStatefulPrecompileFunc {
+	return func(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		gasCost := contract.GetGasCost(accessibleState, ContractAddress, SetAllowedCodeHashGasCostKey, SetAllowedCodeHashGasCost)
+		if remainingGas, err = contract.DeductGas(suppliedGas, gasCost+CodeHashSetEventGasCost); err != nil {
+			return nil, 0, err
+		}
+		if readOnly {
+			return nil, remainingGas, vmerrs.ErrWriteProtection
+		}
+		codeHash, allowed, err := UnpackSetAllowedCodeHashInput(kind, input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		stateDB := accessibleState.GetStateDB()
+		// Verify that the caller is an admin with permission to modify the code hash rules.
+		callerStatus := GetContractDeployerAllowListStatus(stateDB, caller)
+		if !callerStatus.IsAdmin() {
+			return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotSetCodeHash, caller)
+		}
+
+		topics, data, err := PackCodeHashSetEvent(kind, codeHash, caller, allowed)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		stateDB.AddLog(
+			ContractAddress,
+			topics,
+			data,
+			accessibleState.GetBlockContext().Number().Uint64(),
+		)
+		SetAllowedCodeHash(stateDB, kind, codeHash, allowed)
+
+		// Return an empty output and the remaining gas
+		return []byte{}, remainingGas, nil
+	}
+}
+
+// createIsAllowedCodeHash returns an execution function that reads the [kind] code hash rules.
+func createIsAllowedCodeHash(kind CodeHashKind) contract.RunStatefulPrecompileFunc {
+	return func(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		gasCost := contract.GetGasCost(accessibleState, ContractAddress, IsAllowedCodeHashGasCostKey, IsAllowedCodeHashGasCost)
+		if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
+			return nil, 0, err
+		}
+		codeHash, err := UnpackIsAllowedCodeHashInput(kind, input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		allowed := IsAllowedCodeHash(accessibleState.GetStateDB(), kind, codeHash)
+		packedOutput, err := PackIsAllowedCodeHashOutput(kind, allowed)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		return packedOutput, remainingGas, nil
+	}
+}
+
+// isCodeHashRulesActivated enables the code hash functions only if the code hash rules are enabled.
+func isCodeHashRulesActivated(accessibleState contract.AccessibleState) bool {
+	return IsCodeHashRulesEnabled(accessibleState.GetStateDB())
+}
+
+// createContractDeployerAllowListPrecompile returns a StatefulPrecompiledContract with R/W control
+// of the allow list and of the code hash rules of the contract deployer allow list.
+func createContractDeployerAllowListPrecompile() contract.StatefulPrecompiledContract {
+	var functions []*contract.StatefulPrecompileFunction
+	functions = append(functions, allowlist.CreateAllowListFunctions(ContractAddress)...)
+
+	for _, kind := range []CodeHashKind{InitCodeHash, RuntimeCodeHash} {
+		setter, getter := kind.functionNames()
+		abiFunctionMap := map[string]contract.RunStatefulPrecompileFunc{
+			setter: createSetAllowedCodeHash(kind),
+			getter: createIsAllowedCodeHash(kind),
+		}
+		for name, function := range abiFunctionMap {
+			method, ok := ContractDeployerAllowListABI.Methods[name]
+			if !ok {
+				panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+			}
+			functions = append(functions, contract.NewStatefulPrecompileFunctionWithActivator(method.ID, function, isCodeHashRulesActivated))
+		}
+	}
+	// Construct the contract with no fallback function.
+	statefulContract, err := contract.NewStatefulPrecompileContract(nil, functions)
+	if err != nil {
+		panic(err)
+	}
+	return statefulContract
+}
@@ -8,12 +8,163 @@ import (
 
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/testutils"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
 )
 
+var testCodeHash = common.HexToHash("0x1234")
+
+func setDefaultCodeHashRules(t testing.TB, state contract.StateDB) {
+	EnableCodeHashRules(state)
+	allowlist.SetDefaultRoles(ContractAddress)(t, state)
+}
+
+func packSetAllowedCodeHash(kind CodeHashKind, allowed bool) func(t testing.TB) []byte {
+	return func(t testing.TB) []byte {
+		input, err := PackSetAllowedCodeHash(kind, testCodeHash, allowed)
+		require.NoError(t, err)
+		return input
+	}
+}
+
+func packIsAllowedCodeHash(kind CodeHashKind) func(t testing.TB) []byte {
+	return func(t testing.TB) []byte {
+		input, err := PackIsAllowedCodeHash(kind, testCodeHash)
+		require.NoError(t, err)
+		return input
+	}
+}
+
+func packIsAllowedCodeHashOutput(t testing.TB, kind CodeHashKind, allowed bool) []byte {
+	output, err := PackIsAllowedCodeHashOutput(kind, allowed)
+	require.NoError(t, err)
+	return output
+}
+
+func codeHashTests(t testing.TB) map[string]testutils.PrecompileTest {
+	tests := map[string]testutils.PrecompileTest{}
+	for _, kind := range []CodeHashKind{InitCodeHash, RuntimeCodeHash} {
+		kind := kind
+		tests["admin sets allowed "+kind.String()+" code hash"] = testutils.PrecompileTest{
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  setDefaultCodeHashRules,
+			InputFn:     packSetAllowedCodeHash(kind, true),
+			SuppliedGas: SetAllowedCodeHashGasCost + CodeHashSetEventGasCost,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.True(t, IsAllowedCodeHash(state, kind, testCodeHash))
+				other := InitCodeHash
+				if kind == InitCodeHash {
+					other = RuntimeCodeHash
+				}
+				require.False(t, IsAllowedCodeHash(state, other, testCodeHash))
+
+				logsTopics, logsData := state.GetLogData()
+				require.Len(t, logsTopics, 1)
+				require.Equal(t, []common.Hash{
+					ContractDeployerAllowListABI.Events[kind.eventName()].ID,
+					testCodeHash,
+					common.BytesToHash(allowlist.TestAdminAddr.Bytes()),
+				}, logsTopics[0])
+				allowed, err := UnpackCodeHashSetEventData(kind, logsData[0])
+				require.NoError(t, err)
+				require.True(t, allowed)
+			},
+		}
+		tests["admin disallows "+kind.String()+" code hash"] = testutils.PrecompileTest{
+			Caller: allowlist.TestAdminAddr,
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				setDefaultCodeHashRules(t, state)
+				SetAllowedCodeHash(state, kind, testCodeHash, true)
+			},
+			InputFn:     packSetAllowedCodeHash(kind, false),
+			SuppliedGas: SetAllowedCodeHashGasCost + CodeHashSetEventGasCost,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.False(t, IsAllowedCodeHash(state, kind, testCodeHash))
+			},
+		}
+		for name, caller := range map[string]common.Address{
+			"manager": allowlist.TestManagerAddr,
+			"enabled": allowlist.TestEnabledAddr,
+			"no role": allowlist.TestNoRoleAddr,
+		} {
+			tests[name+" cannot set allowed "+kind.String()+" code hash"] = testutils.PrecompileTest{
+				Caller:      caller,
+				BeforeHook:  setDefaultCodeHashRules,
+				InputFn:     packSetAllowedCodeHash(kind, true),
+				SuppliedGas: SetAllowedCodeHashGasCost + CodeHashSetEventGasCost,
+				ExpectedErr: ErrCannotSetCodeHash.Error(),
+			}
+		}
+		tests["set allowed "+kind.String()+" code hash readOnly"] = testutils.PrecompileTest{
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  setDefaultCodeHashRules,
+			InputFn:     packSetAllowedCodeHash(kind, true),
+			SuppliedGas: SetAllowedCodeHashGasCost + CodeHashSetEventGasCost,
+			ReadOnly:    true,
+			ExpectedErr: vmerrs.ErrWriteProtection.Error(),
+		}
+		tests["set allowed "+kind.String()+" code hash insufficient gas"] = testutils.PrecompileTest{
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  setDefaultCodeHashRules,
+			InputFn:     packSetAllowedCodeHash(kind, true),
+			SuppliedGas: SetAllowedCodeHashGasCost + CodeHashSetEventGasCost - 1,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		}
+		tests["set allowed "+kind.String()+" code hash without code hash rules"] = testutils.PrecompileTest{
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  allowlist.SetDefaultRoles(ContractAddress),
+			InputFn:     packSetAllowedCodeHash(kind, true),
+			SuppliedGas: 0,
+			ExpectedErr: "invalid non-activated function selector",
+		}
+		tests["read allowed "+kind.String()+" code hash"] = testutils.PrecompileTest{
+			Caller: allowlist.TestNoRoleAddr,
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				setDefaultCodeHashRules(t, state)
+				SetAllowedCodeHash(state, kind, testCodeHash, true)
+			},
+			InputFn:     packIsAllowedCodeHash(kind),
+			SuppliedGas: IsAllowedCodeHashGasCost,
+			ReadOnly:    true,
+			ExpectedRes: packIsAllowedCodeHashOutput(t, kind, true),
+		}
+		tests["read disallowed "+kind.String()+" code hash"] = testutils.PrecompileTest{
+			Caller:      allowlist.TestNoRoleAddr,
+			BeforeHook:  setDefaultCodeHashRules,
+			InputFn:     packIsAllowedCodeHash(kind),
+			SuppliedGas: IsAllowedCodeHashGasCost,
+			ReadOnly:    true,
+			ExpectedRes: packIsAllowedCodeHashOutput(t, kind, false),
+		}
+	}
+	tests["initial config sets code hash rules"] = testutils.PrecompileTest{
+		Caller: allowlist.TestNoRoleAddr,
+		Config: &Config{
+			CodeHashRules: &CodeHashRulesConfig{
+				AllowedInitCodeHashes: []common.Hash{testCodeHash},
+			},
+		},
+		InputFn:     packIsAllowedCodeHash(InitCodeHash),
+		SuppliedGas: IsAllowedCodeHashGasCost,
+		ReadOnly:    true,
+		ExpectedRes: packIsAllowedCodeHashOutput(t, InitCodeHash, true),
+		AfterHook: func(t testing.TB, state contract.StateDB) {
+			require.True(t, IsCodeHashRulesEnabled(state))
+			require.False(t, IsAllowedCodeHash(state, RuntimeCodeHash, testCodeHash))
+		},
+	}
+	return tests
+}
+
 func TestContractDeployerAllowListRun(t *testing.T) {
-	allowlist.RunPrecompileWithAllowListTests(t, Module, state.NewTestStateDB, nil)
+	allowlist.RunPrecompileWithAllowListTests(t, Module, state.NewTestStateDB, codeHashTests(t))
 }
 
 func BenchmarkContractDeployerAllowList(b *testing.B) {
-	allowlist.BenchPrecompileWithAllowList(b, Module, state.NewTestStateDB, nil)
+	allowlist.BenchPrecompileWithAllowList(b, Module, state.NewTestStateDB, codeHashTests(b))
 }
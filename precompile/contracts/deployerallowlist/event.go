@@ -0,0 +1,40 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package deployerallowlist
+
+import (
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// CodeHashSetEventGasCost is the gas cost of the InitCodeHashSet and RuntimeCodeHashSet events.
+	// It is the base gas cost + the gas cost of the topics (signature, codeHash, sender)
+	// and the gas cost of the non-indexed data (32 bytes for allowed).
+	CodeHashSetEventGasCost = contract.LogGas + contract.LogTopicGas*3 + contract.LogDataGas*common.HashLength
+)
+
+// eventName returns the name of the event emitted when the [kind] code hash rules are set.
+func (k CodeHashKind) eventName() string {
+	if k == RuntimeCodeHash {
+		return "RuntimeCodeHashSet"
+	}
+	return "InitCodeHashSet"
+}
+
+// PackCodeHashSetEvent packs the event emitted when the [kind] code hash rules are set.
+// It returns topic hashes and the encoded non-indexed data.
+func PackCodeHashSetEvent(kind CodeHashKind, codeHash common.Hash, sender common.Address, allowed bool) ([]common.Hash, []byte, error) {
+	return ContractDeployerAllowListABI.PackEvent(kind.eventName(), codeHash, sender, allowed)
+}
+
+// UnpackCodeHashSetEventData attempts to unpack non-indexed [dataBytes] of the event
+// emitted when the [kind] code hash rules are set.
+func UnpackCodeHashSetEventData(kind CodeHashKind, dataBytes []byte) (bool, error) {
+	var eventData = struct {
+		Allowed bool
+	}{}
+	err := ContractDeployerAllowListABI.UnpackIntoInterface(&eventData, kind.eventName(), dataBytes)
+	return eventData.Allowed, err
+}
@@ -48,5 +48,8 @@ func (c *configurator) Configure(chainConfig precompileconfig.ChainConfig, cfg p
 	if !ok {
 		return fmt.Errorf("expected config type %T, got %T: %v", &Config{}, cfg, cfg)
 	}
+	if config.CodeHashRules != nil {
+		config.CodeHashRules.Configure(state)
+	}
 	return config.AllowListConfig.Configure(chainConfig, ContractAddress, state, blockContext)
 }
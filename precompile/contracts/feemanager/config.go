@@ -17,6 +17,7 @@ var _ precompileconfig.Config = &Config{}
 type Config struct {
 	allowlist.AllowListConfig // Config for the fee config manager allow list
 	precompileconfig.Upgrade
+	precompileconfig.GasCostOverrides
 	InitialFeeConfig *commontype.FeeConfig `json:"initialFeeConfig,omitempty"` // initial fee config to be immediately activated
 }
 
@@ -57,7 +58,7 @@ func (c *Config) Equal(cfg precompileconfig.Config) bool {
 	if !ok {
 		return false
 	}
-	eq := c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig)
+	eq := c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig) && c.GasCostOverrides.Equal(&other.GasCostOverrides)
 	if !eq {
 		return false
 	}
@@ -71,6 +72,9 @@ func (c *Config) Equal(cfg precompileconfig.Config) bool {
 
 // Verify tries to verify Config and returns an error accordingly.
 func (c *Config) Verify(chainConfig precompileconfig.ChainConfig) error {
+	if err := c.GasCostOverrides.Verify(GasSchedule); err != nil {
+		return err
+	}
 	if err := c.AllowListConfig.Verify(chainConfig, c.Upgrade); err != nil {
 		return err
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
 	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -40,6 +41,22 @@ const (
 	GetLastChangedAtGasCost uint64 = contract.ReadGasCostPerSlot
 )
 
+// Names of the gas costs of the precompile, which can be overridden by its
+// config in addition to the allow list gas costs.
+const (
+	SetFeeConfigGasCostKey     = "setFeeConfig"
+	GetFeeConfigGasCostKey     = "getFeeConfig"
+	GetLastChangedAtGasCostKey = "getFeeConfigLastChangedAt"
+)
+
+// GasSchedule contains the gas costs of the precompile operations, including
+// the allow list operations.
+var GasSchedule = allowlist.GasSchedule.With(precompileconfig.GasSchedule{
+	SetFeeConfigGasCostKey:     precompileconfig.NewGasCost(SetFeeConfigGasCost),
+	GetFeeConfigGasCostKey:     precompileconfig.NewGasCost(GetFeeConfigGasCost),
+	GetLastChangedAtGasCostKey: precompileconfig.NewGasCost(GetLastChangedAtGasCost),
+})
+
 var (
 
 	// Singleton StatefulPrecompiledContract for setting fee configs by permissioned callers.
@@ -205,7 +222,8 @@ func UnpackSetFeeConfigInput(input []byte, useStrictMode bool) (commontype.FeeCo
 // setFeeConfig checks if the caller has permissions to set the fee config.
 // The execution function parses [input] into FeeConfig structure and sets contract storage accordingly.
 func setFeeConfig(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, SetFeeConfigGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, SetFeeConfigGasCostKey, SetFeeConfigGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 
@@ -317,7 +335,8 @@ func UnpackGetFeeConfigOutput(output []byte, skipLenCheck bool) (commontype.FeeC
 // getFeeConfig returns the stored fee config as an output.
 // The execution function reads the contract state for the stored fee config and returns the output.
 func getFeeConfig(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, GetFeeConfigGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, GetFeeConfigGasCostKey, GetFeeConfigGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 
@@ -358,7 +377,8 @@ func UnpackGetFeeConfigLastChangedAtOutput(output []byte) (*big.Int, error) {
 // getFeeConfigLastChangedAt returns the block number that fee config was last changed in.
 // The execution function reads the contract state for the stored block number and returns the output.
 func getFeeConfigLastChangedAt(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, GetLastChangedAtGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, GetLastChangedAtGasCostKey, GetLastChangedAtGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 
@@ -0,0 +1,51 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nativeallowance
+
+import (
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
+	"github.com/ava-labs/subnet-evm/precompile/testutils"
+	"github.com/ava-labs/subnet-evm/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestVerifyConfig(t *testing.T) {
+	tests := map[string]testutils.ConfigVerifyTest{
+		"valid config": {
+			Config: NewConfig(utils.NewUint64(3)),
+		},
+		"valid disable config": {
+			Config: NewDisableConfig(utils.NewUint64(3)),
+		},
+	}
+	testutils.RunVerifyTests(t, tests)
+}
+
+func TestEqualConfig(t *testing.T) {
+	tests := map[string]testutils.ConfigEqualTest{
+		"non-nil config and nil other": {
+			Config:   NewConfig(utils.NewUint64(3)),
+			Other:    nil,
+			Expected: false,
+		},
+		"different type": {
+			Config:   NewConfig(utils.NewUint64(3)),
+			Other:    precompileconfig.NewMockConfig(gomock.NewController(t)),
+			Expected: false,
+		},
+		"different timestamp": {
+			Config:   NewConfig(utils.NewUint64(3)),
+			Other:    NewConfig(utils.NewUint64(4)),
+			Expected: false,
+		},
+		"same config": {
+			Config:   NewConfig(utils.NewUint64(3)),
+			Other:    NewConfig(utils.NewUint64(3)),
+			Expected: true,
+		},
+	}
+	testutils.RunEqualTests(t, tests)
+}
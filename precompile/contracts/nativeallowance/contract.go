@@ -0,0 +1,629 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nativeallowance
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+
+	_ "embed"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// EcrecoverGasCost is the gas cost of recovering the signer of a permit,
+	// matching the gas cost of the ecrecover precompile.
+	EcrecoverGasCost uint64 = 3_000
+
+	BalanceOfGasCost       uint64 = contract.ReadGasCostPerSlot
+	AllowanceGasCost       uint64 = contract.ReadGasCostPerSlot
+	NoncesGasCost          uint64 = contract.ReadGasCostPerSlot
+	DomainSeparatorGasCost uint64 = contract.ReadGasCostPerSlot
+	// ApproveGasCost covers writing the allowance and emitting Approval.
+	ApproveGasCost uint64 = contract.WriteGasCostPerSlot + ApprovalEventGasCost
+	// TransferFromGasCost covers reading the allowance and whether the owner
+	// has a quota, writing the allowance and both balances, and emitting Transfer.
+	TransferFromGasCost uint64 = 2*contract.ReadGasCostPerSlot + 3*contract.WriteGasCostPerSlot + TransferEventGasCost
+	// TransferFromQuotaGasCost is charged in addition to TransferFromGasCost if
+	// the owner has a quota on the tx allow list, and covers reading the quota
+	// and its usage, and writing the value usage.
+	TransferFromQuotaGasCost uint64 = txallowlist.ReadQuotaUsageGasCost + 3*contract.WriteGasCostPerSlot
+	// PermitGasCost covers reading the activation, recovering the signer,
+	// writing the nonce and the allowance, and emitting Approval.
+	PermitGasCost uint64 = contract.ReadGasCostPerSlot + EcrecoverGasCost + 2*contract.WriteGasCostPerSlot + ApprovalEventGasCost
+)
+
+var (
+	ErrInsufficientAllowance = errors.New("insufficient allowance")
+	ErrInsufficientBalance   = errors.New("insufficient balance")
+	ErrPermitExpired         = errors.New("permit expired")
+	ErrInvalidSignature      = errors.New("invalid permit signature")
+)
+
+// Singleton StatefulPrecompiledContract and signatures.
+var (
+	// NativeAllowanceRawABI contains the raw ABI of NativeAllowance contract.
+	//go:embed contract.abi
+	NativeAllowanceRawABI string
+
+	NativeAllowanceABI = contract.ParseABI(NativeAllowanceRawABI)
+
+	NativeAllowancePrecompile = createNativeAllowancePrecompile()
+
+	// DomainName and DomainVersion are the name and version of the EIP-712
+	// domain of permit signatures.
+	DomainName    = "NativeAllowance"
+	DomainVersion = "1"
+
+	domainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract,bytes32 salt)"))
+	permitTypeHash = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+
+	allowancePrefix = []byte("allowance")
+	noncePrefix     = []byte("nonce")
+
+	// activationKey is the storage key of the timestamp of the upgrade that
+	// last activated the precompile.
+	activationKey = crypto.Keccak256Hash([]byte("activation"))
+)
+
+type AllowanceInput struct {
+	Owner   common.Address
+	Spender common.Address
+}
+
+type ApproveInput struct {
+	Spender common.Address
+	Value   *big.Int
+}
+
+type PermitInput struct {
+	Owner    common.Address
+	Spender  common.Address
+	Value    *big.Int
+	Deadline *big.Int
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+}
+
+type TransferFromInput struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// allowanceKey returns the storage key of the allowance of [spender] over the native coin of [owner].
+func allowanceKey(owner common.Address, spender common.Address) common.Hash {
+	return crypto.Keccak256Hash(allowancePrefix, owner.Bytes(), spender.Bytes())
+}
+
+// nonceKey returns the storage key of the permit nonce of [owner].
+func nonceKey(owner common.Address) common.Hash {
+	return crypto.Keccak256Hash(noncePrefix, owner.Bytes())
+}
+
+// GetAllowance returns the amount of native coin that [spender] can transfer on behalf of [owner].
+func GetAllowance(stateDB contract.StateDB, owner common.Address, spender common.Address) *big.Int {
+	return stateDB.GetState(ContractAddress, allowanceKey(owner, spender)).Big()
+}
+
+// SetAllowance sets the amount of native coin that [spender] can transfer on behalf of [owner].
+func SetAllowance(stateDB contract.StateDB, owner common.Address, spender common.Address, value *big.Int) {
+	stateDB.SetState(ContractAddress, allowanceKey(owner, spender), common.BigToHash(value))
+}
+
+// GetNonce returns the permit nonce of [owner].
+func GetNonce(stateDB contract.StateDB, owner common.Address) *big.Int {
+	return stateDB.GetState(ContractAddress, nonceKey(owner)).Big()
+}
+
+// GetActivation returns the timestamp of the upgrade that last activated the precompile.
+func GetActivation(stateDB contract.StateDB) uint64 {
+	return stateDB.GetState(ContractAddress, activationKey).Big().Uint64()
+}
+
+// SetActivation sets the timestamp of the upgrade that last activated the precompile.
+func SetActivation(stateDB contract.StateDB, timestamp uint64) {
+	stateDB.SetState(ContractAddress, activationKey, common.BigToHash(new(big.Int).SetUint64(timestamp)))
+}
+
+// DomainSeparator returns the EIP-712 domain separator of permit signatures on the chain with
+// [chainID], while the precompile is activated by the upgrade at [activation]. The activation is
+// the salt of the domain, so that permits cannot be replayed after the precompile is disabled,
+// which clears the nonces, and enabled again.
+func DomainSeparator(chainID *big.Int, activation uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		domainTypeHash.Bytes(),
+		crypto.Keccak256([]byte(DomainName)),
+		crypto.Keccak256([]byte(DomainVersion)),
+		common.BigToHash(chainID).Bytes(),
+		common.BytesToHash(ContractAddress.Bytes()).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(activation)).Bytes(),
+	)
+}
+
+// PermitDigest returns the EIP-712 digest that [owner] signs to set the allowance of [spender]
+// to [value] with [nonce] until [deadline] on the chain with [chainID], while the precompile is
+// activated by the upgrade at [activation].
+func PermitDigest(chainID *big.Int, activation uint64, owner common.Address, spender common.Address, value *big.Int, nonce *big.Int, deadline *big.Int) common.Hash {
+	structHash := crypto.Keccak256(
+		permitTypeHash.Bytes(),
+		common.BytesToHash(owner.Bytes()).Bytes(),
+		common.BytesToHash(spender.Bytes()).Bytes(),
+		common.BigToHash(value).Bytes(),
+		common.BigToHash(nonce).Bytes(),
+		common.BigToHash(deadline).Bytes(),
+	)
+	return crypto.Keccak256Hash([]byte("\x19\x01"), DomainSeparator(chainID, activation).Bytes(), structHash)
+}
+
+// PackDomainSeparator packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackDomainSeparator() ([]byte, error) {
+	return NativeAllowanceABI.Pack("DOMAIN_SEPARATOR")
+}
+
+// PackDomainSeparatorOutput attempts to pack given domainSeparator of type common.Hash
+// to conform the ABI outputs.
+func PackDomainSeparatorOutput(domainSeparator common.Hash) ([]byte, error) {
+	return NativeAllowanceABI.PackOutput("DOMAIN_SEPARATOR", domainSeparator)
+}
+
+// UnpackDomainSeparatorOutput attempts to unpack given [output] into the common.Hash type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackDomainSeparatorOutput(output []byte) (common.Hash, error) {
+	res, err := NativeAllowanceABI.Unpack("DOMAIN_SEPARATOR", output)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new([32]byte)).(*[32]byte)
+	return unpacked, nil
+}
+
+// domainSeparator returns the EIP-712 domain separator of permit signatures.
+func domainSeparator(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, DomainSeparatorGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	separator := DomainSeparator(accessibleState.GetChainConfig().GetChainID(), GetActivation(accessibleState.GetStateDB()))
+	packedOutput, err := PackDomainSeparatorOutput(separator)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// UnpackAllowanceInput attempts to unpack [input] as AllowanceInput
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackAllowanceInput(input []byte) (AllowanceInput, error) {
+	inputStruct := AllowanceInput{}
+	err := NativeAllowanceABI.UnpackInputIntoInterface(&inputStruct, "allowance", input, false)
+	return inputStruct, err
+}
+
+// PackAllowance packs [inputStruct] of type AllowanceInput into the appropriate arguments for allowance.
+func PackAllowance(inputStruct AllowanceInput) ([]byte, error) {
+	return NativeAllowanceABI.Pack("allowance", inputStruct.Owner, inputStruct.Spender)
+}
+
+// PackAllowanceOutput attempts to pack given remaining of type *big.Int
+// to conform the ABI outputs.
+func PackAllowanceOutput(remaining *big.Int) ([]byte, error) {
+	return NativeAllowanceABI.PackOutput("allowance", remaining)
+}
+
+// UnpackAllowanceOutput attempts to unpack given [output] into the *big.Int type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackAllowanceOutput(output []byte) (*big.Int, error) {
+	res, err := NativeAllowanceABI.Unpack("allowance", output)
+	if err != nil {
+		return new(big.Int), err
+	}
+	unpacked := *abi.ConvertType(res[0], new(*big.Int)).(**big.Int)
+	return unpacked, nil
+}
+
+// allowance returns the amount of native coin that the spender can transfer on behalf of the owner.
+func allowance(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, AllowanceGasCost); err != nil {
+		return nil, 0, err
+	}
+	inputStruct, err := UnpackAllowanceInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	remaining := GetAllowance(accessibleState.GetStateDB(), inputStruct.Owner, inputStruct.Spender)
+	packedOutput, err := PackAllowanceOutput(remaining)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// UnpackApproveInput attempts to unpack [input] as ApproveInput
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackApproveInput(input []byte) (ApproveInput, error) {
+	inputStruct := ApproveInput{}
+	err := NativeAllowanceABI.UnpackInputIntoInterface(&inputStruct, "approve", input, false)
+	return inputStruct, err
+}
+
+// PackApprove packs [inputStruct] of type ApproveInput into the appropriate arguments for approve.
+func PackApprove(inputStruct ApproveInput) ([]byte, error) {
+	return NativeAllowanceABI.Pack("approve", inputStruct.Spender, inputStruct.Value)
+}
+
+// PackApproveOutput attempts to pack given success of type bool
+// to conform the ABI outputs.
+func PackApproveOutput(success bool) ([]byte, error) {
+	return NativeAllowanceABI.PackOutput("approve", success)
+}
+
+// UnpackApproveOutput attempts to unpack given [output] into the bool type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackApproveOutput(output []byte) (bool, error) {
+	res, err := NativeAllowanceABI.Unpack("approve", output)
+	if err != nil {
+		return false, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(bool)).(*bool)
+	return unpacked, nil
+}
+
+// approve sets the allowance of the spender over the native coin of the caller.
+func approve(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, ApproveGasCost); err != nil {
+		return nil, 0, err
+	}
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+	inputStruct, err := UnpackApproveInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	if err := setAllowance(accessibleState, caller, inputStruct.Spender, inputStruct.Value); err != nil {
+		return nil, remainingGas, err
+	}
+	packedOutput, err := PackApproveOutput(true)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// setAllowance sets the allowance of [spender] over the native coin of [owner] to [value]
+// and emits Approval.
+func setAllowance(accessibleState contract.AccessibleState, owner common.Address, spender common.Address, value *big.Int) error {
+	stateDB := accessibleState.GetStateDB()
+	topics, data, err := PackApprovalEvent(owner, spender, value)
+	if err != nil {
+		return err
+	}
+	stateDB.AddLog(
+		ContractAddress,
+		topics,
+		data,
+		accessibleState.GetBlockContext().Number().Uint64(),
+	)
+	SetAllowance(stateDB, owner, spender, value)
+	return nil
+}
+
+// UnpackBalanceOfInput attempts to unpack [input] into the common.Address type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackBalanceOfInput(input []byte) (common.Address, error) {
+	res, err := NativeAllowanceABI.UnpackInput("balanceOf", input, false)
+	if err != nil {
+		return common.Address{}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	return unpacked, nil
+}
+
+// PackBalanceOf packs [account] of type common.Address into the appropriate arguments for balanceOf.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackBalanceOf(account common.Address) ([]byte, error) {
+	return NativeAllowanceABI.Pack("balanceOf", account)
+}
+
+// PackBalanceOfOutput attempts to pack given balance of type *big.Int
+// to conform the ABI outputs.
+func PackBalanceOfOutput(balance *big.Int) ([]byte, error) {
+	return NativeAllowanceABI.PackOutput("balanceOf", balance)
+}
+
+// UnpackBalanceOfOutput attempts to unpack given [output] into the *big.Int type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackBalanceOfOutput(output []byte) (*big.Int, error) {
+	res, err := NativeAllowanceABI.Unpack("balanceOf", output)
+	if err != nil {
+		return new(big.Int), err
+	}
+	unpacked := *abi.ConvertType(res[0], new(*big.Int)).(**big.Int)
+	return unpacked, nil
+}
+
+// balanceOf returns the native balance of the account.
+func balanceOf(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, BalanceOfGasCost); err != nil {
+		return nil, 0, err
+	}
+	account, err := UnpackBalanceOfInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	packedOutput, err := PackBalanceOfOutput(accessibleState.GetStateDB().GetBalance(account))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// UnpackNoncesInput attempts to unpack [input] into the common.Address type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackNoncesInput(input []byte) (common.Address, error) {
+	res, err := NativeAllowanceABI.UnpackInput("nonces", input, false)
+	if err != nil {
+		return common.Address{}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	return unpacked, nil
+}
+
+// PackNonces packs [owner] of type common.Address into the appropriate arguments for nonces.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackNonces(owner common.Address) ([]byte, error) {
+	return NativeAllowanceABI.Pack("nonces", owner)
+}
+
+// PackNoncesOutput attempts to pack given nonce of type *big.Int
+// to conform the ABI outputs.
+func PackNoncesOutput(nonce *big.Int) ([]byte, error) {
+	return NativeAllowanceABI.PackOutput("nonces", nonce)
+}
+
+// UnpackNoncesOutput attempts to unpack given [output] into the *big.Int type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackNoncesOutput(output []byte) (*big.Int, error) {
+	res, err := NativeAllowanceABI.Unpack("nonces", output)
+	if err != nil {
+		return new(big.Int), err
+	}
+	unpacked := *abi.ConvertType(res[0], new(*big.Int)).(**big.Int)
+	return unpacked, nil
+}
+
+// nonces returns the permit nonce of the owner.
+func nonces(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, NoncesGasCost); err != nil {
+		return nil, 0, err
+	}
+	owner, err := UnpackNoncesInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	packedOutput, err := PackNoncesOutput(GetNonce(accessibleState.GetStateDB(), owner))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// UnpackPermitInput attempts to unpack [input] as PermitInput
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackPermitInput(input []byte) (PermitInput, error) {
+	inputStruct := PermitInput{}
+	err := NativeAllowanceABI.UnpackInputIntoInterface(&inputStruct, "permit", input, false)
+	return inputStruct, err
+}
+
+// PackPermit packs [inputStruct] of type PermitInput into the appropriate arguments for permit.
+func PackPermit(inputStruct PermitInput) ([]byte, error) {
+	return NativeAllowanceABI.Pack("permit", inputStruct.Owner, inputStruct.Spender, inputStruct.Value, inputStruct.Deadline, inputStruct.V, inputStruct.R, inputStruct.S)
+}
+
+// permit sets the allowance of the spender over the native coin of the owner
+// with an EIP-2612 signature of the owner.
+func permit(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, PermitGasCost); err != nil {
+		return nil, 0, err
+	}
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+	inputStruct, err := UnpackPermitInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	timestamp := new(big.Int).SetUint64(accessibleState.GetBlockContext().Timestamp())
+	if inputStruct.Deadline.Cmp(timestamp) < 0 {
+		return nil, remainingGas, fmt.Errorf("%w: deadline %d, block timestamp %d", ErrPermitExpired, inputStruct.Deadline, timestamp)
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	nonce := GetNonce(stateDB, inputStruct.Owner)
+	digest := PermitDigest(
+		accessibleState.GetChainConfig().GetChainID(),
+		GetActivation(stateDB),
+		inputStruct.Owner,
+		inputStruct.Spender,
+		inputStruct.Value,
+		nonce,
+		inputStruct.Deadline,
+	)
+	signer, err := recoverSigner(digest, inputStruct.V, inputStruct.R, inputStruct.S)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	if signer != inputStruct.Owner {
+		return nil, remainingGas, fmt.Errorf("%w: signer %s, owner %s", ErrInvalidSignature, signer, inputStruct.Owner)
+	}
+
+	stateDB.SetState(ContractAddress, nonceKey(inputStruct.Owner), common.BigToHash(nonce.Add(nonce, common.Big1)))
+	if err := setAllowance(accessibleState, inputStruct.Owner, inputStruct.Spender, inputStruct.Value); err != nil {
+		return nil, remainingGas, err
+	}
+	return []byte{}, remainingGas, nil
+}
+
+// recoverSigner returns the address that signed [digest], rejecting malleable
+// signatures with a high s value.
+func recoverSigner(digest common.Hash, v uint8, r [32]byte, s [32]byte) (common.Address, error) {
+	if v != 27 && v != 28 {
+		return common.Address{}, fmt.Errorf("%w: invalid v %d", ErrInvalidSignature, v)
+	}
+	if !crypto.ValidateSignatureValues(v-27, new(big.Int).SetBytes(r[:]), new(big.Int).SetBytes(s[:]), true) {
+		return common.Address{}, fmt.Errorf("%w: invalid r or s", ErrInvalidSignature)
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig[:32], r[:])
+	copy(sig[32:64], s[:])
+	sig[64] = v - 27
+	pubKey, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// UnpackTransferFromInput attempts to unpack [input] as TransferFromInput
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackTransferFromInput(input []byte) (TransferFromInput, error) {
+	inputStruct := TransferFromInput{}
+	err := NativeAllowanceABI.UnpackInputIntoInterface(&inputStruct, "transferFrom", input, false)
+	return inputStruct, err
+}
+
+// PackTransferFrom packs [inputStruct] of type TransferFromInput into the appropriate arguments for transferFrom.
+func PackTransferFrom(inputStruct TransferFromInput) ([]byte, error) {
+	return NativeAllowanceABI.Pack("transferFrom", inputStruct.From, inputStruct.To, inputStruct.Value)
+}
+
+// PackTransferFromOutput attempts to pack given success of type bool
+// to conform the ABI outputs.
+func PackTransferFromOutput(success bool) ([]byte, error) {
+	return NativeAllowanceABI.PackOutput("transferFrom", success)
+}
+
+// UnpackTransferFromOutput attempts to unpack given [output] into the bool type output
+// assumes that [output] does not include selector (omits first 4 func signature bytes)
+func UnpackTransferFromOutput(output []byte) (bool, error) {
+	res, err := NativeAllowanceABI.Unpack("transferFrom", output)
+	if err != nil {
+		return false, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(bool)).(*bool)
+	return unpacked, nil
+}
+
+// transferFrom transfers native coin from the owner to the recipient using the
+// allowance of the caller. An allowance of the maximum uint256 value is not decreased.
+func transferFrom(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = contract.DeductGas(suppliedGas, TransferFromGasCost); err != nil {
+		return nil, 0, err
+	}
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+	inputStruct, err := UnpackTransferFromInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	from, to, value := inputStruct.From, inputStruct.To, inputStruct.Value
+	remaining := GetAllowance(stateDB, from, caller)
+	if remaining.Cmp(value) < 0 {
+		return nil, remainingGas, fmt.Errorf("%w: allowance %d, value %d", ErrInsufficientAllowance, remaining, value)
+	}
+	if balance := stateDB.GetBalance(from); balance.Cmp(value) < 0 {
+		return nil, remainingGas, fmt.Errorf("%w: balance %d, value %d", ErrInsufficientBalance, balance, value)
+	}
+	// Transfers of the allowance count towards the value quota of the owner on
+	// the tx allow list, as if the owner sent the value itself.
+	timestamp := accessibleState.GetBlockContext().Timestamp()
+	if _, ok := txallowlist.GetQuota(stateDB, from); ok {
+		if remainingGas, err = contract.DeductGas(remainingGas, TransferFromQuotaGasCost); err != nil {
+			return nil, 0, err
+		}
+		if err := txallowlist.CheckValueQuota(stateDB, from, timestamp, value); err != nil {
+			return nil, remainingGas, err
+		}
+	}
+
+	topics, data, err := PackTransferEvent(from, to, value)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	stateDB.AddLog(
+		ContractAddress,
+		topics,
+		data,
+		accessibleState.GetBlockContext().Number().Uint64(),
+	)
+	if remaining.Cmp(math.MaxBig256) != 0 {
+		SetAllowance(stateDB, from, caller, remaining.Sub(remaining, value))
+	}
+	// if there is no address in the state, create one.
+	if !stateDB.Exist(to) {
+		stateDB.CreateAccount(to)
+	}
+	stateDB.SubBalance(from, value)
+	stateDB.AddBalance(to, value)
+	txallowlist.ConsumeQuota(stateDB, from, timestamp, 0, value)
+
+	packedOutput, err := PackTransferFromOutput(true)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// createNativeAllowancePrecompile returns a StatefulPrecompiledContract with the
+// ERC-20 allowance functions over the native coin.
+func createNativeAllowancePrecompile() contract.StatefulPrecompiledContract {
+	var functions []*contract.StatefulPrecompileFunction
+
+	abiFunctionMap := map[string]contract.RunStatefulPrecompileFunc{
+		"DOMAIN_SEPARATOR": domainSeparator,
+		"allowance":        allowance,
+		"approve":          approve,
+		"balanceOf":        balanceOf,
+		"nonces":           nonces,
+		"permit":           permit,
+		"transferFrom":     transferFrom,
+	}
+
+	for name, function := range abiFunctionMap {
+		method, ok := NativeAllowanceABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, contract.NewStatefulPrecompileFunction(method.ID, function))
+	}
+	// Construct the contract with no fallback function.
+	statefulContract, err := contract.NewStatefulPrecompileContract(nil, functions)
+	if err != nil {
+		panic(err)
+	}
+	return statefulContract
+}
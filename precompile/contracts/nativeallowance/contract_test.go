@@ -0,0 +1,337 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nativeallowance
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ava-labs/subnet-evm/precompile/testutils"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testChainID   = big.NewInt(1) // chain ID of the default test chain config
+	testSpender   = common.HexToAddress("0x0000000000000000000000000000000000000022")
+	testRecipient = common.HexToAddress("0x0000000000000000000000000000000000000033")
+)
+
+func signPermit(t testing.TB, key *ecdsa.PrivateKey, activation uint64, spender common.Address, value *big.Int, nonce *big.Int, deadline *big.Int) PermitInput {
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	digest := PermitDigest(testChainID, activation, owner, spender, value, nonce, deadline)
+	sig, err := crypto.Sign(digest.Bytes(), key)
+	require.NoError(t, err)
+	input := PermitInput{
+		Owner:    owner,
+		Spender:  spender,
+		Value:    value,
+		Deadline: deadline,
+		V:        sig[64] + 27,
+	}
+	copy(input.R[:], sig[:32])
+	copy(input.S[:], sig[32:64])
+	return input
+}
+
+func TestNativeAllowance(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	setBalanceAndAllowance := func(balance int64, allowance *big.Int) func(t testing.TB, state contract.StateDB) {
+		return func(t testing.TB, state contract.StateDB) {
+			state.AddBalance(owner, big.NewInt(balance))
+			SetAllowance(state, owner, testSpender, allowance)
+		}
+	}
+	packPermit := func(input PermitInput) func(t testing.TB) []byte {
+		return func(t testing.TB) []byte {
+			packed, err := PackPermit(input)
+			require.NoError(t, err)
+			return packed
+		}
+	}
+	packTransferFrom := func(value int64) func(t testing.TB) []byte {
+		return func(t testing.TB) []byte {
+			input, err := PackTransferFrom(TransferFromInput{From: owner, To: testRecipient, Value: big.NewInt(value)})
+			require.NoError(t, err)
+			return input
+		}
+	}
+	packBool := func(success bool) []byte {
+		output, err := PackTransferFromOutput(success)
+		require.NoError(t, err)
+		return output
+	}
+
+	tests := map[string]testutils.PrecompileTest{
+		"approve": {
+			Caller: owner,
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackApprove(ApproveInput{Spender: testSpender, Value: big.NewInt(100)})
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ApproveGasCost,
+			ExpectedRes: packBool(true),
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.Equal(t, big.NewInt(100), GetAllowance(state, owner, testSpender))
+				require.Zero(t, GetAllowance(state, testSpender, owner).Sign())
+
+				logsTopics, logsData := state.GetLogData()
+				require.Len(t, logsTopics, 1)
+				require.Equal(t, NativeAllowanceABI.Events["Approval"].ID, logsTopics[0][0])
+				value, err := UnpackApprovalEventData(logsData[0])
+				require.NoError(t, err)
+				require.Equal(t, big.NewInt(100), value)
+			},
+		},
+		"approve readOnly": {
+			Caller: owner,
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackApprove(ApproveInput{Spender: testSpender, Value: big.NewInt(100)})
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ApproveGasCost,
+			ReadOnly:    true,
+			ExpectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"approve insufficient gas": {
+			Caller: owner,
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackApprove(ApproveInput{Spender: testSpender, Value: big.NewInt(100)})
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ApproveGasCost - 1,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"allowance": {
+			BeforeHook: setBalanceAndAllowance(0, big.NewInt(100)),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackAllowance(AllowanceInput{Owner: owner, Spender: testSpender})
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: AllowanceGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackAllowanceOutput(big.NewInt(100))
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"balanceOf": {
+			BeforeHook: setBalanceAndAllowance(1000, common.Big0),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackBalanceOf(owner)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: BalanceOfGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackBalanceOfOutput(big.NewInt(1000))
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"transferFrom": {
+			Caller:      testSpender,
+			BeforeHook:  setBalanceAndAllowance(1000, big.NewInt(300)),
+			InputFn:     packTransferFrom(100),
+			SuppliedGas: TransferFromGasCost,
+			ExpectedRes: packBool(true),
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.Equal(t, big.NewInt(900), state.GetBalance(owner))
+				require.Equal(t, big.NewInt(100), state.GetBalance(testRecipient))
+				require.Equal(t, big.NewInt(200), GetAllowance(state, owner, testSpender))
+
+				logsTopics, logsData := state.GetLogData()
+				require.Len(t, logsTopics, 1)
+				require.Equal(t, []common.Hash{
+					NativeAllowanceABI.Events["Transfer"].ID,
+					common.BytesToHash(owner.Bytes()),
+					common.BytesToHash(testRecipient.Bytes()),
+				}, logsTopics[0])
+				value, err := UnpackTransferEventData(logsData[0])
+				require.NoError(t, err)
+				require.Equal(t, big.NewInt(100), value)
+			},
+		},
+		"transferFrom infinite allowance": {
+			Caller:      testSpender,
+			BeforeHook:  setBalanceAndAllowance(1000, math.MaxBig256),
+			InputFn:     packTransferFrom(100),
+			SuppliedGas: TransferFromGasCost,
+			ExpectedRes: packBool(true),
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.Equal(t, big.NewInt(900), state.GetBalance(owner))
+				require.Equal(t, math.MaxBig256, GetAllowance(state, owner, testSpender))
+			},
+		},
+		"transferFrom insufficient allowance": {
+			Caller:      testSpender,
+			BeforeHook:  setBalanceAndAllowance(1000, big.NewInt(99)),
+			InputFn:     packTransferFrom(100),
+			SuppliedGas: TransferFromGasCost,
+			ExpectedErr: ErrInsufficientAllowance.Error(),
+		},
+		"transferFrom without allowance of caller": {
+			Caller:      testRecipient,
+			BeforeHook:  setBalanceAndAllowance(1000, big.NewInt(300)),
+			InputFn:     packTransferFrom(100),
+			SuppliedGas: TransferFromGasCost,
+			ExpectedErr: ErrInsufficientAllowance.Error(),
+		},
+		"transferFrom insufficient balance": {
+			Caller:      testSpender,
+			BeforeHook:  setBalanceAndAllowance(99, big.NewInt(300)),
+			InputFn:     packTransferFrom(100),
+			SuppliedGas: TransferFromGasCost,
+			ExpectedErr: ErrInsufficientBalance.Error(),
+		},
+		"transferFrom within owner quota": {
+			Caller: testSpender,
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				setBalanceAndAllowance(1000, big.NewInt(300))(t, state)
+				txallowlist.SetQuota(state, owner, txallowlist.Quota{MaxTxValue: big.NewInt(100), MaxDailyValue: big.NewInt(150)})
+			},
+			InputFn:     packTransferFrom(100),
+			SuppliedGas: TransferFromGasCost + TransferFromQuotaGasCost,
+			ExpectedRes: packBool(true),
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				usage := txallowlist.GetQuotaUsage(state, owner, uint64(time.Now().Unix()))
+				require.Equal(t, big.NewInt(100), usage.ValueUsed)
+			},
+		},
+		"transferFrom exceeds owner quota": {
+			Caller: testSpender,
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				setBalanceAndAllowance(1000, big.NewInt(300))(t, state)
+				txallowlist.SetQuota(state, owner, txallowlist.Quota{MaxTxValue: big.NewInt(99)})
+			},
+			InputFn:     packTransferFrom(100),
+			SuppliedGas: TransferFromGasCost + TransferFromQuotaGasCost,
+			ExpectedErr: txallowlist.ErrTxValueQuotaExceeded.Error(),
+		},
+		"transferFrom readOnly": {
+			Caller:      testSpender,
+			BeforeHook:  setBalanceAndAllowance(1000, big.NewInt(300)),
+			InputFn:     packTransferFrom(100),
+			SuppliedGas: TransferFromGasCost,
+			ReadOnly:    true,
+			ExpectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"permit": {
+			Caller:      testRecipient,
+			InputFn:     packPermit(signPermit(t, key, 0, testSpender, big.NewInt(100), common.Big0, math.MaxBig256)),
+			SuppliedGas: PermitGasCost,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.Equal(t, big.NewInt(100), GetAllowance(state, owner, testSpender))
+				require.Equal(t, common.Big1, GetNonce(state, owner))
+			},
+		},
+		"permit after activation": {
+			Caller:      testRecipient,
+			Config:      NewConfig(utils.NewUint64(10)),
+			InputFn:     packPermit(signPermit(t, key, 10, testSpender, big.NewInt(100), common.Big0, math.MaxBig256)),
+			SuppliedGas: PermitGasCost,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.Equal(t, big.NewInt(100), GetAllowance(state, owner, testSpender))
+			},
+		},
+		"permit of previous activation": {
+			Caller:      testRecipient,
+			Config:      NewConfig(utils.NewUint64(10)),
+			InputFn:     packPermit(signPermit(t, key, 0, testSpender, big.NewInt(100), common.Big0, math.MaxBig256)),
+			SuppliedGas: PermitGasCost,
+			ExpectedErr: ErrInvalidSignature.Error(),
+		},
+		"permit reused nonce": {
+			Caller: testRecipient,
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				state.SetState(ContractAddress, nonceKey(owner), common.BigToHash(common.Big1))
+			},
+			InputFn:     packPermit(signPermit(t, key, 0, testSpender, big.NewInt(100), common.Big0, math.MaxBig256)),
+			SuppliedGas: PermitGasCost,
+			ExpectedErr: ErrInvalidSignature.Error(),
+		},
+		"permit signed by another key": {
+			Caller: testRecipient,
+			InputFn: func() func(t testing.TB) []byte {
+				input := signPermit(t, otherKey, 0, testSpender, big.NewInt(100), common.Big0, math.MaxBig256)
+				input.Owner = owner
+				return packPermit(input)
+			}(),
+			SuppliedGas: PermitGasCost,
+			ExpectedErr: ErrInvalidSignature.Error(),
+		},
+		"permit invalid v": {
+			Caller: testRecipient,
+			InputFn: func() func(t testing.TB) []byte {
+				input := signPermit(t, key, 0, testSpender, big.NewInt(100), common.Big0, math.MaxBig256)
+				input.V = 1
+				return packPermit(input)
+			}(),
+			SuppliedGas: PermitGasCost,
+			ExpectedErr: ErrInvalidSignature.Error(),
+		},
+		"permit expired": {
+			Caller:      testRecipient,
+			InputFn:     packPermit(signPermit(t, key, 0, testSpender, big.NewInt(100), common.Big0, common.Big1)),
+			SuppliedGas: PermitGasCost,
+			ExpectedErr: ErrPermitExpired.Error(),
+		},
+		"nonces": {
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				state.SetState(ContractAddress, nonceKey(owner), common.BigToHash(common.Big2))
+			},
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackNonces(owner)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: NoncesGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackNoncesOutput(common.Big2)
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"DOMAIN_SEPARATOR": {
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackDomainSeparator()
+				require.NoError(t, err)
+				return input
+			},
+			Config:      NewConfig(utils.NewUint64(10)),
+			SuppliedGas: DomainSeparatorGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackDomainSeparatorOutput(DomainSeparator(testChainID, 10))
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+	}
+
+	testutils.RunPrecompileTests(t, Module, state.NewTestStateDB, tests)
+}
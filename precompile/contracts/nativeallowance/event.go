@@ -0,0 +1,52 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nativeallowance
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// ApprovalEventGasCost is the gas cost of the Approval event.
+	// It is the base gas cost + the gas cost of the topics (signature, owner, spender)
+	// and the gas cost of the non-indexed data (32 bytes for value).
+	ApprovalEventGasCost = contract.LogGas + contract.LogTopicGas*3 + contract.LogDataGas*common.HashLength
+	// TransferEventGasCost is the gas cost of the Transfer event.
+	// It is the base gas cost + the gas cost of the topics (signature, from, to)
+	// and the gas cost of the non-indexed data (32 bytes for value).
+	TransferEventGasCost = contract.LogGas + contract.LogTopicGas*3 + contract.LogDataGas*common.HashLength
+)
+
+// PackApprovalEvent packs the event into the appropriate arguments for Approval.
+// It returns topic hashes and the encoded non-indexed data.
+func PackApprovalEvent(owner common.Address, spender common.Address, value *big.Int) ([]common.Hash, []byte, error) {
+	return NativeAllowanceABI.PackEvent("Approval", owner, spender, value)
+}
+
+// UnpackApprovalEventData attempts to unpack non-indexed [dataBytes].
+func UnpackApprovalEventData(dataBytes []byte) (*big.Int, error) {
+	var eventData = struct {
+		Value *big.Int
+	}{}
+	err := NativeAllowanceABI.UnpackIntoInterface(&eventData, "Approval", dataBytes)
+	return eventData.Value, err
+}
+
+// PackTransferEvent packs the event into the appropriate arguments for Transfer.
+// It returns topic hashes and the encoded non-indexed data.
+func PackTransferEvent(from common.Address, to common.Address, value *big.Int) ([]common.Hash, []byte, error) {
+	return NativeAllowanceABI.PackEvent("Transfer", from, to, value)
+}
+
+// UnpackTransferEventData attempts to unpack non-indexed [dataBytes].
+func UnpackTransferEventData(dataBytes []byte) (*big.Int, error) {
+	var eventData = struct {
+		Value *big.Int
+	}{}
+	err := NativeAllowanceABI.UnpackIntoInterface(&eventData, "Transfer", dataBytes)
+	return eventData.Value, err
+}
@@ -21,6 +21,7 @@ var _ precompileconfig.Config = &Config{}
 type Config struct {
 	allowlist.AllowListConfig
 	precompileconfig.Upgrade
+	precompileconfig.GasCostOverrides
 	InitialMint map[common.Address]*math.HexOrDecimal256 `json:"initialMint,omitempty"` // addresses to receive the initial mint mapped to the amount to mint
 }
 
@@ -61,7 +62,7 @@ func (c *Config) Equal(cfg precompileconfig.Config) bool {
 	if !ok {
 		return false
 	}
-	eq := c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig)
+	eq := c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig) && c.GasCostOverrides.Equal(&other.GasCostOverrides)
 	if !eq {
 		return false
 	}
@@ -96,5 +97,8 @@ func (c *Config) Verify(chainConfig precompileconfig.ChainConfig) error {
 			return fmt.Errorf("initial mint cannot contain invalid amount %v for address %s", bigIntAmount, addr)
 		}
 	}
+	if err := c.GasCostOverrides.Verify(GasSchedule); err != nil {
+		return err
+	}
 	return c.AllowListConfig.Verify(chainConfig, c.Upgrade)
 }
@@ -57,6 +57,33 @@ func TestVerify(t *testing.T) {
 				}),
 			ExpectedError: "initial mint cannot contain invalid amount",
 		},
+		"gas cost override within bounds": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, nil, nil, nil)
+				config.GasCosts = map[string]uint64{
+					MintGasCostKey:                    2 * MintGasCost,
+					allowlist.ReadAllowListGasCostKey: allowlist.ReadAllowListGasCost / 2,
+				}
+				return config
+			}(),
+			ExpectedError: "",
+		},
+		"gas cost override out of bounds": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, nil, nil, nil)
+				config.GasCosts = map[string]uint64{MintGasCostKey: 1}
+				return config
+			}(),
+			ExpectedError: "out of bounds",
+		},
+		"unknown gas cost override": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, nil, nil, nil)
+				config.GasCosts = map[string]uint64{"setFeeConfig": MintGasCost}
+				return config
+			}(),
+			ExpectedError: "unknown gas cost",
+		},
 	}
 	allowlist.VerifyPrecompileWithAllowListTests(t, Module, tests)
 }
@@ -103,6 +130,15 @@ func TestEqual(t *testing.T) {
 				}),
 			Expected: false,
 		},
+		"different gas costs": {
+			Config: NewConfig(utils.NewUint64(3), admins, nil, nil, nil),
+			Other: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, nil, nil, nil)
+				config.GasCosts = map[string]uint64{MintGasCostKey: 2 * MintGasCost}
+				return config
+			}(),
+			Expected: false,
+		},
 		"same config": {
 			Config: NewConfig(utils.NewUint64(3), admins, nil, nil,
 				map[common.Address]*math.HexOrDecimal256{
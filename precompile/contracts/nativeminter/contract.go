@@ -11,6 +11,7 @@ import (
 
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
 	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -21,6 +22,18 @@ const (
 	MintGasCost = 30_000
 )
 
+// Names of the gas costs of the precompile, which can be overridden by its
+// config in addition to the allow list gas costs.
+const (
+	MintGasCostKey = "mintNativeCoin"
+)
+
+// GasSchedule contains the gas costs of the precompile operations, including
+// the allow list operations.
+var GasSchedule = allowlist.GasSchedule.With(precompileconfig.GasSchedule{
+	MintGasCostKey: precompileconfig.NewGasCost(MintGasCost),
+})
+
 type MintNativeCoinInput struct {
 	Addr   common.Address
 	Amount *big.Int
@@ -76,7 +89,8 @@ func UnpackMintNativeCoinInput(input []byte, useStrictMode bool) (common.Address
 // mintNativeCoin checks if the caller is permissioned for minting operation.
 // The execution function parses the [input] into native coin amount and receiver address.
 func mintNativeCoin(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, MintGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, MintGasCostKey, MintGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/ava-labs/subnet-evm/precompile/contract"
 	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ava-labs/subnet-evm/precompile/testutils"
+	"github.com/ava-labs/subnet-evm/utils"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -245,9 +246,63 @@ var (
 				assertNativeCoinMintedEvent(t, logsTopics, logsData, allowlist.TestEnabledAddr, allowlist.TestEnabledAddr, common.Big1)
 			},
 		},
+		"calling mintNativeCoin with overridden gas cost and insufficient gas should fail": {
+			Caller:        allowlist.TestEnabledAddr,
+			BeforeHook:    allowlist.SetDefaultRoles(Module.Address),
+			ChainConfigFn: gasOverrideChainConfig(map[string]uint64{MintGasCostKey: 2 * MintGasCost}),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackMintNativeCoin(allowlist.TestEnabledAddr, common.Big1)
+				require.NoError(t, err)
+
+				return input
+			},
+			SuppliedGas: MintGasCost + NativeCoinMintedEventGasCost,
+			ReadOnly:    false,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"calling mintNativeCoin with overridden gas cost should succeed": {
+			Caller:        allowlist.TestEnabledAddr,
+			BeforeHook:    allowlist.SetDefaultRoles(Module.Address),
+			ChainConfigFn: gasOverrideChainConfig(map[string]uint64{MintGasCostKey: 2 * MintGasCost}),
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackMintNativeCoin(allowlist.TestEnabledAddr, common.Big1)
+				require.NoError(t, err)
+
+				return input
+			},
+			SuppliedGas: 2*MintGasCost + NativeCoinMintedEventGasCost,
+			ReadOnly:    false,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, stateDB contract.StateDB) {
+				require.Equal(t, common.Big1, stateDB.GetBalance(allowlist.TestEnabledAddr), "expected minted funds")
+			},
+		},
 	}
 )
 
+// activeConfigChainConfig is a ChainConfig returning [config] as the active
+// config of every precompile.
+type activeConfigChainConfig struct {
+	precompileconfig.ChainConfig
+	config precompileconfig.Config
+}
+
+func (c *activeConfigChainConfig) GetActivePrecompileConfig(common.Address, uint64) precompileconfig.Config {
+	return c.config
+}
+
+// gasOverrideChainConfig returns a ChainConfigFn whose active native minter
+// config overrides [gasCosts].
+func gasOverrideChainConfig(gasCosts map[string]uint64) func(*gomock.Controller) precompileconfig.ChainConfig {
+	return func(ctrl *gomock.Controller) precompileconfig.ChainConfig {
+		mockChainConfig := precompileconfig.NewMockChainConfig(ctrl)
+		mockChainConfig.EXPECT().IsDurango(gomock.Any()).AnyTimes().Return(true)
+		config := NewConfig(utils.NewUint64(0), nil, nil, nil, nil)
+		config.GasCosts = gasCosts
+		return &activeConfigChainConfig{ChainConfig: mockChainConfig, config: config}
+	}
+}
+
 func TestContractNativeMinterRun(t *testing.T) {
 	allowlist.RunPrecompileWithAllowListTests(t, Module, state.NewTestStateDB, tests)
 }
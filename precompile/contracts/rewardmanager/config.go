@@ -58,6 +58,7 @@ func (i *InitialRewardConfig) Configure(state contract.StateDB) error {
 type Config struct {
 	allowlist.AllowListConfig
 	precompileconfig.Upgrade
+	precompileconfig.GasCostOverrides
 	InitialRewardConfig *InitialRewardConfig `json:"initialRewardConfig,omitempty"`
 }
 
@@ -97,6 +98,9 @@ func (c *Config) Verify(chainConfig precompileconfig.ChainConfig) error {
 			return err
 		}
 	}
+	if err := c.GasCostOverrides.Verify(GasSchedule); err != nil {
+		return err
+	}
 	return c.AllowListConfig.Verify(chainConfig, c.Upgrade)
 }
 
@@ -117,5 +121,5 @@ func (c *Config) Equal(cfg precompileconfig.Config) bool {
 		}
 	}
 
-	return c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig)
+	return c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig) && c.GasCostOverrides.Equal(&other.GasCostOverrides)
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
 	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -28,6 +29,26 @@ const (
 	SetRewardAddressGasCost        uint64 = contract.WriteGasCostPerSlot + allowlist.ReadAllowListGasCost // write 1 slot + read allow list
 )
 
+// Names of the gas costs of the precompile, which can be overridden by its
+// config in addition to the allow list gas costs.
+const (
+	AllowFeeRecipientsGasCostKey      = "allowFeeRecipients"
+	AreFeeRecipientsAllowedGasCostKey = "areFeeRecipientsAllowed"
+	CurrentRewardAddressGasCostKey    = "currentRewardAddress"
+	DisableRewardsGasCostKey          = "disableRewards"
+	SetRewardAddressGasCostKey        = "setRewardAddress"
+)
+
+// GasSchedule contains the gas costs of the precompile operations, including
+// the allow list operations.
+var GasSchedule = allowlist.GasSchedule.With(precompileconfig.GasSchedule{
+	AllowFeeRecipientsGasCostKey:      precompileconfig.NewGasCost(AllowFeeRecipientsGasCost),
+	AreFeeRecipientsAllowedGasCostKey: precompileconfig.NewGasCost(AreFeeRecipientsAllowedGasCost),
+	CurrentRewardAddressGasCostKey:    precompileconfig.NewGasCost(CurrentRewardAddressGasCost),
+	DisableRewardsGasCostKey:          precompileconfig.NewGasCost(DisableRewardsGasCost),
+	SetRewardAddressGasCostKey:        precompileconfig.NewGasCost(SetRewardAddressGasCost),
+})
+
 // Singleton StatefulPrecompiledContract and signatures.
 var (
 	ErrCannotAllowFeeRecipients      = errors.New("non-enabled cannot call allowFeeRecipients")
@@ -78,7 +99,8 @@ func DisableFeeRewards(stateDB contract.StateDB) {
 }
 
 func allowFeeRecipients(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, AllowFeeRecipientsGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, AllowFeeRecipientsGasCostKey, AllowFeeRecipientsGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 	if readOnly {
@@ -130,7 +152,8 @@ func PackAreFeeRecipientsAllowedOutput(isAllowed bool) ([]byte, error) {
 }
 
 func areFeeRecipientsAllowed(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, AreFeeRecipientsAllowedGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, AreFeeRecipientsAllowedGasCostKey, AreFeeRecipientsAllowedGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 	// no input provided for this function
@@ -192,7 +215,8 @@ func UnpackSetRewardAddressInput(input []byte, useStrictMode bool) (common.Addre
 }
 
 func setRewardAddress(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, SetRewardAddressGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, SetRewardAddressGasCostKey, SetRewardAddressGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 	if readOnly {
@@ -246,7 +270,8 @@ func setRewardAddress(accessibleState contract.AccessibleState, caller common.Ad
 }
 
 func currentRewardAddress(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, CurrentRewardAddressGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, CurrentRewardAddressGasCostKey, CurrentRewardAddressGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 
@@ -269,7 +294,8 @@ func PackDisableRewards() ([]byte, error) {
 }
 
 func disableRewards(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, DisableRewardsGasCost); err != nil {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, DisableRewardsGasCostKey, DisableRewardsGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 	if readOnly {
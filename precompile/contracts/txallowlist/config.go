@@ -4,7 +4,11 @@
 package txallowlist
 
 import (
+	"fmt"
+	"maps"
+
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
 	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -16,6 +20,45 @@ var _ precompileconfig.Config = &Config{}
 type Config struct {
 	allowlist.AllowListConfig
 	precompileconfig.Upgrade
+	precompileconfig.GasCostOverrides
+
+	// Quotas enables admins to limit the gas and value of the transactions of
+	// each address, if not nil.
+	Quotas *QuotasConfig `json:"quotas,omitempty"`
+}
+
+// QuotasConfig specifies the initial quotas of addresses.
+type QuotasConfig struct {
+	AddressQuotas map[common.Address]*Quota `json:"addressQuotas,omitempty"`
+}
+
+// Configure enables the quotas and sets the initial quotas.
+func (c *QuotasConfig) Configure(state contract.StateDB) {
+	EnableQuotas(state)
+	for address, quota := range c.AddressQuotas {
+		SetQuota(state, address, *quota)
+	}
+}
+
+// Equal returns true iff [other] sets the same initial quotas.
+func (c *QuotasConfig) Equal(other *QuotasConfig) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return maps.EqualFunc(c.AddressQuotas, other.AddressQuotas, (*Quota).Equal)
+}
+
+// Verify returns an error if an initial quota is invalid.
+func (c *QuotasConfig) Verify() error {
+	for address, quota := range c.AddressQuotas {
+		if quota == nil {
+			return fmt.Errorf("%w: missing quota of %s", ErrInvalidQuota, address)
+		}
+		if err := quota.Verify(); err != nil {
+			return fmt.Errorf("quota of %s: %w", address, err)
+		}
+	}
+	return nil
 }
 
 // NewConfig returns a config for a network upgrade at [blockTimestamp] that enables
@@ -51,9 +94,17 @@ func (c *Config) Equal(cfg precompileconfig.Config) bool {
 	if !ok {
 		return false
 	}
-	return c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig)
+	return c.Upgrade.Equal(&other.Upgrade) && c.AllowListConfig.Equal(&other.AllowListConfig) && c.GasCostOverrides.Equal(&other.GasCostOverrides) && c.Quotas.Equal(other.Quotas)
 }
 
 func (c *Config) Verify(chainConfig precompileconfig.ChainConfig) error {
+	if err := c.GasCostOverrides.Verify(GasSchedule); err != nil {
+		return err
+	}
+	if c.Quotas != nil {
+		if err := c.Quotas.Verify(); err != nil {
+			return err
+		}
+	}
 	return c.AllowListConfig.Verify(chainConfig, c.Upgrade)
 }
@@ -4,6 +4,7 @@
 package txallowlist
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
@@ -15,7 +16,45 @@ import (
 )
 
 func TestVerify(t *testing.T) {
-	allowlist.VerifyPrecompileWithAllowListTests(t, Module, nil)
+	admins := []common.Address{allowlist.TestAdminAddr}
+	withQuota := func(quota *Quota) *Config {
+		config := NewConfig(utils.NewUint64(3), admins, nil, nil)
+		config.Quotas = &QuotasConfig{
+			AddressQuotas: map[common.Address]*Quota{allowlist.TestEnabledAddr: quota},
+		}
+		return config
+	}
+	tests := map[string]testutils.ConfigVerifyTest{
+		"valid quota": {
+			Config:        withQuota(&Quota{MaxGas: 1_000_000, GasWindow: 60, MaxTxValue: big.NewInt(1), MaxDailyValue: big.NewInt(10)}),
+			ExpectedError: "",
+		},
+		"max gas without gas window": {
+			Config:        withQuota(&Quota{MaxGas: 1_000_000}),
+			ExpectedError: "gasWindow must be positive",
+		},
+		"negative value limit": {
+			Config:        withQuota(&Quota{MaxTxValue: big.NewInt(-1)}),
+			ExpectedError: "cannot be negative",
+		},
+		"value limit too large": {
+			Config:        withQuota(&Quota{MaxDailyValue: new(big.Int).Lsh(common.Big1, 256)}),
+			ExpectedError: "exceeds 256 bits",
+		},
+		"missing quota": {
+			Config:        withQuota(nil),
+			ExpectedError: "missing quota",
+		},
+		"quota gas cost override": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, nil, nil)
+				config.GasCosts = map[string]uint64{SetQuotaGasCostKey: 2 * SetQuotaGasCost}
+				return config
+			}(),
+			ExpectedError: "",
+		},
+	}
+	allowlist.VerifyPrecompileWithAllowListTests(t, Module, tests)
 }
 
 func TestEqual(t *testing.T) {
@@ -38,6 +77,41 @@ func TestEqual(t *testing.T) {
 			Other:    NewConfig(utils.NewUint64(4), admins, enableds, managers),
 			Expected: false,
 		},
+		"different quotas": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, enableds, managers)
+				config.Quotas = &QuotasConfig{AddressQuotas: map[common.Address]*Quota{{1}: {MaxTxValue: big.NewInt(1)}}}
+				return config
+			}(),
+			Other: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, enableds, managers)
+				config.Quotas = &QuotasConfig{AddressQuotas: map[common.Address]*Quota{{1}: {MaxTxValue: big.NewInt(2)}}}
+				return config
+			}(),
+			Expected: false,
+		},
+		"nil and empty quotas": {
+			Config: NewConfig(utils.NewUint64(3), admins, enableds, managers),
+			Other: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, enableds, managers)
+				config.Quotas = &QuotasConfig{}
+				return config
+			}(),
+			Expected: false,
+		},
+		"same quotas": {
+			Config: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, enableds, managers)
+				config.Quotas = &QuotasConfig{AddressQuotas: map[common.Address]*Quota{{1}: {MaxTxValue: big.NewInt(1)}}}
+				return config
+			}(),
+			Other: func() *Config {
+				config := NewConfig(utils.NewUint64(3), admins, enableds, managers)
+				config.Quotas = &QuotasConfig{AddressQuotas: map[common.Address]*Quota{{1}: {MaxTxValue: big.NewInt(1)}}}
+				return config
+			}(),
+			Expected: true,
+		},
 		"same config": {
 			Config:   NewConfig(utils.NewUint64(3), admins, enableds, managers),
 			Other:    NewConfig(utils.NewUint64(3), admins, enableds, managers),
@@ -4,13 +4,33 @@
 package txallowlist
 
 import (
+	_ "embed"
+	"fmt"
+
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
 	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
 	"github.com/ethereum/go-ethereum/common"
 )
 
-// Singleton StatefulPrecompiledContract for W/R access to the tx allow list.
-var TxAllowListPrecompile contract.StatefulPrecompiledContract = allowlist.CreateAllowListPrecompile(ContractAddress)
+// GasSchedule contains the gas costs of the precompile operations, including
+// the allow list operations.
+var GasSchedule = allowlist.GasSchedule.With(precompileconfig.GasSchedule{
+	SetQuotaGasCostKey:       precompileconfig.NewGasCost(SetQuotaGasCost),
+	ReadQuotaGasCostKey:      precompileconfig.NewGasCost(ReadQuotaGasCost),
+	ReadQuotaUsageGasCostKey: precompileconfig.NewGasCost(ReadQuotaUsageGasCost),
+})
+
+var (
+	// Singleton StatefulPrecompiledContract for W/R access to the tx allow list.
+	TxAllowListPrecompile contract.StatefulPrecompiledContract = createTxAllowListPrecompile()
+
+	// TxAllowListRawABI contains the raw ABI of TxAllowList contract.
+	//go:embed contract.abi
+	TxAllowListRawABI string
+
+	TxAllowListABI = contract.ParseABI(TxAllowListRawABI)
+)
 
 // GetTxAllowListStatus returns the role of [address] for the tx allow list.
 func GetTxAllowListStatus(stateDB contract.StateDB, address common.Address) allowlist.Role {
@@ -23,3 +43,29 @@ func GetTxAllowListStatus(stateDB contract.StateDB, address common.Address) allo
 func SetTxAllowListStatus(stateDB contract.StateDB, address common.Address, role allowlist.Role) {
 	allowlist.SetAllowListRole(stateDB, ContractAddress, address, role)
 }
+
+// createTxAllowListPrecompile returns a StatefulPrecompiledContract with R/W control
+// of the allow list and of the quotas of the tx allow list.
+func createTxAllowListPrecompile() contract.StatefulPrecompiledContract {
+	var functions []*contract.StatefulPrecompileFunction
+	functions = append(functions, allowlist.CreateAllowListFunctions(ContractAddress)...)
+
+	abiFunctionMap := map[string]contract.RunStatefulPrecompileFunc{
+		"setQuota":       setQuota,
+		"readQuota":      readQuota,
+		"readQuotaUsage": readQuotaUsage,
+	}
+	for name, function := range abiFunctionMap {
+		method, ok := TxAllowListABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, contract.NewStatefulPrecompileFunctionWithActivator(method.ID, function, isQuotasActivated))
+	}
+	// Construct the contract with no fallback function.
+	statefulContract, err := contract.NewStatefulPrecompileContract(nil, functions)
+	if err != nil {
+		panic(err)
+	}
+	return statefulContract
+}
@@ -4,16 +4,195 @@
 package txallowlist
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/precompile/testutils"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
 )
 
+var testQuota = Quota{
+	MaxGas:        1_000_000,
+	GasWindow:     60,
+	MaxTxValue:    big.NewInt(100),
+	MaxDailyValue: big.NewInt(1_000),
+}
+
+func setDefaultQuotas(t testing.TB, state contract.StateDB) {
+	EnableQuotas(state)
+	allowlist.SetDefaultRoles(ContractAddress)(t, state)
+}
+
+func packSetQuota(quota Quota) func(t testing.TB) []byte {
+	return func(t testing.TB) []byte {
+		input, err := PackSetQuota(allowlist.TestEnabledAddr, quota)
+		require.NoError(t, err)
+		return input
+	}
+}
+
+func quotaTests(t testing.TB) map[string]testutils.PrecompileTest {
+	return map[string]testutils.PrecompileTest{
+		"admin sets quota": {
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  setDefaultQuotas,
+			InputFn:     packSetQuota(testQuota),
+			SuppliedGas: SetQuotaGasCost + QuotaSetEventGasCost,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				quota, ok := GetQuota(state, allowlist.TestEnabledAddr)
+				require.True(t, ok)
+				require.True(t, testQuota.Equal(&quota))
+
+				logsTopics, logsData := state.GetLogData()
+				require.Len(t, logsTopics, 1)
+				require.Equal(t, []common.Hash{
+					TxAllowListABI.Events["QuotaSet"].ID,
+					common.BytesToHash(allowlist.TestEnabledAddr.Bytes()),
+					common.BytesToHash(allowlist.TestAdminAddr.Bytes()),
+				}, logsTopics[0])
+				eventQuota, err := UnpackQuotaSetEventData(logsData[0])
+				require.NoError(t, err)
+				require.True(t, testQuota.Equal(&eventQuota))
+			},
+		},
+		"admin removes quota": {
+			Caller: allowlist.TestAdminAddr,
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				setDefaultQuotas(t, state)
+				SetQuota(state, allowlist.TestEnabledAddr, testQuota)
+			},
+			InputFn:     packSetQuota(Quota{}),
+			SuppliedGas: SetQuotaGasCost + QuotaSetEventGasCost,
+			ExpectedRes: []byte{},
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				_, ok := GetQuota(state, allowlist.TestEnabledAddr)
+				require.False(t, ok)
+			},
+		},
+		"admin sets invalid quota": {
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  setDefaultQuotas,
+			InputFn:     packSetQuota(Quota{MaxGas: 1}),
+			SuppliedGas: SetQuotaGasCost + QuotaSetEventGasCost,
+			ExpectedErr: ErrInvalidQuota.Error(),
+		},
+		"manager cannot set quota": {
+			Caller:      allowlist.TestManagerAddr,
+			BeforeHook:  setDefaultQuotas,
+			InputFn:     packSetQuota(testQuota),
+			SuppliedGas: SetQuotaGasCost + QuotaSetEventGasCost,
+			ExpectedErr: ErrCannotSetQuota.Error(),
+		},
+		"enabled cannot set quota": {
+			Caller:      allowlist.TestEnabledAddr,
+			BeforeHook:  setDefaultQuotas,
+			InputFn:     packSetQuota(Quota{}),
+			SuppliedGas: SetQuotaGasCost + QuotaSetEventGasCost,
+			ExpectedErr: ErrCannotSetQuota.Error(),
+		},
+		"set quota readOnly": {
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  setDefaultQuotas,
+			InputFn:     packSetQuota(testQuota),
+			SuppliedGas: SetQuotaGasCost + QuotaSetEventGasCost,
+			ReadOnly:    true,
+			ExpectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"set quota insufficient gas": {
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  setDefaultQuotas,
+			InputFn:     packSetQuota(testQuota),
+			SuppliedGas: SetQuotaGasCost + QuotaSetEventGasCost - 1,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"set quota without quotas": {
+			Caller:      allowlist.TestAdminAddr,
+			BeforeHook:  allowlist.SetDefaultRoles(ContractAddress),
+			InputFn:     packSetQuota(testQuota),
+			SuppliedGas: 0,
+			ExpectedErr: "invalid non-activated function selector",
+		},
+		"read quota": {
+			Caller: allowlist.TestNoRoleAddr,
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				setDefaultQuotas(t, state)
+				SetQuota(state, allowlist.TestEnabledAddr, testQuota)
+			},
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackReadQuota(allowlist.TestEnabledAddr)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ReadQuotaGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackReadQuotaOutput(testQuota)
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"read quota usage": {
+			Caller: allowlist.TestNoRoleAddr,
+			BeforeHook: func(t testing.TB, state contract.StateDB) {
+				setDefaultQuotas(t, state)
+				SetQuota(state, allowlist.TestEnabledAddr, testQuota)
+				ConsumeQuota(state, allowlist.TestEnabledAddr, 100, 21_000, big.NewInt(10))
+				ConsumeQuota(state, allowlist.TestEnabledAddr, 150, 21_000, big.NewInt(20))
+			},
+			SetupBlockContext: func(mbc *contract.MockBlockContext) {
+				mbc.EXPECT().Timestamp().Return(uint64(170))
+			},
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackReadQuotaUsage(allowlist.TestEnabledAddr)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ReadQuotaUsageGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				// The gas window that started at 100 elapsed at 160, and 50 of
+				// its 60 seconds are within the sliding window ending at 170.
+				output, err := PackReadQuotaUsageOutput(QuotaUsage{GasUsed: 35_000, ValueUsed: big.NewInt(30)})
+				require.NoError(t, err)
+				return output
+			}(),
+		},
+		"initial config sets quotas": {
+			Caller: allowlist.TestNoRoleAddr,
+			Config: &Config{
+				Quotas: &QuotasConfig{
+					AddressQuotas: map[common.Address]*Quota{allowlist.TestEnabledAddr: &testQuota},
+				},
+			},
+			InputFn: func(t testing.TB) []byte {
+				input, err := PackReadQuota(allowlist.TestEnabledAddr)
+				require.NoError(t, err)
+				return input
+			},
+			SuppliedGas: ReadQuotaGasCost,
+			ReadOnly:    true,
+			ExpectedRes: func() []byte {
+				output, err := PackReadQuotaOutput(testQuota)
+				require.NoError(t, err)
+				return output
+			}(),
+			AfterHook: func(t testing.TB, state contract.StateDB) {
+				require.True(t, IsQuotasEnabled(state))
+			},
+		},
+	}
+}
+
 func TestTxAllowListRun(t *testing.T) {
-	allowlist.RunPrecompileWithAllowListTests(t, Module, state.NewTestStateDB, nil)
+	allowlist.RunPrecompileWithAllowListTests(t, Module, state.NewTestStateDB, quotaTests(t))
 }
 
 func BenchmarkTxAllowList(b *testing.B) {
-	allowlist.BenchPrecompileWithAllowList(b, Module, state.NewTestStateDB, nil)
+	allowlist.BenchPrecompileWithAllowList(b, Module, state.NewTestStateDB, quotaTests(b))
 }
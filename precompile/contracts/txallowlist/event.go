@@ -0,0 +1,39 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txallowlist
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// QuotaSetEventGasCost is the gas cost of the QuotaSet event.
+	// It is the base gas cost + the gas cost of the topics (signature, account, sender)
+	// and the gas cost of the non-indexed data (4 * 32 bytes for the quota).
+	QuotaSetEventGasCost = contract.LogGas + contract.LogTopicGas*3 + contract.LogDataGas*4*common.HashLength
+)
+
+// QuotaSetEventData represents a QuotaSet non-indexed event data raised by the TxAllowList contract.
+type QuotaSetEventData struct {
+	MaxGas        uint64
+	GasWindow     uint64
+	MaxTxValue    *big.Int
+	MaxDailyValue *big.Int
+}
+
+// PackQuotaSetEvent packs the event emitted when [sender] sets the quota of [account] to [quota].
+// It returns topic hashes and the encoded non-indexed data.
+func PackQuotaSetEvent(account common.Address, sender common.Address, quota Quota) ([]common.Hash, []byte, error) {
+	return TxAllowListABI.PackEvent("QuotaSet", account, sender, quota.MaxGas, quota.GasWindow, bigOrZero(quota.MaxTxValue), bigOrZero(quota.MaxDailyValue))
+}
+
+// UnpackQuotaSetEventData attempts to unpack non-indexed [dataBytes] of the QuotaSet event.
+func UnpackQuotaSetEventData(dataBytes []byte) (Quota, error) {
+	eventData := QuotaSetEventData{}
+	err := TxAllowListABI.UnpackIntoInterface(&eventData, "QuotaSet", dataBytes)
+	return Quota(eventData), err
+}
@@ -48,5 +48,8 @@ func (*configurator) Configure(chainConfig precompileconfig.ChainConfig, cfg pre
 	if !ok {
 		return fmt.Errorf("expected config type %T, got %T: %v", &Config{}, cfg, cfg)
 	}
+	if config.Quotas != nil {
+		config.Quotas.Configure(state)
+	}
 	return config.AllowListConfig.Configure(chainConfig, ContractAddress, state, blockContext)
 }
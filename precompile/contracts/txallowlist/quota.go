@@ -0,0 +1,533 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txallowlist
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// ValueQuotaWindow is the length in seconds of the window of the daily value quota.
+	ValueQuotaWindow uint64 = 24 * 60 * 60
+
+	SetQuotaGasCost       uint64 = 3 * contract.WriteGasCostPerSlot
+	ReadQuotaGasCost      uint64 = 3 * contract.ReadGasCostPerSlot
+	ReadQuotaUsageGasCost uint64 = 5 * contract.ReadGasCostPerSlot
+)
+
+// Names of the gas costs of the quota functions, which can be overridden by the
+// config in addition to the allow list gas costs.
+const (
+	SetQuotaGasCostKey       = "setQuota"
+	ReadQuotaGasCostKey      = "readQuota"
+	ReadQuotaUsageGasCostKey = "readQuotaUsage"
+)
+
+var (
+	ErrCannotSetQuota           = errors.New("non-admin cannot set quotas")
+	ErrGasQuotaExceeded         = errors.New("gas quota exceeded")
+	ErrTxValueQuotaExceeded     = errors.New("transaction value quota exceeded")
+	ErrDailyValueQuotaExceeded  = errors.New("daily value quota exceeded")
+	ErrInvalidQuota             = errors.New("invalid quota")
+	errQuotaValueLengthExceeded = errors.New("quota value exceeds 256 bits")
+
+	// Quotas are stored at keys that cannot collide with the roles of the allow
+	// list, which are stored at the address of each account.
+	quotasKey      = crypto.Keccak256Hash([]byte("txallowlist.quotas"))
+	quotaKeyPrefix = []byte("txallowlist.quota")
+
+	quotasEnabled = common.BigToHash(common.Big1)
+)
+
+// Fields of the quota of an account, each stored in its own slot.
+const (
+	quotaLimitsField byte = iota // set flag, max gas and gas window
+	quotaMaxTxValueField
+	quotaMaxDailyValueField
+	quotaGasUsageField // gas window start, gas used in the previous and current windows
+	quotaValueWindowStartField
+	quotaValueUsedField
+	quotaPrevValueUsedField
+)
+
+// Quota limits the transactions an account can issue while the tx allow list
+// is enabled. A zero limit means that the corresponding usage is not limited.
+//
+// Usage is limited over a sliding window. Usage is recorded in consecutive
+// windows, and the usage in the window ending at a given time is the usage in
+// the current window plus the usage in the previous one, weighted by the part
+// of it that overlaps the sliding window.
+type Quota struct {
+	// MaxGas is the maximum amount of gas the account can use in GasWindow seconds.
+	MaxGas    uint64 `json:"maxGas,omitempty"`
+	GasWindow uint64 `json:"gasWindow,omitempty"`
+	// MaxTxValue is the maximum native value of a single transaction, or of a
+	// single transfer of the native allowance of the account.
+	MaxTxValue *big.Int `json:"maxTxValue,omitempty"`
+	// MaxDailyValue is the maximum native value the account can transfer with
+	// its transactions and its native allowance in ValueQuotaWindow seconds.
+	MaxDailyValue *big.Int `json:"maxDailyValue,omitempty"`
+}
+
+// IsZero returns true if [q] does not limit anything.
+func (q *Quota) IsZero() bool {
+	return q.MaxGas == 0 && isZero(q.MaxTxValue) && isZero(q.MaxDailyValue)
+}
+
+// Equal returns true iff [other] has the same limits as [q].
+func (q *Quota) Equal(other *Quota) bool {
+	if q == nil || other == nil {
+		return q == other
+	}
+	return q.MaxGas == other.MaxGas &&
+		q.GasWindow == other.GasWindow &&
+		bigEqual(q.MaxTxValue, other.MaxTxValue) &&
+		bigEqual(q.MaxDailyValue, other.MaxDailyValue)
+}
+
+// Verify returns an error if [q] cannot be stored or enforced.
+func (q *Quota) Verify() error {
+	if q.MaxGas != 0 && q.GasWindow == 0 {
+		return fmt.Errorf("%w: gasWindow must be positive if maxGas is set", ErrInvalidQuota)
+	}
+	for _, value := range []*big.Int{q.MaxTxValue, q.MaxDailyValue} {
+		if value == nil {
+			continue
+		}
+		if value.Sign() < 0 {
+			return fmt.Errorf("%w: value limit %s cannot be negative", ErrInvalidQuota, value)
+		}
+		if value.BitLen() > 256 {
+			return fmt.Errorf("%w: %w", ErrInvalidQuota, errQuotaValueLengthExceeded)
+		}
+	}
+	return nil
+}
+
+// QuotaUsage is the usage of the quota of an account in its current windows.
+type QuotaUsage struct {
+	GasUsed   uint64
+	ValueUsed *big.Int
+}
+
+func isZero(value *big.Int) bool {
+	return value == nil || value.Sign() == 0
+}
+
+func bigEqual(a, b *big.Int) bool {
+	if isZero(a) || isZero(b) {
+		return isZero(a) && isZero(b)
+	}
+	return a.Cmp(b) == 0
+}
+
+func quotaKey(account common.Address, field byte) common.Hash {
+	return crypto.Keccak256Hash(quotaKeyPrefix, account.Bytes(), []byte{field})
+}
+
+// packUint64Pair packs [a] and [b] into the last 16 bytes of a storage slot.
+func packUint64Pair(a, b uint64) common.Hash {
+	var h common.Hash
+	binary.BigEndian.PutUint64(h[16:24], a)
+	binary.BigEndian.PutUint64(h[24:32], b)
+	return h
+}
+
+func unpackUint64Pair(h common.Hash) (uint64, uint64) {
+	return binary.BigEndian.Uint64(h[16:24]), binary.BigEndian.Uint64(h[24:32])
+}
+
+// packUint64Triple packs [a], [b] and [c] into the last 24 bytes of a storage slot.
+func packUint64Triple(a, b, c uint64) common.Hash {
+	h := packUint64Pair(b, c)
+	binary.BigEndian.PutUint64(h[8:16], a)
+	return h
+}
+
+func unpackUint64Triple(h common.Hash) (uint64, uint64, uint64) {
+	b, c := unpackUint64Pair(h)
+	return binary.BigEndian.Uint64(h[8:16]), b, c
+}
+
+// EnableQuotas enables the quota functions of the tx allow list.
+func EnableQuotas(stateDB contract.StateDB) {
+	stateDB.SetState(ContractAddress, quotasKey, quotasEnabled)
+}
+
+// IsQuotasEnabled returns true if admins can set quotas on the tx allow list.
+func IsQuotasEnabled(stateDB contract.StateDB) bool {
+	return stateDB.GetState(ContractAddress, quotasKey) == quotasEnabled
+}
+
+// GetQuota returns the quota of [account] and true, or false if [account] has no quota.
+func GetQuota(stateDB contract.StateDB, account common.Address) (Quota, bool) {
+	limits := stateDB.GetState(ContractAddress, quotaKey(account, quotaLimitsField))
+	if limits == (common.Hash{}) {
+		return Quota{}, false
+	}
+	maxGas, gasWindow := unpackUint64Pair(limits)
+	return Quota{
+		MaxGas:        maxGas,
+		GasWindow:     gasWindow,
+		MaxTxValue:    stateDB.GetState(ContractAddress, quotaKey(account, quotaMaxTxValueField)).Big(),
+		MaxDailyValue: stateDB.GetState(ContractAddress, quotaKey(account, quotaMaxDailyValueField)).Big(),
+	}, true
+}
+
+// SetQuota sets the quota of [account] to [quota], or removes it if [quota] is zero.
+// The usage of the quota in its current windows is preserved.
+// assumes [quota] has already been verified.
+func SetQuota(stateDB contract.StateDB, account common.Address, quota Quota) {
+	limits := common.Hash{}
+	if !quota.IsZero() {
+		limits = packUint64Pair(quota.MaxGas, quota.GasWindow)
+		// Flag the quota as set, as all of its limits may fit in the other slots.
+		limits[0] = 1
+	}
+	stateDB.SetState(ContractAddress, quotaKey(account, quotaLimitsField), limits)
+	stateDB.SetState(ContractAddress, quotaKey(account, quotaMaxTxValueField), bigToHash(quota.MaxTxValue))
+	stateDB.SetState(ContractAddress, quotaKey(account, quotaMaxDailyValueField), bigToHash(quota.MaxDailyValue))
+}
+
+func bigToHash(value *big.Int) common.Hash {
+	if value == nil {
+		return common.Hash{}
+	}
+	return common.BigToHash(value)
+}
+
+// windowUsage is the usage of a quota in the window starting at [start] and
+// in the window before it.
+type windowUsage struct {
+	start uint64
+	prev  *big.Int
+	used  *big.Int
+}
+
+// advance returns the usage in the windows of [window] seconds at [timestamp].
+// Windows follow each other from the first usage, until [timestamp] is more
+// than a window past the current one, and a new window starts at [timestamp].
+func (u windowUsage) advance(window uint64, timestamp uint64) windowUsage {
+	switch {
+	case u.prev.Sign() == 0 && u.used.Sign() == 0:
+		return windowUsage{start: timestamp, prev: u.prev, used: u.used}
+	case timestamp >= u.start && timestamp-u.start < window:
+		return u
+	case timestamp >= u.start && timestamp-u.start-window < window:
+		return windowUsage{start: u.start + window, prev: u.used, used: new(big.Int)}
+	default:
+		return windowUsage{start: timestamp, prev: new(big.Int), used: new(big.Int)}
+	}
+}
+
+// total returns the usage in the [window] seconds up to [timestamp], which must
+// be in the current window. The usage of the previous window is weighted by the
+// part of it in the sliding window, rounding up.
+func (u windowUsage) total(window uint64, timestamp uint64) *big.Int {
+	total := new(big.Int).Mul(u.prev, new(big.Int).SetUint64(window-(timestamp-u.start)))
+	total.Add(total, new(big.Int).SetUint64(window-1))
+	total.Div(total, new(big.Int).SetUint64(window))
+	return total.Add(total, u.used)
+}
+
+func getGasUsage(stateDB contract.StateDB, account common.Address) windowUsage {
+	start, prev, used := unpackUint64Triple(stateDB.GetState(ContractAddress, quotaKey(account, quotaGasUsageField)))
+	return windowUsage{start: start, prev: new(big.Int).SetUint64(prev), used: new(big.Int).SetUint64(used)}
+}
+
+func setGasUsage(stateDB contract.StateDB, account common.Address, usage windowUsage) {
+	stateDB.SetState(ContractAddress, quotaKey(account, quotaGasUsageField), packUint64Triple(usage.start, saturatingUint64(usage.prev), saturatingUint64(usage.used)))
+}
+
+func getValueUsage(stateDB contract.StateDB, account common.Address) windowUsage {
+	return windowUsage{
+		start: stateDB.GetState(ContractAddress, quotaKey(account, quotaValueWindowStartField)).Big().Uint64(),
+		prev:  stateDB.GetState(ContractAddress, quotaKey(account, quotaPrevValueUsedField)).Big(),
+		used:  stateDB.GetState(ContractAddress, quotaKey(account, quotaValueUsedField)).Big(),
+	}
+}
+
+func setValueUsage(stateDB contract.StateDB, account common.Address, usage windowUsage) {
+	stateDB.SetState(ContractAddress, quotaKey(account, quotaValueWindowStartField), common.BigToHash(new(big.Int).SetUint64(usage.start)))
+	stateDB.SetState(ContractAddress, quotaKey(account, quotaPrevValueUsedField), common.BigToHash(usage.prev))
+	stateDB.SetState(ContractAddress, quotaKey(account, quotaValueUsedField), common.BigToHash(usage.used))
+}
+
+func saturatingUint64(value *big.Int) uint64 {
+	if !value.IsUint64() {
+		return ^uint64(0)
+	}
+	return value.Uint64()
+}
+
+// GetQuotaUsage returns the usage of the quota of [account] in the windows ending at [timestamp].
+func GetQuotaUsage(stateDB contract.StateDB, account common.Address, timestamp uint64) QuotaUsage {
+	quota, _ := GetQuota(stateDB, account)
+	var gas uint64
+	if quota.GasWindow != 0 {
+		gas = saturatingUint64(getGasUsage(stateDB, account).advance(quota.GasWindow, timestamp).total(quota.GasWindow, timestamp))
+	}
+	return QuotaUsage{
+		GasUsed:   gas,
+		ValueUsed: getValueUsage(stateDB, account).advance(ValueQuotaWindow, timestamp).total(ValueQuotaWindow, timestamp),
+	}
+}
+
+// CheckQuota returns an error if a transaction of [account] with [gasLimit] and
+// [value] at [timestamp] would exceed the quota of [account].
+func CheckQuota(stateDB contract.StateDB, account common.Address, timestamp uint64, gasLimit uint64, value *big.Int) error {
+	quota, ok := GetQuota(stateDB, account)
+	if !ok {
+		return nil
+	}
+	usage := GetQuotaUsage(stateDB, account, timestamp)
+	if quota.MaxGas != 0 && (usage.GasUsed > quota.MaxGas || gasLimit > quota.MaxGas-usage.GasUsed) {
+		return fmt.Errorf("%w: gas limit %d, used %d, max %d", ErrGasQuotaExceeded, gasLimit, usage.GasUsed, quota.MaxGas)
+	}
+	return checkValueQuota(quota, usage, value)
+}
+
+// CheckValueQuota returns an error if a transfer of [value] by [account] at
+// [timestamp] would exceed the quota of [account].
+func CheckValueQuota(stateDB contract.StateDB, account common.Address, timestamp uint64, value *big.Int) error {
+	quota, ok := GetQuota(stateDB, account)
+	if !ok {
+		return nil
+	}
+	return checkValueQuota(quota, GetQuotaUsage(stateDB, account, timestamp), value)
+}
+
+func checkValueQuota(quota Quota, usage QuotaUsage, value *big.Int) error {
+	if !isZero(quota.MaxTxValue) && value.Cmp(quota.MaxTxValue) > 0 {
+		return fmt.Errorf("%w: value %s, max %s", ErrTxValueQuotaExceeded, value, quota.MaxTxValue)
+	}
+	if !isZero(quota.MaxDailyValue) && new(big.Int).Add(usage.ValueUsed, value).Cmp(quota.MaxDailyValue) > 0 {
+		return fmt.Errorf("%w: value %s, used %s, max %s", ErrDailyValueQuotaExceeded, value, usage.ValueUsed, quota.MaxDailyValue)
+	}
+	return nil
+}
+
+// ConsumeQuota records the usage of [gasUsed] and [value] by a transaction or
+// a transfer of [account] at [timestamp], if [account] has a quota.
+func ConsumeQuota(stateDB contract.StateDB, account common.Address, timestamp uint64, gasUsed uint64, value *big.Int) {
+	quota, ok := GetQuota(stateDB, account)
+	if !ok {
+		return
+	}
+	if quota.MaxGas != 0 && gasUsed != 0 {
+		usage := getGasUsage(stateDB, account).advance(quota.GasWindow, timestamp)
+		usage.used.Add(usage.used, new(big.Int).SetUint64(gasUsed))
+		setGasUsage(stateDB, account, usage)
+	}
+	if !isZero(quota.MaxDailyValue) && value.Sign() > 0 {
+		usage := getValueUsage(stateDB, account).advance(ValueQuotaWindow, timestamp)
+		usage.used.Add(usage.used, value)
+		setValueUsage(stateDB, account, usage)
+	}
+}
+
+// ConsumeQuotaGasCost returns the gas charged to a transaction of [account]
+// transferring [value] for the storage writes of ConsumeQuota, which records
+// its usage after execution.
+func ConsumeQuotaGasCost(stateDB contract.StateDB, account common.Address, value *big.Int) uint64 {
+	quota, ok := GetQuota(stateDB, account)
+	if !ok {
+		return 0
+	}
+	var slots uint64
+	if quota.MaxGas != 0 {
+		slots++
+	}
+	if !isZero(quota.MaxDailyValue) && value.Sign() > 0 {
+		slots += 3
+	}
+	return slots * contract.WriteGasCostPerSlot
+}
+
+// SetQuotaInput is the input of setQuota.
+type SetQuotaInput struct {
+	Account       common.Address
+	MaxGas        uint64
+	GasWindow     uint64
+	MaxTxValue    *big.Int
+	MaxDailyValue *big.Int
+}
+
+// ReadQuotaOutput is the output of readQuota.
+type ReadQuotaOutput struct {
+	MaxGas        uint64
+	GasWindow     uint64
+	MaxTxValue    *big.Int
+	MaxDailyValue *big.Int
+}
+
+// ReadQuotaUsageOutput is the output of readQuotaUsage.
+type ReadQuotaUsageOutput struct {
+	GasUsed   uint64
+	ValueUsed *big.Int
+}
+
+// PackSetQuota packs [account] and [quota] into the input data to setQuota.
+func PackSetQuota(account common.Address, quota Quota) ([]byte, error) {
+	return TxAllowListABI.Pack("setQuota", account, quota.MaxGas, quota.GasWindow, bigOrZero(quota.MaxTxValue), bigOrZero(quota.MaxDailyValue))
+}
+
+// UnpackSetQuotaInput attempts to unpack [input] into the arguments to setQuota.
+func UnpackSetQuotaInput(input []byte) (common.Address, Quota, error) {
+	inputStruct := SetQuotaInput{}
+	if err := TxAllowListABI.UnpackInputIntoInterface(&inputStruct, "setQuota", input, false); err != nil {
+		return common.Address{}, Quota{}, err
+	}
+	return inputStruct.Account, Quota{
+		MaxGas:        inputStruct.MaxGas,
+		GasWindow:     inputStruct.GasWindow,
+		MaxTxValue:    inputStruct.MaxTxValue,
+		MaxDailyValue: inputStruct.MaxDailyValue,
+	}, nil
+}
+
+// PackReadQuota packs [account] into the input data to readQuota.
+func PackReadQuota(account common.Address) ([]byte, error) {
+	return TxAllowListABI.Pack("readQuota", account)
+}
+
+// PackReadQuotaOutput attempts to pack [quota] to conform the ABI outputs of readQuota.
+func PackReadQuotaOutput(quota Quota) ([]byte, error) {
+	return TxAllowListABI.PackOutput("readQuota", quota.MaxGas, quota.GasWindow, bigOrZero(quota.MaxTxValue), bigOrZero(quota.MaxDailyValue))
+}
+
+// UnpackReadQuotaOutput attempts to unpack [output] into the quota returned by readQuota.
+func UnpackReadQuotaOutput(output []byte) (Quota, error) {
+	outputStruct := ReadQuotaOutput{}
+	if err := TxAllowListABI.UnpackIntoInterface(&outputStruct, "readQuota", output); err != nil {
+		return Quota{}, err
+	}
+	return Quota(outputStruct), nil
+}
+
+// PackReadQuotaUsage packs [account] into the input data to readQuotaUsage.
+func PackReadQuotaUsage(account common.Address) ([]byte, error) {
+	return TxAllowListABI.Pack("readQuotaUsage", account)
+}
+
+// PackReadQuotaUsageOutput attempts to pack [usage] to conform the ABI outputs of readQuotaUsage.
+func PackReadQuotaUsageOutput(usage QuotaUsage) ([]byte, error) {
+	return TxAllowListABI.PackOutput("readQuotaUsage", usage.GasUsed, bigOrZero(usage.ValueUsed))
+}
+
+// UnpackReadQuotaUsageOutput attempts to unpack [output] into the usage returned by readQuotaUsage.
+func UnpackReadQuotaUsageOutput(output []byte) (QuotaUsage, error) {
+	outputStruct := ReadQuotaUsageOutput{}
+	if err := TxAllowListABI.UnpackIntoInterface(&outputStruct, "readQuotaUsage", output); err != nil {
+		return QuotaUsage{}, err
+	}
+	return QuotaUsage(outputStruct), nil
+}
+
+func bigOrZero(value *big.Int) *big.Int {
+	if value == nil {
+		return new(big.Int)
+	}
+	return value
+}
+
+// unpackAccountInput attempts to unpack [input] into the account argument to [name].
+func unpackAccountInput(name string, input []byte) (common.Address, error) {
+	var account common.Address
+	err := TxAllowListABI.UnpackInputIntoInterface(&account, name, input, false)
+	return account, err
+}
+
+// setQuota sets the quota of an account.
+// Only admins can set quotas.
+func setQuota(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, SetQuotaGasCostKey, SetQuotaGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost+QuotaSetEventGasCost); err != nil {
+		return nil, 0, err
+	}
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+	account, quota, err := UnpackSetQuotaInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	if err := quota.Verify(); err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	// Verify that the caller is an admin with permission to modify the quotas.
+	callerStatus := GetTxAllowListStatus(stateDB, caller)
+	if !callerStatus.IsAdmin() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotSetQuota, caller)
+	}
+
+	topics, data, err := PackQuotaSetEvent(account, caller, quota)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	stateDB.AddLog(
+		ContractAddress,
+		topics,
+		data,
+		accessibleState.GetBlockContext().Number().Uint64(),
+	)
+	SetQuota(stateDB, account, quota)
+
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+// readQuota returns the quota of an account.
+func readQuota(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, ReadQuotaGasCostKey, ReadQuotaGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
+		return nil, 0, err
+	}
+	account, err := unpackAccountInput("readQuota", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	quota, _ := GetQuota(accessibleState.GetStateDB(), account)
+	packedOutput, err := PackReadQuotaOutput(quota)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// readQuotaUsage returns the usage of the quota of an account in its windows at
+// the timestamp of the current block.
+func readQuotaUsage(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	gasCost := contract.GetGasCost(accessibleState, ContractAddress, ReadQuotaUsageGasCostKey, ReadQuotaUsageGasCost)
+	if remainingGas, err = contract.DeductGas(suppliedGas, gasCost); err != nil {
+		return nil, 0, err
+	}
+	account, err := unpackAccountInput("readQuotaUsage", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	usage := GetQuotaUsage(accessibleState.GetStateDB(), account, accessibleState.GetBlockContext().Timestamp())
+	packedOutput, err := PackReadQuotaUsageOutput(usage)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return packedOutput, remainingGas, nil
+}
+
+// isQuotasActivated enables the quota functions only if quotas are enabled.
+func isQuotasActivated(accessibleState contract.AccessibleState) bool {
+	return IsQuotasEnabled(accessibleState.GetStateDB())
+}
@@ -0,0 +1,104 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txallowlist
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/precompile/contract"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuota(t *testing.T) {
+	require := require.New(t)
+	stateDB := state.NewTestStateDB(t)
+	account := common.Address{1}
+	requireUsage := func(timestamp uint64, gasUsed uint64, valueUsed int64) {
+		t.Helper()
+		usage := GetQuotaUsage(stateDB, account, timestamp)
+		require.Equal(gasUsed, usage.GasUsed)
+		require.Zero(usage.ValueUsed.Cmp(big.NewInt(valueUsed)), "value used %s, expected %d", usage.ValueUsed, valueUsed)
+	}
+
+	// Accounts without a quota are not limited.
+	require.NoError(CheckQuota(stateDB, account, 0, 1_000_000, big.NewInt(1_000_000)))
+	ConsumeQuota(stateDB, account, 0, 1_000_000, big.NewInt(1_000_000))
+	requireUsage(0, 0, 0)
+
+	quota := Quota{
+		MaxGas:        100_000,
+		GasWindow:     10,
+		MaxTxValue:    big.NewInt(100),
+		MaxDailyValue: big.NewInt(150),
+	}
+	SetQuota(stateDB, account, quota)
+	stored, ok := GetQuota(stateDB, account)
+	require.True(ok)
+	require.True(quota.Equal(&stored))
+
+	require.ErrorIs(CheckQuota(stateDB, account, 100, 21_000, big.NewInt(101)), ErrTxValueQuotaExceeded)
+	require.ErrorIs(CheckQuota(stateDB, account, 100, 100_001, common.Big0), ErrGasQuotaExceeded)
+	require.NoError(CheckQuota(stateDB, account, 100, 100_000, big.NewInt(100)))
+
+	ConsumeQuota(stateDB, account, 100, 60_000, big.NewInt(100))
+	requireUsage(105, 60_000, 100)
+	require.ErrorIs(CheckQuota(stateDB, account, 105, 50_000, common.Big0), ErrGasQuotaExceeded)
+	require.ErrorIs(CheckQuota(stateDB, account, 105, 21_000, big.NewInt(51)), ErrDailyValueQuotaExceeded)
+	require.NoError(CheckQuota(stateDB, account, 105, 40_000, big.NewInt(50)))
+
+	// The gas window started with the first usage. The usage of the previous
+	// window is weighted by the part of it in the sliding window, so the limit
+	// cannot be exceeded by using it on both sides of a window boundary.
+	requireUsage(110, 60_000, 100)
+	requireUsage(115, 30_000, 100)
+	require.ErrorIs(CheckQuota(stateDB, account, 115, 70_001, common.Big0), ErrGasQuotaExceeded)
+	require.NoError(CheckQuota(stateDB, account, 115, 70_000, common.Big0))
+	ConsumeQuota(stateDB, account, 115, 30_000, big.NewInt(50))
+	requireUsage(115, 60_000, 150)
+	require.ErrorIs(CheckQuota(stateDB, account, 115, 21_000, common.Big1), ErrDailyValueQuotaExceeded)
+	requireUsage(120, 30_000, 150)
+	requireUsage(127, 9_000, 150)
+	requireUsage(130, 0, 150)
+
+	// The value window lasts for a day.
+	requireUsage(100+ValueQuotaWindow/2, 0, 150)
+	requireUsage(100+ValueQuotaWindow+ValueQuotaWindow/2, 0, 75)
+	requireUsage(100+2*ValueQuotaWindow, 0, 0)
+
+	// Updating the quota preserves its usage, and removing it lifts all limits.
+	SetQuota(stateDB, account, Quota{MaxGas: 40_000, GasWindow: 10})
+	require.ErrorIs(CheckQuota(stateDB, account, 125, 25_001, common.Big0), ErrGasQuotaExceeded)
+	require.NoError(CheckQuota(stateDB, account, 125, 25_000, big.NewInt(1_000)))
+	SetQuota(stateDB, account, Quota{})
+	_, ok = GetQuota(stateDB, account)
+	require.False(ok)
+	require.NoError(CheckQuota(stateDB, account, 110, 1_000_000, big.NewInt(1_000)))
+}
+
+func TestConsumeQuotaGasCost(t *testing.T) {
+	account := common.Address{1}
+	tests := []struct {
+		name     string
+		quota    Quota
+		value    *big.Int
+		expected uint64
+	}{
+		{name: "no quota", value: common.Big1, expected: 0},
+		{name: "gas quota", quota: Quota{MaxGas: 100_000, GasWindow: 10}, value: common.Big1, expected: contract.WriteGasCostPerSlot},
+		{name: "value quota without value", quota: Quota{MaxDailyValue: big.NewInt(150)}, value: common.Big0, expected: 0},
+		{name: "value quota", quota: Quota{MaxDailyValue: big.NewInt(150)}, value: common.Big1, expected: 3 * contract.WriteGasCostPerSlot},
+		{name: "tx value quota", quota: Quota{MaxTxValue: big.NewInt(100)}, value: common.Big1, expected: 0},
+		{name: "all quotas", quota: Quota{MaxGas: 100_000, GasWindow: 10, MaxDailyValue: big.NewInt(150)}, value: common.Big1, expected: 4 * contract.WriteGasCostPerSlot},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stateDB := state.NewTestStateDB(t)
+			SetQuota(stateDB, account, test.quota)
+			require.Equal(t, test.expected, ConsumeQuotaGasCost(stateDB, account, test.value))
+		})
+	}
+}
@@ -5,6 +5,8 @@
 package precompileconfig
 
 import (
+	"math/big"
+
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
@@ -88,6 +90,8 @@ type ChainConfig interface {
 	GetFeeConfig() commontype.FeeConfig
 	// AllowedFeeRecipients returns true if fee recipients are allowed in the genesis.
 	AllowedFeeRecipients() bool
+	// GetChainID returns the chain ID used for replay protection.
+	GetChainID() *big.Int
 	// IsDurango returns true if the time is after Durango.
 	IsDurango(time uint64) bool
 }
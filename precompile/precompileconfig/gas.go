@@ -0,0 +1,93 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompileconfig
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GasCost is the default gas cost of an operation of a precompile, along with
+// the bounds within which a precompile config can override it.
+type GasCost struct {
+	Default uint64
+	Min     uint64
+	Max     uint64
+}
+
+// NewGasCost returns a GasCost defaulting to [defaultCost], which can be
+// overridden with values from a tenth to a hundred times [defaultCost].
+func NewGasCost(defaultCost uint64) GasCost {
+	return GasCost{
+		Default: defaultCost,
+		Min:     defaultCost / 10,
+		Max:     defaultCost * 100,
+	}
+}
+
+// GasSchedule maps the names of the operations of a precompile to their gas
+// costs.
+type GasSchedule map[string]GasCost
+
+// With returns a GasSchedule containing the gas costs of both [s] and [other].
+func (s GasSchedule) With(other GasSchedule) GasSchedule {
+	merged := maps.Clone(s)
+	maps.Copy(merged, other)
+	return merged
+}
+
+// GasCostOverrides can be embedded in the config of a precompile to override
+// the gas costs of its operations while the config is active.
+type GasCostOverrides struct {
+	GasCosts map[string]uint64 `json:"gasCosts,omitempty"`
+}
+
+// GetGasCost returns the overridden gas cost of the operation [name], or
+// [defaultCost] if it is not overridden.
+func (o *GasCostOverrides) GetGasCost(name string, defaultCost uint64) uint64 {
+	if cost, ok := o.GasCosts[name]; ok {
+		return cost
+	}
+	return defaultCost
+}
+
+// Equal returns true iff [other] overrides the same gas costs with the same
+// values.
+func (o *GasCostOverrides) Equal(other *GasCostOverrides) bool {
+	if other == nil {
+		return false
+	}
+	return maps.Equal(o.GasCosts, other.GasCosts)
+}
+
+// Verify returns an error if an override does not correspond to an operation
+// of [schedule], or is outside of the bounds of the operation.
+func (o *GasCostOverrides) Verify(schedule GasSchedule) error {
+	for name, cost := range o.GasCosts {
+		bounds, ok := schedule[name]
+		if !ok {
+			return fmt.Errorf("unknown gas cost %q", name)
+		}
+		if cost < bounds.Min || cost > bounds.Max {
+			return fmt.Errorf("gas cost %q of %d is out of bounds [%d, %d]", name, cost, bounds.Min, bounds.Max)
+		}
+	}
+	return nil
+}
+
+// GasConfig is an optional interface for precompile configs that override the
+// gas costs of the operations of their precompile.
+type GasConfig interface {
+	GetGasCost(name string, defaultCost uint64) uint64
+}
+
+// ActiveConfigGetter is an optional interface for ChainConfig implementations
+// that can return the config of a precompile active at a timestamp.
+type ActiveConfigGetter interface {
+	// GetActivePrecompileConfig returns the most recent config of the
+	// precompile at [address] activated at or before [timestamp], or nil.
+	GetActivePrecompileConfig(address common.Address, timestamp uint64) Config
+}
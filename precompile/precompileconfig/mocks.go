@@ -10,6 +10,7 @@
 package precompileconfig
 
 import (
+	big "math/big"
 	reflect "reflect"
 
 	commontype "github.com/ava-labs/subnet-evm/commontype"
@@ -213,6 +214,20 @@ func (mr *MockChainConfigMockRecorder) GetFeeConfig() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeConfig", reflect.TypeOf((*MockChainConfig)(nil).GetFeeConfig))
 }
 
+// GetChainID mocks base method.
+func (m *MockChainConfig) GetChainID() *big.Int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChainID")
+	ret0, _ := ret[0].(*big.Int)
+	return ret0
+}
+
+// GetChainID indicates an expected call of GetChainID.
+func (mr *MockChainConfigMockRecorder) GetChainID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChainID", reflect.TypeOf((*MockChainConfig)(nil).GetChainID))
+}
+
 // IsDurango mocks base method.
 func (m *MockChainConfig) IsDurango(arg0 uint64) bool {
 	m.ctrl.T.Helper()
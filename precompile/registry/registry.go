@@ -18,6 +18,12 @@ import (
 	_ "github.com/ava-labs/subnet-evm/precompile/contracts/rewardmanager"
 
 	_ "github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+
+	_ "github.com/ava-labs/subnet-evm/precompile/contracts/blsverifier"
+
+	_ "github.com/ava-labs/subnet-evm/precompile/contracts/blockhistory"
+
+	_ "github.com/ava-labs/subnet-evm/precompile/contracts/nativeallowance"
 	// ADD YOUR PRECOMPILE HERE
 	// _ "github.com/ava-labs/subnet-evm/precompile/contracts/yourprecompile"
 )
@@ -40,5 +46,8 @@ import (
 // FeeManagerAddress                = common.HexToAddress("0x0200000000000000000000000000000000000003")
 // RewardManagerAddress             = common.HexToAddress("0x0200000000000000000000000000000000000004")
 // WarpAddress                      = common.HexToAddress("0x0200000000000000000000000000000000000005")
+// BLSVerifierAddress               = common.HexToAddress("0x0200000000000000000000000000000000000006")
+// BlockHistoryAddress              = common.HexToAddress("0x0200000000000000000000000000000000000007")
+// NativeAllowanceAddress           = common.HexToAddress("0x0200000000000000000000000000000000000008")
 // ADD YOUR PRECOMPILE HERE
 // {YourPrecompile}Address          = common.HexToAddress("0x03000000000000000000000000000000000000??")
@@ -98,6 +98,7 @@ func (test PrecompileTest) setup(t testing.TB, module modules.Module, state cont
 			mockChainConfig.EXPECT().GetFeeConfig().AnyTimes().Return(commontype.ValidTestFeeConfig)
 			mockChainConfig.EXPECT().AllowedFeeRecipients().AnyTimes().Return(false)
 			mockChainConfig.EXPECT().IsDurango(gomock.Any()).AnyTimes().Return(true)
+			mockChainConfig.EXPECT().GetChainID().AnyTimes().Return(big.NewInt(1))
 			return mockChainConfig
 		}
 	}
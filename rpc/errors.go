@@ -65,6 +65,7 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
 	_ Error = new(internalServerError)
+	_ Error = new(rateLimitError)
 )
 
 const (
@@ -77,6 +78,12 @@ const (
 	legacyErrcodeNotificationsUnsupported = -32001
 )
 
+// ErrcodePrunedHistory is the error code of requests for the body or receipts
+// of a block that were pruned from the block history of the node, as used by
+// go-ethereum for EIP-4444 history expiry. The error data is the number of the
+// oldest block whose body and receipts are available.
+const ErrcodePrunedHistory = 4444
+
 const (
 	errMsgTimeout          = "request timed out"
 	errMsgResponseTooLarge = "response too large"
@@ -83,6 +83,7 @@ type handler struct {
 
 	deadlineContext time.Duration // limits execution after some time.Duration
 	limiter         *rate.Limiter
+	rateLimiter     *RateLimiter // limits calls per client and method
 }
 
 type callProc struct {
@@ -592,6 +593,12 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	// Unsubscribing is never limited, so clients can always release resources.
+	if !msg.isUnsubscribe() {
+		if err := h.rateLimiter.allow(cp.ctx, msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -0,0 +1,309 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"golang.org/x/time/rate"
+)
+
+const (
+	errcodeClientRateLimited = -32005
+	errcodeMethodRateLimited = -32006
+)
+
+var clientRateLimitedCounter = metrics.NewRegisteredCounter("rpc/ratelimit/client/limited", nil)
+
+// RateLimit configures a token bucket that is refilled with [Rate] tokens per
+// second and holds at most [Burst] tokens. Every call consumes one token.
+// A zero [Rate] disables the limit.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Rate > 0
+}
+
+func (l RateLimit) newLimiter() *rate.Limiter {
+	burst := l.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(l.Rate), burst)
+}
+
+// RateLimiterConfig configures the call limits applied to every client of a
+// Server.
+type RateLimiterConfig struct {
+	// ClientHeader is the HTTP header identifying a client, e.g. an API key.
+	// The header is only honored for the keys of [Keys] and for the requests
+	// forwarded by [TrustedProxies], so that clients cannot escape their
+	// limits by sending new keys. Other clients, or all clients if it is
+	// empty, are identified by their IP address.
+	ClientHeader string
+	// Keys maps the names of known clients to their keys, which are honored
+	// from any address. The calls of known clients are reported in metrics
+	// under their names.
+	Keys map[string]string
+	// TrustedProxies are the networks of the proxies trusted to set the
+	// ClientHeader of the requests they forward, and to report the IP address
+	// of their clients in the X-Forwarded-For header.
+	TrustedProxies []netip.Prefix
+	// MaxClients is the number of clients whose buckets are retained. The
+	// buckets of the least recently seen clients are dropped beyond this.
+	// At least one client is retained.
+	MaxClients int
+	// IP limits the calls from each IP address across all methods, including
+	// the calls of clients identified by a key.
+	IP RateLimit
+	// Client limits the calls of each client across all methods.
+	Client RateLimit
+	// Methods limits the calls of each client to the given methods.
+	Methods map[string]RateLimit
+}
+
+// Enabled returns true if the config limits any calls.
+func (c *RateLimiterConfig) Enabled() bool {
+	if c.IP.enabled() || c.Client.enabled() {
+		return true
+	}
+	for _, limit := range c.Methods {
+		if limit.enabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimiter applies token bucket limits to the calls of every client, both
+// across all methods and per method, and to the calls from every IP address.
+type RateLimiter struct {
+	cfg   RateLimiterConfig
+	known map[string]*clientMetrics // metrics of the known clients by key
+
+	lock    sync.Mutex
+	ips     lru.BasicLRU[string, *rate.Limiter]
+	clients lru.BasicLRU[string, *clientLimiter]
+}
+
+// clientMetrics counts the calls of a known client.
+type clientMetrics struct {
+	calls   metrics.Counter
+	limited metrics.Counter
+}
+
+type clientLimiter struct {
+	all     *rate.Limiter
+	methods map[string]*rate.Limiter
+}
+
+// NewRateLimiter creates a RateLimiter applying the limits of [cfg].
+func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
+	known := make(map[string]*clientMetrics, len(cfg.Keys))
+	for name, key := range cfg.Keys {
+		known[key] = &clientMetrics{
+			calls:   metrics.GetOrRegisterCounter(fmt.Sprintf("rpc/ratelimit/client/%s/calls", name), nil),
+			limited: metrics.GetOrRegisterCounter(fmt.Sprintf("rpc/ratelimit/client/%s/limited", name), nil),
+		}
+	}
+	return &RateLimiter{
+		cfg:     cfg,
+		known:   known,
+		ips:     lru.NewBasicLRU[string, *rate.Limiter](cfg.MaxClients),
+		clients: lru.NewBasicLRU[string, *clientLimiter](cfg.MaxClients),
+	}
+}
+
+// The limits applied to every call, in the order they are checked.
+const (
+	ipLimit = iota
+	clientLimit
+	methodLimit
+	numLimits
+)
+
+// allow consumes a token for a call of [method] by the client making the
+// request of [ctx], returning an error if the client exhausted any of its
+// limits.
+func (l *RateLimiter) allow(ctx context.Context, method string) error {
+	if l == nil {
+		return nil
+	}
+	ip, client, m := l.clientID(PeerInfoFromContext(ctx), headersFromContext(ctx))
+
+	var (
+		now          = time.Now()
+		exhausted    = -1
+		reservations = make([]*rate.Reservation, 0, numLimits)
+	)
+	l.lock.Lock()
+	c := l.client(client)
+	limiters := [numLimits]*rate.Limiter{
+		ipLimit:     l.ip(ip),
+		clientLimit: c.all,
+		methodLimit: l.method(c, method),
+	}
+	for limit, limiter := range limiters {
+		r := reserve(limiter, now)
+		if r == nil {
+			continue
+		}
+		reservations = append(reservations, r)
+		if r.DelayFrom(now) > 0 {
+			// A rejected call does not consume any token.
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			exhausted = limit
+			break
+		}
+	}
+	l.lock.Unlock()
+
+	if m != nil {
+		m.calls.Inc(1)
+		if exhausted >= 0 {
+			m.limited.Inc(1)
+		}
+	}
+	switch exhausted {
+	case ipLimit:
+		clientRateLimitedCounter.Inc(1)
+		return &rateLimitError{errcodeClientRateLimited, "IP rate limit exceeded"}
+	case clientLimit:
+		clientRateLimitedCounter.Inc(1)
+		return &rateLimitError{errcodeClientRateLimited, "client rate limit exceeded"}
+	case methodLimit:
+		// Only configured methods are limited, so the number of these
+		// counters is bounded.
+		metrics.GetOrRegisterCounter(fmt.Sprintf("rpc/ratelimit/method/%s/limited", method), nil).Inc(1)
+		return &rateLimitError{errcodeMethodRateLimited, fmt.Sprintf("rate limit exceeded for method %s", method)}
+	}
+	return nil
+}
+
+// ip returns the bucket of the IP address [ip], creating it if necessary, or
+// nil if IP addresses are not limited. Assumes [l.lock] is held.
+func (l *RateLimiter) ip(ip string) *rate.Limiter {
+	if !l.cfg.IP.enabled() {
+		return nil
+	}
+	if limiter, ok := l.ips.Get(ip); ok {
+		return limiter
+	}
+	limiter := l.cfg.IP.newLimiter()
+	l.ips.Add(ip, limiter)
+	return limiter
+}
+
+// client returns the buckets of [id], creating them if necessary.
+// Assumes [l.lock] is held.
+func (l *RateLimiter) client(id string) *clientLimiter {
+	if c, ok := l.clients.Get(id); ok {
+		return c
+	}
+	c := &clientLimiter{methods: make(map[string]*rate.Limiter)}
+	if l.cfg.Client.enabled() {
+		c.all = l.cfg.Client.newLimiter()
+	}
+	l.clients.Add(id, c)
+	return c
+}
+
+// method returns the bucket of [c] for [method], or nil if the method is not
+// limited. Assumes [l.lock] is held.
+func (l *RateLimiter) method(c *clientLimiter, method string) *rate.Limiter {
+	limit, ok := l.cfg.Methods[method]
+	if !ok || !limit.enabled() {
+		return nil
+	}
+	limiter, ok := c.methods[method]
+	if !ok {
+		limiter = limit.newLimiter()
+		c.methods[method] = limiter
+	}
+	return limiter
+}
+
+// clientID returns the IP address and the identity of the client described
+// by [info] that sent the HTTP [headers], and its metrics if it is known.
+func (l *RateLimiter) clientID(info PeerInfo, headers http.Header) (string, string, *clientMetrics) {
+	host, _, err := net.SplitHostPort(info.RemoteAddr)
+	if err != nil {
+		host = info.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	trusted := err == nil && l.trusted(addr)
+	if trusted {
+		host = l.forwardedFor(addr, headers).String()
+	}
+	if l.cfg.ClientHeader != "" {
+		key := headers.Get(l.cfg.ClientHeader)
+		if m, known := l.known[key]; key != "" && (known || trusted) {
+			return host, "key:" + key, m
+		}
+	}
+	return host, "ip:" + host, nil
+}
+
+// trusted returns whether [addr] is the address of a trusted proxy.
+func (l *RateLimiter) trusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range l.cfg.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the address of the client of the trusted proxy
+// [proxy], which is the last address of the X-Forwarded-For [headers] that is
+// not a trusted proxy. [proxy] is returned if the header does not report it.
+func (l *RateLimiter) forwardedFor(proxy netip.Addr, headers http.Header) netip.Addr {
+	var hops []string
+	for _, value := range headers.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	addr := proxy
+	for i := len(hops) - 1; i >= 0 && l.trusted(addr); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+	}
+	return addr
+}
+
+// reserve reserves a token of [limiter] at [now], if [limiter] is not nil.
+func reserve(limiter *rate.Limiter, now time.Time) *rate.Reservation {
+	if limiter == nil {
+		return nil
+	}
+	return limiter.ReserveN(now, 1)
+}
+
+// rateLimitError is returned when a call exceeds one of the limits of a
+// RateLimiter. The error code identifies the limit that was exceeded.
+type rateLimitError struct {
+	code    int
+	message string
+}
+
+func (e *rateLimitError) ErrorCode() int { return e.code }
+
+func (e *rateLimitError) Error() string { return e.message }
@@ -0,0 +1,263 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/metrics"
+)
+
+func TestRateLimiter(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetRateLimiter(NewRateLimiter(RateLimiterConfig{
+		ClientHeader: "X-Api-Key",
+		Keys:         map[string]string{"a": "key-a", "b": "key-b"},
+		MaxClients:   10,
+		Client:       RateLimit{Rate: 1e-9, Burst: 3},
+		Methods: map[string]RateLimit{
+			"test_echo": {Rate: 1e-9, Burst: 1},
+		},
+	}))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	count := func(name string) int64 {
+		return metrics.GetOrRegisterCounter("rpc/ratelimit/client/"+name, nil).Snapshot().Count()
+	}
+	var (
+		callsA, limitedA = count("a/calls"), count("a/limited")
+		callsB, limitedB = count("b/calls"), count("b/limited")
+	)
+
+	dial := func(key string) *Client {
+		client, err := Dial(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "" {
+			client.SetHeader("X-Api-Key", key)
+		}
+		return client
+	}
+	errorCode := func(err error) int {
+		var rpcErr Error
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected rpc error, got %v", err)
+		}
+		return rpcErr.ErrorCode()
+	}
+
+	client := dial("key-a")
+	defer client.Close()
+	var res echoResult
+	if err := client.Call(&res, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	// The second call exceeds the method limit, without consuming the client
+	// limit.
+	if code := errorCode(client.Call(&res, "test_echo", "x", 1)); code != errcodeMethodRateLimited {
+		t.Fatalf("expected method limit error code %d, got %d", errcodeMethodRateLimited, code)
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if code := errorCode(client.Call(nil, "test_noArgsRets")); code != errcodeClientRateLimited {
+		t.Fatalf("expected client limit error code %d, got %d", errcodeClientRateLimited, code)
+	}
+
+	// A client with a different key is limited independently.
+	other := dial("key-b")
+	defer other.Close()
+	if err := other.Call(&res, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Clients without a known key are identified by their IP.
+	anonymous := dial("")
+	defer anonymous.Close()
+	if err := anonymous.Call(&res, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if code := errorCode(anonymous.Call(&res, "test_echo", "x", 1)); code != errcodeMethodRateLimited {
+		t.Fatalf("expected method limit error code %d, got %d", errcodeMethodRateLimited, code)
+	}
+	unknown := dial("key-c")
+	defer unknown.Close()
+	if code := errorCode(unknown.Call(&res, "test_echo", "x", 1)); code != errcodeMethodRateLimited {
+		t.Fatalf("expected method limit error code %d, got %d", errcodeMethodRateLimited, code)
+	}
+
+	// The calls of known clients are reported under their names.
+	if calls, limited := count("a/calls")-callsA, count("a/limited")-limitedA; calls != 5 || limited != 2 {
+		t.Fatalf("client a: got %d calls and %d limited, want 5 and 2", calls, limited)
+	}
+	if calls, limited := count("b/calls")-callsB, count("b/limited")-limitedB; calls != 1 || limited != 0 {
+		t.Fatalf("client b: got %d calls and %d limited, want 1 and 0", calls, limited)
+	}
+}
+
+func TestRateLimiterIP(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetRateLimiter(NewRateLimiter(RateLimiterConfig{
+		ClientHeader: "X-Api-Key",
+		Keys:         map[string]string{"a": "key-a", "b": "key-b"},
+		MaxClients:   10,
+		IP:           RateLimit{Rate: 1e-9, Burst: 2},
+		Client:       RateLimit{Rate: 1e-9, Burst: 2},
+	}))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	// Clients identified by a key share the limit of their IP.
+	for _, key := range []string{"key-a", "key-b"} {
+		client, err := Dial(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		client.SetHeader("X-Api-Key", key)
+		if err := client.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.SetHeader("X-Api-Key", "key-a")
+	err = client.Call(nil, "test_noArgsRets")
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeClientRateLimited || rpcErr.Error() != "IP rate limit exceeded" {
+		t.Fatalf("expected IP limit error, got %v", err)
+	}
+}
+
+func TestRateLimiterWebsocket(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetRateLimiter(NewRateLimiter(RateLimiterConfig{
+		ClientHeader: "X-Api-Key",
+		Keys:         map[string]string{"a": "key-a", "b": "key-b"},
+		MaxClients:   10,
+		Client:       RateLimit{Rate: 1e-9, Burst: 1},
+	}))
+	ts := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer ts.Close()
+
+	dial := func(key string) *Client {
+		client, err := DialOptions(context.Background(), "ws://"+ts.Listener.Addr().String(), WithHeader("X-Api-Key", key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	// Clients are identified by the headers of the upgrade request.
+	client := dial("key-a")
+	defer client.Close()
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_noArgsRets"); err == nil {
+		t.Fatal("expected client limit error")
+	}
+	other := dial("key-b")
+	defer other.Close()
+	if err := other.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimiterClientID(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{
+		ClientHeader: "X-Api-Key",
+		Keys:         map[string]string{"known": "secret"},
+		TrustedProxies: []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("192.168.1.1/32"),
+		},
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    http.Header
+		wantIP     string
+		wantID     string
+	}{
+		{
+			name:       "no key",
+			remoteAddr: "127.0.0.1:1234",
+			wantIP:     "127.0.0.1",
+			wantID:     "ip:127.0.0.1",
+		},
+		{
+			name:       "known key",
+			remoteAddr: "127.0.0.1:1234",
+			headers:    http.Header{"X-Api-Key": {"secret"}},
+			wantIP:     "127.0.0.1",
+			wantID:     "key:secret",
+		},
+		{
+			name:       "unknown key",
+			remoteAddr: "127.0.0.1:1234",
+			headers:    http.Header{"X-Api-Key": {"other"}},
+			wantIP:     "127.0.0.1",
+			wantID:     "ip:127.0.0.1",
+		},
+		{
+			name:       "unknown key from trusted proxy",
+			remoteAddr: "10.1.2.3:1234",
+			headers:    http.Header{"X-Api-Key": {"other"}},
+			wantIP:     "10.1.2.3",
+			wantID:     "key:other",
+		},
+		{
+			name:       "forwarded by trusted proxies",
+			remoteAddr: "10.1.2.3:1234",
+			headers:    http.Header{"X-Forwarded-For": {"1.1.1.1, 2.2.2.2", "192.168.1.1"}},
+			wantIP:     "2.2.2.2",
+			wantID:     "ip:2.2.2.2",
+		},
+		{
+			name:       "forwarded by untrusted proxy",
+			remoteAddr: "127.0.0.1:1234",
+			headers:    http.Header{"X-Forwarded-For": {"2.2.2.2"}, "X-Api-Key": {"other"}},
+			wantIP:     "127.0.0.1",
+			wantID:     "ip:127.0.0.1",
+		},
+		{
+			name:       "invalid forwarded address",
+			remoteAddr: "10.1.2.3:1234",
+			headers:    http.Header{"X-Forwarded-For": {"unknown"}},
+			wantIP:     "10.1.2.3",
+			wantID:     "ip:10.1.2.3",
+		},
+		{
+			name:       "ipc",
+			remoteAddr: "",
+			wantIP:     "",
+			wantID:     "ip:",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ip, id, _ := limiter.clientID(PeerInfo{RemoteAddr: test.remoteAddr}, test.headers)
+			if ip != test.wantIP || id != test.wantID {
+				t.Fatalf("unexpected client (%q, %q), want (%q, %q)", ip, id, test.wantIP, test.wantID)
+			}
+		})
+	}
+}
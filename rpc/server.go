@@ -62,6 +62,7 @@ type Server struct {
 	run                atomic.Bool
 	batchItemLimit     int
 	batchResponseLimit int
+	rateLimiter        *RateLimiter
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -94,6 +95,14 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// SetRateLimiter sets the limiter applied to the calls of every client.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRateLimiter(limiter *RateLimiter) {
+	s.rateLimiter = limiter
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -119,6 +128,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption, apiMaxDurati
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		rateLimiter:        s.rateLimiter,
 	}
 	c := initClient(codec, &s.services, cfg, apiMaxDuration, refillRate, maxStored)
 	<-codec.closed()
@@ -155,6 +165,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.deadlineContext = s.maximumDuration
 	h.allowSubscribe = false
+	h.rateLimiter = s.rateLimiter
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
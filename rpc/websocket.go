@@ -293,8 +293,9 @@ func wsClientHeaders(endpoint, origin string) (string, http.Header, error) {
 
 type websocketCodec struct {
 	*jsonCodec
-	conn *websocket.Conn
-	info PeerInfo
+	conn   *websocket.Conn
+	info   PeerInfo
+	header http.Header // Headers of the upgrade request
 
 	wg           sync.WaitGroup
 	pingReset    chan struct{}
@@ -309,6 +310,7 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 	wc := &websocketCodec{
 		jsonCodec:    NewFuncCodec(conn, encode, conn.ReadJSON).(*jsonCodec),
 		conn:         conn,
+		header:       req,
 		pingReset:    make(chan struct{}, 1),
 		pongReceived: make(chan struct{}),
 		info: PeerInfo{
@@ -22,6 +22,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/types"
@@ -51,6 +52,8 @@ var (
 	errUnmarshalResponse      = errors.New("failed to unmarshal response")
 	errInvalidCodeResponseLen = errors.New("number of code bytes in response does not match requested hashes")
 	errMaxCodeSizeExceeded    = errors.New("max code size exceeded")
+	errTooManyReceipts        = errors.New("response contains more receipts than requested")
+	errInvalidReceiptsLen     = errors.New("number of receipts does not match block transactions")
 )
 var _ Client = &client{}
 
@@ -67,6 +70,11 @@ type Client interface {
 
 	// GetCode synchronously retrieves code associated with the given hashes
 	GetCode(ctx context.Context, hashes []common.Hash) ([][]byte, error)
+
+	// GetReceipts synchronously retrieves the receipts of the given blocks
+	// Returns receipts for a non-empty prefix of [blocks], each verified against
+	// the receipt root of its block header.
+	GetReceipts(ctx context.Context, blocks []*types.Block) ([]types.Receipts, error)
 }
 
 // parseResponseFn parses given response bytes in context of specified request
@@ -281,6 +289,73 @@ func parseCode(codec codec.Manager, req message.Request, data []byte) (interface
 	return response.Data, totalBytes, nil
 }
 
+func (c *client) GetReceipts(ctx context.Context, blocks []*types.Block) ([]types.Receipts, error) {
+	hashes := make([]common.Hash, len(blocks))
+	for i, block := range blocks {
+		hashes[i] = block.Hash()
+	}
+	req := message.ReceiptsRequest{BlockHashes: hashes}
+
+	data, err := c.get(ctx, req, func(codec codec.Manager, req message.Request, data []byte) (interface{}, int, error) {
+		return parseReceipts(codec, blocks, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get receipts (%s): %w", req, err)
+	}
+
+	return data.([]types.Receipts), nil
+}
+
+// parseReceipts validates given object as message.ReceiptsResponse
+// for a message.ReceiptsRequest of the hashes of [blocks]
+// returns []types.Receipts as interface{}
+// returns a non-nil error if the request should be retried
+// returns error when:
+// - response bytes could not be unmarshalled into message.ReceiptsResponse
+// - response is empty or contains more receipts than requested
+// - the number of receipts of a block does not match its transactions
+// - the receipts of a block do not match the receipt root of its header
+func parseReceipts(codec codec.Manager, blocks []*types.Block, data []byte) (interface{}, int, error) {
+	var response message.ReceiptsResponse
+	if _, err := codec.Unmarshal(data, &response); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", errUnmarshalResponse, err)
+	}
+	if len(response.Receipts) == 0 {
+		return nil, 0, errEmptyResponse
+	}
+	if len(response.Receipts) > len(blocks) {
+		return nil, 0, errTooManyReceipts
+	}
+
+	receipts := make([]types.Receipts, len(response.Receipts))
+	numReceipts := 0
+	for i, receiptsBytes := range response.Receipts {
+		block := blocks[i]
+		var storageReceipts []*types.ReceiptForStorage
+		if err := rlp.DecodeBytes(receiptsBytes, &storageReceipts); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", errUnmarshalResponse, err)
+		}
+		txs := block.Transactions()
+		if len(storageReceipts) != len(txs) {
+			return nil, 0, fmt.Errorf("%w for block %s: (got %d) (expected %d)", errInvalidReceiptsLen, block.Hash(), len(storageReceipts), len(txs))
+		}
+		blockReceipts := make(types.Receipts, len(storageReceipts))
+		for j, receipt := range storageReceipts {
+			blockReceipts[j] = (*types.Receipt)(receipt)
+			// The type is not part of the storage encoding, but is needed to
+			// derive the receipt root.
+			blockReceipts[j].Type = txs[j].Type()
+		}
+		if root := types.DeriveSha(blockReceipts, trie.NewStackTrie(nil)); root != block.ReceiptHash() {
+			return nil, 0, fmt.Errorf("%w for receipts of block %s: (got %v) (expected %v)", errHashMismatch, block.Hash(), root, block.ReceiptHash())
+		}
+		receipts[i] = blockReceipts
+		numReceipts += len(blockReceipts)
+	}
+
+	return receipts, numReceipts, nil
+}
+
 // get submits given request and blockingly returns with either a parsed response object or an error
 // if [ctx] expires before the client can successfully retrieve a valid response.
 // Retries if there is a network error or if the [parseResponseFn] returns an error indicating an invalid response.
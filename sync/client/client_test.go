@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"strings"
 	"testing"
@@ -28,6 +29,7 @@ import (
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestGetCode(t *testing.T) {
@@ -879,3 +881,170 @@ func TestStateSyncNodes(t *testing.T) {
 	assert.Contains(t, mockNetClient.nodesRequested, stateSyncNodes[2])
 	assert.Contains(t, mockNetClient.nodesRequested, stateSyncNodes[3])
 }
+
+func TestGetReceipts(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		funds   = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+		gspec   = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{addr1: {Balance: funds}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	memdb := rawdb.NewMemoryDatabase()
+	tdb := trie.NewDatabase(memdb, nil)
+	genesis := gspec.MustCommit(memdb, tdb)
+	engine := dummy.NewETHFaker()
+	numBlocks := 8
+	blocks, receipts, err := core.GenerateChain(gspec.Config, genesis, engine, memdb, numBlocks, 0, func(i int, b *core.BlockGen) {
+		// add a different number of transactions of both types to each block
+		for j := 0; j < i%3; j++ {
+			var txData types.TxData
+			if j%2 == 0 {
+				txData = &types.LegacyTx{Nonce: b.TxNonce(addr1), To: &addr1, Value: big.NewInt(1), Gas: params.TxGas, GasPrice: b.BaseFee()}
+			} else {
+				txData = &types.DynamicFeeTx{ChainID: gspec.Config.ChainID, Nonce: b.TxNonce(addr1), To: &addr1, Value: big.NewInt(1), Gas: params.TxGas, GasFeeCap: b.BaseFee(), GasTipCap: big.NewInt(0)}
+			}
+			tx, err := types.SignNewTx(key1, signer, txData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.AddTx(tx)
+		}
+	})
+	if err != nil {
+		t.Fatal("unexpected error when generating test blockchain", err)
+	}
+	assert.Len(t, blocks, numBlocks)
+
+	// serve receipts of all blocks except the last one
+	receiptsDB := rawdb.NewMemoryDatabase()
+	for i, block := range blocks[:numBlocks-1] {
+		rawdb.WriteHeaderNumber(receiptsDB, block.Hash(), block.NumberU64())
+		rawdb.WriteReceipts(receiptsDB, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	receiptsRequestHandler := handlers.NewReceiptsRequestHandler(receiptsDB, message.Codec, handlerstats.NewNoopHandlerStats())
+
+	mockNetClient := &mockNetwork{}
+	stateSyncClient := NewClient(&ClientConfig{
+		NetworkClient:    mockNetClient,
+		Codec:            message.Codec,
+		Stats:            clientstats.NewNoOpStats(),
+		StateSyncNodeIDs: nil,
+		BlockParser:      mockBlockParser,
+	})
+
+	handleRequest := func(t *testing.T, blocks []*types.Block) []byte {
+		hashes := make([]common.Hash, len(blocks))
+		for i, block := range blocks {
+			hashes[i] = block.Hash()
+		}
+		response, err := receiptsRequestHandler.OnReceiptsRequest(context.Background(), ids.GenerateTestNodeID(), 1, message.ReceiptsRequest{BlockHashes: hashes})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+	marshalResponse := func(t *testing.T, receipts [][]byte) []byte {
+		response, err := message.Codec.Marshal(message.Version, message.ReceiptsResponse{Receipts: receipts})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	tests := map[string]struct {
+		blocks           []*types.Block
+		getResponse      func(t *testing.T, blocks []*types.Block) []byte
+		expectedReceipts []types.Receipts
+		expectedErr      error
+	}{
+		"all receipts": {
+			blocks:           blocks[:numBlocks-1],
+			getResponse:      handleRequest,
+			expectedReceipts: receipts[:numBlocks-1],
+		},
+		"prefix of receipts": {
+			blocks:           blocks[numBlocks-3:],
+			getResponse:      handleRequest,
+			expectedReceipts: receipts[numBlocks-3 : numBlocks-1],
+		},
+		"empty response": {
+			blocks: blocks[:1],
+			getResponse: func(t *testing.T, _ []*types.Block) []byte {
+				return marshalResponse(t, nil)
+			},
+			expectedErr: errEmptyResponse,
+		},
+		"too many receipts": {
+			blocks: blocks[:1],
+			getResponse: func(t *testing.T, _ []*types.Block) []byte {
+				return marshalResponse(t, [][]byte{{0xc0}, {0xc0}})
+			},
+			expectedErr: errTooManyReceipts,
+		},
+		"tampered receipts": {
+			blocks: blocks[1:2],
+			getResponse: func(t *testing.T, _ []*types.Block) []byte {
+				tampered := []*types.ReceiptForStorage{{
+					Status:            types.ReceiptStatusFailed,
+					CumulativeGasUsed: receipts[1][0].CumulativeGasUsed,
+					Logs:              []*types.Log{},
+				}}
+				receiptsBytes, err := rlp.EncodeToBytes(tampered)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return marshalResponse(t, [][]byte{receiptsBytes})
+			},
+			expectedErr: errHashMismatch,
+		},
+		"wrong number of receipts": {
+			blocks: blocks[2:3],
+			getResponse: func(t *testing.T, _ []*types.Block) []byte {
+				return handleRequest(t, blocks[1:2])
+			},
+			expectedErr: errInvalidReceiptsLen,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			responseBytes := test.getResponse(t, test.blocks)
+			if test.expectedErr == nil {
+				mockNetClient.mockResponse(1, nil, responseBytes)
+			} else {
+				attempted := false
+				mockNetClient.mockResponse(2, func() {
+					// Cancel before the second attempt is processed.
+					if attempted {
+						cancel()
+					}
+					attempted = true
+				}, responseBytes)
+			}
+
+			blockReceipts, err := stateSyncClient.GetReceipts(ctx, test.blocks)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, blockReceipts, len(test.expectedReceipts))
+			for i, receipts := range blockReceipts {
+				assert.Len(t, receipts, len(test.expectedReceipts[i]))
+				for j, receipt := range receipts {
+					expected := test.expectedReceipts[i][j]
+					assert.Equal(t, expected.Type, receipt.Type)
+					assert.Equal(t, expected.Status, receipt.Status)
+					assert.Equal(t, expected.CumulativeGasUsed, receipt.CumulativeGasUsed)
+					assert.Equal(t, expected.Bloom, receipt.Bloom)
+				}
+			}
+		})
+	}
+}
@@ -24,13 +24,15 @@ var (
 
 // TODO replace with gomock library
 type MockClient struct {
-	codec          codec.Manager
-	leafsHandler   *handlers.LeafsRequestHandler
-	leavesReceived int32
-	codesHandler   *handlers.CodeRequestHandler
-	codeReceived   int32
-	blocksHandler  *handlers.BlockRequestHandler
-	blocksReceived int32
+	codec            codec.Manager
+	leafsHandler     *handlers.LeafsRequestHandler
+	leavesReceived   int32
+	codesHandler     *handlers.CodeRequestHandler
+	codeReceived     int32
+	blocksHandler    *handlers.BlockRequestHandler
+	blocksReceived   int32
+	receiptsHandler  *handlers.ReceiptsRequestHandler
+	receiptsReceived int32
 	// GetLeafsIntercept is called on every GetLeafs request if set to a non-nil callback.
 	// The returned response will be returned by MockClient to the caller.
 	GetLeafsIntercept func(req message.LeafsRequest, res message.LeafsResponse) (message.LeafsResponse, error)
@@ -47,12 +49,14 @@ func NewMockClient(
 	leafHandler *handlers.LeafsRequestHandler,
 	codesHandler *handlers.CodeRequestHandler,
 	blocksHandler *handlers.BlockRequestHandler,
+	receiptsHandler *handlers.ReceiptsRequestHandler,
 ) *MockClient {
 	return &MockClient{
-		codec:         codec,
-		leafsHandler:  leafHandler,
-		codesHandler:  codesHandler,
-		blocksHandler: blocksHandler,
+		codec:           codec,
+		leafsHandler:    leafHandler,
+		codesHandler:    codesHandler,
+		blocksHandler:   blocksHandler,
+		receiptsHandler: receiptsHandler,
 	}
 }
 
@@ -138,6 +142,32 @@ func (ml *MockClient) BlocksReceived() int32 {
 	return atomic.LoadInt32(&ml.blocksReceived)
 }
 
+func (ml *MockClient) GetReceipts(ctx context.Context, blocks []*types.Block) ([]types.Receipts, error) {
+	if ml.receiptsHandler == nil {
+		panic("no receipts handler for mock client")
+	}
+	hashes := make([]common.Hash, len(blocks))
+	for i, block := range blocks {
+		hashes[i] = block.Hash()
+	}
+	request := message.ReceiptsRequest{BlockHashes: hashes}
+	response, err := ml.receiptsHandler.OnReceiptsRequest(ctx, ids.GenerateTestNodeID(), 1, request)
+	if err != nil {
+		return nil, err
+	}
+
+	receiptsRes, numReceipts, err := parseReceipts(ml.codec, blocks, response)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt32(&ml.receiptsReceived, int32(numReceipts))
+	return receiptsRes.([]types.Receipts), nil
+}
+
+func (ml *MockClient) ReceiptsReceived() int32 {
+	return atomic.LoadInt32(&ml.receiptsReceived)
+}
+
 type testBlockParser struct{}
 
 func (t *testBlockParser) ParseEthBlock(b []byte) (*types.Block, error) {
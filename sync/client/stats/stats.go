@@ -77,7 +77,8 @@ func (m *messageMetric) UpdateRequestLatency(duration time.Duration) {
 type clientSyncerStats struct {
 	stateTrieLeavesMetric,
 	codeRequestMetric,
-	blockRequestMetric MessageMetric
+	blockRequestMetric,
+	receiptsRequestMetric MessageMetric
 }
 
 // NewClientSyncerStats returns stats for the client syncer
@@ -86,6 +87,7 @@ func NewClientSyncerStats() ClientSyncerStats {
 		stateTrieLeavesMetric: NewMessageMetric("sync_state_trie_leaves"),
 		codeRequestMetric:     NewMessageMetric("sync_code"),
 		blockRequestMetric:    NewMessageMetric("sync_blocks"),
+		receiptsRequestMetric: NewMessageMetric("sync_receipts"),
 	}
 }
 
@@ -98,6 +100,8 @@ func (c *clientSyncerStats) GetMetric(msgIntf message.Request) (MessageMetric, e
 		return c.codeRequestMetric, nil
 	case message.LeafsRequest:
 		return c.stateTrieLeavesMetric, nil
+	case message.ReceiptsRequest:
+		return c.receiptsRequestMetric, nil
 	default:
 		return nil, fmt.Errorf("attempted to get metric for invalid request with type %T", msg)
 	}
@@ -0,0 +1,195 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/sync/handlers/stats"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxBandwidthPeers is the number of peers whose budgets are retained. The
+// budgets of the least recently served peers are dropped beyond this.
+const maxBandwidthPeers = 4096
+
+// BandwidthConfig configures the budgets of bytes served in response to sync
+// requests. Budgets are refilled at a rate of bytes per second up to a burst,
+// and a zero rate disables a budget.
+type BandwidthConfig struct {
+	PeerRate  uint64 // Bytes per second served to each peer
+	PeerBurst uint64 // Bytes that may be served to a peer at once, defaults to PeerRate
+
+	TotalRate  uint64 // Bytes per second served to all peers
+	TotalBurst uint64 // Bytes that may be served to all peers at once, defaults to TotalRate
+
+	// ValidatorReserve is the fraction of the total budget that only
+	// validators may use, so that syncing non-validators cannot starve them.
+	ValidatorReserve float64
+}
+
+// ValidatorSet reports whether a peer is a validator.
+type ValidatorSet interface {
+	Has(ctx context.Context, nodeID ids.NodeID) bool
+}
+
+// BandwidthLimiter enforces the budgets of a BandwidthConfig on the requests
+// served to peers. Since the size of a response is only known after it is
+// built, requests are allowed while a budget is positive and the bytes served
+// are charged afterwards, which may leave the budget in debt until it is
+// refilled. A nil BandwidthLimiter allows every request.
+type BandwidthLimiter struct {
+	config     BandwidthConfig
+	validators ValidatorSet
+	stats      stats.BandwidthLimiterStats
+	now        func() time.Time
+
+	lock  sync.Mutex
+	total *tokenBucket
+	peers lru.BasicLRU[ids.NodeID, *tokenBucket]
+}
+
+// NewBandwidthLimiter returns a BandwidthLimiter enforcing [config], or nil if
+// [config] has no budgets. [validators] may be nil, in which case no peer is
+// given priority.
+func NewBandwidthLimiter(config BandwidthConfig, validators ValidatorSet, stats stats.BandwidthLimiterStats) *BandwidthLimiter {
+	if config.PeerRate == 0 && config.TotalRate == 0 {
+		return nil
+	}
+	if config.PeerBurst == 0 {
+		config.PeerBurst = config.PeerRate
+	}
+	if config.TotalBurst == 0 {
+		config.TotalBurst = config.TotalRate
+	}
+	b := &BandwidthLimiter{
+		config:     config,
+		validators: validators,
+		stats:      stats,
+		now:        time.Now,
+		peers:      lru.NewBasicLRU[ids.NodeID, *tokenBucket](maxBandwidthPeers),
+	}
+	if config.TotalRate > 0 {
+		b.total = newTokenBucket(config.TotalRate, config.TotalBurst, b.now())
+	}
+	return b
+}
+
+// Serve calls [serve] to respond to a request from [nodeID] if its budgets
+// allow it, and charges the bytes of the response to them. Throttled requests
+// are dropped, like other requests the handlers do not serve.
+func (b *BandwidthLimiter) Serve(ctx context.Context, nodeID ids.NodeID, requestID uint32, serve func() ([]byte, error)) ([]byte, error) {
+	if b == nil {
+		return serve()
+	}
+	if !b.allow(ctx, nodeID) {
+		log.Debug("sync bandwidth budget exhausted, dropping request", "nodeID", nodeID, "requestID", requestID)
+		return nil, nil
+	}
+	response, err := serve()
+	b.consume(nodeID, len(response))
+	return response, err
+}
+
+// allow returns true if the budgets of [nodeID] are not exhausted.
+func (b *BandwidthLimiter) allow(ctx context.Context, nodeID ids.NodeID) bool {
+	// Only check the validator set if it matters, since it is not free.
+	validator := true
+	if b.total != nil && b.config.ValidatorReserve > 0 && b.validators != nil {
+		validator = b.validators.Has(ctx, nodeID)
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	if peer := b.peer(nodeID, now); peer != nil && peer.available(now) <= 0 {
+		b.stats.IncThrottledPeer()
+		return false
+	}
+	if b.total == nil {
+		return true
+	}
+	available := b.total.available(now)
+	if available <= 0 {
+		b.stats.IncThrottledTotal()
+		return false
+	}
+	if !validator && available <= b.config.ValidatorReserve*float64(b.config.TotalBurst) {
+		b.stats.IncThrottledNonValidator()
+		return false
+	}
+	return true
+}
+
+// consume charges [bytes] served to [nodeID] against its budgets.
+func (b *BandwidthLimiter) consume(nodeID ids.NodeID, bytes int) {
+	b.stats.UpdateBytesServed(bytes)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	if peer := b.peer(nodeID, now); peer != nil {
+		peer.consume(now, bytes)
+	}
+	if b.total != nil {
+		b.total.consume(now, bytes)
+	}
+}
+
+// peer returns the budget of [nodeID], creating it if necessary, or nil if
+// peers are not limited. Assumes [b.lock] is held.
+func (b *BandwidthLimiter) peer(nodeID ids.NodeID, now time.Time) *tokenBucket {
+	if b.config.PeerRate == 0 {
+		return nil
+	}
+	if peer, ok := b.peers.Get(nodeID); ok {
+		return peer
+	}
+	peer := newTokenBucket(b.config.PeerRate, b.config.PeerBurst, now)
+	b.peers.Add(nodeID, peer)
+	return peer
+}
+
+// tokenBucket is a budget of bytes that is refilled at [rate] bytes per
+// second up to [burst] bytes. Unlike rate.Limiter, it may be consumed beyond
+// zero, since a response is charged after it is built.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst uint64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+// available refills the bucket up to [now] and returns its tokens.
+func (t *tokenBucket) available(now time.Time) float64 {
+	if elapsed := now.Sub(t.last); elapsed > 0 {
+		t.tokens += elapsed.Seconds() * t.rate
+		if t.tokens > t.burst {
+			t.tokens = t.burst
+		}
+		t.last = now
+	}
+	return t.tokens
+}
+
+// consume removes [bytes] tokens from the bucket at [now].
+func (t *tokenBucket) consume(now time.Time, bytes int) {
+	t.available(now)
+	t.tokens -= float64(bytes)
+}
@@ -0,0 +1,101 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/subnet-evm/sync/handlers/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+type testValidatorSet struct {
+	set.Set[ids.NodeID]
+}
+
+func (v testValidatorSet) Has(_ context.Context, nodeID ids.NodeID) bool {
+	return v.Contains(nodeID)
+}
+
+func TestBandwidthLimiter(t *testing.T) {
+	var (
+		validator    = ids.GenerateTestNodeID()
+		nonValidator = ids.GenerateTestNodeID()
+		validators   = testValidatorSet{set.Of(validator)}
+		response     = make([]byte, 100)
+	)
+	serve := func() ([]byte, error) { return response, nil }
+
+	tests := map[string]struct {
+		config   BandwidthConfig
+		requests []ids.NodeID
+		elapsed  time.Duration // Time between requests
+		served   []bool
+		assertFn func(t *testing.T, mockStats *stats.MockHandlerStats)
+	}{
+		"peer budget": {
+			config:   BandwidthConfig{PeerRate: 100, PeerBurst: 150},
+			requests: []ids.NodeID{validator, validator, nonValidator, validator},
+			served:   []bool{true, true, true, false},
+			assertFn: func(t *testing.T, mockStats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, mockStats.ThrottledPeerCount)
+				assert.EqualValues(t, 300, mockStats.BytesServedSum)
+			},
+		},
+		"peer budget refilled": {
+			config:   BandwidthConfig{PeerRate: 100},
+			requests: []ids.NodeID{validator, validator, validator},
+			elapsed:  time.Second,
+			served:   []bool{true, true, true},
+		},
+		"total budget": {
+			config:   BandwidthConfig{TotalRate: 100, TotalBurst: 200},
+			requests: []ids.NodeID{validator, nonValidator, validator},
+			served:   []bool{true, true, false},
+			assertFn: func(t *testing.T, mockStats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, mockStats.ThrottledTotalCount)
+			},
+		},
+		"validator reserve": {
+			config:   BandwidthConfig{TotalRate: 100, TotalBurst: 400, ValidatorReserve: 0.5},
+			requests: []ids.NodeID{nonValidator, nonValidator, nonValidator, validator, validator, validator},
+			served:   []bool{true, true, false, true, true, false},
+			assertFn: func(t *testing.T, mockStats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, mockStats.ThrottledNonValidatorCount)
+				assert.EqualValues(t, 1, mockStats.ThrottledTotalCount)
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockStats := &stats.MockHandlerStats{}
+			limiter := NewBandwidthLimiter(test.config, validators, mockStats)
+			now := time.Now()
+			limiter.now = func() time.Time { return now }
+
+			for i, nodeID := range test.requests {
+				got, err := limiter.Serve(context.Background(), nodeID, uint32(i), serve)
+				assert.NoError(t, err)
+				assert.Equal(t, test.served[i], got != nil, "request %d", i)
+				now = now.Add(test.elapsed)
+			}
+			if test.assertFn != nil {
+				test.assertFn(t, mockStats)
+			}
+		})
+	}
+}
+
+func TestBandwidthLimiterDisabled(t *testing.T) {
+	assert.Nil(t, NewBandwidthLimiter(BandwidthConfig{}, nil, stats.NewNoopHandlerStats()))
+
+	var limiter *BandwidthLimiter
+	got, err := limiter.Serve(context.Background(), ids.GenerateTestNodeID(), 0, func() ([]byte, error) { return []byte{1}, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, got)
+}
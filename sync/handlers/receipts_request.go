@@ -0,0 +1,105 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/sync/handlers/stats"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReceiptsRequestHandler is a peer.RequestHandler for message.ReceiptsRequest
+// serving the stored receipts of the requested blocks
+type ReceiptsRequestHandler struct {
+	receiptsReader ethdb.Reader
+	codec          codec.Manager
+	stats          stats.ReceiptsRequestHandlerStats
+}
+
+func NewReceiptsRequestHandler(receiptsReader ethdb.Reader, codec codec.Manager, handlerStats stats.ReceiptsRequestHandlerStats) *ReceiptsRequestHandler {
+	return &ReceiptsRequestHandler{
+		receiptsReader: receiptsReader,
+		codec:          codec,
+		stats:          handlerStats,
+	}
+}
+
+// OnReceiptsRequest handles incoming message.ReceiptsRequest, returning the
+// RLP encoded receipts of the requested blocks in order
+// Never returns error
+// Expects returned errors to be treated as FATAL
+// Returns receipts for a prefix of the requested blocks if receipts are missing,
+// the response size limit is reached or ctx expires during fetch
+// Assumes ctx is active
+func (r *ReceiptsRequestHandler) OnReceiptsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, receiptsRequest message.ReceiptsRequest) ([]byte, error) {
+	startTime := time.Now()
+	r.stats.IncReceiptsRequest()
+
+	receipts := make([][]byte, 0, len(receiptsRequest.BlockHashes))
+
+	// ensure metrics are captured properly on all return paths
+	defer func() {
+		r.stats.UpdateReceiptsRequestProcessingTime(time.Since(startTime))
+		r.stats.UpdateReceiptsReturned(uint16(len(receipts)))
+	}()
+
+	if len(receiptsRequest.BlockHashes) > message.MaxReceiptsHashesPerRequest {
+		r.stats.IncTooManyReceiptsRequested()
+		log.Debug("too many receipts requested, dropping request", "nodeID", nodeID, "requestID", requestID, "numHashes", len(receiptsRequest.BlockHashes))
+		return nil, nil
+	}
+
+	totalBytes := 0
+	for _, hash := range receiptsRequest.BlockHashes {
+		if ctx.Err() != nil {
+			break
+		}
+
+		number := rawdb.ReadHeaderNumber(r.receiptsReader, hash)
+		if number == nil {
+			r.stats.IncMissingReceipts()
+			break
+		}
+		// Receipts of blocks without transactions are stored as an empty
+		// RLP list, so missing data means the receipts are not available.
+		data := rawdb.ReadReceiptsRLP(r.receiptsReader, hash, *number)
+		if len(data) == 0 {
+			r.stats.IncMissingReceipts()
+			break
+		}
+
+		if len(data)+totalBytes > targetMessageByteSize && len(receipts) > 0 {
+			log.Debug("Skipping receipts due to max total bytes size", "totalReceiptsDataSize", totalBytes, "receiptsSize", len(data), "maxTotalBytesSize", targetMessageByteSize)
+			break
+		}
+
+		receipts = append(receipts, data)
+		totalBytes += len(data)
+	}
+
+	if len(receipts) == 0 {
+		// drop this request
+		log.Debug("no requested receipts found, dropping request", "nodeID", nodeID, "requestID", requestID, "numHashes", len(receiptsRequest.BlockHashes))
+		return nil, nil
+	}
+
+	response := message.ReceiptsResponse{
+		Receipts: receipts,
+	}
+	responseBytes, err := r.codec.Marshal(message.Version, response)
+	if err != nil {
+		log.Error("failed to marshal ReceiptsResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "numHashes", len(receiptsRequest.BlockHashes), "receiptsLen", len(response.Receipts), "err", err)
+		return nil, nil
+	}
+
+	return responseBytes, nil
+}
@@ -0,0 +1,110 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/sync/handlers/stats"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiptsRequestHandler(t *testing.T) {
+	database := rawdb.NewMemoryDatabase()
+
+	var (
+		hashes   []common.Hash
+		receipts []types.Receipts
+	)
+	for i := uint64(0); i < 3; i++ {
+		hash := common.BigToHash(new(big.Int).SetUint64(i + 1))
+		blockReceipts := types.Receipts{}
+		for j := uint64(0); j < i; j++ {
+			blockReceipts = append(blockReceipts, &types.Receipt{
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: 21_000 * (j + 1),
+				Logs:              []*types.Log{},
+			})
+		}
+		rawdb.WriteHeaderNumber(database, hash, i)
+		rawdb.WriteReceipts(database, hash, i, blockReceipts)
+		hashes = append(hashes, hash)
+		receipts = append(receipts, blockReceipts)
+	}
+	missingHash := common.HexToHash("0xdead")
+
+	mockHandlerStats := &stats.MockHandlerStats{}
+	receiptsRequestHandler := NewReceiptsRequestHandler(database, message.Codec, mockHandlerStats)
+
+	tests := map[string]struct {
+		request          message.ReceiptsRequest
+		expectedReceipts []types.Receipts
+		verifyStats      func(t *testing.T, stats *stats.MockHandlerStats)
+	}{
+		"all receipts": {
+			request:          message.ReceiptsRequest{BlockHashes: hashes},
+			expectedReceipts: receipts,
+			verifyStats: func(t *testing.T, stats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, stats.ReceiptsRequestCount)
+				assert.EqualValues(t, len(hashes), stats.ReceiptsReturnedSum)
+			},
+		},
+		"missing receipts returns prefix": {
+			request:          message.ReceiptsRequest{BlockHashes: []common.Hash{hashes[2], missingHash, hashes[1]}},
+			expectedReceipts: receipts[2:],
+			verifyStats: func(t *testing.T, stats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, stats.MissingReceiptsCount)
+				assert.EqualValues(t, 1, stats.ReceiptsReturnedSum)
+			},
+		},
+		"missing first receipts": {
+			request: message.ReceiptsRequest{BlockHashes: []common.Hash{missingHash, hashes[0]}},
+			verifyStats: func(t *testing.T, stats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, stats.MissingReceiptsCount)
+				assert.EqualValues(t, 0, stats.ReceiptsReturnedSum)
+			},
+		},
+		"too many hashes": {
+			request: message.ReceiptsRequest{BlockHashes: make([]common.Hash, message.MaxReceiptsHashesPerRequest+1)},
+			verifyStats: func(t *testing.T, stats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, stats.TooManyReceiptsRequested)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			responseBytes, err := receiptsRequestHandler.OnReceiptsRequest(context.Background(), ids.GenerateTestNodeID(), 1, test.request)
+			assert.NoError(t, err)
+			test.verifyStats(t, mockHandlerStats)
+			mockHandlerStats.Reset()
+
+			if len(test.expectedReceipts) == 0 {
+				assert.Nil(t, responseBytes)
+				return
+			}
+
+			var response message.ReceiptsResponse
+			_, err = message.Codec.Unmarshal(responseBytes, &response)
+			assert.NoError(t, err)
+			assert.Len(t, response.Receipts, len(test.expectedReceipts))
+			for i, data := range response.Receipts {
+				var storageReceipts []*types.ReceiptForStorage
+				assert.NoError(t, rlp.DecodeBytes(data, &storageReceipts))
+				assert.Len(t, storageReceipts, len(test.expectedReceipts[i]))
+				for j, receipt := range storageReceipts {
+					assert.Equal(t, test.expectedReceipts[i][j].CumulativeGasUsed, receipt.CumulativeGasUsed)
+				}
+			}
+		})
+	}
+}
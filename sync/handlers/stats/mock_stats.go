@@ -42,6 +42,17 @@ type MockHandlerStats struct {
 	SnapshotReadTime,
 	GenerateRangeProofTime,
 	LeafRequestProcessingTimeSum time.Duration
+
+	ReceiptsRequestCount,
+	MissingReceiptsCount,
+	TooManyReceiptsRequested,
+	ReceiptsReturnedSum uint32
+	ReceiptsRequestProcessingTimeSum time.Duration
+
+	ThrottledPeerCount,
+	ThrottledTotalCount,
+	ThrottledNonValidatorCount uint32
+	BytesServedSum uint64
 }
 
 func (m *MockHandlerStats) Reset() {
@@ -73,6 +84,15 @@ func (m *MockHandlerStats) Reset() {
 	m.SnapshotReadTime = 0
 	m.GenerateRangeProofTime = 0
 	m.LeafRequestProcessingTimeSum = 0
+	m.ReceiptsRequestCount = 0
+	m.MissingReceiptsCount = 0
+	m.TooManyReceiptsRequested = 0
+	m.ReceiptsReturnedSum = 0
+	m.ReceiptsRequestProcessingTimeSum = 0
+	m.ThrottledPeerCount = 0
+	m.ThrottledTotalCount = 0
+	m.ThrottledNonValidatorCount = 0
+	m.BytesServedSum = 0
 }
 
 func (m *MockHandlerStats) IncBlockRequest() {
@@ -230,3 +250,57 @@ func (m *MockHandlerStats) IncSnapshotSegmentInvalid() {
 	defer m.lock.Unlock()
 	m.SnapshotSegmentInvalidCount++
 }
+
+func (m *MockHandlerStats) IncReceiptsRequest() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ReceiptsRequestCount++
+}
+
+func (m *MockHandlerStats) IncMissingReceipts() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.MissingReceiptsCount++
+}
+
+func (m *MockHandlerStats) IncTooManyReceiptsRequested() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.TooManyReceiptsRequested++
+}
+
+func (m *MockHandlerStats) UpdateReceiptsReturned(num uint16) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ReceiptsReturnedSum += uint32(num)
+}
+
+func (m *MockHandlerStats) UpdateReceiptsRequestProcessingTime(duration time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ReceiptsRequestProcessingTimeSum += duration
+}
+
+func (m *MockHandlerStats) IncThrottledPeer() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ThrottledPeerCount++
+}
+
+func (m *MockHandlerStats) IncThrottledTotal() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ThrottledTotalCount++
+}
+
+func (m *MockHandlerStats) IncThrottledNonValidator() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ThrottledNonValidatorCount++
+}
+
+func (m *MockHandlerStats) UpdateBytesServed(bytes int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.BytesServedSum += uint64(bytes)
+}
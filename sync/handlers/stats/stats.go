@@ -14,6 +14,8 @@ type HandlerStats interface {
 	BlockRequestHandlerStats
 	CodeRequestHandlerStats
 	LeafsRequestHandlerStats
+	ReceiptsRequestHandlerStats
+	BandwidthLimiterStats
 }
 
 type BlockRequestHandlerStats interface {
@@ -32,6 +34,21 @@ type CodeRequestHandlerStats interface {
 	UpdateCodeBytesReturned(bytes uint32)
 }
 
+type ReceiptsRequestHandlerStats interface {
+	IncReceiptsRequest()
+	IncMissingReceipts()
+	IncTooManyReceiptsRequested()
+	UpdateReceiptsReturned(num uint16)
+	UpdateReceiptsRequestProcessingTime(duration time.Duration)
+}
+
+type BandwidthLimiterStats interface {
+	IncThrottledPeer()
+	IncThrottledTotal()
+	IncThrottledNonValidator()
+	UpdateBytesServed(bytes int)
+}
+
 type LeafsRequestHandlerStats interface {
 	IncLeafsRequest()
 	IncInvalidLeafsRequest()
@@ -83,6 +100,19 @@ type handlerStats struct {
 	snapshotReadSuccess        metrics.Counter
 	snapshotSegmentValid       metrics.Counter
 	snapshotSegmentInvalid     metrics.Counter
+
+	// ReceiptsRequestHandler stats
+	receiptsRequest               metrics.Counter
+	missingReceipts               metrics.Counter
+	tooManyReceiptsRequested      metrics.Counter
+	receiptsReturned              metrics.Histogram
+	receiptsRequestProcessingTime metrics.Timer
+
+	// BandwidthLimiter stats
+	throttledPeer         metrics.Counter
+	throttledTotal        metrics.Counter
+	throttledNonValidator metrics.Counter
+	bytesServed           metrics.Counter
 }
 
 func (h *handlerStats) IncBlockRequest() {
@@ -166,6 +196,42 @@ func (h *handlerStats) IncSnapshotReadSuccess()    { h.snapshotReadSuccess.Inc(1
 func (h *handlerStats) IncSnapshotSegmentValid()   { h.snapshotSegmentValid.Inc(1) }
 func (h *handlerStats) IncSnapshotSegmentInvalid() { h.snapshotSegmentInvalid.Inc(1) }
 
+func (h *handlerStats) IncReceiptsRequest() {
+	h.receiptsRequest.Inc(1)
+}
+
+func (h *handlerStats) IncMissingReceipts() {
+	h.missingReceipts.Inc(1)
+}
+
+func (h *handlerStats) IncTooManyReceiptsRequested() {
+	h.tooManyReceiptsRequested.Inc(1)
+}
+
+func (h *handlerStats) UpdateReceiptsReturned(num uint16) {
+	h.receiptsReturned.Update(int64(num))
+}
+
+func (h *handlerStats) UpdateReceiptsRequestProcessingTime(duration time.Duration) {
+	h.receiptsRequestProcessingTime.Update(duration)
+}
+
+func (h *handlerStats) IncThrottledPeer() {
+	h.throttledPeer.Inc(1)
+}
+
+func (h *handlerStats) IncThrottledTotal() {
+	h.throttledTotal.Inc(1)
+}
+
+func (h *handlerStats) IncThrottledNonValidator() {
+	h.throttledNonValidator.Inc(1)
+}
+
+func (h *handlerStats) UpdateBytesServed(bytes int) {
+	h.bytesServed.Inc(int64(bytes))
+}
+
 func NewHandlerStats(enabled bool) HandlerStats {
 	if !enabled {
 		return NewNoopHandlerStats()
@@ -202,6 +268,19 @@ func NewHandlerStats(enabled bool) HandlerStats {
 		snapshotReadSuccess:        metrics.GetOrRegisterCounter("leafs_request_snapshot_read_success", nil),
 		snapshotSegmentValid:       metrics.GetOrRegisterCounter("leafs_request_snapshot_segment_valid", nil),
 		snapshotSegmentInvalid:     metrics.GetOrRegisterCounter("leafs_request_snapshot_segment_invalid", nil),
+
+		// initialize receipts request stats
+		receiptsRequest:               metrics.GetOrRegisterCounter("receipts_request_count", nil),
+		missingReceipts:               metrics.GetOrRegisterCounter("receipts_request_missing_receipts", nil),
+		tooManyReceiptsRequested:      metrics.GetOrRegisterCounter("receipts_request_too_many_hashes", nil),
+		receiptsReturned:              metrics.GetOrRegisterHistogram("receipts_request_total_receipts", nil, metrics.NewExpDecaySample(1028, 0.015)),
+		receiptsRequestProcessingTime: metrics.GetOrRegisterTimer("receipts_request_processing_time", nil),
+
+		// initialize bandwidth limiter stats
+		throttledPeer:         metrics.GetOrRegisterCounter("sync_server_throttled_peer", nil),
+		throttledTotal:        metrics.GetOrRegisterCounter("sync_server_throttled_total", nil),
+		throttledNonValidator: metrics.GetOrRegisterCounter("sync_server_throttled_non_validator", nil),
+		bytesServed:           metrics.GetOrRegisterCounter("sync_server_bytes_served", nil),
 	}
 }
 
@@ -239,3 +318,12 @@ func (n *noopHandlerStats) IncSnapshotReadAttempt()
 func (n *noopHandlerStats) IncSnapshotReadSuccess()                             {}
 func (n *noopHandlerStats) IncSnapshotSegmentValid()                            {}
 func (n *noopHandlerStats) IncSnapshotSegmentInvalid()                          {}
+func (n *noopHandlerStats) IncReceiptsRequest()                                 {}
+func (n *noopHandlerStats) IncMissingReceipts()                                 {}
+func (n *noopHandlerStats) IncTooManyReceiptsRequested()                        {}
+func (n *noopHandlerStats) UpdateReceiptsReturned(uint16)                       {}
+func (n *noopHandlerStats) UpdateReceiptsRequestProcessingTime(time.Duration)   {}
+func (n *noopHandlerStats) IncThrottledPeer()                                   {}
+func (n *noopHandlerStats) IncThrottledTotal()                                  {}
+func (n *noopHandlerStats) IncThrottledNonValidator()                           {}
+func (n *noopHandlerStats) UpdateBytesServed(int)                               {}
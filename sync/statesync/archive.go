@@ -0,0 +1,515 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// A state sync archive holds everything a node fetches from peers during
+// state sync, so that nodes can be bootstrapped from a local file instead.
+//
+// The archive starts with [archiveMagic] and [archiveVersion], followed by a
+// sequence of records, each encoded as a one byte record type, the uvarint
+// length of the payload and the payload itself:
+//   - one summary record holding the codec encoded [message.SyncSummary]
+//   - block records holding the RLP encoded blocks, starting with the summary
+//     block and followed by its parents in descending order
+//   - code, account and storage records in account trie order. Each account
+//     record holds the account hash and the RLP encoded account, and is
+//     followed by the storage records (slot hash and value) of its storage
+//     trie. Storage tries and code shared by multiple accounts are included
+//     only once, the first time they are referenced. Code records precede the
+//     first account referencing them.
+//   - one end record holding the number of records of each type, so that
+//     truncated archives are detected.
+//
+// Nothing in the archive is trusted except the choice of summary: blocks must
+// form a hash chain from the summary block, and the tries are rebuilt and
+// their roots checked against the summary and account roots, the same way the
+// network syncer verifies data received from peers.
+const (
+	archiveVersion = uint16(1)
+
+	// maxArchiveRecordSize bounds the payload of a single record, which is at
+	// most a block and is expected to be far below this limit.
+	maxArchiveRecordSize = 64 * 1024 * 1024
+)
+
+// archive record types
+const (
+	archiveSummaryRecord byte = iota + 1
+	archiveBlockRecord
+	archiveCodeRecord
+	archiveAccountRecord
+	archiveStorageRecord
+	archiveEndRecord
+)
+
+var (
+	archiveMagic = []byte("subnet-evm-state-sync-archive")
+
+	errInvalidArchive       = errors.New("invalid state sync archive")
+	errArchiveRootMismatch  = errors.New("state sync archive root mismatch")
+	errArchiveBlockMismatch = errors.New("state sync archive block mismatch")
+)
+
+// archiveCounts is the payload of the end record
+type archiveCounts struct {
+	Blocks   uint64
+	Codes    uint64
+	Accounts uint64
+	Slots    uint64
+}
+
+type archiveWriter struct {
+	w      *bufio.Writer
+	counts archiveCounts
+}
+
+func (a *archiveWriter) writeRecord(recordType byte, payload ...[]byte) error {
+	size := 0
+	for _, p := range payload {
+		size += len(p)
+	}
+	if err := a.w.WriteByte(recordType); err != nil {
+		return err
+	}
+	if _, err := a.w.Write(binary.AppendUvarint(nil, uint64(size))); err != nil {
+		return err
+	}
+	for _, p := range payload {
+		if _, err := a.w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportArchive writes a state sync archive for [summary] to [w], including
+// [blocks] (the summary block followed by its parents in descending order),
+// the state trie at the summary root from [trieDB] and the code it references
+// from [codeReader].
+func ExportArchive(ctx context.Context, w io.Writer, summary message.SyncSummary, blocks []*types.Block, trieDB *trie.Database, codeReader ethdb.KeyValueReader) error {
+	if len(blocks) == 0 || blocks[0].Hash() != summary.BlockHash {
+		return fmt.Errorf("%w: first block must be the summary block %s", errArchiveBlockMismatch, summary.BlockHash)
+	}
+	archive := &archiveWriter{w: bufio.NewWriter(w)}
+	if _, err := archive.w.Write(archiveMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(archive.w, binary.BigEndian, archiveVersion); err != nil {
+		return err
+	}
+	if err := archive.writeRecord(archiveSummaryRecord, summary.Bytes()); err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		blockBytes, err := rlp.EncodeToBytes(block)
+		if err != nil {
+			return err
+		}
+		if err := archive.writeRecord(archiveBlockRecord, blockBytes); err != nil {
+			return err
+		}
+		archive.counts.Blocks++
+	}
+
+	accountTrie, err := trie.New(trie.StateTrieID(summary.BlockRoot), trieDB)
+	if err != nil {
+		return err
+	}
+	nodeIt, err := accountTrie.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	var (
+		it            = trie.NewIterator(nodeIt)
+		exportedCode  = make(map[common.Hash]struct{})
+		exportedRoots = make(map[common.Hash]struct{})
+	)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(it.Value, &acc); err != nil {
+			return err
+		}
+
+		codeHash := common.BytesToHash(acc.CodeHash)
+		if _, exported := exportedCode[codeHash]; codeHash != types.EmptyCodeHash && !exported {
+			code := rawdb.ReadCode(codeReader, codeHash)
+			if len(code) == 0 {
+				return fmt.Errorf("missing code %s of account %x", codeHash, it.Key)
+			}
+			if err := archive.writeRecord(archiveCodeRecord, code); err != nil {
+				return err
+			}
+			exportedCode[codeHash] = struct{}{}
+			archive.counts.Codes++
+		}
+
+		if err := archive.writeRecord(archiveAccountRecord, it.Key, it.Value); err != nil {
+			return err
+		}
+		archive.counts.Accounts++
+
+		if _, exported := exportedRoots[acc.Root]; acc.Root == types.EmptyRootHash || exported {
+			continue
+		}
+		if err := archive.exportStorage(ctx, trieDB, summary.BlockRoot, common.BytesToHash(it.Key), acc.Root); err != nil {
+			return err
+		}
+		exportedRoots[acc.Root] = struct{}{}
+	}
+	if it.Err != nil {
+		return it.Err
+	}
+
+	counts, err := rlp.EncodeToBytes(&archive.counts)
+	if err != nil {
+		return err
+	}
+	if err := archive.writeRecord(archiveEndRecord, counts); err != nil {
+		return err
+	}
+	log.Info("exported state sync archive", "root", summary.BlockRoot, "height", summary.BlockNumber, "blocks", archive.counts.Blocks, "codes", archive.counts.Codes, "accounts", archive.counts.Accounts, "slots", archive.counts.Slots)
+	return archive.w.Flush()
+}
+
+func (a *archiveWriter) exportStorage(ctx context.Context, trieDB *trie.Database, stateRoot, accountHash, root common.Hash) error {
+	storageTrie, err := trie.New(trie.StorageTrieID(stateRoot, accountHash, root), trieDB)
+	if err != nil {
+		return err
+	}
+	nodeIt, err := storageTrie.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	it := trie.NewIterator(nodeIt)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := a.writeRecord(archiveStorageRecord, it.Key, it.Value); err != nil {
+			return err
+		}
+		a.counts.Slots++
+	}
+	return it.Err
+}
+
+// ArchiveReader reads a state sync archive
+type ArchiveReader struct {
+	r       *bufio.Reader
+	summary message.SyncSummary
+}
+
+// NewArchiveReader reads the header and summary of the state sync archive in [r]
+func NewArchiveReader(r io.Reader) (*ArchiveReader, error) {
+	archive := &ArchiveReader{r: bufio.NewReader(r)}
+	header := make([]byte, len(archiveMagic))
+	if _, err := io.ReadFull(archive.r, header); err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %w", errInvalidArchive, err)
+	}
+	if !bytes.Equal(header, archiveMagic) {
+		return nil, fmt.Errorf("%w: unexpected header %q", errInvalidArchive, header)
+	}
+	var version uint16
+	if err := binary.Read(archive.r, binary.BigEndian, &version); err != nil {
+		return nil, fmt.Errorf("%w: failed to read version: %w", errInvalidArchive, err)
+	}
+	if version != archiveVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", errInvalidArchive, version)
+	}
+
+	recordType, payload, err := archive.readRecord()
+	if err != nil {
+		return nil, err
+	}
+	if recordType != archiveSummaryRecord {
+		return nil, fmt.Errorf("%w: expected summary record, got record type %d", errInvalidArchive, recordType)
+	}
+	archive.summary, err = message.NewSyncSummaryFromBytes(payload, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse summary: %w", errInvalidArchive, err)
+	}
+	return archive, nil
+}
+
+// Summary returns the summary the archive was exported for
+func (a *ArchiveReader) Summary() message.SyncSummary {
+	return a.summary
+}
+
+func (a *ArchiveReader) readRecord() (byte, []byte, error) {
+	recordType, err := a.r.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: failed to read record type: %w", errInvalidArchive, err)
+	}
+	size, err := binary.ReadUvarint(a.r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: failed to read record size: %w", errInvalidArchive, err)
+	}
+	if size > maxArchiveRecordSize {
+		return 0, nil, fmt.Errorf("%w: record size %d exceeds maximum %d", errInvalidArchive, size, maxArchiveRecordSize)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(a.r, payload); err != nil {
+		return 0, nil, fmt.Errorf("%w: failed to read record: %w", errInvalidArchive, err)
+	}
+	return recordType, payload, nil
+}
+
+// archiveImport keeps the state of importing an archive into a database
+type archiveImport struct {
+	summary   message.SyncSummary
+	db        ethdb.Database
+	batch     ethdb.Batch
+	batchSize int
+	trieDB    *trie.Database
+
+	counts archiveCounts
+
+	// last block imported, the next block must be its parent
+	lastBlock *types.Block
+
+	accountTrie  *trie.StackTrie
+	lastAccount  common.Hash
+	importedCode map[common.Hash]struct{}
+
+	// storage trie of the account currently being imported, if any
+	storageAccount common.Hash
+	storageRoot    common.Hash
+	storageTrie    *trie.StackTrie
+	lastSlot       []byte
+	importedRoots  map[common.Hash]struct{}
+}
+
+// Import writes the blocks and state in the archive to [db], verifying the
+// blocks form a hash chain from the summary block and the rebuilt tries match
+// the summary root. Batches are written when they exceed [batchSize].
+func (a *ArchiveReader) Import(ctx context.Context, db ethdb.Database, batchSize int) error {
+	batch := db.NewBatch()
+	imp := &archiveImport{
+		summary:       a.summary,
+		db:            db,
+		batch:         batch,
+		batchSize:     batchSize,
+		trieDB:        trie.NewDatabase(db, nil),
+		importedCode:  make(map[common.Hash]struct{}),
+		importedRoots: make(map[common.Hash]struct{}),
+	}
+	// TODO: migrate state sync to use database schemes.
+	imp.accountTrie = trie.NewStackTrie(&trie.StackTrieOptions{
+		Writer: func(path []byte, hash common.Hash, blob []byte) {
+			rawdb.WriteTrieNode(batch, common.Hash{}, path, hash, blob, rawdb.HashScheme)
+		},
+	})
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		recordType, payload, err := a.readRecord()
+		if err != nil {
+			return err
+		}
+		if recordType != archiveStorageRecord {
+			if err := imp.finishStorage(); err != nil {
+				return err
+			}
+		}
+
+		switch recordType {
+		case archiveBlockRecord:
+			err = imp.importBlock(payload)
+		case archiveCodeRecord:
+			err = imp.importCode(payload)
+		case archiveAccountRecord:
+			err = imp.importAccount(payload)
+		case archiveStorageRecord:
+			err = imp.importSlot(payload)
+		case archiveEndRecord:
+			return imp.finish(payload)
+		default:
+			err = fmt.Errorf("%w: unexpected record type %d", errInvalidArchive, recordType)
+		}
+		if err != nil {
+			return err
+		}
+		if imp.batch.ValueSize() > imp.batchSize {
+			if err := imp.batch.Write(); err != nil {
+				return err
+			}
+			imp.batch.Reset()
+		}
+	}
+}
+
+func (imp *archiveImport) importBlock(payload []byte) error {
+	if imp.counts.Codes+imp.counts.Accounts > 0 {
+		return fmt.Errorf("%w: unexpected block record after state records", errInvalidArchive)
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(payload, block); err != nil {
+		return fmt.Errorf("%w: failed to decode block: %w", errInvalidArchive, err)
+	}
+	if imp.lastBlock == nil {
+		if block.Hash() != imp.summary.BlockHash || block.NumberU64() != imp.summary.BlockNumber {
+			return fmt.Errorf("%w: (got block %s at height %d) (expected summary block %s at height %d)", errArchiveBlockMismatch, block.Hash(), block.NumberU64(), imp.summary.BlockHash, imp.summary.BlockNumber)
+		}
+		if block.Root() != imp.summary.BlockRoot {
+			return fmt.Errorf("%w: (got block root %s) (expected summary root %s)", errArchiveBlockMismatch, block.Root(), imp.summary.BlockRoot)
+		}
+	} else if block.Hash() != imp.lastBlock.ParentHash() {
+		return fmt.Errorf("%w: (got block %s) (expected parent %s of block %s)", errArchiveBlockMismatch, block.Hash(), imp.lastBlock.ParentHash(), imp.lastBlock.Hash())
+	}
+	rawdb.WriteBlock(imp.batch, block)
+	rawdb.WriteCanonicalHash(imp.batch, block.Hash(), block.NumberU64())
+	imp.lastBlock = block
+	imp.counts.Blocks++
+	return nil
+}
+
+func (imp *archiveImport) importCode(code []byte) error {
+	codeHash := crypto.Keccak256Hash(code)
+	rawdb.WriteCode(imp.batch, codeHash, code)
+	imp.importedCode[codeHash] = struct{}{}
+	imp.counts.Codes++
+	return nil
+}
+
+func (imp *archiveImport) importAccount(payload []byte) error {
+	if imp.lastBlock == nil {
+		return fmt.Errorf("%w: missing summary block", errInvalidArchive)
+	}
+	if len(payload) <= common.HashLength {
+		return fmt.Errorf("%w: account record too short (%d bytes)", errInvalidArchive, len(payload))
+	}
+	accountHash := common.BytesToHash(payload[:common.HashLength])
+	if imp.counts.Accounts > 0 && bytes.Compare(accountHash[:], imp.lastAccount[:]) <= 0 {
+		return fmt.Errorf("%w: account %s out of order", errInvalidArchive, accountHash)
+	}
+	value := payload[common.HashLength:]
+	var acc types.StateAccount
+	if err := rlp.DecodeBytes(value, &acc); err != nil {
+		return fmt.Errorf("%w: failed to decode account %s: %w", errInvalidArchive, accountHash, err)
+	}
+	if codeHash := common.BytesToHash(acc.CodeHash); codeHash != types.EmptyCodeHash {
+		if _, ok := imp.importedCode[codeHash]; !ok {
+			return fmt.Errorf("%w: missing code %s of account %s", errInvalidArchive, codeHash, accountHash)
+		}
+	}
+	if err := imp.accountTrie.Update(accountHash[:], value); err != nil {
+		return err
+	}
+	writeAccountSnapshot(imp.batch, accountHash, acc)
+	imp.lastAccount = accountHash
+	imp.counts.Accounts++
+
+	if acc.Root == types.EmptyRootHash {
+		return nil
+	}
+	if _, imported := imp.importedRoots[acc.Root]; imported {
+		// The storage trie was imported for a previous account, copy its
+		// snapshot from the trie after flushing the batch holding its nodes.
+		if err := imp.batch.Write(); err != nil {
+			return err
+		}
+		imp.batch.Reset()
+		// The account trie is not committed yet, so the storage trie is
+		// opened by its own root.
+		storageTrie, err := trie.New(trie.TrieID(acc.Root), imp.trieDB)
+		if err != nil {
+			return err
+		}
+		if err := writeAccountStorageSnapshotFromTrie(imp.batch, imp.batchSize, accountHash, storageTrie); err != nil {
+			return err
+		}
+		imp.batch.Reset()
+		return nil
+	}
+	batch := imp.batch
+	imp.storageAccount = accountHash
+	imp.storageRoot = acc.Root
+	imp.lastSlot = nil
+	imp.storageTrie = trie.NewStackTrie(&trie.StackTrieOptions{
+		Writer: func(path []byte, hash common.Hash, blob []byte) {
+			rawdb.WriteTrieNode(batch, accountHash, path, hash, blob, rawdb.HashScheme)
+		},
+	})
+	return nil
+}
+
+func (imp *archiveImport) importSlot(payload []byte) error {
+	if imp.storageTrie == nil {
+		return fmt.Errorf("%w: storage record without account with storage", errInvalidArchive)
+	}
+	if len(payload) <= common.HashLength {
+		return fmt.Errorf("%w: storage record too short (%d bytes)", errInvalidArchive, len(payload))
+	}
+	key, value := payload[:common.HashLength], payload[common.HashLength:]
+	if imp.lastSlot != nil && bytes.Compare(key, imp.lastSlot) <= 0 {
+		return fmt.Errorf("%w: storage slot %x of account %s out of order", errInvalidArchive, key, imp.storageAccount)
+	}
+	if err := imp.storageTrie.Update(key, value); err != nil {
+		return err
+	}
+	rawdb.WriteStorageSnapshot(imp.batch, imp.storageAccount, common.BytesToHash(key), value)
+	imp.lastSlot = key
+	imp.counts.Slots++
+	return nil
+}
+
+// finishStorage verifies the root of the storage trie being imported, if any
+func (imp *archiveImport) finishStorage() error {
+	if imp.storageTrie == nil {
+		return nil
+	}
+	if root := imp.storageTrie.Commit(); root != imp.storageRoot {
+		return fmt.Errorf("%w: storage of account %s (got %s) (expected %s)", errArchiveRootMismatch, imp.storageAccount, root, imp.storageRoot)
+	}
+	imp.importedRoots[imp.storageRoot] = struct{}{}
+	imp.storageTrie = nil
+	return nil
+}
+
+func (imp *archiveImport) finish(payload []byte) error {
+	var counts archiveCounts
+	if err := rlp.DecodeBytes(payload, &counts); err != nil {
+		return fmt.Errorf("%w: failed to decode end record: %w", errInvalidArchive, err)
+	}
+	if counts != imp.counts {
+		return fmt.Errorf("%w: (got %+v records) (expected %+v)", errInvalidArchive, imp.counts, counts)
+	}
+	if imp.lastBlock == nil {
+		return fmt.Errorf("%w: missing summary block", errInvalidArchive)
+	}
+	if root := imp.accountTrie.Commit(); root != imp.summary.BlockRoot {
+		return fmt.Errorf("%w: (got %s) (expected %s)", errArchiveRootMismatch, root, imp.summary.BlockRoot)
+	}
+	if err := imp.batch.Write(); err != nil {
+		return err
+	}
+	log.Info("imported state sync archive", "root", imp.summary.BlockRoot, "height", imp.summary.BlockNumber, "blocks", counts.Blocks, "codes", counts.Codes, "accounts", counts.Accounts, "slots", counts.Slots)
+	return nil
+}
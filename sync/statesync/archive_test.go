@@ -0,0 +1,167 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+// rewriteArchive returns a copy of [archive] with each record passed through
+// [rewrite], dropping records for which it returns false.
+func rewriteArchive(t *testing.T, archive []byte, rewrite func(recordType byte, payload []byte) ([]byte, bool)) []byte {
+	headerLen := len(archiveMagic) + 2
+	reader := &ArchiveReader{r: bufio.NewReader(bytes.NewReader(archive[headerLen:]))}
+
+	var out bytes.Buffer
+	out.Write(archive[:headerLen])
+	writer := &archiveWriter{w: bufio.NewWriter(&out)}
+	for {
+		recordType, payload, err := reader.readRecord()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if payload, keep := rewrite(recordType, payload); keep {
+			require.NoError(t, writer.writeRecord(recordType, payload))
+		}
+	}
+	require.NoError(t, writer.w.Flush())
+	return out.Bytes()
+}
+
+// rewriteNth rewrites the [n]th record of [recordType] with [rewrite]
+func rewriteNth(recordType byte, n int, rewrite func(payload []byte) ([]byte, bool)) func(byte, []byte) ([]byte, bool) {
+	seen := 0
+	return func(typ byte, payload []byte) ([]byte, bool) {
+		if typ != recordType {
+			return payload, true
+		}
+		seen++
+		if seen != n {
+			return payload, true
+		}
+		return rewrite(payload)
+	}
+}
+
+func TestStateSyncArchive(t *testing.T) {
+	rand.Seed(1)
+	serverDB := rawdb.NewMemoryDatabase()
+	serverTrieDB := trie.NewDatabase(serverDB, nil)
+	root, _ := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, 300, 3)
+	root = fillAccountsWithStorage(t, serverDB, serverTrieDB, root, 50)
+
+	var blocks []*types.Block
+	parentHash := common.Hash{}
+	for i := 0; i <= 10; i++ {
+		header := &types.Header{
+			ParentHash: parentHash,
+			Number:     big.NewInt(int64(i)),
+			Root:       root,
+		}
+		block := types.NewBlockWithHeader(header)
+		blocks = append([]*types.Block{block}, blocks...)
+		parentHash = block.Hash()
+	}
+	summary, err := message.NewSyncSummary(blocks[0].Hash(), blocks[0].NumberU64(), root)
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	require.NoError(t, ExportArchive(context.Background(), &archive, summary, blocks, serverTrieDB, serverDB))
+
+	t.Run("import", func(t *testing.T) {
+		reader, err := NewArchiveReader(bytes.NewReader(archive.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, summary.Bytes(), reader.Summary().Bytes())
+
+		clientDB := rawdb.NewMemoryDatabase()
+		// Use a low batch size to cover batches being written early.
+		require.NoError(t, reader.Import(context.Background(), clientDB, 1000))
+		assertDBConsistency(t, root, clientDB, serverTrieDB, trie.NewDatabase(clientDB, nil))
+		for _, block := range blocks {
+			require.Equal(t, block.Hash(), rawdb.ReadCanonicalHash(clientDB, block.NumberU64()))
+			require.NotNil(t, rawdb.ReadBlock(clientDB, block.Hash(), block.NumberU64()))
+		}
+	})
+
+	tests := map[string]struct {
+		rewrite     func(recordType byte, payload []byte) ([]byte, bool)
+		expectedErr error
+	}{
+		"modified account": {
+			rewrite: rewriteNth(archiveAccountRecord, 10, func(payload []byte) ([]byte, bool) {
+				var acc types.StateAccount
+				require.NoError(t, rlp.DecodeBytes(payload[common.HashLength:], &acc))
+				acc.Balance = new(big.Int).Add(acc.Balance, common.Big1)
+				value, err := rlp.EncodeToBytes(&acc)
+				require.NoError(t, err)
+				return append(payload[:common.HashLength:common.HashLength], value...), true
+			}),
+			expectedErr: errArchiveRootMismatch,
+		},
+		"missing account": {
+			rewrite: rewriteNth(archiveAccountRecord, 1, func(payload []byte) ([]byte, bool) {
+				return nil, false
+			}),
+			expectedErr: errInvalidArchive,
+		},
+		"modified storage slot": {
+			rewrite: rewriteNth(archiveStorageRecord, 5, func(payload []byte) ([]byte, bool) {
+				modified := common.CopyBytes(payload)
+				modified[len(modified)-1]++
+				return modified, true
+			}),
+			expectedErr: errArchiveRootMismatch,
+		},
+		"missing code": {
+			rewrite: rewriteNth(archiveCodeRecord, 1, func(payload []byte) ([]byte, bool) {
+				return nil, false
+			}),
+			expectedErr: errInvalidArchive,
+		},
+		"missing parent block": {
+			rewrite: rewriteNth(archiveBlockRecord, 2, func(payload []byte) ([]byte, bool) {
+				return nil, false
+			}),
+			expectedErr: errArchiveBlockMismatch,
+		},
+		"truncated": {
+			rewrite: func(recordType byte, payload []byte) ([]byte, bool) {
+				return payload, recordType != archiveEndRecord
+			},
+			expectedErr: errInvalidArchive,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			modified := rewriteArchive(t, archive.Bytes(), test.rewrite)
+			reader, err := NewArchiveReader(bytes.NewReader(modified))
+			require.NoError(t, err)
+			err = reader.Import(context.Background(), rawdb.NewMemoryDatabase(), 1000)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+
+	t.Run("invalid header", func(t *testing.T) {
+		modified := common.CopyBytes(archive.Bytes())
+		modified[0]++
+		_, err := NewArchiveReader(bytes.NewReader(modified))
+		require.ErrorIs(t, err, errInvalidArchive)
+	})
+}